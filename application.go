@@ -1,6 +1,10 @@
 package routest
 
 import (
+	"context"
+	"log"
+	"net/http"
+
 	"github.com/qatoolist/RouTest/internal/interfaces"
 	"github.com/qatoolist/RouTest/internal/loaders"
 	"github.com/qatoolist/RouTest/internal/models"
@@ -54,6 +58,12 @@ func NewApplication(meta string) interfaces.Application {
 		panic(err)
 	}
 
+	policy, err := config.GetRetryPolicy()
+	if err != nil {
+		panic(err)
+	}
+	app.SetRetryPolicy(policy)
+
 	return &application{app: app}
 }
 
@@ -101,3 +111,47 @@ func (a *application) GetApplicationHooksRegistry() interfaces.HooksRegistry {
 func (a *application) GetMeta() interfaces.Meta {
 	return a.app.GetMeta()
 }
+
+func (a *application) GetHost() interfaces.Host {
+	return a.app.GetHost()
+}
+
+func (a *application) GetRetryPolicy() interfaces.RetryPolicy {
+	return a.app.GetRetryPolicy()
+}
+
+func (a *application) SetRetryPolicy(policy interfaces.RetryPolicy) {
+	a.app.SetRetryPolicy(policy)
+}
+
+func (a *application) Run(ctx context.Context) interfaces.Report {
+	return a.app.Run(ctx)
+}
+
+func (a *application) GetVariables() interfaces.Variables {
+	return a.app.GetVariables()
+}
+
+func (a *application) GetLogger() *log.Logger {
+	return a.app.GetLogger()
+}
+
+func (a *application) SetLogger(logger *log.Logger) {
+	a.app.SetLogger(logger)
+}
+
+func (a *application) GetHTTPClient() *http.Client {
+	return a.app.GetHTTPClient()
+}
+
+func (a *application) SetHTTPClient(client *http.Client) {
+	a.app.SetHTTPClient(client)
+}
+
+func (a *application) GetSpecValidator() interfaces.SpecValidator {
+	return a.app.GetSpecValidator()
+}
+
+func (a *application) SetSpecValidator(validator interfaces.SpecValidator) {
+	a.app.SetSpecValidator(validator)
+}
@@ -0,0 +1,102 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/qatoolist/RouTest/formatters"
+	"github.com/qatoolist/RouTest/internal/compare"
+	"github.com/qatoolist/RouTest/internal/parser"
+	"github.com/spf13/cobra"
+)
+
+var (
+	compareEnv           string
+	compareAgainst       string
+	compareSelect        []string
+	compareIgnore        []string
+	compareIgnoreHeaders []string
+	compareTimeout       time.Duration
+)
+
+// CreateCompareCmd creates the compare subcommand.
+func CreateCompareCmd() cobra.Command {
+	compareCmd := cobra.Command{
+		Use:   "compare <suite.yaml> --env <env> --against <env>",
+		Short: "Run the scenarios of a suite against two environments and diff their responses",
+		Long: `Run the scenarios of a suite against two environments, one after the other,
+and report the differences in status, headers and JSON bodies of their responses.
+
+Every environment is configured by "<env>.json", "<env>.yaml" or "<env>.env"
+in the config directory of the suite. The body fields ignored by the snapshot
+assertion of a scenario are ignored too, as well as the fields given with
+--ignore and volatile headers such as Date.`,
+		Args: cobra.ExactArgs(1),
+		RunE: compareCmdRunFunc,
+	}
+
+	compareCmd.Flags().StringVar(&compareEnv, "env", "", "environment the differences are reported against")
+	compareCmd.Flags().StringVar(&compareAgainst, "against", "", "environment compared with --env")
+	compareCmd.Flags().StringSliceVar(&compareSelect, "select", nil, "route or route/scenario patterns to compare, e.g. users/*")
+	compareCmd.Flags().StringSliceVar(&compareIgnore, "ignore", nil, "JSONPath of a body field that is not compared, e.g. $..updated_at")
+	compareCmd.Flags().StringSliceVar(&compareIgnoreHeaders, "ignore-header", nil, "response header that is not compared, in addition to Date and similar")
+	compareCmd.Flags().DurationVar(&compareTimeout, "timeout", 0, "deadline of both runs, e.g. 5m (0 means no deadline)")
+	compareCmd.MarkFlagRequired("env")
+	compareCmd.MarkFlagRequired("against")
+
+	return compareCmd
+}
+
+func compareCmdRunFunc(cmd *cobra.Command, args []string) error {
+	suite, err := parser.ParseSuiteFile(args[0])
+	if err != nil {
+		return err
+	}
+	if err := suite.Select(compareSelect); err != nil {
+		return err
+	}
+	suite.SkipSnapshots = true
+
+	base, err := suite.BuildForEnvironment(compareEnv)
+	if err != nil {
+		return err
+	}
+	against, err := suite.BuildForEnvironment(compareAgainst)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if compareTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, compareTimeout)
+		defer cancel()
+	}
+
+	report, err := compare.Compare(ctx,
+		compare.Environment{Name: compareEnv, Application: base},
+		compare.Environment{Name: compareAgainst, Application: against},
+		compare.Options{Ignore: compareIgnore, IgnoreFor: suite.SnapshotIgnore, IgnoreHeaders: compareIgnoreHeaders})
+	if err != nil {
+		return err
+	}
+
+	if err := formatters.NewTextFormatter(os.Stdout).FormatComparison(report); err != nil {
+		return err
+	}
+
+	if report.Interrupted != nil {
+		return fmt.Errorf("run interrupted: %v", report.Interrupted)
+	}
+	if report.Different() > 0 {
+		return errors.New("the environments differ")
+	}
+	return nil
+}
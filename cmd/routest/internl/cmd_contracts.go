@@ -0,0 +1,168 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/qatoolist/RouTest/formatters"
+	"github.com/qatoolist/RouTest/internal/contract"
+	"github.com/qatoolist/RouTest/internal/parser"
+	"github.com/spf13/cobra"
+)
+
+var (
+	publishConsumer string
+	publishProvider string
+	publishDir      string
+	publishEnv      string
+	publishSelect   []string
+
+	verifyBaseURL  string
+	verifyProvider string
+	verifyHeaders  []string
+	verifyTimeout  time.Duration
+)
+
+// CreatePublishContractsCmd creates the publish-contracts subcommand.
+func CreatePublishContractsCmd() cobra.Command {
+	publishCmd := cobra.Command{
+		Use:   "publish-contracts <suite.yaml>",
+		Short: "Publish the contract of a consumer from the scenarios of its suite",
+		Long: `Run the scenarios of a consumer suite and write the contract it expects
+from a provider to <dir>/<provider>/<consumer>.yaml: the request of every
+scenario and the response fields it relies on.
+
+The fields of a scenario are declared with its contract key:
+
+  contract:
+    fields: [$.id, $.items[*].name]   # matched by type
+    values: [$.status]                # matched exactly
+
+Without it, every field of the response body is matched by type, and
+'contract: false' leaves the scenario out. Every scenario must pass for
+the contract to be written. Credentials such as Authorization and
+Cookie headers are never written to contracts.`,
+		Args: cobra.ExactArgs(1),
+		RunE: publishContractsCmdRunFunc,
+	}
+
+	publishCmd.Flags().StringVar(&publishConsumer, "consumer", "", "name of the consumer publishing the contract (required)")
+	publishCmd.Flags().StringVar(&publishProvider, "provider", "", "name of the provider the contract is expected from (required)")
+	publishCmd.Flags().StringVar(&publishDir, "dir", "contracts", "directory of the contracts")
+	publishCmd.Flags().StringVar(&publishEnv, "env", "", "environment whose configuration is loaded from the config directory of the suite")
+	publishCmd.Flags().StringSliceVar(&publishSelect, "select", nil, "route or route/scenario patterns to publish, e.g. users/*")
+	publishCmd.MarkFlagRequired("consumer")
+	publishCmd.MarkFlagRequired("provider")
+
+	return publishCmd
+}
+
+func publishContractsCmdRunFunc(cmd *cobra.Command, args []string) error {
+	suite, err := parser.ParseSuiteFile(args[0])
+	if err != nil {
+		return err
+	}
+	if err := suite.Select(publishSelect); err != nil {
+		return err
+	}
+
+	app, err := suite.BuildForEnvironment(publishEnv)
+	if err != nil {
+		return err
+	}
+	capture := contract.NewCapture(app.GetHTTPClient().Transport)
+	app.SetHTTPClient(&http.Client{Transport: capture})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	report := app.Run(ctx)
+	if err := formatters.NewTextFormatter(os.Stdout).Format(report); err != nil {
+		return err
+	}
+	if report.Interrupted() != nil {
+		return fmt.Errorf("run interrupted: %v, no contract written", report.Interrupted())
+	}
+	if len(report.Errors()) > 0 {
+		return errors.New("some hooks failed, no contract written")
+	}
+
+	c, err := capture.Build(report, contract.Options{
+		Consumer: publishConsumer,
+		Provider: publishProvider,
+		Fields:   suite.ContractFields,
+	})
+	if err != nil {
+		return err
+	}
+	if err := c.Save(publishDir); err != nil {
+		return err
+	}
+	fmt.Printf("\ncontract with %d interactions written to %s\n", len(c.Interactions), contract.PathFor(publishDir, c.Provider, c.Consumer))
+	return nil
+}
+
+// CreateVerifyContractsCmd creates the verify-contracts subcommand.
+func CreateVerifyContractsCmd() cobra.Command {
+	verifyCmd := cobra.Command{
+		Use:   "verify-contracts <dir>",
+		Short: "Verify the contracts of the consumers against a provider",
+		Long: `Send the request of every interaction of the contracts in the directory
+to the provider at --base-url, and check that the status, headers and
+response fields every consumer relies on are there.
+
+Every failed expectation is listed with the consumer and the scenario
+it comes from. Use --header to add the credentials contracts do not
+carry, e.g. --header 'Authorization: Bearer ...'.`,
+		Args: cobra.ExactArgs(1),
+		RunE: verifyContractsCmdRunFunc,
+	}
+
+	verifyCmd.Flags().StringVar(&verifyBaseURL, "base-url", "", "URL of the provider, e.g. http://localhost:8080 (required)")
+	verifyCmd.Flags().StringVar(&verifyProvider, "provider", "", "verify only the contracts of this provider")
+	verifyCmd.Flags().StringArrayVar(&verifyHeaders, "header", nil, "header added to every request, as 'Name: value'")
+	verifyCmd.Flags().DurationVar(&verifyTimeout, "timeout", 30*time.Second, "deadline of every request")
+	verifyCmd.MarkFlagRequired("base-url")
+
+	return verifyCmd
+}
+
+func verifyContractsCmdRunFunc(cmd *cobra.Command, args []string) error {
+	headers := make(map[string]string)
+	for _, header := range verifyHeaders {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok {
+			return fmt.Errorf("invalid header %q, expected 'Name: value'", header)
+		}
+		headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	report, err := contract.Verify(ctx, args[0], contract.VerifyOptions{
+		BaseURL:  verifyBaseURL,
+		Provider: verifyProvider,
+		Headers:  headers,
+		Client:   &http.Client{Timeout: verifyTimeout},
+	})
+	if report != nil {
+		if err := formatters.NewTextFormatter(os.Stdout).FormatVerification(report); err != nil {
+			return err
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if report.Failed() > 0 {
+		return errors.New("some contracts are not met")
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/qatoolist/RouTest/internal/export"
+	"github.com/qatoolist/RouTest/internal/interfaces"
+	"github.com/qatoolist/RouTest/internal/models"
+	"github.com/qatoolist/RouTest/internal/parser"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportScenario string
+	exportRoutes   []string
+	exportOutput   string
+	exportEnv      string
+	exportReveal   bool
+)
+
+// CreateExportCmd creates the export subcommand, rendering the requests of scenarios to replay them by hand.
+func CreateExportCmd() cobra.Command {
+	exportCmd := cobra.Command{
+		Use:   "export",
+		Short: "Render the requests of scenarios as cURL commands or .http files",
+		Long: `Render the requests of scenarios to replay them by hand. The requests are
+resolved as the run builds them, with the parameters of the application,
+route and scenario merged and the {{name}} references of the data rows
+and configuration expanded, but before any hook runs: references to
+values captured by hooks or earlier scenarios are left as they are.
+
+Headers, query parameters and body fields carrying secrets, such as
+Authorization, cookies, tokens and passwords, are masked unless --reveal
+is passed.`,
+	}
+	exportCmd.PersistentFlags().StringVar(&exportEnv, "env", "", "environment whose configuration is loaded from the config directory of the suite")
+	exportCmd.PersistentFlags().BoolVar(&exportReveal, "reveal", false, "render the secrets instead of masking them")
+
+	curlCmd := cobra.Command{
+		Use:   "curl <suite.yaml>",
+		Short: "Print the request of a scenario as a cURL command",
+		Example: `  routest export curl suites/users.yaml --scenario "get one"
+  routest export curl suites/users.yaml --scenario 'users/*' --env staging`,
+		Args: cobra.ExactArgs(1),
+		RunE: exportCurlCmdRunFunc,
+	}
+	curlCmd.Flags().StringVar(&exportScenario, "scenario", "", "name of the scenario, or route/scenario pattern, e.g. users/* (required)")
+	curlCmd.MarkFlagRequired("scenario")
+
+	httpCmd := cobra.Command{
+		Use:   "http <suite.yaml>",
+		Short: "Write the requests of whole routes to a .http file",
+		Long: `Write the requests of every scenario of the routes to a file in the .http
+format of the REST clients of VS Code and JetBrains IDEs, one request per
+scenario named route/scenario.`,
+		Example: `  routest export http suites/users.yaml --route users -o users.http`,
+		Args:    cobra.ExactArgs(1),
+		RunE:    exportHTTPCmdRunFunc,
+	}
+	httpCmd.Flags().StringSliceVar(&exportRoutes, "route", nil, "route name patterns to export, every route by default, repeatable")
+	httpCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "path of the file written, the standard output by default")
+
+	exportCmd.AddCommand(&curlCmd, &httpCmd)
+	return exportCmd
+}
+
+func exportCurlCmdRunFunc(cmd *cobra.Command, args []string) error {
+	requests, err := exportRequests(args[0], func(route, scenario string) bool {
+		return scenario == exportScenario || parser.MatchAny([]string{exportScenario}, scenario) || parser.MatchAny([]string{exportScenario}, route+"/"+scenario)
+	})
+	if err != nil {
+		return err
+	}
+	if len(requests) == 0 {
+		return fmt.Errorf("no scenario matches %s", exportScenario)
+	}
+
+	for _, r := range requests {
+		if len(requests) > 1 {
+			fmt.Printf("# %s\n", r.Name)
+		}
+		fmt.Println(export.Curl(r.Request, export.Options{Reveal: exportReveal}))
+	}
+	return nil
+}
+
+func exportHTTPCmdRunFunc(cmd *cobra.Command, args []string) error {
+	requests, err := exportRequests(args[0], func(route, scenario string) bool {
+		return len(exportRoutes) == 0 || parser.MatchAny(exportRoutes, route)
+	})
+	if err != nil {
+		return err
+	}
+	if len(requests) == 0 {
+		return fmt.Errorf("no route matches %s", strings.Join(exportRoutes, ", "))
+	}
+
+	out := os.Stdout
+	if exportOutput != "" {
+		if out, err = os.Create(exportOutput); err != nil {
+			return err
+		}
+		defer out.Close()
+	}
+	if err := export.HTTP(out, requests, export.Options{Reveal: exportReveal}); err != nil {
+		return err
+	}
+	if exportOutput != "" {
+		fmt.Printf("%d requests written to %s\n", len(requests), exportOutput)
+	}
+	return nil
+}
+
+// exportRequests resolves the requests of the scenarios of the suite selected by the match function,
+// one per row of data-driven scenarios, in the order of the suite.
+func exportRequests(suitePath string, match func(route, scenario string) bool) ([]export.Request, error) {
+	suite, err := parser.ParseSuiteFile(suitePath)
+	if err != nil {
+		return nil, err
+	}
+	app, err := suite.BuildForEnvironment(exportEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	var requests []export.Request
+	for _, route := range models.Routes(app.RouteRegistry) {
+		for _, scenario := range *route.GetScenarioRegistry().GetScenarios() {
+			if !match(route.GetName(), scenario.GetName()) {
+				continue
+			}
+			executions := []interfaces.Scenario{scenario}
+			if dataset := scenario.GetDataset(); dataset != nil {
+				executions = executions[:0]
+				for i := 0; i < dataset.Len(); i++ {
+					executions = append(executions, models.NewDataRowScenario(scenario, i))
+				}
+			}
+			for _, execution := range executions {
+				req, err := models.ResolveRequest(context.Background(), execution)
+				if err != nil {
+					return nil, fmt.Errorf("%s/%s: %v", route.GetName(), execution.GetName(), err)
+				}
+				requests = append(requests, export.Request{Name: route.GetName() + "/" + execution.GetName(), Request: req})
+			}
+		}
+	}
+	return requests, nil
+}
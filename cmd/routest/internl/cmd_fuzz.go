@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/qatoolist/RouTest/formatters"
+	"github.com/qatoolist/RouTest/internal/fuzz"
+	"github.com/qatoolist/RouTest/internal/interfaces"
+	"github.com/qatoolist/RouTest/internal/models"
+	"github.com/qatoolist/RouTest/internal/parser"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fuzzTimeout time.Duration
+	fuzzEnv     string
+	fuzzRoutes  []string
+	fuzzKeep    bool
+	fuzzFrom    string
+)
+
+// CreateFuzzCmd creates the fuzz subcommand.
+func CreateFuzzCmd() cobra.Command {
+	fuzzCmd := cobra.Command{
+		Use:   "fuzz <suite.yaml>",
+		Short: "Send invalid payloads derived from the request schemas of a suite",
+		Long: `Generate negative scenarios from the request schema of every route of
+the suite and run them: payloads missing a required field, with a value
+of the wrong type, a number out of range, a string longer than its
+maxLength, a value outside its enum, an additional property where
+additionalProperties is false, and malformed JSON.
+
+Every payload must be rejected with a 4xx status; a 2xx or a 5xx fails
+its scenario. The generated scenarios are tagged negative, and take the
+path variables, query parameters and headers of the first scenario of
+their route, or of the one named by --scenario. The scenarios of the
+suite itself are only run with --keep-scenarios.`,
+		Args: cobra.ExactArgs(1),
+		RunE: fuzzCmdRunFunc,
+	}
+
+	fuzzCmd.Flags().DurationVar(&fuzzTimeout, "timeout", 0, "deadline of the whole run, e.g. 5m (0 means no deadline)")
+	fuzzCmd.Flags().StringVar(&fuzzEnv, "env", "", "environment whose configuration is loaded from the config directory of the suite")
+	fuzzCmd.Flags().StringSliceVar(&fuzzRoutes, "route", nil, "route name patterns to fuzz, e.g. users-* (default all)")
+	fuzzCmd.Flags().BoolVar(&fuzzKeep, "keep-scenarios", false, "also run the scenarios of the suite")
+	fuzzCmd.Flags().StringVar(&fuzzFrom, "scenario", "", "scenario whose parameters the generated scenarios take, when the route has one (default the first)")
+
+	return fuzzCmd
+}
+
+func fuzzCmdRunFunc(cmd *cobra.Command, args []string) error {
+	suite, err := parser.ParseSuiteFile(args[0])
+	if err != nil {
+		return err
+	}
+	suite.SkipSnapshots = !fuzzKeep
+
+	app, err := suite.BuildForEnvironment(fuzzEnv)
+	if err != nil {
+		return err
+	}
+
+	generated := 0
+	for _, route := range models.Routes(app.RouteRegistry) {
+		template := templateScenario(route)
+		if !fuzzKeep {
+			if r, ok := route.(*models.Route); ok {
+				r.ScenarioRegistry = models.NewScenarioRegistry()
+			}
+		}
+		if len(fuzzRoutes) > 0 && !parser.MatchAny(fuzzRoutes, route.GetName()) {
+			continue
+		}
+		scenarios, err := fuzz.AddScenarios(route, template)
+		if err != nil {
+			return err
+		}
+		generated += len(scenarios)
+	}
+	if generated == 0 {
+		return errors.New("no selected route has a request schema to derive invalid payloads from")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if fuzzTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, fuzzTimeout)
+		defer cancel()
+	}
+
+	report := app.Run(ctx)
+
+	if err := formatters.NewTextFormatter(os.Stdout).Format(report); err != nil {
+		return err
+	}
+
+	if report.Interrupted() != nil {
+		return fmt.Errorf("run interrupted: %v", report.Interrupted())
+	}
+	if report.Failed() > 0 || len(report.Errors()) > 0 {
+		return errors.New("some scenarios failed")
+	}
+	return nil
+}
+
+// templateScenario returns the scenario of the route named by --scenario, or its first scenario, or nil if it has none.
+func templateScenario(route interfaces.Route) interfaces.Scenario {
+	scenarios := *route.GetScenarioRegistry().GetScenarios()
+	for _, scenario := range scenarios {
+		if fuzzFrom != "" && scenario.GetName() == fuzzFrom {
+			return scenario
+		}
+	}
+	if len(scenarios) == 0 {
+		return nil
+	}
+	return scenarios[0]
+}
@@ -0,0 +1,188 @@
+package internal
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/qatoolist/RouTest/colors"
+	"github.com/qatoolist/RouTest/internal/importer"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importOutput       string
+	importEnvironment  string
+	importHostFilter   []string
+	importSnapshot     bool
+	importAllowHeaders []string
+	importDenyHeaders  []string
+)
+
+// CreateImportCmd creates the import subcommand, converting the assets of other API tools to suites.
+func CreateImportCmd() cobra.Command {
+	importCmd := cobra.Command{
+		Use:   "import",
+		Short: "Convert the assets of other API tools to suites",
+	}
+	importCmd.PersistentFlags().StringVarP(&importOutput, "output", "o", "", "path of the suite written, by default named after the input in the current directory")
+
+	postmanCmd := cobra.Command{
+		Use:   "postman <collection.json>",
+		Short: "Convert a Postman collection and environment to a suite",
+		Long: `Convert a Postman collection, exported in the v2.1 format, to a suite.
+
+Requests sharing a method and a path become the scenarios of one route,
+and their folders become the component and tags of the route. The
+variables of the collection and of the --env environment are written to
+config/<environment>.yaml next to the suite, with the host when it comes
+from a variable: run the suite with --env <environment>.
+
+Auth blocks become headers or query parameters, and the status checks of
+test scripts become expectations. Scripts are copied to TODO comments in
+the suite, to be rewritten as hooks, and everything else that could not
+be converted is listed at the end.`,
+		Args: cobra.ExactArgs(1),
+		RunE: importPostmanCmdRunFunc,
+	}
+	postmanCmd.Flags().StringVar(&importEnvironment, "env", "", "Postman environment export whose variables are written to the configuration")
+
+	harCmd := cobra.Command{
+		Use:   "har <file.har>",
+		Short: "Convert the requests of a HAR file to a suite",
+		Long: `Convert the requests recorded in a HAR file, e.g. exported from the
+network tab of a browser, to a suite.
+
+Requests sharing a method and a path become the scenarios of one route,
+named after their position in the file. Their query parameters, headers
+and bodies are kept, and the recorded responses become the expected
+status of the scenarios and, with --snapshot, their snapshot golden files.
+
+Headers set by the browser and credentials are left out, see
+--allow-header and --deny-header.`,
+		Args: cobra.ExactArgs(1),
+		RunE: importHARCmdRunFunc,
+	}
+	harCmd.Flags().StringSliceVar(&importHostFilter, "host-filter", nil, "only import the requests sent to this host or its subdomains, repeatable")
+	harCmd.Flags().BoolVar(&importSnapshot, "snapshot", false, "assert the bodies of the responses against the recorded ones")
+
+	curlCmd := cobra.Command{
+		Use:   "curl <command>",
+		Short: "Convert a cURL command to a suite",
+		Long: `Convert a cURL command, e.g. copied from the network tab of a browser,
+to a suite with one scenario. Pass the command as a single quoted argument,
+after -- as separate arguments, or - to read it from the standard input.`,
+		Example: `  routest import curl "curl -X POST https://api.example.com/users -H 'Content-Type: application/json' -d '{\"name\":\"bob\"}'"
+  routest import curl -o users.yaml -- curl https://api.example.com/users -H 'Accept: application/json'`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: importCurlCmdRunFunc,
+	}
+
+	for _, cmd := range []*cobra.Command{&harCmd, &curlCmd} {
+		cmd.Flags().StringSliceVar(&importAllowHeaders, "allow-header", nil, "only keep these request headers, a trailing * matches any suffix, repeatable")
+		cmd.Flags().StringSliceVar(&importDenyHeaders, "deny-header", nil, "leave these request headers out, in addition to the headers set by clients and the credentials, repeatable")
+	}
+
+	importCmd.AddCommand(&postmanCmd, &harCmd, &curlCmd)
+	return importCmd
+}
+
+func importPostmanCmdRunFunc(cmd *cobra.Command, args []string) error {
+	collection, err := ioutil.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	var environment []byte
+	if importEnvironment != "" {
+		if environment, err = ioutil.ReadFile(importEnvironment); err != nil {
+			return err
+		}
+	}
+
+	result, err := importer.Postman(collection, environment)
+	if err != nil {
+		return err
+	}
+	return writeImport(result, args[0])
+}
+
+func importHARCmdRunFunc(cmd *cobra.Command, args []string) error {
+	data, err := ioutil.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	requests, err := importer.HAR(data, importHostFilter)
+	if err != nil {
+		return err
+	}
+
+	result, err := importer.FromRequests(requests, importRequestOptions())
+	if err != nil {
+		return err
+	}
+	return writeImport(result, args[0])
+}
+
+func importCurlCmdRunFunc(cmd *cobra.Command, args []string) error {
+	if len(args) == 1 && args[0] == "-" {
+		command, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		args = []string{string(command)}
+	}
+	req, warnings, err := importer.Curl(args)
+	if err != nil {
+		return err
+	}
+
+	result, err := importer.FromRequests([]importer.Request{req}, importRequestOptions())
+	if err != nil {
+		return err
+	}
+	result.Warnings = append(warnings, result.Warnings...)
+	return writeImport(result, "curl")
+}
+
+// importRequestOptions returns the options of the conversion of captured requests set by the flags.
+func importRequestOptions() importer.RequestOptions {
+	opts := importer.RequestOptions{Snapshot: importSnapshot}
+	opts.Headers.Allow = importAllowHeaders
+	if len(importDenyHeaders) > 0 {
+		opts.Headers.Deny = append(append([]string{}, importer.DefaultDeniedHeaders...), importDenyHeaders...)
+	}
+	return opts
+}
+
+// writeImport writes the imported suite and its configurations, and lists what could not be converted.
+func writeImport(result *importer.Result, input string) error {
+	output := importOutput
+	if output == "" {
+		output = strings.TrimSuffix(filepath.Base(input), filepath.Ext(input)) + ".yaml"
+	}
+
+	written, err := result.Write(output)
+	for _, path := range written {
+		fmt.Printf("%s written\n", path)
+	}
+	if err != nil {
+		return err
+	}
+
+	routes, scenarios := len(result.Suite.Routes), 0
+	for _, route := range result.Suite.Routes {
+		scenarios += len(route.Scenarios)
+	}
+	out := colors.Colored(os.Stdout)
+	fmt.Fprintf(out, "\n%s\n", colors.Green(fmt.Sprintf("%d routes and %d scenarios imported", routes, scenarios)))
+	if len(result.Warnings) == 0 {
+		return nil
+	}
+	fmt.Fprintf(out, "\n%s\n", colors.Yellow(fmt.Sprintf("%d items not converted, marked with TODO(import) in the suite when they belong to it:", len(result.Warnings))))
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(out, "  %s\n", warning)
+	}
+	return nil
+}
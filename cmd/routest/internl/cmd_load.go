@@ -0,0 +1,112 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/qatoolist/RouTest/formatters"
+	"github.com/qatoolist/RouTest/internal/load"
+	"github.com/qatoolist/RouTest/internal/models"
+	"github.com/qatoolist/RouTest/internal/parser"
+	"github.com/spf13/cobra"
+)
+
+var (
+	loadVUs        int
+	loadRPS        float64
+	loadDuration   time.Duration
+	loadRampUp     time.Duration
+	loadEnv        string
+	loadSelect     []string
+	loadThresholds []string
+)
+
+// discardLogger silences the hooks of the virtual users.
+var discardLogger = log.New(ioutil.Discard, "", 0)
+
+// CreateLoadCmd creates the load subcommand.
+func CreateLoadCmd() cobra.Command {
+	loadCmd := cobra.Command{
+		Use:   "load <suite.yaml>",
+		Short: "Drive the scenarios of a suite as a load test",
+		Long: `Execute the scenarios of a suite concurrently, with --vus virtual users
+executing them back to back, or at --rps executions per second, for the
+given --duration. The virtual users, or the rate, are ramped up linearly
+over --ramp-up.
+
+Every virtual user runs the BeforeAll hooks once, then the scenarios in
+turn with the same hooks, parameters and assertions as 'routest run'.
+The command fails when a --threshold is not met, e.g.
+
+  routest load suite.yaml --rps 50 --duration 1m --ramp-up 10s \
+    --threshold 'p99<500ms' --threshold 'error_rate<1%'`,
+		Args: cobra.ExactArgs(1),
+		RunE: loadCmdRunFunc,
+	}
+
+	loadCmd.Flags().IntVar(&loadVUs, "vus", 0, "number of virtual users, or the maximum executions in flight with --rps")
+	loadCmd.Flags().Float64Var(&loadRPS, "rps", 0, "target scenario executions per second")
+	loadCmd.Flags().DurationVar(&loadDuration, "duration", 30*time.Second, "how long the load is applied, ramp-up included")
+	loadCmd.Flags().DurationVar(&loadRampUp, "ramp-up", 0, "time over which the virtual users or the rate are ramped up")
+	loadCmd.Flags().StringVar(&loadEnv, "env", "", "environment whose configuration is loaded from the config directory of the suite")
+	loadCmd.Flags().StringSliceVar(&loadSelect, "select", nil, "route or route/scenario patterns to execute, e.g. users/*")
+	loadCmd.Flags().StringArrayVar(&loadThresholds, "threshold", nil, "gate failing the command, e.g. p99<500ms, error_rate<1%, rps>=40")
+
+	return loadCmd
+}
+
+func loadCmdRunFunc(cmd *cobra.Command, args []string) error {
+	thresholds, err := load.ParseThresholds(loadThresholds)
+	if err != nil {
+		return err
+	}
+
+	suite, err := parser.ParseSuiteFile(args[0])
+	if err != nil {
+		return err
+	}
+	if err := suite.Select(loadSelect); err != nil {
+		return err
+	}
+	// Snapshots would be rewritten or compared thousands of times.
+	suite.SkipSnapshots = true
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	result, err := load.Run(ctx, func() (*models.Application, error) {
+		app, err := suite.BuildForEnvironment(loadEnv)
+		if err != nil {
+			return nil, err
+		}
+		// The hooks of thousands of executions would drown the results.
+		app.SetLogger(discardLogger)
+		return app, nil
+	}, load.Options{VUs: loadVUs, RPS: loadRPS, Duration: loadDuration, RampUp: loadRampUp})
+	if err != nil {
+		return err
+	}
+
+	formatter := formatters.NewTextFormatter(os.Stdout)
+	if err := formatter.FormatLoad(result); err != nil {
+		return err
+	}
+	failures := result.Check(thresholds)
+	if err := formatter.FormatThresholds(thresholds, failures); err != nil {
+		return err
+	}
+
+	if len(failures) > 0 {
+		return errors.New("some thresholds failed")
+	}
+	if len(result.SessionErrors) > 0 {
+		return errors.New("some virtual users failed to start or stop")
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/qatoolist/RouTest/internal/mock"
+	"github.com/qatoolist/RouTest/internal/parser"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mockAddr string
+	mockSeed int64
+)
+
+// CreateMockCmd creates the mock subcommand.
+func CreateMockCmd() cobra.Command {
+	mockCmd := cobra.Command{
+		Use:   "mock <suite.yaml>",
+		Short: "Serve stub responses for the routes of a suite",
+		Long: `Start a local HTTP server answering the requests that match the method
+and path of a route of the suite.
+
+The response is the example of a scenario of the route, chosen with the
+X-Mock-Scenario header or the first successful one, or is generated from
+the response schema of the route with --seed. Request bodies are validated against
+the request schema, and every mismatch is logged.`,
+		Args: cobra.ExactArgs(1),
+		RunE: mockCmdRunFunc,
+	}
+
+	mockCmd.Flags().StringVar(&mockAddr, "addr", "127.0.0.1:8080", "address to listen on")
+	mockCmd.Flags().Int64Var(&mockSeed, "seed", 0, "seed of the bodies generated from response schemas")
+
+	return mockCmd
+}
+
+func mockCmdRunFunc(cmd *cobra.Command, args []string) error {
+	suite, err := parser.ParseSuiteFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	app, err := suite.Build()
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", mockAddr)
+	if err != nil {
+		return err
+	}
+
+	logger := app.GetLogger()
+	handler := mock.NewServer(app.RouteRegistry, logger)
+	handler.Seed = mockSeed
+	server := &http.Server{Handler: handler}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	logger.Printf("mock server listening on http://%s", listener.Addr())
+	if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -1 +1,39 @@
 package internal
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// CreateRootCmd creates the root command of the routest CLI with all its subcommands.
+func CreateRootCmd() cobra.Command {
+	rootCmd := cobra.Command{
+		Use:           "routest",
+		Short:         "Test HTTP APIs with declarative routes and scenarios",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	versionCmd := CreateVersionCmd()
+	runCmd := CreateRunCmd()
+	mockCmd := CreateMockCmd()
+	compareCmd := CreateCompareCmd()
+	loadCmd := CreateLoadCmd()
+	fuzzCmd := CreateFuzzCmd()
+	publishContractsCmd := CreatePublishContractsCmd()
+	verifyContractsCmd := CreateVerifyContractsCmd()
+	importCmd := CreateImportCmd()
+	exportCmd := CreateExportCmd()
+
+	rootCmd.AddCommand(&versionCmd)
+	rootCmd.AddCommand(&runCmd)
+	rootCmd.AddCommand(&mockCmd)
+	rootCmd.AddCommand(&compareCmd)
+	rootCmd.AddCommand(&loadCmd)
+	rootCmd.AddCommand(&fuzzCmd)
+	rootCmd.AddCommand(&publishContractsCmd)
+	rootCmd.AddCommand(&verifyContractsCmd)
+	rootCmd.AddCommand(&importCmd)
+	rootCmd.AddCommand(&exportCmd)
+
+	return rootCmd
+}
@@ -1 +1,206 @@
 package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/qatoolist/RouTest/formatters"
+	"github.com/qatoolist/RouTest/internal/cassette"
+	"github.com/qatoolist/RouTest/internal/coverage"
+	"github.com/qatoolist/RouTest/internal/interfaces"
+	"github.com/qatoolist/RouTest/internal/models"
+	"github.com/qatoolist/RouTest/internal/openapi"
+	"github.com/qatoolist/RouTest/internal/parser"
+	"github.com/spf13/cobra"
+)
+
+var (
+	runTimeout       time.Duration
+	runRecord        string
+	runReplay        string
+	runMatchOn       []string
+	runFilterHeaders []string
+	runFilterQuery   []string
+	runUpdateSnaps   bool
+	runEnv           string
+	runSelect        []string
+	runSeed          int64
+	runOpenAPI       string
+	runFailOnDrift   bool
+	runCoverage      bool
+	runCoverageJSON  string
+)
+
+// CreateRunCmd creates the run subcommand.
+func CreateRunCmd() cobra.Command {
+	runCmd := cobra.Command{
+		Use:   "run <suite.yaml>",
+		Short: "Run the scenarios of a suite",
+		Long: `Run the scenarios of a suite and print a report of the results.
+
+Pressing Ctrl-C cancels the requests in flight, skips the remaining
+scenarios and still prints the report of the scenarios executed so far.
+
+With --record, every request and response of a scenario is saved to a
+cassette file in the given directory. With --replay, the responses are
+served from those cassettes instead of the network, and a request that
+matches no recorded interaction fails its scenario with a replay miss.
+
+With --openapi, or the openapi key of the suite, every response is checked
+against the OpenAPI document: undocumented paths, status codes, headers
+and content types, and bodies that do not match their schema are reported
+as spec drift, apart from the failures of the scenarios.
+
+With --coverage, the report ends with the coverage of the API: the
+operations called, the documented response codes observed and the
+parameters varied, out of those of the OpenAPI document or, without one,
+of the routes of the suite. --coverage-json writes it to a file. The
+calls of WebSocket and gRPC routes are not sent with the HTTP client of
+the suite, so they are not counted.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runCmdRunFunc,
+	}
+
+	runCmd.Flags().DurationVar(&runTimeout, "timeout", 0, "deadline of the whole run, e.g. 5m (0 means no deadline)")
+	runCmd.Flags().StringVar(&runRecord, "record", "", "record the traffic of every scenario to cassettes in this directory")
+	runCmd.Flags().StringVar(&runReplay, "replay", "", "replay the traffic from the cassettes in this directory instead of the network")
+	runCmd.Flags().StringSliceVar(&runMatchOn, "match", cassette.DefaultMatchOn, "rules a request must satisfy to be replayed: method, path, query, body")
+	runCmd.Flags().StringVar(&runEnv, "env", "", "environment whose configuration is loaded from the config directory of the suite")
+	runCmd.Flags().StringSliceVar(&runSelect, "select", nil, "route or route/scenario patterns to run, e.g. users/*")
+	runCmd.Flags().Int64Var(&runSeed, "seed", 0, "seed of the bodies declared as 'body: generate', overriding the seed of the suite")
+	runCmd.Flags().StringVar(&runOpenAPI, "openapi", "", "OpenAPI document every response is checked against, overriding the one of the suite")
+	runCmd.Flags().BoolVar(&runFailOnDrift, "fail-on-drift", false, "fail the run when a response drifts from the OpenAPI document")
+	runCmd.Flags().BoolVar(&runCoverage, "coverage", false, "print the coverage of the API by the run")
+	runCmd.Flags().StringVar(&runCoverageJSON, "coverage-json", "", "write the coverage of the API by the run to this JSON file")
+	runCmd.Flags().BoolVar(&runUpdateSnaps, "update-snapshots", false, "rewrite the golden files of the snapshot assertions instead of comparing against them")
+	runCmd.Flags().StringSliceVar(&runFilterHeaders, "filter-header", nil, "header not written to the cassettes, in addition to Authorization, Cookie and similar")
+	runCmd.Flags().StringSliceVar(&runFilterQuery, "filter-query", nil, "query parameter masked in the cassettes, in addition to token, api_key and similar")
+
+	return runCmd
+}
+
+func runCmdRunFunc(cmd *cobra.Command, args []string) error {
+	suite, err := parser.ParseSuiteFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	if err := suite.Select(runSelect); err != nil {
+		return err
+	}
+	suite.UpdateSnapshots = runUpdateSnaps
+	if cmd.Flags().Changed("seed") {
+		suite.Seed = runSeed
+	}
+	if runOpenAPI != "" {
+		if suite.OpenAPI, err = filepath.Abs(runOpenAPI); err != nil {
+			return err
+		}
+	}
+
+	app, err := suite.BuildForEnvironment(runEnv)
+	if err != nil {
+		return err
+	}
+
+	if err := useCassettes(app); err != nil {
+		return err
+	}
+
+	var recorder *coverage.Recorder
+	if runCoverage || runCoverageJSON != "" {
+		recorder = coverage.NewRecorder(app.GetHTTPClient().Transport)
+		client := *app.GetHTTPClient()
+		client.Transport = recorder
+		app.SetHTTPClient(&client)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if runTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, runTimeout)
+		defer cancel()
+	}
+
+	report := app.Run(ctx)
+
+	if err := formatters.NewTextFormatter(os.Stdout).Format(report); err != nil {
+		return err
+	}
+
+	if recorder != nil {
+		if err := reportCoverage(app, recorder); err != nil {
+			return err
+		}
+	}
+
+	if report.Interrupted() != nil {
+		return fmt.Errorf("run interrupted: %v", report.Interrupted())
+	}
+	if report.Failed() > 0 || len(report.Errors()) > 0 {
+		return errors.New("some scenarios failed")
+	}
+	if runFailOnDrift && formatters.Drifted(report) > 0 {
+		return errors.New("some responses drifted from the OpenAPI document")
+	}
+	return nil
+}
+
+// reportCoverage prints the coverage of the API by the calls of the recorder and writes it to the --coverage-json file.
+// The operations are those of the OpenAPI document of the run, or of the routes of the suite without one.
+func reportCoverage(app *models.Application, recorder *coverage.Recorder) error {
+	spec := coverage.FromRoutes(app.RouteRegistry)
+	if validator, ok := app.GetSpecValidator().(*openapi.Validator); ok {
+		spec = coverage.FromOpenAPI(validator)
+	}
+	report := spec.Measure(recorder.Calls())
+
+	if runCoverage {
+		if err := formatters.NewTextFormatter(os.Stdout).FormatCoverage(report); err != nil {
+			return err
+		}
+	}
+	if runCoverageJSON == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(runCoverageJSON, append(data, '\n'), 0o644)
+}
+
+// useCassettes plugs the recorder or the player of the --record and --replay flags beneath the client of the application.
+func useCassettes(app interfaces.Application) error {
+	if runRecord != "" && runReplay != "" {
+		return errors.New("--record and --replay cannot be used together")
+	}
+
+	var transport http.RoundTripper
+	var err error
+	switch {
+	case runRecord != "":
+		transport, err = cassette.NewRecorder(cassette.Options{Dir: runRecord, MatchOn: runMatchOn, FilterHeaders: runFilterHeaders, FilterQuery: runFilterQuery}, nil)
+	case runReplay != "":
+		transport, err = cassette.NewPlayer(cassette.Options{Dir: runReplay, MatchOn: runMatchOn, FilterHeaders: runFilterHeaders, FilterQuery: runFilterQuery})
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	app.SetHTTPClient(&http.Client{Transport: transport})
+	return nil
+}
@@ -1 +1,16 @@
-package internal
+package main
+
+import (
+	"fmt"
+	"os"
+
+	internal "github.com/qatoolist/RouTest/cmd/routest/internl"
+)
+
+func main() {
+	rootCmd := internal.CreateRootCmd()
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
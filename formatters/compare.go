@@ -0,0 +1,56 @@
+package formatters
+
+import (
+	"fmt"
+
+	"github.com/qatoolist/RouTest/colors"
+	"github.com/qatoolist/RouTest/internal/compare"
+)
+
+// FormatComparison writes the comparison of two environments, one line per scenario followed by its differences
+// and a summary.
+func (f *TextFormatter) FormatComparison(report *compare.Report) error {
+	for _, result := range report.Results {
+		status := colors.Green("SAME")
+		if !result.Same() {
+			status = colors.Red("DIFF")
+		}
+		if _, err := fmt.Fprintf(f.out, "%s %s/%s\n", status, result.RouteName, result.ScenarioName); err != nil {
+			return err
+		}
+
+		if result.BaseTiming != nil && result.AgainstTiming != nil {
+			if _, err := fmt.Fprintf(f.out, "    total %s %s, %s %s; ttfb %s %s, %s %s\n",
+				report.Base, ms(result.BaseTiming.Total()), report.Against, ms(result.AgainstTiming.Total()),
+				report.Base, ms(result.BaseTiming.TimeToFirstByte()), report.Against, ms(result.AgainstTiming.TimeToFirstByte())); err != nil {
+				return err
+			}
+		}
+
+		if result.Err != nil {
+			if _, err := fmt.Fprintf(f.out, "    %s\n", colors.Yellow(result.Err)); err != nil {
+				return err
+			}
+		}
+		for _, difference := range result.Differences {
+			if _, err := fmt.Fprintf(f.out, "    %s\n", colors.Red(difference)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if report.Interrupted != nil {
+		if _, err := fmt.Fprintf(f.out, "\n%s\n", colors.Yellow(fmt.Sprintf("run interrupted: %v, the comparison is partial", report.Interrupted))); err != nil {
+			return err
+		}
+	}
+
+	summary := fmt.Sprintf("%d of %d scenarios differ between %s and %s", report.Different(), len(report.Results), report.Base, report.Against)
+	if report.Different() > 0 {
+		summary = colors.Red(summary)
+	} else {
+		summary = colors.Green(summary)
+	}
+	_, err := fmt.Fprintf(f.out, "\n%s\n", summary)
+	return err
+}
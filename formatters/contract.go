@@ -0,0 +1,42 @@
+package formatters
+
+import (
+	"fmt"
+
+	"github.com/qatoolist/RouTest/colors"
+	"github.com/qatoolist/RouTest/internal/contract"
+)
+
+// FormatVerification writes the verification of the contracts, one line per interaction followed by
+// every expectation of its consumer that failed, and a summary.
+func (f *TextFormatter) FormatVerification(report *contract.Report) error {
+	for _, result := range report.Results {
+		status := colors.Green("PASS")
+		if !result.Passed() {
+			status = colors.Red("FAIL")
+		}
+		if _, err := fmt.Fprintf(f.out, "%s %s -> %s: %s\n", status, result.Consumer, result.Provider, result.Description); err != nil {
+			return err
+		}
+
+		if result.Err != nil {
+			if _, err := fmt.Fprintf(f.out, "    %s\n", colors.Red(result.Err)); err != nil {
+				return err
+			}
+		}
+		for _, failure := range result.Failures {
+			if _, err := fmt.Fprintf(f.out, "    %s\n", colors.Red(fmt.Sprintf("%s %s", result.Consumer, failure))); err != nil {
+				return err
+			}
+		}
+	}
+
+	summary := fmt.Sprintf("%d of %d interactions verified", len(report.Results)-report.Failed(), len(report.Results))
+	if report.Failed() > 0 {
+		summary = colors.Red(fmt.Sprintf("%s, %d failed", summary, report.Failed()))
+	} else {
+		summary = colors.Green(summary)
+	}
+	_, err := fmt.Fprintf(f.out, "\n%s\n", summary)
+	return err
+}
@@ -0,0 +1,70 @@
+package formatters
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/qatoolist/RouTest/colors"
+	"github.com/qatoolist/RouTest/internal/coverage"
+)
+
+// FormatCoverage writes the coverage of the API by a run: the percentages of operations called,
+// documented statuses observed and parameters varied, followed by everything that was not covered.
+func (f *TextFormatter) FormatCoverage(report *coverage.Report) error {
+	lines := []string{
+		"\nAPI coverage",
+		"  operations called    " + formatRatio(report.Operations),
+		"  statuses observed    " + formatRatio(report.Statuses),
+		"  parameters varied    " + formatRatio(report.Parameters),
+	}
+
+	if uncovered := report.Uncovered(); len(uncovered) > 0 {
+		lines = append(lines, "\nOperations never called:")
+		for _, op := range uncovered {
+			lines = append(lines, colors.Red("  "+op.Method+" "+op.Path))
+		}
+	}
+
+	var statuses, parameters []string
+	for _, op := range report.Coverage {
+		if op.Calls > 0 && len(op.Uncovered) > 0 {
+			statuses = append(statuses, fmt.Sprintf("  %s %s: %s", op.Method, op.Path, strings.Join(op.Uncovered, ", ")))
+		}
+		if len(op.Unvaried) > 0 {
+			names := make([]string, 0, len(op.Unvaried))
+			for _, p := range op.Unvaried {
+				names = append(names, p.String())
+			}
+			parameters = append(parameters, fmt.Sprintf("  %s %s: %s", op.Method, op.Path, strings.Join(names, ", ")))
+		}
+	}
+	if len(statuses) > 0 {
+		lines = append(lines, "\nDocumented statuses never observed:")
+		lines = append(lines, colors.Yellow(strings.Join(statuses, "\n")))
+	}
+	if len(parameters) > 0 {
+		lines = append(lines, "\nParameters never varied:")
+		lines = append(lines, colors.Yellow(strings.Join(parameters, "\n")))
+	}
+	if len(report.Unmatched) > 0 {
+		lines = append(lines, "\nCalls to undocumented operations:")
+		for _, call := range report.Unmatched {
+			lines = append(lines, colors.Yellow("  "+call))
+		}
+	}
+
+	_, err := fmt.Fprintln(f.out, strings.Join(lines, "\n"))
+	return err
+}
+
+// formatRatio formats a ratio as "3 of 4 (75.0%)", or "none documented" when there is nothing to cover.
+func formatRatio(r coverage.Ratio) string {
+	if r.Total == 0 {
+		return "none documented"
+	}
+	line := fmt.Sprintf("%d of %d (%.1f%%)", r.Covered, r.Total, r.Percent)
+	if r.Covered == r.Total {
+		return colors.Green(line)
+	}
+	return line
+}
@@ -0,0 +1,97 @@
+package formatters
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/qatoolist/RouTest/colors"
+	"github.com/qatoolist/RouTest/internal/load"
+)
+
+// FormatLoad writes the results of a load test: throughput, latency percentiles, the executions by status
+// and the latency and failures of every scenario.
+func (f *TextFormatter) FormatLoad(result *load.Result) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "executions   %d in %s (%.1f/s)", result.Executions(), round(result.Duration), result.Throughput())
+	if result.Dropped > 0 {
+		fmt.Fprintf(&b, ", %s", colors.Yellow(fmt.Sprintf("%d dropped", result.Dropped)))
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "latency      %s\n", formatStats(result.Latency))
+	fmt.Fprintf(&b, "ttfb         %s\n", formatStats(result.TimeToFirstByte))
+	fmt.Fprintf(&b, "traffic      %s sent, %s received\n", formatBytes(result.BytesSent), formatBytes(result.BytesReceived))
+
+	errorRate := fmt.Sprintf("%.2f%% (%d assertion failures, %d without response)", result.ErrorRate()*100, result.Failures, result.Errors)
+	if result.Failures+result.Errors > 0 {
+		errorRate = colors.Red(errorRate)
+	}
+	fmt.Fprintf(&b, "error rate   %s\n", errorRate)
+
+	statuses := make([]int, 0, len(result.Statuses))
+	for status := range result.Statuses {
+		statuses = append(statuses, status)
+	}
+	sort.Ints(statuses)
+	counts := make([]string, len(statuses))
+	for i, status := range statuses {
+		counts[i] = fmt.Sprintf("%s: %d", load.StatusName(status), result.Statuses[status])
+	}
+	fmt.Fprintf(&b, "statuses     %s\n", strings.Join(counts, ", "))
+
+	b.WriteString("\n")
+	for _, s := range result.Scenarios {
+		line := fmt.Sprintf("%s/%s: %d, %s", s.RouteName, s.ScenarioName, s.Latency.Count, formatStats(s.Latency))
+		if s.Failures+s.Errors > 0 {
+			line += colors.Red(fmt.Sprintf(", %d failed", s.Failures+s.Errors))
+		}
+		fmt.Fprintf(&b, "  %s\n", line)
+	}
+
+	for _, err := range result.SessionErrors {
+		fmt.Fprintf(&b, "%s %s\n", colors.Red("ERROR"), err)
+	}
+	if result.Interrupted != nil {
+		fmt.Fprintf(&b, "\n%s\n", colors.Yellow(fmt.Sprintf("load test interrupted: %v, the results are partial", result.Interrupted)))
+	}
+
+	_, err := fmt.Fprint(f.out, b.String())
+	return err
+}
+
+// FormatThresholds writes the outcome of the thresholds of a load test.
+func (f *TextFormatter) FormatThresholds(thresholds []load.Threshold, failures []string) error {
+	if len(thresholds) == 0 {
+		return nil
+	}
+	if len(failures) == 0 {
+		_, err := fmt.Fprintf(f.out, "\n%s\n", colors.Green(fmt.Sprintf("%d thresholds passed", len(thresholds))))
+		return err
+	}
+	if _, err := fmt.Fprintln(f.out); err != nil {
+		return err
+	}
+	for _, failure := range failures {
+		if _, err := fmt.Fprintf(f.out, "%s %s\n", colors.Red("FAIL"), failure); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatStats(s load.Stats) string {
+	if s.Count == 0 {
+		return "no executions"
+	}
+	return fmt.Sprintf("p50=%s p90=%s p99=%s avg=%s max=%s", ms(s.P50), ms(s.P90), ms(s.P99), ms(s.Avg), ms(s.Max))
+}
+
+// ms rounds a latency for printing, keeping sub-millisecond latencies readable.
+func ms(d time.Duration) time.Duration {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond)
+	}
+	return d.Round(100 * time.Microsecond)
+}
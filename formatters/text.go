@@ -0,0 +1,200 @@
+// Package formatters provides the report formatters used to print the results of a run.
+package formatters
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/qatoolist/RouTest/colors"
+	"github.com/qatoolist/RouTest/internal/export"
+	"github.com/qatoolist/RouTest/internal/interfaces"
+)
+
+// maxBodyLength is the maximum number of body bytes printed for a response.
+const maxBodyLength = 200
+
+// TextFormatter writes a human readable report of a run.
+type TextFormatter struct {
+	out io.Writer
+}
+
+// NewTextFormatter creates a new TextFormatter writing to the given writer.
+func NewTextFormatter(out io.Writer) *TextFormatter {
+	return &TextFormatter{out: colors.Colored(out)}
+}
+
+// Format writes the report, one line per scenario followed by a summary.
+// Scenarios that needed more than one attempt list every attempt, so that flakiness stays visible.
+// Spec drift is listed under its scenario and counted apart from the failures.
+// Failed scenarios end with the cURL command reproducing their request, with its secrets masked.
+func (f *TextFormatter) Format(report interfaces.Report) error {
+	for _, result := range report.Results() {
+		if err := f.formatResult(result); err != nil {
+			return err
+		}
+	}
+
+	for _, reportErr := range report.Errors() {
+		if _, err := fmt.Fprintf(f.out, "%s %s\n", colors.Red("ERROR"), reportErr); err != nil {
+			return err
+		}
+	}
+
+	if err := report.Interrupted(); err != nil {
+		if _, err := fmt.Fprintf(f.out, "\n%s\n", colors.Yellow(fmt.Sprintf("run interrupted: %v, the report is partial", err))); err != nil {
+			return err
+		}
+	}
+
+	summary := fmt.Sprintf("%d passed, %d failed", report.Passed(), report.Failed())
+	if report.Failed() > 0 {
+		summary = colors.Red(summary)
+	} else {
+		summary = colors.Green(summary)
+	}
+	if _, err := fmt.Fprintf(f.out, "\n%s in %s\n", summary, round(report.Duration())); err != nil {
+		return err
+	}
+
+	if drifted := Drifted(report); drifted > 0 {
+		_, err := fmt.Fprintln(f.out, colors.Yellow(fmt.Sprintf("%d of %d scenarios drifted from the specification", drifted, len(report.Results()))))
+		return err
+	}
+	return nil
+}
+
+// Drifted returns the number of scenarios whose response drifted from the specification of the API.
+func Drifted(report interfaces.Report) int {
+	n := 0
+	for _, result := range report.Results() {
+		if resp := result.Response(); resp != nil && len(resp.GetSpecDrift()) > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func (f *TextFormatter) formatResult(result interfaces.ScenarioResult) error {
+	status := colors.Green("PASS")
+	if !result.Passed() {
+		status = colors.Red("FAIL")
+	}
+
+	var attempts []interfaces.Attempt
+	details := round(result.Duration()).String()
+	if resp := result.Response(); resp != nil {
+		attempts = resp.GetAttempts()
+		if len(attempts) > 0 {
+			details = attempts[len(attempts)-1].Status() + ", " + details
+		}
+		if len(attempts) > 1 {
+			details = fmt.Sprintf("%s, %d attempts", details, len(attempts))
+		}
+		if resp.GetPolls() > 0 {
+			details = fmt.Sprintf("%s, %d polls in %s", details, resp.GetPolls(), round(resp.GetPollDuration()))
+		}
+	}
+
+	_, err := fmt.Fprintf(f.out, "%s %s/%s (%s)\n", status, result.RouteName(), result.ScenarioName(), details)
+	if err != nil {
+		return err
+	}
+
+	if resp := result.Response(); resp != nil && resp.GetTiming() != nil {
+		if _, err := fmt.Fprintf(f.out, "    %s\n", formatTiming(resp.GetTiming())); err != nil {
+			return err
+		}
+	}
+
+	if resp := result.Response(); resp != nil {
+		for _, drift := range resp.GetSpecDrift() {
+			if _, err := fmt.Fprintf(f.out, "    %s %s\n", colors.Yellow("DRIFT"), drift); err != nil {
+				return err
+			}
+		}
+	}
+
+	if result.Err() != nil {
+		message := strings.ReplaceAll(result.Err().Error(), "\n", "\n    ")
+		if _, err := fmt.Fprintf(f.out, "    %s\n", colors.Red(message)); err != nil {
+			return err
+		}
+		if resp := result.Response(); resp != nil && resp.GetPolls() > 0 {
+			if _, err := fmt.Fprintf(f.out, "    last response: %s\n", truncate(resp.Bytes())); err != nil {
+				return err
+			}
+		}
+		if req := result.Request(); req != nil {
+			if _, err := fmt.Fprintf(f.out, "    reproduce: %s\n", export.Curl(req, export.Options{})); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(attempts) < 2 {
+		return nil
+	}
+	for _, attempt := range attempts {
+		if err := f.formatAttempt(attempt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *TextFormatter) formatAttempt(attempt interfaces.Attempt) error {
+	outcome := attempt.Status()
+	if attempt.Err() != nil {
+		outcome = attempt.Err().Error()
+	}
+
+	line := fmt.Sprintf("    attempt %d: %s in %s", attempt.Number(), outcome, round(attempt.Duration()))
+	if attempt.Wait() > 0 {
+		line += fmt.Sprintf(", retried after %s", round(attempt.Wait()))
+	}
+	if _, err := fmt.Fprintln(f.out, colors.Yellow(line)); err != nil {
+		return err
+	}
+
+	if len(attempt.Body()) == 0 {
+		return nil
+	}
+	_, err := fmt.Fprintf(f.out, "      %s\n", truncate(attempt.Body()))
+	return err
+}
+
+// formatTiming describes the timing breakdown of a request on a single line.
+func formatTiming(t interfaces.Timing) string {
+	connection := "new connection"
+	if t.ConnectionReused() {
+		connection = "reused connection"
+	}
+	return fmt.Sprintf("dns %s, connect %s, tls %s, ttfb %s, total %s, sent %s, received %s, %s",
+		ms(t.DNS()), ms(t.Connect()), ms(t.TLSHandshake()), ms(t.TimeToFirstByte()), ms(t.Total()),
+		formatBytes(t.BytesSent()), formatBytes(t.BytesReceived()), connection)
+}
+
+// formatBytes formats a size in bytes with a binary unit.
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+// truncate shortens a body to maxBodyLength bytes for printing.
+func truncate(body []byte) []byte {
+	if len(body) > maxBodyLength {
+		return append(body[:maxBodyLength:maxBodyLength], "..."...)
+	}
+	return body
+}
+
+func round(d time.Duration) time.Duration {
+	return d.Round(time.Millisecond)
+}
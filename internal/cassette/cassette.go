@@ -0,0 +1,320 @@
+// Package cassette records the requests and responses of the scenarios to disk and replays them,
+// so that suites can run offline and deterministically.
+// Both the Recorder and the Player are http.RoundTrippers, plugged in beneath the http.Client of the application.
+package cassette
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/qatoolist/RouTest/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// The rules a recorded request can be matched on.
+const (
+	MatchMethod = "method"
+	MatchPath   = "path"
+	MatchQuery  = "query"
+	MatchBody   = "body"
+)
+
+// DefaultMatchOn are the rules used when none are given.
+var DefaultMatchOn = []string{MatchMethod, MatchPath, MatchQuery}
+
+// DefaultFilterHeaders are the headers never written to a cassette, as they usually carry secrets.
+var DefaultFilterHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+
+// DefaultFilterQuery are the query parameters whose values are masked in cassettes, as they usually carry secrets.
+// The fields of JSON and form request bodies with the same names are masked too.
+var DefaultFilterQuery = []string{"access_token", "api_key", "apikey", "client_secret", "key", "password", "secret", "sig", "signature", "token"}
+
+// DefaultMaxBodySize is the size of the response bodies recorded when none is given. Longer bodies, e.g.
+// downloads, are passed through whole and recorded truncated.
+const DefaultMaxBodySize = 1 << 20
+
+// filteredValue replaces the values of the filtered query parameters.
+const filteredValue = "***"
+
+// Options configures where cassettes are stored, how requests are matched and which headers are filtered.
+type Options struct {
+	// Dir is the directory of the cassettes, one file per scenario.
+	Dir string
+
+	// MatchOn are the rules a request must satisfy to match a recorded one. Defaults to DefaultMatchOn.
+	MatchOn []string
+
+	// FilterHeaders are the request and response headers that are not recorded, in addition to DefaultFilterHeaders.
+	FilterHeaders []string
+
+	// FilterQuery are the query parameters whose values are masked, in addition to DefaultFilterQuery.
+	// Requests are matched on the masked query. The fields of JSON and form request bodies with these names,
+	// at any depth, are masked too, but the body hash is the one of the body as sent.
+	FilterQuery []string
+
+	// MaxBodySize is the maximum size of a recorded response body. Defaults to DefaultMaxBodySize.
+	MaxBodySize int64
+}
+
+// validate checks the options and fills in the defaults.
+func (o *Options) validate() error {
+	if o.Dir == "" {
+		return fmt.Errorf("cassette directory is not defined")
+	}
+	if len(o.MatchOn) == 0 {
+		o.MatchOn = DefaultMatchOn
+	}
+	for _, rule := range o.MatchOn {
+		switch rule {
+		case MatchMethod, MatchPath, MatchQuery, MatchBody:
+		default:
+			return fmt.Errorf("unknown match rule %q, expected one of method, path, query or body", rule)
+		}
+	}
+	o.FilterHeaders = append(append([]string{}, DefaultFilterHeaders...), o.FilterHeaders...)
+	o.FilterQuery = append(append([]string{}, DefaultFilterQuery...), o.FilterQuery...)
+	if o.MaxBodySize <= 0 {
+		o.MaxBodySize = DefaultMaxBodySize
+	}
+	return nil
+}
+
+// Cassette is the list of interactions recorded for a scenario.
+type Cassette struct {
+	Route        string        `yaml:"route,omitempty"`
+	Scenario     string        `yaml:"scenario,omitempty"`
+	Interactions []Interaction `yaml:"interactions"`
+}
+
+// Interaction is a recorded request together with the response it received.
+type Interaction struct {
+	Request  Request  `yaml:"request"`
+	Response Response `yaml:"response"`
+}
+
+// Request is a recorded request.
+type Request struct {
+	Method   string              `yaml:"method"`
+	URL      string              `yaml:"url"`
+	Headers  map[string][]string `yaml:"headers,omitempty"`
+	Body     string              `yaml:"body,omitempty"`
+	BodyHash string              `yaml:"body_hash,omitempty"`
+}
+
+// Response is a recorded response.
+type Response struct {
+	Status  int                 `yaml:"status"`
+	Headers map[string][]string `yaml:"headers,omitempty"`
+	Body    string              `yaml:"body,omitempty"`
+
+	// Truncated reports that only the beginning of the body, up to Options.MaxBodySize, was recorded.
+	Truncated bool `yaml:"truncated,omitempty"`
+}
+
+// load reads the cassette at the given path.
+func load(path string) (*Cassette, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Cassette
+	if err := yaml.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return &c, nil
+}
+
+// save writes the cassette to the given path, creating its directory if needed.
+func (c *Cassette) save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0o644)
+}
+
+// unsafeChars are the characters replaced in the file names of cassettes.
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// pathFor returns the path of the cassette of the scenario the request belongs to, with its route and scenario names.
+// Requests sent outside of a scenario share a single cassette.
+func pathFor(dir string, req *http.Request) (path, route, scenario string) {
+	s, ok := models.ScenarioFromContext(req.Context())
+	if !ok {
+		return filepath.Join(dir, "unscoped.yaml"), "", ""
+	}
+	if r := s.GetParentRoute(); r != nil {
+		route = r.GetName()
+	}
+	scenario = s.GetName()
+	return filepath.Join(dir, fileName(route), fileName(scenario)+".yaml"), route, scenario
+}
+
+func fileName(name string) string {
+	name = strings.Trim(unsafeChars.ReplaceAllString(name, "_"), "_")
+	if name == "" {
+		return "unnamed"
+	}
+	return name
+}
+
+// readBody reads the body of the request and restores it, so that the request can still be sent.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Body.Close()
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func hashBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// filterHeaders returns a copy of the headers without the filtered ones.
+func filterHeaders(headers http.Header, filtered []string) map[string][]string {
+	if len(headers) == 0 {
+		return nil
+	}
+	copy := make(map[string][]string, len(headers))
+	for key, values := range headers {
+		if !containsFold(filtered, key) {
+			copy[key] = values
+		}
+	}
+	return copy
+}
+
+// filterQuery returns a copy of the URL with the values of the filtered query parameters masked.
+func filterQuery(u *url.URL, filtered []string) *url.URL {
+	query := u.Query()
+	copy := *u
+	if maskValues(query, filtered) {
+		copy.RawQuery = query.Encode()
+	}
+	return &copy
+}
+
+// filterBody returns the request body with the values of the filtered fields masked, when it is a JSON or form body.
+// Other bodies, and bodies without any filtered field, are returned as they are.
+func filterBody(body []byte, contentType string, filtered []string) []byte {
+	if len(body) == 0 {
+		return body
+	}
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		form, err := url.ParseQuery(string(body))
+		if err != nil || !maskValues(form, filtered) {
+			return body
+		}
+		return []byte(form.Encode())
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil || !maskFields(value, filtered) {
+		return body
+	}
+	masked, err := json.Marshal(value)
+	if err != nil {
+		return body
+	}
+	return masked
+}
+
+// maskValues masks the values of the filtered keys and reports whether any was masked.
+func maskValues(values url.Values, filtered []string) bool {
+	masked := false
+	for key, list := range values {
+		if containsFold(filtered, key) {
+			for i := range list {
+				list[i] = filteredValue
+			}
+			masked = true
+		}
+	}
+	return masked
+}
+
+// maskFields masks the values of the filtered fields of the JSON value, whatever their type, in every nested
+// object and array, and reports whether any was masked.
+func maskFields(value interface{}, filtered []string) bool {
+	masked := false
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			if containsFold(filtered, key) {
+				value[key] = filteredValue
+				masked = true
+			} else if maskFields(field, filtered) {
+				masked = true
+			}
+		}
+	case []interface{}:
+		for _, item := range value {
+			if maskFields(item, filtered) {
+				masked = true
+			}
+		}
+	}
+	return masked
+}
+
+// matches reports whether the recorded request satisfies every rule for the given request,
+// whose query is compared with the filtered parameters masked as in the recorded one.
+func (r Request) matches(req *http.Request, bodyHash string, rules, filteredQuery []string) bool {
+	recorded, err := url.Parse(r.URL)
+	if err != nil {
+		return false
+	}
+	for _, rule := range rules {
+		switch rule {
+		case MatchMethod:
+			if !strings.EqualFold(r.Method, req.Method) {
+				return false
+			}
+		case MatchPath:
+			if recorded.Path != req.URL.Path {
+				return false
+			}
+		case MatchQuery:
+			if recorded.Query().Encode() != filterQuery(req.URL, filteredQuery).Query().Encode() {
+				return false
+			}
+		case MatchBody:
+			if r.BodyHash != bodyHash {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
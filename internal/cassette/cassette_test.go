@@ -0,0 +1,28 @@
+package cassette
+
+import "testing"
+
+func TestFilterBody(t *testing.T) {
+	filtered := append([]string{"pin"}, DefaultFilterQuery...)
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+	}{
+		{"json", "application/json", `{"user": "bob", "password": "s3cr3t"}`, `{"password":"***","user":"bob"}`},
+		{"json non-string values", "application/json", `{"pin": 1234, "token": {"value": "t"}, "key": null}`, `{"key":"***","pin":"***","token":"***"}`},
+		{"json nested", "application/json", `{"users": [{"name": "bob", "Secret": "s"}]}`, `{"users":[{"Secret":"***","name":"bob"}]}`},
+		{"json without secrets", "application/json", `{"user": "bob",  "tags": []}`, `{"user": "bob",  "tags": []}`},
+		{"json without content type", "", `{"token": "t"}`, `{"token":"***"}`},
+		{"form", "application/x-www-form-urlencoded", "user=bob&password=s3cr3t", "password=%2A%2A%2A&user=bob"},
+		{"form without secrets", "application/x-www-form-urlencoded; charset=utf-8", "user=bob&page=2", "user=bob&page=2"},
+		{"text", "text/plain", "password=s3cr3t", "password=s3cr3t"},
+		{"empty", "application/json", "", ""},
+	}
+	for _, test := range tests {
+		if got := string(filterBody([]byte(test.body), test.contentType, filtered)); got != test.want {
+			t.Errorf("%s: expected %s, got %s", test.name, test.want, got)
+		}
+	}
+}
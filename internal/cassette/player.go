@@ -0,0 +1,155 @@
+package cassette
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ReplayMissError is returned when no recorded interaction matches a request.
+type ReplayMissError struct {
+	// Path is the path of the cassette that was searched.
+	Path string
+
+	// Method and URL identify the request that was not found.
+	Method string
+	URL    string
+
+	// Reason explains why nothing matched.
+	Reason string
+}
+
+func (e *ReplayMissError) Error() string {
+	return fmt.Sprintf("replay miss: %s %s: %s (%s)", e.Method, e.URL, e.Reason, e.Path)
+}
+
+// Player is an http.RoundTripper answering the requests from the cassettes of their scenarios, without using the network.
+// Matching interactions are replayed in the order they were recorded, so that retries and polls see the same
+// sequence of responses; once all of them were replayed, the last one is replayed again.
+type Player struct {
+	opts      Options
+	mu        sync.Mutex
+	cassettes map[string]*playback
+}
+
+// playback is a loaded cassette and the number of times each interaction was replayed.
+type playback struct {
+	cassette *Cassette
+	replayed []int
+}
+
+// NewPlayer creates a new Player reading the cassettes from the directory of the options.
+func NewPlayer(opts Options) (*Player, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	return &Player{
+		opts:      opts,
+		cassettes: make(map[string]*playback),
+	}, nil
+}
+
+// RoundTrip returns the recorded response of the first matching interaction,
+// or a *ReplayMissError when there is none.
+func (p *Player) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	path, _, _ := pathFor(p.opts.Dir, req)
+	miss := &ReplayMissError{Path: path, Method: req.Method, URL: filterQuery(req.URL, p.opts.FilterQuery).String()}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pb, err := p.load(path)
+	if errors.Is(err, os.ErrNotExist) {
+		miss.Reason = "no cassette was recorded for the scenario"
+		return nil, miss
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	hash := hashBody(body)
+	last := -1
+	for i, interaction := range pb.cassette.Interactions {
+		if !interaction.Request.matches(req, hash, p.opts.MatchOn, p.opts.FilterQuery) {
+			continue
+		}
+		last = i
+		if pb.replayed[i] == 0 {
+			break
+		}
+	}
+	if last < 0 {
+		miss.Reason = fmt.Sprintf("no recorded interaction matches on %s", strings.Join(p.opts.MatchOn, ", "))
+		return nil, miss
+	}
+	pb.replayed[last]++
+
+	recorded := pb.cassette.Interactions[last].Response
+	header := http.Header{}
+	for key, values := range recorded.Headers {
+		header[key] = values
+	}
+	header.Del("Content-Length")
+
+	return &http.Response{
+		StatusCode:    recorded.Status,
+		Status:        fmt.Sprintf("%d %s", recorded.Status, http.StatusText(recorded.Status)),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader([]byte(recorded.Body))),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}, nil
+}
+
+// load returns the cassette at the given path, reading it on first use.
+func (p *Player) load(path string) (*playback, error) {
+	if pb, ok := p.cassettes[path]; ok {
+		return pb, nil
+	}
+	c, err := load(path)
+	if err != nil {
+		return nil, err
+	}
+	pb := &playback{cassette: c, replayed: make([]int, len(c.Interactions))}
+	p.cassettes[path] = pb
+	return pb, nil
+}
+
+/* Example usage -
+
+// Record the traffic of a run
+recorder, err := cassette.NewRecorder(cassette.Options{Dir: "cassettes"}, nil)
+if err != nil {
+    log.Fatal(err)
+}
+app.SetHTTPClient(&http.Client{Transport: recorder})
+app.Run(ctx)
+
+// Replay it later without the network, matching on the body as well
+player, err := cassette.NewPlayer(cassette.Options{
+    Dir:     "cassettes",
+    MatchOn: []string{cassette.MatchMethod, cassette.MatchPath, cassette.MatchQuery, cassette.MatchBody},
+})
+if err != nil {
+    log.Fatal(err)
+}
+app.SetHTTPClient(&http.Client{Transport: player})
+app.Run(ctx)
+
+*/
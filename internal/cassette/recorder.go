@@ -0,0 +1,135 @@
+package cassette
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// Recorder is an http.RoundTripper sending the requests with the next RoundTripper
+// and saving every request and response pair to the cassette of its scenario.
+// The cassette of a scenario is rewritten from scratch the first time the scenario sends a request,
+// and saved after every interaction so that an interrupted run keeps what was recorded.
+type Recorder struct {
+	opts      Options
+	next      http.RoundTripper
+	mu        sync.Mutex
+	cassettes map[string]*Cassette
+}
+
+// NewRecorder creates a new Recorder sending the requests with next, or http.DefaultTransport when next is nil.
+func NewRecorder(opts Options, next http.RoundTripper) (*Recorder, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Recorder{
+		opts:      opts,
+		next:      next,
+		cassettes: make(map[string]*Cassette),
+	}, nil
+}
+
+// RoundTrip sends the request and records it together with its response.
+// The response body is recorded as it is read, so that event streams and downloads are passed through
+// as they arrive, and the interaction is saved once the body is read whole or closed.
+// The secret fields of JSON and form request bodies are masked like the secret query parameters.
+// Requests failing without a response are not recorded.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	interaction := Interaction{
+		Request: Request{
+			Method:   req.Method,
+			URL:      filterQuery(req.URL, r.opts.FilterQuery).String(),
+			Headers:  filterHeaders(req.Header, r.opts.FilterHeaders),
+			Body:     string(filterBody(reqBody, req.Header.Get("Content-Type"), r.opts.FilterQuery)),
+			BodyHash: hashBody(reqBody),
+		},
+		Response: Response{
+			Status:  resp.StatusCode,
+			Headers: filterHeaders(resp.Header, r.opts.FilterHeaders),
+		},
+	}
+	resp.Body = &recordingBody{
+		ReadCloser: resp.Body,
+		max:        r.opts.MaxBodySize,
+		save: func(body []byte, truncated bool) error {
+			interaction.Response.Body = string(body)
+			interaction.Response.Truncated = truncated
+			return r.record(req, interaction)
+		},
+	}
+	return resp, nil
+}
+
+// recordingBody is a response body keeping what is read from it, up to max bytes, and saving it
+// when it is read whole or closed, whichever comes first.
+type recordingBody struct {
+	io.ReadCloser
+	max       int64
+	buf       bytes.Buffer
+	truncated bool
+	once      sync.Once
+	save      func(body []byte, truncated bool) error
+	err       error
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if keep := b.max - int64(b.buf.Len()); int64(n) > keep {
+		b.buf.Write(p[:keep])
+		b.truncated = true
+	} else {
+		b.buf.Write(p[:n])
+	}
+	if err == io.EOF {
+		if saveErr := b.flush(); saveErr != nil {
+			return n, saveErr
+		}
+	}
+	return n, err
+}
+
+// Close closes the body and saves what was read of it, e.g. the events of a stream closed after a timeout.
+func (b *recordingBody) Close() error {
+	err := b.ReadCloser.Close()
+	if saveErr := b.flush(); saveErr != nil {
+		return saveErr
+	}
+	return err
+}
+
+func (b *recordingBody) flush() error {
+	b.once.Do(func() {
+		b.err = b.save(b.buf.Bytes(), b.truncated)
+	})
+	return b.err
+}
+
+// record appends the interaction to the cassette of the scenario of the request and saves it.
+func (r *Recorder) record(req *http.Request, interaction Interaction) error {
+	path, route, scenario := pathFor(r.opts.Dir, req)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.cassettes[path]
+	if !ok {
+		c = &Cassette{Route: route, Scenario: scenario}
+		r.cassettes[path] = c
+	}
+	c.Interactions = append(c.Interactions, interaction)
+	return c.save(path)
+}
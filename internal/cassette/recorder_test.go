@@ -0,0 +1,126 @@
+package cassette
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRecorderStream checks that an event stream that never ends is passed through as it arrives and that
+// the events read before it is closed are recorded.
+func TestRecorderStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 1; ; i++ {
+			fmt.Fprintf(w, "data: %d\n\n", i)
+			w.(http.Flusher).Flush()
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	recorder, err := NewRecorder(Options{Dir: dir}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/events", nil)
+	resp, err := recorder.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		reader := bufio.NewReader(resp.Body)
+		for events := 0; events < 2; {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if line == "\n" {
+				events++
+			}
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the events to be passed through as they arrive")
+	}
+	resp.Body.Close()
+
+	c, err := load(filepath.Join(dir, "unscoped.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body := c.Interactions[0].Response.Body; !strings.HasPrefix(body, "data: 1\n\ndata: 2\n\n") {
+		t.Errorf("expected the events read to be recorded, got %q", body)
+	}
+}
+
+// TestRecorderFilters checks the masking of secrets in the recorded requests, the truncation of large bodies
+// and the replay of requests with masked query parameters.
+func TestRecorderFilters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("x", 100)))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	opts := Options{Dir: dir, FilterQuery: []string{"session"}, MaxBodySize: 10}
+	recorder, err := NewRecorder(opts, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/items?page=2&token=s3cr3t&Session=abc", nil)
+	req.Header.Set("Authorization", "Bearer s3cr3t")
+	resp, err := recorder.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if len(body) != 100 {
+		t.Errorf("expected the whole body to be passed through, got %d bytes", len(body))
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "unscoped.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"s3cr3t", "abc"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("expected %q to be masked, got:\n%s", secret, data)
+		}
+	}
+	c, _ := load(filepath.Join(dir, "unscoped.yaml"))
+	if recorded := c.Interactions[0].Response; len(recorded.Body) != 10 || !recorded.Truncated {
+		t.Errorf("expected a truncated body of 10 bytes, got %d bytes, truncated %v", len(recorded.Body), recorded.Truncated)
+	}
+
+	player, err := NewPlayer(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ = http.NewRequest(http.MethodGet, server.URL+"/items?page=2&token=other&Session=xyz", nil)
+	if _, err := player.RoundTrip(req); err != nil {
+		t.Errorf("expected the request to match whatever its secrets, got %v", err)
+	}
+	req, _ = http.NewRequest(http.MethodGet, server.URL+"/items?page=3&token=s3cr3t&Session=abc", nil)
+	if _, err := player.RoundTrip(req); err == nil {
+		t.Error("expected a miss for another page")
+	}
+}
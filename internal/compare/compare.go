@@ -0,0 +1,302 @@
+// Package compare runs the same scenarios against two environments and reports the differences between their responses.
+package compare
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/qatoolist/RouTest/internal/interfaces"
+	"github.com/qatoolist/RouTest/internal/jsondiff"
+	"github.com/qatoolist/RouTest/internal/jsonpath"
+)
+
+// DefaultIgnoreHeaders are the response headers that are expected to differ between any two responses.
+var DefaultIgnoreHeaders = []string{
+	"Date", "Content-Length", "Age", "Expires", "Last-Modified", "Etag", "Set-Cookie",
+	"X-Request-Id", "X-Correlation-Id", "X-Amzn-Trace-Id", "Cf-Ray", "Via", "Server-Timing",
+}
+
+// Environment is an application to compare, together with the name it is reported with.
+type Environment struct {
+	Name        string
+	Application interfaces.Application
+}
+
+// Options configures what is compared.
+type Options struct {
+	// Ignore are the JSONPath expressions of the body fields ignored for every scenario.
+	Ignore []string
+
+	// IgnoreFor returns the JSONPath expressions of the body fields ignored for a single scenario,
+	// e.g. the ignore rules of its snapshot assertion.
+	IgnoreFor func(route, scenario string) []string
+
+	// IgnoreHeaders are the response headers that are not compared, in addition to DefaultIgnoreHeaders.
+	IgnoreHeaders []string
+}
+
+// Result is the comparison of the responses of a scenario in both environments.
+type Result struct {
+	RouteName    string
+	ScenarioName string
+
+	// Differences describes every difference in status, headers and body, one per line.
+	Differences []string
+
+	// Err is set when the scenario could not be compared because it received no response in an environment.
+	Err error
+
+	// BaseTiming and AgainstTiming are the timing breakdowns of the requests in both environments, when both responded.
+	// Timings are reported but never compared, as they always differ.
+	BaseTiming    interfaces.Timing
+	AgainstTiming interfaces.Timing
+}
+
+// Same reports whether both environments responded the same way.
+func (r *Result) Same() bool {
+	return r.Err == nil && len(r.Differences) == 0
+}
+
+// Report is the comparison of every scenario run against both environments.
+type Report struct {
+	Base    string
+	Against string
+	Results []*Result
+
+	// Interrupted is the error that stopped the runs, or nil if every scenario was executed.
+	Interrupted error
+}
+
+// Different returns the number of scenarios that responded differently or could not be compared.
+func (r *Report) Different() int {
+	n := 0
+	for _, result := range r.Results {
+		if !result.Same() {
+			n++
+		}
+	}
+	return n
+}
+
+// Compare runs every scenario of both applications, one environment after the other,
+// and compares the responses of the scenarios with the same route and scenario names.
+// The assertions of the scenarios do not matter: a scenario failing the same way in both environments is the same.
+func Compare(ctx context.Context, base, against Environment, opts Options) (*Report, error) {
+	ignore := make([]*jsonpath.Path, 0, len(opts.Ignore))
+	for _, expr := range opts.Ignore {
+		p, err := jsonpath.Parse(expr)
+		if err != nil {
+			return nil, err
+		}
+		ignore = append(ignore, p)
+	}
+	ignoreHeaders := append(append([]string{}, DefaultIgnoreHeaders...), opts.IgnoreHeaders...)
+
+	baseReport := base.Application.Run(ctx)
+	againstReport := against.Application.Run(ctx)
+
+	report := &Report{Base: base.Name, Against: against.Name}
+	if err := baseReport.Interrupted(); err != nil {
+		report.Interrupted = err
+	} else if err := againstReport.Interrupted(); err != nil {
+		report.Interrupted = err
+	}
+
+	againstResults := make(map[string]interfaces.ScenarioResult)
+	for _, result := range againstReport.Results() {
+		againstResults[key(result)] = result
+	}
+
+	for _, baseResult := range baseReport.Results() {
+		result := &Result{RouteName: baseResult.RouteName(), ScenarioName: baseResult.ScenarioName()}
+		report.Results = append(report.Results, result)
+
+		againstResult, ok := againstResults[key(baseResult)]
+		if !ok {
+			result.Err = fmt.Errorf("not executed against %s", against.Name)
+			continue
+		}
+		delete(againstResults, key(baseResult))
+
+		baseResp, againstResp := baseResult.Response(), againstResult.Response()
+		switch {
+		case baseResp == nil && againstResp == nil:
+			result.Err = fmt.Errorf("no response from either environment: %v", baseResult.Err())
+			continue
+		case baseResp == nil:
+			result.Err = fmt.Errorf("no response from %s: %v", base.Name, baseResult.Err())
+			continue
+		case againstResp == nil:
+			result.Err = fmt.Errorf("no response from %s: %v", against.Name, againstResult.Err())
+			continue
+		}
+
+		result.BaseTiming, result.AgainstTiming = baseResp.GetTiming(), againstResp.GetTiming()
+
+		paths := ignore
+		if opts.IgnoreFor != nil {
+			for _, expr := range opts.IgnoreFor(result.RouteName, result.ScenarioName) {
+				p, err := jsonpath.Parse(expr)
+				if err != nil {
+					return nil, err
+				}
+				paths = append(paths[:len(paths):len(paths)], p)
+			}
+		}
+
+		c := comparison{base: base.Name, against: against.Name}
+		c.status(baseResp, againstResp)
+		c.headers(baseResp, againstResp, ignoreHeaders)
+		c.body(baseResp, againstResp, paths)
+		result.Differences = c.differences
+	}
+
+	// Scenarios executed only against the second environment, e.g. because the first run was interrupted
+	for _, againstResult := range againstReport.Results() {
+		if _, ok := againstResults[key(againstResult)]; ok {
+			report.Results = append(report.Results, &Result{
+				RouteName:    againstResult.RouteName(),
+				ScenarioName: againstResult.ScenarioName(),
+				Err:          fmt.Errorf("not executed against %s", base.Name),
+			})
+		}
+	}
+
+	return report, nil
+}
+
+func key(result interfaces.ScenarioResult) string {
+	return result.RouteName() + "/" + result.ScenarioName()
+}
+
+// comparison collects the differences between two responses.
+type comparison struct {
+	base        string
+	against     string
+	differences []string
+}
+
+func (c *comparison) add(format string, args ...interface{}) {
+	c.differences = append(c.differences, fmt.Sprintf(format, args...))
+}
+
+func (c *comparison) status(base, against interfaces.Response) {
+	if base.GetStatusCode() != against.GetStatusCode() {
+		c.add("status: %s %d, %s %d", c.base, base.GetStatusCode(), c.against, against.GetStatusCode())
+	}
+}
+
+func (c *comparison) headers(base, against interfaces.Response, ignore []string) {
+	baseHeaders, againstHeaders := headerValues(base, ignore), headerValues(against, ignore)
+
+	names := make([]string, 0, len(baseHeaders)+len(againstHeaders))
+	for name := range baseHeaders {
+		names = append(names, name)
+	}
+	for name := range againstHeaders {
+		if _, ok := baseHeaders[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		b, inBase := baseHeaders[name]
+		a, inAgainst := againstHeaders[name]
+		switch {
+		case !inAgainst:
+			c.add("header %s: only in %s (%q)", name, c.base, b)
+		case !inBase:
+			c.add("header %s: only in %s (%q)", name, c.against, a)
+		case a != b:
+			c.add("header %s: %s %q, %s %q", name, c.base, b, c.against, a)
+		}
+	}
+}
+
+// headerValues returns the values of the headers of the response that are not ignored, by canonical name.
+func headerValues(resp interfaces.Response, ignore []string) map[string]string {
+	values := make(map[string][]string)
+	for _, header := range resp.GetHeaders() {
+		name := http.CanonicalHeaderKey(header.Key())
+		ignored := false
+		for _, i := range ignore {
+			if strings.EqualFold(i, name) {
+				ignored = true
+				break
+			}
+		}
+		if !ignored {
+			values[name] = append(values[name], header.Value())
+		}
+	}
+
+	joined := make(map[string]string, len(values))
+	for name, v := range values {
+		joined[name] = strings.Join(v, ", ")
+	}
+	return joined
+}
+
+func (c *comparison) body(base, against interfaces.Response, ignore []*jsonpath.Path) {
+	baseDoc, baseErr := decode(base.Bytes(), ignore)
+	againstDoc, againstErr := decode(against.Bytes(), ignore)
+	if baseErr != nil || againstErr != nil {
+		if !bytes.Equal(base.Bytes(), against.Bytes()) {
+			c.add("body: %s %d bytes, %s %d bytes, not both JSON", c.base, len(base.Bytes()), c.against, len(against.Bytes()))
+		}
+		return
+	}
+
+	for _, d := range jsondiff.Compare(baseDoc, againstDoc) {
+		switch {
+		case d.Missing:
+			c.add("body %s: only in %s (%s)", d.Path, c.base, jsondiff.FormatValue(d.Expected))
+		case d.Unexpected:
+			c.add("body %s: only in %s (%s)", d.Path, c.against, jsondiff.FormatValue(d.Actual))
+		default:
+			c.add("body %s: %s %s, %s %s", d.Path, c.base, jsondiff.FormatValue(d.Expected), c.against, jsondiff.FormatValue(d.Actual))
+		}
+	}
+}
+
+// decode decodes a JSON body and replaces the ignored fields.
+func decode(body []byte, ignore []*jsonpath.Path) (interface{}, error) {
+	var doc interface{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	for _, p := range ignore {
+		p.Replace(doc, func(interface{}) interface{} { return nil })
+	}
+	return doc, nil
+}
+
+/* Example usage -
+
+staging, err := suite.BuildForEnvironment("staging")
+if err != nil {
+    log.Fatal(err)
+}
+canary, err := suite.BuildForEnvironment("canary")
+if err != nil {
+    log.Fatal(err)
+}
+
+report, err := compare.Compare(ctx,
+    compare.Environment{Name: "staging", Application: staging},
+    compare.Environment{Name: "canary", Application: canary},
+    compare.Options{Ignore: []string{"$..updated_at"}, IgnoreFor: suite.SnapshotIgnore})
+if err != nil {
+    log.Fatal(err)
+}
+fmt.Printf("%d scenarios differ\n", report.Different())
+
+*/
@@ -0,0 +1,122 @@
+package compare
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/qatoolist/RouTest/internal/parser"
+)
+
+const testSuite = `
+host: {protocol: http, hostname: 127.0.0.1, port: %s}
+routes:
+  - {name: same, method: GET, path: /same, scenarios: [{name: example}]}
+  - {name: status, method: GET, path: /status, scenarios: [{name: example}]}
+  - {name: headers, method: GET, path: /headers, scenarios: [{name: example}]}
+  - {name: user, method: GET, path: /user, scenarios: [{name: example}]}
+  - {name: text, method: GET, path: /text, scenarios: [{name: example}]}
+`
+
+// TestCompare checks the differences reported in status, headers and JSON bodies, and the ignored ones.
+func TestCompare(t *testing.T) {
+	base := testEnvironment(t, "staging", map[string]string{
+		"/status":  "200",
+		"/headers": "X-Version: 1\nX-Only: yes",
+		"/user":    `{"id": 1, "name": "bob", "updated_at": "monday", "roles": ["admin"]}`,
+		"/text":    "pong",
+	})
+	against := testEnvironment(t, "canary", map[string]string{
+		"/status":  "503",
+		"/headers": "X-Version: 2",
+		"/user":    `{"id": 2, "name": "alice", "updated_at": "tuesday", "roles": ["admin", "ops"]}`,
+		"/text":    "pong!",
+	})
+
+	report, err := Compare(context.Background(), base, against, Options{
+		Ignore: []string{"$..updated_at"},
+		IgnoreFor: func(route, scenario string) []string {
+			if route == "user" {
+				return []string{"$.id"}
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]string{
+		"same/example":    nil,
+		"status/example":  {"status: staging 200, canary 503"},
+		"headers/example": {`header X-Only: only in staging ("yes")`, `header X-Version: staging "1", canary "2"`},
+		"user/example":    {`body $.name: staging "bob", canary "alice"`, `body $.roles[1]: only in canary ("ops")`},
+		"text/example":    {"body: staging 4 bytes, canary 5 bytes, not both JSON"},
+	}
+	if len(report.Results) != len(want) {
+		t.Fatalf("expected %d results, got %d", len(want), len(report.Results))
+	}
+	for _, result := range report.Results {
+		name := result.RouteName + "/" + result.ScenarioName
+		if result.Err != nil {
+			t.Errorf("%s: %v", name, result.Err)
+		}
+		if got := strings.Join(result.Differences, "\n"); got != strings.Join(want[name], "\n") {
+			t.Errorf("%s: expected:\n%s\ngot:\n%s", name, strings.Join(want[name], "\n"), got)
+		}
+	}
+	if report.Different() != 4 {
+		t.Errorf("expected 4 different scenarios, got %d", report.Different())
+	}
+}
+
+func TestCompareInvalidIgnore(t *testing.T) {
+	env := Environment{Name: "staging"}
+	if _, err := Compare(context.Background(), env, env, Options{Ignore: []string{"$["}}); err == nil {
+		t.Error("expected an error for an invalid JSONPath expression")
+	}
+}
+
+// testEnvironment serves the given responses, by path: a status code, headers on lines of "Name: value", or a body.
+func testEnvironment(t *testing.T, name string, responses map[string]string) Environment {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := responses[r.URL.Path]
+		switch {
+		case r.URL.Path == "/status":
+			var status int
+			fmt.Sscan(response, &status)
+			w.WriteHeader(status)
+		case r.URL.Path == "/headers":
+			for _, line := range strings.Split(response, "\n") {
+				parts := strings.SplitN(line, ": ", 2)
+				w.Header().Set(parts[0], parts[1])
+			}
+		default:
+			fmt.Fprint(w, response)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	_, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	suite, err := parser.ParseSuite([]byte(fmt.Sprintf(testSuite, port)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	app, err := suite.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.SetLogger(log.New(ioutil.Discard, "", 0))
+	return Environment{Name: name, Application: app}
+}
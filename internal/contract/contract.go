@@ -0,0 +1,153 @@
+// Package contract implements consumer-driven contract testing. Consumers publish the requests of their scenarios
+// and the response fields they rely on as contracts in a plain directory, e.g. checked into a shared repository,
+// and providers verify every contract of the directory against a running instance.
+package contract
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Contract is the set of interactions a consumer expects from a provider.
+// It is stored as <dir>/<provider>/<consumer>.yaml.
+type Contract struct {
+	Consumer     string        `yaml:"consumer"`
+	Provider     string        `yaml:"provider"`
+	Interactions []Interaction `yaml:"interactions"`
+}
+
+// Interaction is a request of the consumer and what it relies on in the response.
+type Interaction struct {
+	// Description names the scenario the interaction was derived from, as route/scenario.
+	Description string   `yaml:"description"`
+	Request     Request  `yaml:"request"`
+	Response    Response `yaml:"response"`
+}
+
+// Request is the request sent by the consumer, relative to the base URL of the provider.
+type Request struct {
+	Method string `yaml:"method"`
+
+	// Path is the path of the request, with its query string if any.
+	Path    string            `yaml:"path"`
+	Headers map[string]string `yaml:"headers,omitempty"`
+	Body    string            `yaml:"body,omitempty"`
+}
+
+// Response is what the consumer relies on in the response.
+type Response struct {
+	Status int `yaml:"status"`
+
+	// Headers are the expected values of response headers; Content-Type is compared without its parameters.
+	Headers map[string]string `yaml:"headers,omitempty"`
+
+	// Fields are the fields of the JSON body the consumer reads.
+	Fields []Field `yaml:"fields,omitempty"`
+}
+
+// Field is a field of the response body a consumer relies on: it must be present with the given type,
+// and with the given value when the consumer relies on the value itself.
+type Field struct {
+	// Path is the JSONPath of the field, e.g. $.id or $.items[*].name.
+	Path string `yaml:"path"`
+
+	// Type is one of string, number, boolean, object, array and null.
+	Type string `yaml:"type"`
+
+	// Value is the exact value expected, or nil when any value of the type is accepted.
+	Value interface{} `yaml:"value,omitempty"`
+}
+
+// unsafeChars are the characters replaced in the consumer and provider names to build file names.
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// PathFor returns the path of the contract between a consumer and a provider in the directory.
+func PathFor(dir, provider, consumer string) string {
+	return filepath.Join(dir, unsafeChars.ReplaceAllString(provider, "_"), unsafeChars.ReplaceAllString(consumer, "_")+".yaml")
+}
+
+// Save writes the contract to its path in the directory, replacing the previous version.
+func (c *Contract) Save(dir string) error {
+	path := PathFor(dir, c.Provider, c.Consumer)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0o644)
+}
+
+// Load reads the contract at the given path.
+func Load(path string) (*Contract, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Contract
+	if err := yaml.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if c.Consumer == "" || c.Provider == "" {
+		return nil, fmt.Errorf("%s: consumer and provider are required", path)
+	}
+	return &c, nil
+}
+
+// LoadDir reads the contracts of the directory, those of a single provider when provider is not empty,
+// ordered by provider and consumer.
+func LoadDir(dir, provider string) ([]*Contract, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*", "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	var contracts []*Contract
+	for _, path := range paths {
+		c, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		if provider == "" || c.Provider == provider {
+			contracts = append(contracts, c)
+		}
+	}
+	sort.Slice(contracts, func(i, j int) bool {
+		if contracts[i].Provider != contracts[j].Provider {
+			return contracts[i].Provider < contracts[j].Provider
+		}
+		return contracts[i].Consumer < contracts[j].Consumer
+	})
+	return contracts, nil
+}
+
+/* Example contract - contracts/users-api/web-app.yaml
+
+consumer: web-app
+provider: users-api
+interactions:
+  - description: get-user/existing user
+    request:
+      method: GET
+      path: /users/1
+      headers:
+        Accept: application/json
+    response:
+      status: 200
+      headers:
+        Content-Type: application/json
+      fields:
+        - path: $.id
+          type: number
+        - path: $.status
+          type: string
+          value: active
+
+*/
@@ -0,0 +1,108 @@
+package contract
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/qatoolist/RouTest/internal/parser"
+)
+
+// The consumer reaches the provider under /api, e.g. through a gateway.
+const consumerSuite = `
+host: {protocol: http, hostname: "%s/api"}
+routes:
+  - name: get-user
+    method: GET
+    path: /users/{id}
+    scenarios:
+      - name: existing user
+        path_variables: {id: "1"}
+        query: {fields: "id,name"}
+        headers: {Authorization: Bearer secret, X-Tenant: acme}
+        contract: {fields: [$.name], values: [$.id]}
+      - name: exploratory
+        path_variables: {id: "2"}
+        expect: {status: 500}
+        contract: false
+`
+
+// TestPublishVerify publishes the contract of a consumer suite and verifies it against providers.
+func TestPublishVerify(t *testing.T) {
+	user := `{"id": 1, "name": "bob", "email": "bob@example.com"}`
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.TrimPrefix(r.URL.Path, "/api") != "/users/1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, user)
+	}))
+	defer provider.Close()
+
+	suite, err := parser.ParseSuite([]byte(fmt.Sprintf(consumerSuite, strings.TrimPrefix(provider.URL, "http://"))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	app, err := suite.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	capture := NewCapture(nil)
+	app.SetHTTPClient(&http.Client{Transport: capture})
+	report := app.Run(context.Background())
+
+	contract, err := capture.Build(report, Options{Consumer: "web", Provider: "users", Fields: suite.ContractFields})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(contract.Interactions) != 1 {
+		t.Fatalf("expected 1 interaction, got %d", len(contract.Interactions))
+	}
+	request := contract.Interactions[0].Request
+	if request.Path != "/users/1?fields=id%2Cname" {
+		t.Errorf("expected the path relative to the base URL, got %s", request.Path)
+	}
+	if request.Headers["X-Tenant"] != "acme" || request.Headers["Authorization"] != "" {
+		t.Errorf("expected the headers without credentials, got %v", request.Headers)
+	}
+
+	dir := t.TempDir()
+	if err := contract.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		user    string
+		wantErr string
+	}{
+		{"same response", user, ""},
+		{"added field", `{"id": 1, "name": "bob", "age": 3}`, ""},
+		{"changed name", `{"id": 1, "name": "alice"}`, ""},
+		{"changed id", `{"id": 2, "name": "bob"}`, "expects $.id to be 1, got 2"},
+		{"changed type", `{"id": 1, "name": 7}`, "expects $.name to be a string, got a number"},
+		{"missing field", `{"id": 1}`, "expects $.name (string), missing"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			user = test.user
+			result, err := Verify(context.Background(), dir, VerifyOptions{BaseURL: provider.URL + "/api"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := ""
+			if r := result.Results[0]; r.Err != nil {
+				got = r.Err.Error()
+			} else {
+				got = strings.Join(r.Failures, "\n")
+			}
+			if !strings.Contains(got, test.wantErr) || (test.wantErr == "") != (got == "") {
+				t.Errorf("expected %q, got %q", test.wantErr, got)
+			}
+		})
+	}
+}
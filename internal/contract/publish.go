@@ -0,0 +1,327 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/qatoolist/RouTest/internal/cassette"
+	"github.com/qatoolist/RouTest/internal/interfaces"
+	"github.com/qatoolist/RouTest/internal/jsonpath"
+	"github.com/qatoolist/RouTest/internal/models"
+)
+
+// transportHeaders are the request headers set by the HTTP client rather than by the consumer.
+var transportHeaders = []string{"User-Agent", "Accept-Encoding", "Content-Length", "Host", "Connection"}
+
+// Capture is an http.RoundTripper sending the requests with the next RoundTripper and remembering
+// the last request of every scenario, so that the contract can be built from them once the scenarios passed.
+type Capture struct {
+	next     http.RoundTripper
+	mu       sync.Mutex
+	requests map[string]Request
+}
+
+// NewCapture creates a new Capture sending the requests with next, or http.DefaultTransport when next is nil.
+func NewCapture(next http.RoundTripper) *Capture {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Capture{next: next, requests: make(map[string]Request)}
+}
+
+// RoundTrip remembers the request as the one of its scenario and sends it.
+func (c *Capture) RoundTrip(req *http.Request) (*http.Response, error) {
+	scenario, ok := models.ScenarioFromContext(req.Context())
+	if !ok {
+		return c.next.RoundTrip(req)
+	}
+
+	var body []byte
+	if req.GetBody != nil {
+		r, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		body, err = ioutil.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	headers := make(map[string]string)
+	for key, values := range req.Header {
+		if !contains(cassette.DefaultFilterHeaders, key) && !contains(transportHeaders, key) {
+			headers[key] = strings.Join(values, ", ")
+		}
+	}
+	if len(headers) == 0 {
+		headers = nil
+	}
+
+	c.mu.Lock()
+	c.requests[key(scenario)] = Request{
+		Method:  req.Method,
+		Path:    relativeURI(req.URL, scenario),
+		Headers: headers,
+		Body:    string(body),
+	}
+	c.mu.Unlock()
+
+	return c.next.RoundTrip(req)
+}
+
+// relativeURI returns the request URI relative to the base URL of the application of the scenario, e.g. /users/1
+// for https://example.com/api/users/1 with the base URL https://example.com/api, as the provider may be served
+// under another base path.
+func relativeURI(u *url.URL, scenario interfaces.Scenario) string {
+	uri := u.RequestURI()
+	route := scenario.GetParentRoute()
+	if route == nil || route.GetParentApplication() == nil || route.GetParentApplication().GetHost() == nil {
+		return uri
+	}
+	base, err := url.Parse(route.GetParentApplication().GetHost().BaseURL())
+	if err != nil {
+		return uri
+	}
+	prefix := strings.TrimSuffix(base.EscapedPath(), "/")
+	if prefix == "" || !strings.HasPrefix(uri, prefix+"/") {
+		return uri
+	}
+	return strings.TrimPrefix(uri, prefix)
+}
+
+func key(scenario interfaces.Scenario) string {
+	var route string
+	if r := scenario.GetParentRoute(); r != nil {
+		route = r.GetName()
+	}
+	return route + "/" + scenario.GetName()
+}
+
+// Options configures how the contract of a consumer is built.
+type Options struct {
+	Consumer string
+	Provider string
+
+	// Fields returns, for a scenario, the JSONPath expressions of the response fields the consumer relies on,
+	// matched by type, and of those whose exact value it relies on, and whether the scenario is part of the contract.
+	// Without expressions, every field of the response body is included, matched by type. Nil includes every scenario.
+	Fields func(route, scenario string) (types, values []string, include bool)
+}
+
+// Build builds the contract from the results of a run, one interaction per scenario.
+// A contract must only describe what the consumer was shown to rely on: every scenario included must have passed.
+func (c *Capture) Build(report interfaces.Report, opts Options) (*Contract, error) {
+	if opts.Consumer == "" || opts.Provider == "" {
+		return nil, errors.New("consumer and provider are required")
+	}
+
+	contract := &Contract{Consumer: opts.Consumer, Provider: opts.Provider}
+	for _, result := range report.Results() {
+		description := result.RouteName() + "/" + result.ScenarioName()
+		var types, values []string
+		if opts.Fields != nil {
+			var include bool
+			types, values, include = opts.Fields(result.RouteName(), result.ScenarioName())
+			if !include {
+				continue
+			}
+		}
+		if !result.Passed() {
+			return nil, fmt.Errorf("%s failed, contracts are only built from passing scenarios", description)
+		}
+
+		c.mu.Lock()
+		request, ok := c.requests[description]
+		c.mu.Unlock()
+		if !ok {
+			return nil, fmt.Errorf("%s: no request was sent", description)
+		}
+
+		response, err := expectedResponse(result.Response(), types, values)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", description, err)
+		}
+		contract.Interactions = append(contract.Interactions, Interaction{
+			Description: description,
+			Request:     request,
+			Response:    response,
+		})
+	}
+	return contract, nil
+}
+
+// expectedResponse describes what the consumer relies on in the response.
+func expectedResponse(resp interfaces.Response, types, values []string) (Response, error) {
+	expected := Response{Status: resp.GetStatusCode()}
+	if contentType, err := resp.HeaderValue("Content-Type"); err == nil && contentType != "" {
+		if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+			expected.Headers = map[string]string{"Content-Type": mediaType}
+		}
+	}
+
+	var body interface{}
+	dec := json.NewDecoder(bytes.NewReader(resp.Bytes()))
+	dec.UseNumber()
+	if err := dec.Decode(&body); err != nil {
+		if len(types)+len(values) > 0 {
+			return expected, errors.New("the response body is not JSON, its fields cannot be part of the contract")
+		}
+		return expected, nil
+	}
+
+	if len(types)+len(values) == 0 {
+		leaves(body, "$", &expected.Fields)
+		return expected, nil
+	}
+
+	for _, expr := range types {
+		field, err := fieldAt(body, expr, false)
+		if err != nil {
+			return expected, err
+		}
+		expected.Fields = append(expected.Fields, field)
+	}
+	for _, expr := range values {
+		field, err := fieldAt(body, expr, true)
+		if err != nil {
+			return expected, err
+		}
+		expected.Fields = append(expected.Fields, field)
+	}
+	return expected, nil
+}
+
+// fieldAt returns the field selected by a JSONPath expression in the body, with its value if withValue is set.
+func fieldAt(body interface{}, expr string, withValue bool) (Field, error) {
+	p, err := jsonpath.Parse(expr)
+	if err != nil {
+		return Field{}, err
+	}
+	found := p.Get(body)
+	if len(found) == 0 {
+		return Field{}, fmt.Errorf("%s is not in the response body", expr)
+	}
+	field := Field{Path: expr, Type: typeOf(found[0])}
+	if withValue {
+		field.Value = plain(found[0])
+	}
+	return field, nil
+}
+
+// leaves adds a field matched by type for every scalar, empty object and empty array of the value.
+// The items of an array are described once, by the fields of its first item under [*].
+func leaves(value interface{}, path string, fields *[]Field) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			break
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			leaves(v[name], member(path, name), fields)
+		}
+		return
+	case []interface{}:
+		if len(v) == 0 {
+			break
+		}
+		leaves(v[0], path+"[*]", fields)
+		return
+	}
+	*fields = append(*fields, Field{Path: path, Type: typeOf(value)})
+}
+
+// member returns the path of a member of the object at path, quoting names that are not identifiers.
+func member(path, name string) string {
+	for _, r := range name {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return fmt.Sprintf("%s['%s']", path, name)
+		}
+	}
+	if name == "" {
+		return path + "['']"
+	}
+	return path + "." + name
+}
+
+// typeOf returns the JSON type of a decoded value.
+func typeOf(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case json.Number, float64, int, int64:
+		return "number"
+	case bool:
+		return "boolean"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	}
+	return "null"
+}
+
+// plain converts the numbers of a decoded value, so that they are written to the contract as numbers.
+func plain(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for name, e := range v {
+			v[name] = plain(e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = plain(e)
+		}
+	}
+	return value
+}
+
+func contains(list []string, name string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, name) {
+			return true
+		}
+	}
+	return false
+}
+
+/* Example usage -
+
+capture := contract.NewCapture(nil)
+app.SetHTTPClient(&http.Client{Transport: capture})
+
+report := app.Run(ctx)
+
+c, err := capture.Build(report, contract.Options{
+    Consumer: "web-app",
+    Provider: "users-api",
+    Fields:   suite.ContractFields,
+})
+if err != nil {
+    log.Fatal(err)
+}
+if err := c.Save("contracts"); err != nil {
+    log.Fatal(err)
+}
+
+*/
@@ -0,0 +1,219 @@
+package contract
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/qatoolist/RouTest/internal/jsonpath"
+)
+
+// VerifyOptions configures the verification of contracts against a provider.
+type VerifyOptions struct {
+	// BaseURL is the URL of the provider the requests of the interactions are sent to, e.g. http://localhost:8080.
+	BaseURL string
+
+	// Provider verifies only the contracts of this provider. Empty verifies every contract of the directory.
+	Provider string
+
+	// Headers are added to every request, e.g. the credentials that contracts never carry.
+	Headers map[string]string
+
+	// Client sends the requests. Defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// Result is the verification of a single interaction.
+type Result struct {
+	Consumer    string
+	Provider    string
+	Description string
+
+	// Failures describes every expectation of the consumer the response did not meet, one per line.
+	Failures []string
+
+	// Err is set when the request could not be sent or received no response.
+	Err error
+}
+
+// Passed reports whether the provider met every expectation of the interaction.
+func (r *Result) Passed() bool {
+	return r.Err == nil && len(r.Failures) == 0
+}
+
+// Report is the verification of every interaction of the contracts.
+type Report struct {
+	Results []*Result
+}
+
+// Failed returns the number of interactions whose expectations were not met.
+func (r *Report) Failed() int {
+	n := 0
+	for _, result := range r.Results {
+		if !result.Passed() {
+			n++
+		}
+	}
+	return n
+}
+
+// Verify sends the request of every interaction of the contracts of the directory to the provider
+// and checks the response against what the consumer relies on. Interactions are verified in order,
+// and cancelling the context stops the verification with the results collected so far.
+func Verify(ctx context.Context, dir string, opts VerifyOptions) (*Report, error) {
+	contracts, err := LoadDir(dir, opts.Provider)
+	if err != nil {
+		return nil, err
+	}
+	if len(contracts) == 0 {
+		if opts.Provider != "" {
+			return nil, fmt.Errorf("no contract of provider %s in %s", opts.Provider, dir)
+		}
+		return nil, fmt.Errorf("no contract in %s", dir)
+	}
+
+	client := opts.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	report := &Report{}
+	for _, c := range contracts {
+		for _, interaction := range c.Interactions {
+			if ctx.Err() != nil {
+				return report, ctx.Err()
+			}
+			result := &Result{Consumer: c.Consumer, Provider: c.Provider, Description: interaction.Description}
+			result.Failures, result.Err = verify(ctx, client, opts, interaction)
+			report.Results = append(report.Results, result)
+		}
+	}
+	return report, nil
+}
+
+// verify sends the request of the interaction and returns the expectations its response does not meet.
+func verify(ctx context.Context, client *http.Client, opts VerifyOptions, interaction Interaction) ([]string, error) {
+	url := strings.TrimSuffix(opts.BaseURL, "/") + interaction.Request.Path
+	req, err := http.NewRequestWithContext(ctx, interaction.Request.Method, url, strings.NewReader(interaction.Request.Body))
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range interaction.Request.Headers {
+		req.Header.Set(key, value)
+	}
+	for key, value := range opts.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	expected := interaction.Response
+	var failures []string
+	if resp.StatusCode != expected.Status {
+		failures = append(failures, fmt.Sprintf("expects status %d, got %d", expected.Status, resp.StatusCode))
+	}
+
+	for key, value := range expected.Headers {
+		actual := resp.Header.Get(key)
+		if strings.EqualFold(key, "Content-Type") {
+			actual, _, _ = mime.ParseMediaType(actual)
+		}
+		if actual != value {
+			failures = append(failures, fmt.Sprintf("expects header %s to be %q, got %q", key, value, actual))
+		}
+	}
+
+	if len(expected.Fields) == 0 {
+		return failures, nil
+	}
+	var doc interface{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return append(failures, "expects a JSON body, got one that is not JSON"), nil
+	}
+
+	for _, field := range expected.Fields {
+		if failure := checkField(doc, field); failure != "" {
+			failures = append(failures, failure)
+		}
+	}
+	return failures, nil
+}
+
+// checkField returns how the body fails to meet the expectation on a field, or an empty string.
+// With a wildcard, every selected value must meet it.
+func checkField(doc interface{}, field Field) string {
+	p, err := jsonpath.Parse(field.Path)
+	if err != nil {
+		return fmt.Sprintf("expects %s, an invalid path: %v", field.Path, err)
+	}
+	found := p.Get(doc)
+	if len(found) == 0 {
+		return fmt.Sprintf("expects %s (%s), missing", field.Path, field.Type)
+	}
+	for _, value := range found {
+		if actual := typeOf(value); actual != field.Type {
+			return fmt.Sprintf("expects %s to be a %s, got a %s", field.Path, field.Type, actual)
+		}
+		if field.Value != nil && !equal(field.Value, value) {
+			return fmt.Sprintf("expects %s to be %s, got %s", field.Path, format(field.Value), format(value))
+		}
+	}
+	return ""
+}
+
+// equal compares two JSON values whatever their Go representation, e.g. an int read from a contract and a json.Number.
+func equal(a, b interface{}) bool {
+	return reflect.DeepEqual(normalize(a), normalize(b))
+}
+
+func normalize(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var v interface{}
+	json.Unmarshal(data, &v)
+	return v
+}
+
+func format(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+/* Example usage -
+
+report, err := contract.Verify(ctx, "contracts", contract.VerifyOptions{
+    BaseURL:  "http://localhost:8080",
+    Provider: "users-api",
+    Headers:  map[string]string{"Authorization": "Bearer " + token},
+})
+if err != nil {
+    log.Fatal(err)
+}
+for _, result := range report.Results {
+    for _, failure := range result.Failures {
+        fmt.Printf("%s %s: %s\n", result.Consumer, result.Description, failure)
+    }
+}
+
+*/
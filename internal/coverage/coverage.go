@@ -0,0 +1,434 @@
+// Package coverage measures how much of an API a run exercised: which operations were called,
+// which of their documented response codes were observed and which of their parameters were varied.
+// The operations come from an OpenAPI document or, without one, from the routes of the application.
+package coverage
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/qatoolist/RouTest/internal/interfaces"
+	"github.com/qatoolist/RouTest/internal/models"
+	"github.com/qatoolist/RouTest/internal/openapi"
+)
+
+// Operation is an operation of the API, a method and a path template.
+type Operation struct {
+	Method string
+	Path   string
+
+	// Statuses are the documented response codes, e.g. 200, 4XX or default. Routes document none.
+	Statuses   []string
+	Parameters []Parameter
+}
+
+// Parameter is a parameter of an operation.
+type Parameter struct {
+	Name string `json:"name"`
+
+	// In is where the parameter is sent: path, query, header or cookie.
+	In string `json:"in"`
+}
+
+// String returns the parameter as "<in> <name>", e.g. "query page".
+func (p Parameter) String() string {
+	return p.In + " " + p.Name
+}
+
+// Spec is the API whose coverage is measured: its operations and how the path of a request maps to their paths.
+type Spec struct {
+	Operations []Operation
+	match      func(path string) (string, bool)
+}
+
+// FromOpenAPI returns the operations documented by an OpenAPI document.
+func FromOpenAPI(v *openapi.Validator) *Spec {
+	spec := &Spec{match: v.Match}
+	for _, op := range v.Operations() {
+		operation := Operation{Method: op.Method, Path: op.Path, Statuses: op.Statuses}
+		for _, p := range op.Parameters {
+			operation.Parameters = append(operation.Parameters, Parameter{Name: p.Name, In: p.In})
+		}
+		spec.Operations = append(spec.Operations, operation)
+	}
+	return spec
+}
+
+// FromRoutes returns the operations of the routes of an application. Their parameters are the variables
+// of their path and the query parameters declared on the route or its scenarios; routes document no status.
+func FromRoutes(registry interfaces.RouteRegistry) *Spec {
+	operations := make(map[string]*Operation)
+	for _, route := range models.Routes(registry) {
+		info := route.GetInfo()
+		if info == nil || info.GetMethod() == nil {
+			continue
+		}
+		key := info.GetMethod().String() + " " + info.GetPath()
+		op, ok := operations[key]
+		if !ok {
+			op = &Operation{Method: info.GetMethod().String(), Path: info.GetPath()}
+			for _, name := range pathVariables(info.GetPath()) {
+				op.Parameters = append(op.Parameters, Parameter{Name: name, In: "path"})
+			}
+			operations[key] = op
+		}
+
+		registries := []interfaces.ParametersRegistry{route.GetRouteParametersRegistry()}
+		for _, scenario := range *route.GetScenarioRegistry().GetScenarios() {
+			registries = append(registries, scenario.GetScenarioParametersRegistry())
+		}
+		for _, r := range registries {
+			if r == nil {
+				continue
+			}
+			for _, q := range r.GetQueryParameters() {
+				if parameter := (Parameter{Name: q.Key(), In: "query"}); !hasParameter(op.Parameters, parameter) {
+					op.Parameters = append(op.Parameters, parameter)
+				}
+			}
+		}
+	}
+
+	spec := &Spec{}
+	var patterns []*template
+	for _, op := range operations {
+		spec.Operations = append(spec.Operations, *op)
+		patterns = append(patterns, compile(op.Path))
+	}
+	sort.Slice(spec.Operations, func(i, j int) bool {
+		if spec.Operations[i].Path != spec.Operations[j].Path {
+			return spec.Operations[i].Path < spec.Operations[j].Path
+		}
+		return spec.Operations[i].Method < spec.Operations[j].Method
+	})
+	// The most specific paths first, so that /users/me is preferred over /users/{id}.
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i].names) != len(patterns[j].names) {
+			return len(patterns[i].names) < len(patterns[j].names)
+		}
+		return patterns[i].path < patterns[j].path
+	})
+	spec.match = func(path string) (string, bool) {
+		for _, t := range patterns {
+			if _, prefix, ok := t.values(path); ok && prefix == "" {
+				return t.path, true
+			}
+		}
+		return "", false
+	}
+	return spec
+}
+
+// Call is a request sent during the run and the status it was answered with.
+type Call struct {
+	Method string
+	URL    *url.URL
+	Header http.Header
+	Status int
+}
+
+// Recorder is an http.RoundTripper sending the requests with the next RoundTripper and recording every call
+// answered by the server, retries and polls included. The scenarios whose exchange opens its own connection
+// instead of using the HTTP client of the application, i.e. WebSocket and gRPC scenarios, bypass it: their
+// calls are not recorded and their operations are reported as not called.
+type Recorder struct {
+	next  http.RoundTripper
+	mu    sync.Mutex
+	calls []Call
+}
+
+// NewRecorder creates a new Recorder sending the requests with next, or http.DefaultTransport when next is nil.
+func NewRecorder(next http.RoundTripper) *Recorder {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Recorder{next: next}
+}
+
+// RoundTrip sends the request and records the call when a response is received.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	r.mu.Lock()
+	r.calls = append(r.calls, Call{Method: req.Method, URL: req.URL, Header: req.Header.Clone(), Status: resp.StatusCode})
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// Calls returns the calls recorded so far.
+func (r *Recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Call(nil), r.calls...)
+}
+
+// Ratio is the part of a total that was covered.
+type Ratio struct {
+	Covered int     `json:"covered"`
+	Total   int     `json:"total"`
+	Percent float64 `json:"percent"`
+}
+
+func ratio(covered, total int) Ratio {
+	r := Ratio{Covered: covered, Total: total}
+	if total > 0 {
+		r.Percent = float64(int(float64(covered)*1000/float64(total)+0.5)) / 10
+	}
+	return r
+}
+
+// OperationCoverage is the coverage of a single operation.
+type OperationCoverage struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Calls  int    `json:"calls"`
+
+	// Observed are the response codes received, e.g. 200 and 404.
+	Observed []int `json:"observed_statuses"`
+
+	// Uncovered are the documented response codes no response matched.
+	Uncovered []string `json:"uncovered_statuses"`
+
+	// Unvaried are the parameters never sent with two different values, absence counting as a value.
+	Unvaried []Parameter `json:"unvaried_parameters"`
+}
+
+// Report is the coverage of the API by a run.
+type Report struct {
+	Operations Ratio `json:"operations"`
+	Statuses   Ratio `json:"statuses"`
+	Parameters Ratio `json:"parameters"`
+
+	// Coverage lists every operation, in the order of the spec.
+	Coverage []OperationCoverage `json:"coverage"`
+
+	// Unmatched lists the calls matching no operation, as "<method> <path>".
+	Unmatched []string `json:"unmatched"`
+}
+
+// Uncovered returns the operations that were never called.
+func (r *Report) Uncovered() []OperationCoverage {
+	var uncovered []OperationCoverage
+	for _, op := range r.Coverage {
+		if op.Calls == 0 {
+			uncovered = append(uncovered, op)
+		}
+	}
+	return uncovered
+}
+
+// Measure returns the coverage of the spec by the calls.
+func (s *Spec) Measure(calls []Call) *Report {
+	byKey := make(map[string][]Call)
+	unmatched := make(map[string]bool)
+	for _, call := range calls {
+		path, ok := s.match(call.URL.Path)
+		if ok && s.operation(call.Method, path) {
+			key := call.Method + " " + path
+			byKey[key] = append(byKey[key], call)
+		} else {
+			unmatched[call.Method+" "+call.URL.Path] = true
+		}
+	}
+
+	report := &Report{Unmatched: []string{}}
+	var called, statuses, covered, parameters, varied int
+	for _, op := range s.Operations {
+		opCalls := byKey[op.Method+" "+op.Path]
+		c := OperationCoverage{
+			Method:    op.Method,
+			Path:      op.Path,
+			Calls:     len(opCalls),
+			Observed:  []int{},
+			Uncovered: []string{},
+			Unvaried:  []Parameter{},
+		}
+		if len(opCalls) > 0 {
+			called++
+		}
+
+		matched := make(map[string]bool)
+		observed := make(map[int]bool)
+		for _, call := range opCalls {
+			observed[call.Status] = true
+			if status, ok := documented(op.Statuses, call.Status); ok {
+				matched[status] = true
+			}
+		}
+		for status := range observed {
+			c.Observed = append(c.Observed, status)
+		}
+		sort.Ints(c.Observed)
+		for _, status := range op.Statuses {
+			if !matched[status] {
+				c.Uncovered = append(c.Uncovered, status)
+			}
+		}
+		statuses += len(op.Statuses)
+		covered += len(op.Statuses) - len(c.Uncovered)
+
+		t := compile(op.Path)
+		for _, p := range op.Parameters {
+			values := make(map[string]bool)
+			for _, call := range opCalls {
+				values[valueOf(t, call, p)] = true
+			}
+			if len(values) < 2 {
+				c.Unvaried = append(c.Unvaried, p)
+			}
+		}
+		parameters += len(op.Parameters)
+		varied += len(op.Parameters) - len(c.Unvaried)
+
+		report.Coverage = append(report.Coverage, c)
+	}
+
+	for call := range unmatched {
+		report.Unmatched = append(report.Unmatched, call)
+	}
+	sort.Strings(report.Unmatched)
+
+	report.Operations = ratio(called, len(s.Operations))
+	report.Statuses = ratio(covered, statuses)
+	report.Parameters = ratio(varied, parameters)
+	return report
+}
+
+func (s *Spec) operation(method, path string) bool {
+	for _, op := range s.Operations {
+		if op.Method == method && op.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// documented returns the documented response code a status is covered by: the exact code first,
+// then its range, e.g. 4XX, then the default response.
+func documented(statuses []string, status int) (string, bool) {
+	code := strconv.Itoa(status)
+	for _, s := range statuses {
+		if s == code {
+			return s, true
+		}
+	}
+	for _, s := range statuses {
+		if strings.EqualFold(s, code[:1]+"XX") {
+			return s, true
+		}
+	}
+	for _, s := range statuses {
+		if s == "default" {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+// absent is the value of a parameter that was not sent.
+const absent = "\x00"
+
+// valueOf returns the value a parameter was sent with in a call.
+func valueOf(t *template, call Call, p Parameter) string {
+	switch p.In {
+	case "path":
+		values, _, _ := t.values(call.URL.Path)
+		if value, ok := values[p.Name]; ok {
+			return value
+		}
+	case "query":
+		if values, ok := call.URL.Query()[p.Name]; ok {
+			return strings.Join(values, ",")
+		}
+	case "header":
+		if values := call.Header.Values(p.Name); len(values) > 0 {
+			return strings.Join(values, ",")
+		}
+	case "cookie":
+		if cookie, err := (&http.Request{Header: call.Header}).Cookie(p.Name); err == nil {
+			return cookie.Value
+		}
+	}
+	return absent
+}
+
+var variable = regexp.MustCompile(`\{\{?([^/{}]+)\}?\}`)
+
+// template matches the paths of a path template and extracts the values of its variables.
+// The path of a server, e.g. /v1, may precede the template.
+type template struct {
+	path    string
+	names   []string
+	pattern *regexp.Regexp
+}
+
+func compile(path string) *template {
+	t := &template{path: path}
+	var b strings.Builder
+	b.WriteString("^(.*?)")
+	last := 0
+	for _, loc := range variable.FindAllStringSubmatchIndex(path, -1) {
+		b.WriteString(regexp.QuoteMeta(path[last:loc[0]]))
+		b.WriteString("([^/]+)")
+		t.names = append(t.names, path[loc[2]:loc[3]])
+		last = loc[1]
+	}
+	b.WriteString(regexp.QuoteMeta(path[last:]))
+	b.WriteString("/?$")
+	t.pattern = regexp.MustCompile(b.String())
+	return t
+}
+
+// values returns the values of the variables of the template in a path and the path of the server preceding them,
+// and whether the path matches.
+func (t *template) values(path string) (map[string]string, string, bool) {
+	match := t.pattern.FindStringSubmatch(path)
+	if match == nil {
+		return nil, "", false
+	}
+	values := make(map[string]string, len(t.names))
+	for i, name := range t.names {
+		values[name] = match[i+2]
+	}
+	return values, match[1], true
+}
+
+func pathVariables(path string) []string {
+	var names []string
+	for _, match := range variable.FindAllStringSubmatch(path, -1) {
+		names = append(names, match[1])
+	}
+	return names
+}
+
+func hasParameter(parameters []Parameter, p Parameter) bool {
+	for _, parameter := range parameters {
+		if parameter == p {
+			return true
+		}
+	}
+	return false
+}
+
+/* Example usage -
+
+recorder := coverage.NewRecorder(app.GetHTTPClient().Transport)
+app.SetHTTPClient(&http.Client{Transport: recorder})
+
+app.Run(ctx)
+
+spec := coverage.FromRoutes(app.RouteRegistry)
+if validator, err := openapi.Load("openapi.yaml"); err == nil {
+    spec = coverage.FromOpenAPI(validator)
+}
+report := spec.Measure(recorder.Calls())
+fmt.Printf("%.1f%% of the operations called\n", report.Operations.Percent)
+
+*/
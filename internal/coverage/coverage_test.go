@@ -0,0 +1,175 @@
+package coverage
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/qatoolist/RouTest/internal/openapi"
+	"github.com/qatoolist/RouTest/internal/parser"
+)
+
+const testDocument = `
+openapi: 3.0.3
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /users:
+    get:
+      responses:
+        '200': {description: users}
+  /users/{id}:
+    parameters:
+      - {name: id, in: path}
+    get:
+      parameters:
+        - {name: verbose, in: query}
+        - {name: X-Tenant, in: header}
+      responses:
+        '200': {description: user}
+        '404': {description: not found}
+        5XX: {description: server error}
+  /users/me:
+    get:
+      responses:
+        '200': {description: current user}
+`
+
+// TestMeasure checks the operations called, the statuses covered and the parameters varied by calls
+// sent to the server of an OpenAPI document.
+func TestMeasure(t *testing.T) {
+	v, err := openapi.New([]byte(testDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := FromOpenAPI(v).Measure([]Call{
+		call(t, "GET", "/v1/users/1?verbose=true", 200),
+		call(t, "GET", "/v1/users/2", 404),
+		call(t, "GET", "/v1/users/2", 404),
+		call(t, "GET", "/v1/users/me", 200),
+		call(t, "DELETE", "/v1/users/1", 204),
+		call(t, "GET", "/v1/orders", 200),
+	})
+
+	if want := (Ratio{Covered: 2, Total: 3, Percent: 66.7}); report.Operations != want {
+		t.Errorf("expected operations %+v, got %+v", want, report.Operations)
+	}
+	if want := (Ratio{Covered: 3, Total: 5, Percent: 60}); report.Statuses != want {
+		t.Errorf("expected statuses %+v, got %+v", want, report.Statuses)
+	}
+	if want := (Ratio{Covered: 2, Total: 3, Percent: 66.7}); report.Parameters != want {
+		t.Errorf("expected parameters %+v, got %+v", want, report.Parameters)
+	}
+
+	user := report.Coverage[2]
+	if user.Path != "/users/{id}" || user.Calls != 3 {
+		t.Fatalf("expected 3 calls of /users/{id}, got %+v", user)
+	}
+	if !reflect.DeepEqual(user.Observed, []int{200, 404}) || !reflect.DeepEqual(user.Uncovered, []string{"5XX"}) {
+		t.Errorf("expected the statuses 200 and 404 observed and 5XX uncovered, got %v and %v", user.Observed, user.Uncovered)
+	}
+	if !reflect.DeepEqual(user.Unvaried, []Parameter{{Name: "X-Tenant", In: "header"}}) {
+		t.Errorf("expected the header X-Tenant never varied, got %v", user.Unvaried)
+	}
+	if uncovered := report.Uncovered(); len(uncovered) != 1 || uncovered[0].Path != "/users" {
+		t.Errorf("expected /users to be uncovered, got %+v", uncovered)
+	}
+	if want := []string{"DELETE /v1/users/1", "GET /v1/orders"}; !reflect.DeepEqual(report.Unmatched, want) {
+		t.Errorf("expected the unmatched calls %v, got %v", want, report.Unmatched)
+	}
+}
+
+// TestFromRoutes checks the operations of the routes of a suite and the parameters their scenarios declare.
+func TestFromRoutes(t *testing.T) {
+	suite, err := parser.ParseSuite([]byte(`
+host: {hostname: localhost}
+routes:
+  - {name: get-user, method: GET, path: '/users/{id}', scenarios: [{name: a, query: {verbose: 'true'}}]}
+  - {name: get-user-again, method: GET, path: '/users/{id}', scenarios: [{name: b, query: {page: '2'}}]}
+  - {name: me, method: GET, path: /users/me, scenarios: [{name: c}]}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	app, err := suite.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := FromRoutes(app.RouteRegistry)
+
+	var operations []string
+	for _, op := range spec.Operations {
+		operations = append(operations, op.Method+" "+op.Path)
+	}
+	if want := []string{"GET /users/me", "GET /users/{id}"}; !reflect.DeepEqual(operations, want) {
+		t.Fatalf("expected the operations %v, got %v", want, operations)
+	}
+	var parameters []string
+	for _, p := range spec.Operations[1].Parameters {
+		parameters = append(parameters, p.String())
+	}
+	if got := strings.Join(parameters, ", "); got != "path id, query verbose, query page" {
+		t.Errorf("expected the path variable and the query parameters of both routes, got %s", got)
+	}
+
+	// Routes have no server path, and the literal path is preferred over the template.
+	report := spec.Measure([]Call{call(t, "GET", "/users/me", 200), call(t, "GET", "/v1/users/1", 200)})
+	if report.Coverage[0].Calls != 1 || report.Coverage[1].Calls != 0 {
+		t.Errorf("expected a single call of /users/me, got %+v", report.Coverage)
+	}
+}
+
+func TestDocumented(t *testing.T) {
+	tests := []struct {
+		statuses []string
+		status   int
+		want     string
+	}{
+		{[]string{"default", "4XX", "404"}, 404, "404"},
+		{[]string{"default", "4XX", "404"}, 400, "4XX"},
+		{[]string{"default", "4xx"}, 418, "4xx"},
+		{[]string{"default", "4XX"}, 500, "default"},
+		{[]string{"200", "4XX"}, 500, ""},
+		{nil, 200, ""},
+	}
+	for _, test := range tests {
+		got, ok := documented(test.statuses, test.status)
+		if got != test.want || ok != (test.want != "") {
+			t.Errorf("%v, %d: expected %q, got %q", test.statuses, test.status, test.want, got)
+		}
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	tests := []struct {
+		template string
+		path     string
+		want     map[string]string
+		prefix   string
+		ok       bool
+	}{
+		{"/users/{id}", "/users/42", map[string]string{"id": "42"}, "", true},
+		{"/users/{id}", "/users/42/", map[string]string{"id": "42"}, "", true},
+		{"/users/{id}/posts/{{post}}", "/v1/users/42/posts/7", map[string]string{"id": "42", "post": "7"}, "/v1", true},
+		{"/users/me", "/api/v1/users/me", map[string]string{}, "/api/v1", true},
+		{"/users/{id}", "/users/42/posts", nil, "", false},
+		{"/users/{id}", "/users/", nil, "", false},
+	}
+	for _, test := range tests {
+		values, prefix, ok := compile(test.template).values(test.path)
+		if ok != test.ok || prefix != test.prefix || !reflect.DeepEqual(values, test.want) {
+			t.Errorf("%s, %s: expected %v, %q, %v, got %v, %q, %v", test.template, test.path, test.want, test.prefix, test.ok, values, prefix, ok)
+		}
+	}
+}
+
+func call(t *testing.T, method, rawURL string, status int) Call {
+	t.Helper()
+	u, err := url.Parse("https://api.example.com" + rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Call{Method: method, URL: u, Header: http.Header{}, Status: status}
+}
@@ -0,0 +1,253 @@
+// Package export renders the requests of scenarios in the formats developers replay requests with by hand:
+// cURL commands and the .http files of the REST clients of VS Code and JetBrains IDEs.
+// Secrets are masked unless they are revealed explicitly, so that the output can be shared.
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Mask replaces the value of the secrets in the rendered requests.
+const Mask = "***"
+
+// sensitive matches the names of the headers, query parameters and body fields that carry secrets.
+var sensitive = regexp.MustCompile(`(?i)authorization|cookie|password|passwd|secret|token|api[-_]?key|session|credential|signature`)
+
+// jsonSecret matches a JSON field with a sensitive name and a string, number, boolean or null value.
+// It masks the bodies that are not valid JSON, e.g. with unexpanded templates; maskJSON masks the others.
+var jsonSecret = regexp.MustCompile(`("[^"]*(?i:authorization|cookie|password|passwd|secret|token|api[-_]?key|session|credential|signature)[^"]*"\s*:\s*)(?:"(?:[^"\\]|\\.)*"|-?[0-9][0-9.eE+-]*|true|false|null)`)
+
+// Options configures how requests are rendered.
+type Options struct {
+	// Reveal renders the secrets as they are instead of masking them.
+	Reveal bool
+}
+
+// Request is a request to render, named after its route and scenario.
+type Request struct {
+	Name    string
+	Request *http.Request
+}
+
+// Curl renders the request as a single line cURL command.
+// The body is read through the GetBody of the request, and is left out when it cannot be read again.
+func Curl(req *http.Request, opts Options) string {
+	parts := []string{"curl"}
+	if req.Method != http.MethodGet {
+		parts = append(parts, "-X", req.Method)
+	}
+	parts = append(parts, quote(requestURL(req, opts)))
+	for _, header := range headers(req, opts) {
+		parts = append(parts, "-H", quote(header))
+	}
+	if body := requestBody(req, opts); body != "" {
+		parts = append(parts, "--data-raw", quote(body))
+	}
+	return strings.Join(parts, " ")
+}
+
+// HTTP writes the requests in the .http format, separated by ### lines carrying their names.
+func HTTP(w io.Writer, requests []Request, opts Options) error {
+	for i, r := range requests {
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "### %s\n%s %s\n", r.Name, r.Request.Method, requestURL(r.Request, opts)); err != nil {
+			return err
+		}
+		for _, header := range headers(r.Request, opts) {
+			if _, err := fmt.Fprintln(w, header); err != nil {
+				return err
+			}
+		}
+		if body := requestBody(r.Request, opts); body != "" {
+			if _, err := fmt.Fprintf(w, "\n%s\n", body); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func requestURL(req *http.Request, opts Options) string {
+	u := *req.URL
+	if !opts.Reveal && u.RawQuery != "" {
+		if query, masked := maskValues(u.Query()); masked {
+			u.RawQuery = query
+		}
+	}
+	if _, ok := u.User.Password(); ok && !opts.Reveal {
+		// The mask is written unescaped, as the asterisk is allowed in the user info.
+		u.User = url.UserPassword(u.User.Username(), Mask)
+		return strings.Replace(u.String(), ":"+url.QueryEscape(Mask)+"@", ":"+Mask+"@", 1)
+	}
+	return u.String()
+}
+
+// headers returns the headers of the request as "Name: value" lines, sorted by name.
+func headers(req *http.Request, opts Options) []string {
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var lines []string
+	for _, name := range names {
+		for _, value := range req.Header[name] {
+			if !opts.Reveal && sensitive.MatchString(name) {
+				value = maskHeader(value)
+			}
+			lines = append(lines, name+": "+value)
+		}
+	}
+	return lines
+}
+
+// maskHeader masks the value of a header, keeping the scheme of credentials, e.g. "Bearer ***".
+func maskHeader(value string) string {
+	if scheme, _, ok := strings.Cut(value, " "); ok && !strings.ContainsAny(scheme, "=;") {
+		return scheme + " " + Mask
+	}
+	return Mask
+}
+
+func requestBody(req *http.Request, opts Options) string {
+	if req.GetBody == nil {
+		return ""
+	}
+	reader, err := req.GetBody()
+	if err != nil {
+		return ""
+	}
+	defer reader.Close()
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return ""
+	}
+	body := string(data)
+	if opts.Reveal || body == "" {
+		return body
+	}
+
+	if strings.HasPrefix(req.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		if form, err := url.ParseQuery(body); err == nil {
+			if masked, ok := maskValues(form); ok {
+				return masked
+			}
+			return body
+		}
+	}
+	if masked, ok := maskJSON(body); ok {
+		return masked
+	}
+	return jsonSecret.ReplaceAllString(body, `$1"`+Mask+`"`)
+}
+
+// maskJSON masks the values of the sensitive fields of a JSON body, whatever their type, objects and arrays included,
+// keeping the rest of the body as it was written. It reports false when the body is not valid JSON.
+func maskJSON(body string) (string, bool) {
+	type frame struct {
+		object bool
+		key    bool // the next token of the object is a key
+	}
+	var stack []frame
+	valueDone := func() {
+		if n := len(stack); n > 0 && stack[n-1].object {
+			stack[n-1].key = true
+		}
+	}
+
+	dec := json.NewDecoder(strings.NewReader(body))
+	var masked strings.Builder
+	last := 0
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", false
+		}
+
+		if n := len(stack); n > 0 && stack[n-1].key {
+			if token == json.Delim('}') {
+				stack = stack[:n-1]
+				valueDone()
+				continue
+			}
+			stack[n-1].key = false
+			if key, _ := token.(string); sensitive.MatchString(key) {
+				start := int(dec.InputOffset())
+				var value json.RawMessage
+				if err := dec.Decode(&value); err != nil {
+					return "", false
+				}
+				end := int(dec.InputOffset())
+				masked.WriteString(body[last : start+strings.Index(body[start:end], string(value))])
+				masked.WriteString(`"` + Mask + `"`)
+				last = end
+				valueDone()
+			}
+			continue
+		}
+
+		switch token {
+		case json.Delim('{'):
+			stack = append(stack, frame{object: true, key: true})
+		case json.Delim('['):
+			stack = append(stack, frame{})
+		case json.Delim(']'):
+			stack = stack[:len(stack)-1]
+			valueDone()
+		default:
+			valueDone()
+		}
+	}
+	masked.WriteString(body[last:])
+	return masked.String(), true
+}
+
+// maskValues masks the sensitive values and returns them encoded, and whether any was masked.
+func maskValues(values url.Values) (string, bool) {
+	masked := false
+	for key, list := range values {
+		if sensitive.MatchString(key) {
+			for i := range list {
+				list[i] = Mask
+			}
+			masked = true
+		}
+	}
+	return strings.ReplaceAll(values.Encode(), url.QueryEscape(Mask), Mask), masked
+}
+
+// quote quotes s for POSIX shells.
+func quote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:=@,+%") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+/* Example usage -
+
+req, err := models.ResolveRequest(ctx, scenario)
+if err != nil {
+    log.Fatal(err)
+}
+fmt.Println(export.Curl(req, export.Options{}))
+
+export.HTTP(os.Stdout, []export.Request{{Name: "users/get one", Request: req}}, export.Options{Reveal: true})
+
+*/
@@ -0,0 +1,397 @@
+// Package fuzz derives invalid request bodies from the request body schema of a route, and negative scenarios
+// sending them and asserting that the API rejects them with a 4xx response rather than accepting them or failing.
+package fuzz
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/qatoolist/RouTest/internal/models"
+)
+
+// maxDepth bounds the recursion of the generator for recursive schemas.
+const maxDepth = 8
+
+// Case is an invalid request body generated from a schema.
+type Case struct {
+	// Name describes what makes the body invalid, e.g. "missing required $.name".
+	Name string
+
+	// Body is the JSON body, or malformed JSON.
+	Body []byte
+}
+
+// Generate derives invalid bodies from a valid example of the schema, one mutation at a time:
+// a missing required field, a value of the wrong type, a number out of range, a string longer than its maxLength,
+// a value outside its enum, an additional property where additionalProperties is false, and malformed JSON.
+// Mutations that still validate against the schema, e.g. a wrong type where any type is allowed, are left out.
+// The valid example is the one declared in the schema, or one generated from it as for the stub responses of the mock server.
+func Generate(schema *models.RequestBodySchema) ([]Case, error) {
+	doc, err := schema.SchemaLoader.LoadJSON()
+	if err != nil {
+		return nil, err
+	}
+	root, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("schema is not an object")
+	}
+	valid, err := schema.Example()
+	if err != nil {
+		return nil, err
+	}
+
+	g := &generator{root: root, valid: valid, schema: schema}
+	g.walk(root, nil, 0)
+
+	data, err := json.Marshal(valid)
+	if err != nil {
+		return nil, err
+	}
+	// An unbalanced brace is not JSON whatever the example, unlike a truncation, e.g. of 12 to 1.
+	g.cases = append(g.cases, Case{Name: "malformed JSON", Body: append([]byte("{"), data...)})
+	return g.cases, nil
+}
+
+// generator collects the cases derived from the valid example.
+type generator struct {
+	root   map[string]interface{}
+	valid  interface{}
+	schema *models.RequestBodySchema
+	cases  []Case
+}
+
+// add adds the case of the valid example with the value at path replaced, or removed if remove is set,
+// unless the result is still valid.
+func (g *generator) add(name string, path []interface{}, value interface{}, remove bool) {
+	doc := mutate(clone(g.valid), path, value, remove)
+	if g.schema.Validate(doc) == nil {
+		return
+	}
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return
+	}
+	g.cases = append(g.cases, Case{Name: name, Body: body})
+}
+
+// walk adds the cases of the schema of the value at path, then of its properties and items.
+func (g *generator) walk(schema map[string]interface{}, path []interface{}, depth int) {
+	if schema == nil || depth > maxDepth {
+		return
+	}
+	if ref, ok := schema["$ref"].(string); ok {
+		g.walk(resolveRef(g.root, ref), path, depth+1)
+		return
+	}
+	for _, key := range []string{"allOf", "anyOf", "oneOf"} {
+		subs, _ := schema[key].([]interface{})
+		for i, sub := range subs {
+			// The example of anyOf and oneOf follows their first option.
+			if key != "allOf" && i > 0 {
+				break
+			}
+			subSchema, _ := sub.(map[string]interface{})
+			g.walk(subSchema, path, depth+1)
+		}
+	}
+
+	value, ok := lookup(g.valid, path)
+	if !ok {
+		return
+	}
+	at := format(path)
+
+	typ := schemaType(schema)
+	if typ != "" {
+		wrongType, wrong := wrongValue(typ)
+		g.add(fmt.Sprintf("wrong type for %s (%s instead of %s)", at, wrongType, typ), path, wrong, false)
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		g.add(fmt.Sprintf("%s outside its enum", at), path, outsideEnum(enum), false)
+	}
+
+	switch typ {
+	case "integer", "number":
+		if min, ok := number(schema["minimum"]); ok {
+			g.add(fmt.Sprintf("%s below minimum %v", at, min), path, below(min, typ), false)
+		}
+		if max, ok := number(schema["maximum"]); ok {
+			g.add(fmt.Sprintf("%s above maximum %v", at, max), path, above(max, typ), false)
+		}
+		if min, ok := number(schema["exclusiveMinimum"]); ok {
+			g.add(fmt.Sprintf("%s equal to exclusive minimum %v", at, min), path, min, false)
+		}
+		if max, ok := number(schema["exclusiveMaximum"]); ok {
+			g.add(fmt.Sprintf("%s equal to exclusive maximum %v", at, max), path, max, false)
+		}
+	case "string":
+		if max, ok := number(schema["maxLength"]); ok {
+			g.add(fmt.Sprintf("%s longer than maxLength %d", at, int(max)), path, strings.Repeat("x", int(max)+1), false)
+		}
+	case "object":
+		object, _ := value.(map[string]interface{})
+		if object == nil {
+			return
+		}
+
+		required, _ := schema["required"].([]interface{})
+		for _, r := range required {
+			name, _ := r.(string)
+			if _, ok := object[name]; ok {
+				g.add(fmt.Sprintf("missing required %s", format(child(path, name))), child(path, name), nil, true)
+			}
+		}
+
+		if additional, ok := schema["additionalProperties"].(bool); ok && !additional {
+			name := unexpectedProperty(object)
+			g.add(fmt.Sprintf("additional property %s", format(child(path, name))), child(path, name), "unexpected", false)
+		}
+
+		properties, _ := schema["properties"].(map[string]interface{})
+		names := make([]string, 0, len(properties))
+		for name := range properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, _ := properties[name].(map[string]interface{})
+			g.walk(property, child(path, name), depth+1)
+		}
+	case "array":
+		items, _ := schema["items"].(map[string]interface{})
+		if array, _ := value.([]interface{}); len(array) > 0 {
+			g.walk(items, child(path, 0), depth+1)
+		}
+	}
+}
+
+// wrongValue returns the name and a value of a type other than the given one.
+func wrongValue(typ string) (string, interface{}) {
+	switch typ {
+	case "string":
+		return "integer", 12345
+	case "integer", "number":
+		return "string", "not a number"
+	case "boolean":
+		return "string", "true"
+	case "array":
+		return "object", map[string]interface{}{}
+	case "null":
+		return "string", "null"
+	}
+	return "array", []interface{}{}
+}
+
+// outsideEnum returns a value that is not in the enum, of the same type as its first value where possible.
+func outsideEnum(enum []interface{}) interface{} {
+	if _, ok := number(enum[0]); ok {
+		max := 0.0
+		for _, v := range enum {
+			if f, ok := number(v); ok {
+				max = math.Max(max, f)
+			}
+		}
+		return max + 1
+	}
+	value := "not-in-enum"
+	for i := 0; ; i++ {
+		found := false
+		for _, v := range enum {
+			if s, ok := v.(string); ok && s == value {
+				found = true
+			}
+		}
+		if !found {
+			return value
+		}
+		value = fmt.Sprintf("not-in-enum-%d", i)
+	}
+}
+
+func below(min float64, typ string) float64 {
+	if typ == "integer" {
+		return math.Ceil(min) - 1
+	}
+	return min - 1
+}
+
+func above(max float64, typ string) float64 {
+	if typ == "integer" {
+		return math.Floor(max) + 1
+	}
+	return max + 1
+}
+
+// unexpectedProperty returns the name of a property the object does not have.
+func unexpectedProperty(object map[string]interface{}) string {
+	name := "unexpected_property"
+	for i := 1; ; i++ {
+		if _, ok := object[name]; !ok {
+			return name
+		}
+		name = fmt.Sprintf("unexpected_property_%d", i)
+	}
+}
+
+// number returns the value of a numeric keyword, decoded either as a float64 or as a json.Number by the schema loader.
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// schemaType returns the type of a schema, inferring it from the keywords when it is not declared.
+// For a list of types, the first one other than null is used.
+func schemaType(schema map[string]interface{}) string {
+	switch t := schema["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, v := range t {
+			if s, ok := v.(string); ok && s != "null" {
+				return s
+			}
+		}
+	}
+	if _, ok := schema["properties"]; ok {
+		return "object"
+	}
+	if _, ok := schema["items"]; ok {
+		return "array"
+	}
+	return ""
+}
+
+// resolveRef resolves a local reference such as "#/definitions/User" against the root schema.
+func resolveRef(root map[string]interface{}, ref string) map[string]interface{} {
+	if !strings.HasPrefix(ref, "#") {
+		return nil
+	}
+	node := root
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#"), "/") {
+		if part == "" {
+			continue
+		}
+		part = strings.NewReplacer("~1", "/", "~0", "~").Replace(part)
+		next, ok := node[part].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		node = next
+	}
+	return node
+}
+
+// clone returns a deep copy of a JSON value.
+func clone(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			c[k] = clone(e)
+		}
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i, e := range v {
+			c[i] = clone(e)
+		}
+		return c
+	}
+	return value
+}
+
+// child returns the path of a member or an item of the value at path, without sharing its backing array.
+func child(path []interface{}, step interface{}) []interface{} {
+	return append(path[:len(path):len(path)], step)
+}
+
+// lookup returns the value at path, made of member names and array indexes.
+func lookup(doc interface{}, path []interface{}) (interface{}, bool) {
+	for _, step := range path {
+		switch s := step.(type) {
+		case string:
+			object, ok := doc.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if doc, ok = object[s]; !ok {
+				return nil, false
+			}
+		case int:
+			array, ok := doc.([]interface{})
+			if !ok || s >= len(array) {
+				return nil, false
+			}
+			doc = array[s]
+		}
+	}
+	return doc, true
+}
+
+// mutate replaces the value at path, or removes it if remove is set, and returns the document.
+func mutate(doc interface{}, path []interface{}, value interface{}, remove bool) interface{} {
+	if len(path) == 0 {
+		return value
+	}
+	parent, ok := lookup(doc, path[:len(path)-1])
+	if !ok {
+		return doc
+	}
+	switch s := path[len(path)-1].(type) {
+	case string:
+		if object, ok := parent.(map[string]interface{}); ok {
+			if remove {
+				delete(object, s)
+			} else {
+				object[s] = value
+			}
+		}
+	case int:
+		if array, ok := parent.([]interface{}); ok && s < len(array) {
+			array[s] = value
+		}
+	}
+	return doc
+}
+
+// format returns the JSONPath of a path, e.g. $.items[0].name.
+func format(path []interface{}) string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, step := range path {
+		switch s := step.(type) {
+		case string:
+			if isIdentifier(s) {
+				b.WriteString("." + s)
+			} else {
+				fmt.Fprintf(&b, "['%s']", s)
+			}
+		case int:
+			fmt.Fprintf(&b, "[%d]", s)
+		}
+	}
+	return b.String()
+}
+
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,103 @@
+package fuzz
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/qatoolist/RouTest/internal/interfaces"
+	"github.com/qatoolist/RouTest/internal/models"
+)
+
+// negativeMeta is the meta of the generated scenarios. Negative scenarios skip the validation of their request body,
+// which is invalid on purpose.
+const negativeMeta = "automation_status: automated\nimportance: medium\nnegative: true\ntags: negative\n"
+
+// AddScenarios generates the negative scenarios of a route from its request body schema and adds them to the route.
+// Every scenario sends one invalid body and expects it to be rejected with a 4xx status. The scenarios take the
+// path variables, query parameters and headers of template, if it is not nil, e.g. the first scenario of the route,
+// so that they address the same resource as a valid request.
+// It returns the added scenarios, or none if the route has no request body schema.
+func AddScenarios(route interfaces.Route, template interfaces.Scenario) ([]interfaces.Scenario, error) {
+	schema, ok := route.GetInfo().GetRequestBodySchema().(*models.RequestBodySchema)
+	if !ok || schema == nil {
+		return nil, nil
+	}
+
+	cases, err := Generate(schema)
+	if err != nil {
+		return nil, fmt.Errorf("route %s: %v", route.GetName(), err)
+	}
+
+	scenarios := make([]interfaces.Scenario, 0, len(cases))
+	for _, c := range cases {
+		info := &models.Info{}
+		info.SetName("negative: " + c.Name)
+		info.SetDescription(fmt.Sprintf("%s is rejected with a 4xx status", c.Name))
+
+		scenario := route.NewScenario(info, negativeMeta)
+		scenario.SetBody(c.Body)
+		if template != nil {
+			if err := copyParameters(scenario.GetScenarioParametersRegistry(), template.GetScenarioParametersRegistry()); err != nil {
+				return nil, fmt.Errorf("route %s: %v", route.GetName(), err)
+			}
+		}
+		scenario.GetScenarioHooksRegistry().RegisterResponseHook(ExpectRejected)
+		scenarios = append(scenarios, scenario)
+	}
+	return scenarios, nil
+}
+
+// copyParameters registers the path variables, query parameters and headers of from in to.
+func copyParameters(to, from interfaces.ParametersRegistry) error {
+	for _, p := range from.GetPathVariables() {
+		if err := to.RegisterPathVariable(p.Key(), p.Value()); err != nil {
+			return err
+		}
+	}
+	for _, p := range from.GetQueryParameters() {
+		if err := to.RegisterQueryParameter(p.Key(), p.Value()); err != nil {
+			return err
+		}
+	}
+	for _, p := range from.GetHeaders() {
+		if err := to.RegisterHeader(p.Key(), p.Value()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExpectRejected is the Response Hook of the negative scenarios: an invalid request must be rejected
+// with a 4xx status, never accepted nor answered with a server error. A request whose path still has
+// unresolved variables fails too, as its rejection says nothing about the validation of the body.
+func ExpectRejected(hc interfaces.HookContext) error {
+	if path := hc.Request().URL.Path; strings.ContainsAny(path, "{}") {
+		return fmt.Errorf("the path %s has unresolved variables, set them on a scenario of the route", path)
+	}
+	status := hc.Response().GetStatusCode()
+	switch {
+	case status >= 500:
+		return fmt.Errorf("expected the invalid request to be rejected with a 4xx status, got server error %d", status)
+	case status < 400:
+		return fmt.Errorf("expected the invalid request to be rejected with a 4xx status, got %d", status)
+	}
+	return nil
+}
+
+/* Example usage -
+
+for _, route := range models.Routes(app.RouteRegistry) {
+    var template interfaces.Scenario
+    if scenarios := *route.GetScenarioRegistry().GetScenarios(); len(scenarios) > 0 {
+        template = scenarios[0]
+    }
+    scenarios, err := fuzz.AddScenarios(route, template)
+    if err != nil {
+        log.Fatal(err)
+    }
+    fmt.Printf("%s: %d negative scenarios\n", route.GetName(), len(scenarios))
+}
+
+report := app.Run(ctx)
+
+*/
@@ -0,0 +1,74 @@
+package fuzz
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/qatoolist/RouTest/internal/models"
+	"github.com/qatoolist/RouTest/internal/parser"
+)
+
+const fuzzSuite = `
+host: {protocol: http, hostname: 127.0.0.1, port: 80}
+routes:
+  - name: create-order
+    method: POST
+    path: /users/{id}/orders
+    request_schema: '{"type": "object", "required": ["qty"], "properties": {"qty": {"type": "integer"}}}'
+    scenarios:
+      - name: valid
+        path_variables: {id: "1"}
+        query: {v: "2"}
+        headers: {X-Tenant: acme}
+        body: {qty: 1}
+`
+
+// TestAddScenarios checks that the generated scenarios take the parameters of the template scenario
+// and that their malformed body is not JSON.
+func TestAddScenarios(t *testing.T) {
+	suite, err := parser.ParseSuite([]byte(fuzzSuite))
+	if err != nil {
+		t.Fatal(err)
+	}
+	app, err := suite.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	route := models.Routes(app.RouteRegistry)[0]
+	template := (*route.GetScenarioRegistry().GetScenarios())[0]
+
+	scenarios, err := AddScenarios(route, template)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scenarios) == 0 {
+		t.Fatal("expected negative scenarios")
+	}
+	for _, scenario := range scenarios {
+		params := scenario.GetScenarioParametersRegistry()
+		if len(params.GetPathVariables()) != 1 || len(params.GetQueryParameters()) != 1 || len(params.GetHeaders()) != 1 {
+			t.Errorf("%s: expected the parameters of the template", scenario.GetName())
+		}
+		if id, _ := params.GetParameterByKey("id", "Path"); id != "1" {
+			t.Errorf("%s: expected path variable id to be 1, got %q", scenario.GetName(), id)
+		}
+		if scenario.GetName() == "negative: malformed JSON" && json.Valid(scenario.GetBody()) {
+			t.Errorf("expected a malformed body, got %s", scenario.GetBody())
+		}
+	}
+}
+
+// TestGenerateMalformedScalar checks that the malformed case of a scalar body is not JSON.
+func TestGenerateMalformedScalar(t *testing.T) {
+	schema, err := models.NewRequestBodySchema(`{"type": "integer", "example": 12}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases, err := Generate(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body := cases[len(cases)-1].Body; json.Valid(body) {
+		t.Errorf("expected a malformed body, got %s", body)
+	}
+}
@@ -0,0 +1,511 @@
+// Package generator generates realistic values matching JSON schemas, e.g. request bodies for positive tests
+// or stub responses. It honours types, formats, enums, ranges, lengths and patterns, and is deterministic:
+// the same seed always generates the same values for the same schema.
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxDepth bounds the recursion of the generator for recursive schemas.
+const maxDepth = 8
+
+// Generator generates values matching JSON schemas from a seeded source of randomness.
+// A Generator is not safe for concurrent use.
+type Generator struct {
+	rand *rand.Rand
+	root map[string]interface{}
+}
+
+// New creates a new Generator with the given seed.
+func New(seed int64) *Generator {
+	return &Generator{rand: rand.New(rand.NewSource(seed))}
+}
+
+// Generate returns a value matching the schema; its local references, such as "#/definitions/User", are resolved
+// against the schema itself. Declared constants, enums and examples are used when present.
+// Successive calls generate different values.
+func (g *Generator) Generate(schema map[string]interface{}) interface{} {
+	g.root = schema
+	return g.value(schema, "", 0)
+}
+
+// value generates the value of a schema; name is the name of the property it is generated for, if any,
+// used to pick realistic strings such as emails or city names.
+func (g *Generator) value(schema map[string]interface{}, name string, depth int) interface{} {
+	if schema == nil || depth > maxDepth {
+		return nil
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		return g.value(resolveRef(g.root, ref), name, depth+1)
+	}
+	if c, ok := schema["const"]; ok {
+		return c
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[g.rand.Intn(len(enum))]
+	}
+	if examples, ok := schema["examples"].([]interface{}); ok && len(examples) > 0 {
+		return examples[g.rand.Intn(len(examples))]
+	}
+	if example, ok := schema["example"]; ok {
+		return example
+	}
+
+	if _, ok := schema["allOf"]; ok {
+		return g.value(g.mergeAllOf(schema, depth), name, depth+1)
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if options, ok := schema[key].([]interface{}); ok && len(options) > 0 {
+			option, _ := options[g.rand.Intn(len(options))].(map[string]interface{})
+			return g.value(merge(without(schema, key), option), name, depth+1)
+		}
+	}
+
+	switch schemaType(schema) {
+	case "object":
+		return g.object(schema, depth)
+	case "array":
+		return g.array(schema, name, depth)
+	case "integer":
+		return g.integer(schema)
+	case "number":
+		return g.decimal(schema)
+	case "boolean":
+		return g.rand.Intn(2) == 1
+	case "null":
+		return nil
+	}
+	return g.string(schema, name)
+}
+
+// object generates every property of the object, the optional ones too so that positive tests cover them,
+// unless maxProperties requires leaving some out.
+func (g *Generator) object(schema map[string]interface{}, depth int) interface{} {
+	properties, _ := schema["properties"].(map[string]interface{})
+	required := make(map[string]bool)
+	if list, ok := schema["required"].([]interface{}); ok {
+		for _, r := range list {
+			if name, ok := r.(string); ok {
+				required[name] = true
+			}
+		}
+	}
+
+	// Sorted so that the same seed generates the same object.
+	names := make([]string, 0, len(properties)+len(required))
+	for name := range properties {
+		names = append(names, name)
+	}
+	for name := range required {
+		if _, ok := properties[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if required[names[i]] != required[names[j]] {
+			return required[names[i]]
+		}
+		return names[i] < names[j]
+	})
+	if max, ok := number(schema["maxProperties"]); ok && len(names) > int(max) {
+		names = names[:int(max)]
+	}
+
+	object := make(map[string]interface{}, len(names))
+	for _, name := range names {
+		property, _ := properties[name].(map[string]interface{})
+		if property == nil {
+			property, _ = schema["additionalProperties"].(map[string]interface{})
+		}
+		object[name] = g.value(property, name, depth+1)
+	}
+
+	if min, ok := number(schema["minProperties"]); ok {
+		additional, _ := schema["additionalProperties"].(map[string]interface{})
+		for i := 1; len(object) < int(min); i++ {
+			if _, ok := object[fmt.Sprintf("property_%d", i)]; !ok {
+				object[fmt.Sprintf("property_%d", i)] = g.value(additional, "", depth+1)
+			}
+		}
+	}
+	return object
+}
+
+// maxUniqueAttempts bounds the attempts at generating an item that is not already in a uniqueItems array.
+const maxUniqueAttempts = 10
+
+// array generates between minItems and maxItems items, three at most unless minItems requires more.
+func (g *Generator) array(schema map[string]interface{}, name string, depth int) interface{} {
+	min, max := 1, 3
+	if v, ok := number(schema["minItems"]); ok {
+		min = int(v)
+		if max < min {
+			max = min
+		}
+	}
+	if v, ok := number(schema["maxItems"]); ok && int(v) < max {
+		max = int(v)
+		if min > max {
+			min = max
+		}
+	}
+	count := min + g.rand.Intn(max-min+1)
+
+	// Tuples list the schema of every position.
+	if tuple, ok := schema["items"].([]interface{}); ok {
+		array := make([]interface{}, 0, len(tuple))
+		for _, item := range tuple {
+			itemSchema, _ := item.(map[string]interface{})
+			array = append(array, g.value(itemSchema, name, depth+1))
+		}
+		return array
+	}
+
+	items, _ := schema["items"].(map[string]interface{})
+	unique, _ := schema["uniqueItems"].(bool)
+	array := make([]interface{}, 0, count)
+	seen := make(map[string]bool)
+	for i := 0; i < count; i++ {
+		for attempt := 0; ; attempt++ {
+			item := g.value(items, singular(name), depth+1)
+			key, _ := json.Marshal(item)
+			if !unique || !seen[string(key)] || attempt >= maxUniqueAttempts {
+				seen[string(key)] = true
+				array = append(array, item)
+				break
+			}
+		}
+	}
+	return array
+}
+
+// integer generates an integer within the bounds of the schema, a multiple of its multipleOf if any.
+func (g *Generator) integer(schema map[string]interface{}) interface{} {
+	lo, hi := bounds(schema, 1, 1000, 1)
+	lo, hi = math.Ceil(lo), math.Floor(hi)
+	if step, ok := number(schema["multipleOf"]); ok && step > 0 {
+		return int64(g.multiple(lo, hi, step))
+	}
+	if hi < lo {
+		return int64(lo)
+	}
+	return int64(lo) + g.rand.Int63n(int64(hi-lo)+1)
+}
+
+// decimal generates a number with two decimals within the bounds of the schema, a multiple of its multipleOf if any.
+func (g *Generator) decimal(schema map[string]interface{}) interface{} {
+	lo, hi := bounds(schema, 0, 1000, 0.01)
+	if step, ok := number(schema["multipleOf"]); ok && step > 0 {
+		return g.multiple(lo, hi, step)
+	}
+	v := math.Round((lo+g.rand.Float64()*(hi-lo))*100) / 100
+	return math.Min(math.Max(v, lo), hi)
+}
+
+func (g *Generator) multiple(lo, hi, step float64) float64 {
+	first, last := math.Ceil(lo/step), math.Floor(hi/step)
+	if last < first {
+		return first * step
+	}
+	return (first + float64(g.rand.Int63n(int64(last-first)+1))) * step
+}
+
+// bounds returns the inclusive range of a numeric schema, with the given defaults when it is unbounded.
+// Exclusive bounds are moved inwards by epsilon, both in the draft 4 boolean form and in the later numeric form.
+func bounds(schema map[string]interface{}, defaultLo, defaultHi, epsilon float64) (float64, float64) {
+	lo, hasLo := number(schema["minimum"])
+	hi, hasHi := number(schema["maximum"])
+	if exclusive, ok := schema["exclusiveMinimum"].(bool); ok && exclusive && hasLo {
+		lo += epsilon
+	}
+	if exclusive, ok := schema["exclusiveMaximum"].(bool); ok && exclusive && hasHi {
+		hi -= epsilon
+	}
+	if v, ok := number(schema["exclusiveMinimum"]); ok && (!hasLo || v+epsilon > lo) {
+		lo, hasLo = v+epsilon, true
+	}
+	if v, ok := number(schema["exclusiveMaximum"]); ok && (!hasHi || v-epsilon < hi) {
+		hi, hasHi = v-epsilon, true
+	}
+
+	switch {
+	case !hasLo && !hasHi:
+		return defaultLo, defaultHi
+	case !hasLo && hi >= defaultLo:
+		return defaultLo, hi
+	case !hasLo:
+		return hi - (defaultHi - defaultLo), hi
+	case !hasHi:
+		return lo, lo + (defaultHi - defaultLo)
+	}
+	return lo, hi
+}
+
+// string generates a string of the format or pattern of the schema, or a realistic one given the name of its property,
+// within its length bounds.
+func (g *Generator) string(schema map[string]interface{}, name string) interface{} {
+	min, hasMin := number(schema["minLength"])
+	max, hasMax := number(schema["maxLength"])
+
+	if pattern, ok := schema["pattern"].(string); ok {
+		// Padding or truncating would break the pattern; the caller validates the value instead.
+		if generated, err := g.pattern(pattern); err == nil {
+			return generated
+		}
+	}
+	if format, ok := schema["format"].(string); ok {
+		if formatted, ok := g.format(format); ok {
+			return formatted
+		}
+	}
+	s := g.named(name)
+
+	if hasMax && len(s) > int(max) {
+		s = strings.TrimSpace(s[:int(max)])
+	}
+	for hasMin && len(s) < int(min) {
+		s += string(rune('a' + g.rand.Intn(26)))
+	}
+	return s
+}
+
+// format generates a string of a format, and reports whether the format is supported.
+func (g *Generator) format(format string) (string, bool) {
+	switch format {
+	case "email", "idn-email":
+		return g.email(), true
+	case "uuid":
+		return g.uuid(), true
+	case "date-time":
+		return g.time().Format(time.RFC3339), true
+	case "date":
+		return g.time().Format("2006-01-02"), true
+	case "time":
+		return g.time().Format("15:04:05Z07:00"), true
+	case "uri", "url", "iri", "uri-reference", "iri-reference":
+		return "https://example.com/" + g.pick(words), true
+	case "hostname", "idn-hostname":
+		return g.pick(words) + ".example.com", true
+	case "ipv4":
+		return fmt.Sprintf("10.%d.%d.%d", g.rand.Intn(256), g.rand.Intn(256), 1+g.rand.Intn(254)), true
+	case "ipv6":
+		return fmt.Sprintf("2001:db8::%x", 1+g.rand.Intn(0xfffe)), true
+	}
+	return "", false
+}
+
+// named generates a realistic string for a property, e.g. a city for "city" or an email for "contact_email".
+func (g *Generator) named(name string) string {
+	n := strings.ToLower(name)
+	switch {
+	case strings.Contains(n, "email"):
+		return g.email()
+	case strings.Contains(n, "uuid") || n == "id" || strings.HasSuffix(n, "_id") || strings.HasSuffix(name, "Id"):
+		return g.uuid()
+	case strings.Contains(n, "first"):
+		return g.pick(firstNames)
+	case strings.Contains(n, "last") || strings.Contains(n, "surname"):
+		return g.pick(lastNames)
+	case strings.Contains(n, "name"):
+		return g.pick(firstNames) + " " + g.pick(lastNames)
+	case strings.Contains(n, "city"):
+		return g.pick(cities)
+	case strings.Contains(n, "country"):
+		return g.pick(countries)
+	case strings.Contains(n, "phone"):
+		return fmt.Sprintf("+1-555-%04d", g.rand.Intn(10000))
+	case strings.Contains(n, "url") || strings.Contains(n, "website") || strings.Contains(n, "link"):
+		return "https://example.com/" + g.pick(words)
+	case strings.HasSuffix(n, "_at") || strings.Contains(n, "date") || strings.Contains(n, "time"):
+		return g.time().Format(time.RFC3339)
+	}
+	return g.pick(words) + " " + g.pick(words)
+}
+
+func (g *Generator) email() string {
+	return strings.ToLower(g.pick(firstNames)+"."+g.pick(lastNames)) + "@example.com"
+}
+
+// uuid generates a random version 4 UUID.
+func (g *Generator) uuid() string {
+	b := make([]byte, 16)
+	g.rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// time generates a time in 2023 or 2024, to the second.
+func (g *Generator) time() time.Time {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	return start.Add(time.Duration(g.rand.Int63n(2*365*24*3600)) * time.Second)
+}
+
+func (g *Generator) pick(list []string) string {
+	return list[g.rand.Intn(len(list))]
+}
+
+// mergeAllOf merges the subschemas of allOf into the schema: their properties and required lists are combined,
+// and their other keywords are added when the schema does not declare them.
+func (g *Generator) mergeAllOf(schema map[string]interface{}, depth int) map[string]interface{} {
+	merged := without(schema, "allOf")
+	subs, _ := schema["allOf"].([]interface{})
+	for _, sub := range subs {
+		subSchema, _ := sub.(map[string]interface{})
+		for depth <= maxDepth && subSchema != nil {
+			ref, ok := subSchema["$ref"].(string)
+			if !ok {
+				break
+			}
+			subSchema = resolveRef(g.root, ref)
+			depth++
+		}
+		if _, ok := subSchema["allOf"]; ok && depth <= maxDepth {
+			subSchema = g.mergeAllOf(subSchema, depth+1)
+		}
+		merged = merge(merged, subSchema)
+	}
+	return merged
+}
+
+// merge returns the schema with the keywords of other added; properties and required lists are combined.
+func merge(schema, other map[string]interface{}) map[string]interface{} {
+	merged := without(schema, "")
+	for key, value := range other {
+		switch key {
+		case "properties":
+			properties := make(map[string]interface{})
+			if existing, ok := merged[key].(map[string]interface{}); ok {
+				for k, v := range existing {
+					properties[k] = v
+				}
+			}
+			if added, ok := value.(map[string]interface{}); ok {
+				for k, v := range added {
+					if _, ok := properties[k]; !ok {
+						properties[k] = v
+					}
+				}
+			}
+			merged[key] = properties
+		case "required":
+			existing, _ := merged[key].([]interface{})
+			added, _ := value.([]interface{})
+			merged[key] = append(append([]interface{}{}, existing...), added...)
+		default:
+			if _, ok := merged[key]; !ok {
+				merged[key] = value
+			}
+		}
+	}
+	return merged
+}
+
+// without returns a shallow copy of the schema without the given keyword.
+func without(schema map[string]interface{}, keyword string) map[string]interface{} {
+	c := make(map[string]interface{}, len(schema))
+	for k, v := range schema {
+		if k != keyword {
+			c[k] = v
+		}
+	}
+	return c
+}
+
+// schemaType returns the type of a schema, inferring it from the keywords when it is not declared.
+// For a list of types, the first one other than null is used.
+func schemaType(schema map[string]interface{}) string {
+	switch t := schema["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, v := range t {
+			if s, ok := v.(string); ok && s != "null" {
+				return s
+			}
+		}
+	}
+	if _, ok := schema["properties"]; ok {
+		return "object"
+	}
+	if _, ok := schema["items"]; ok {
+		return "array"
+	}
+	return ""
+}
+
+// number returns the value of a numeric keyword, decoded either as a float64 or as a json.Number by the schema loader.
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// resolveRef resolves a local reference such as "#/definitions/User" against the root schema.
+func resolveRef(root map[string]interface{}, ref string) map[string]interface{} {
+	if !strings.HasPrefix(ref, "#") {
+		return nil
+	}
+	node := root
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#"), "/") {
+		if part == "" {
+			continue
+		}
+		part = strings.NewReplacer("~1", "/", "~0", "~").Replace(part)
+		next, ok := node[part].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		node = next
+	}
+	return node
+}
+
+// singular returns the name of an item of an array property, e.g. "email" for "emails".
+func singular(name string) string {
+	if strings.HasSuffix(name, "s") && len(name) > 1 {
+		return name[:len(name)-1]
+	}
+	return name
+}
+
+var (
+	words      = []string{"alpha", "bravo", "cedar", "delta", "ember", "falcon", "garnet", "harbor", "indigo", "juniper", "kestrel", "lumen", "meadow", "nectar", "orbit", "pebble", "quartz", "river", "summit", "timber"}
+	firstNames = []string{"Ada", "Alan", "Barbara", "Claude", "Donald", "Edsger", "Frances", "Grace", "Hedy", "Ken", "Linus", "Margaret", "Niklaus", "Radia", "Tim"}
+	lastNames  = []string{"Allen", "Berners-Lee", "Dijkstra", "Hamilton", "Hopper", "Knuth", "Lamarr", "Liskov", "Lovelace", "Perlman", "Ritchie", "Shannon", "Thompson", "Turing", "Wirth"}
+	cities     = []string{"Amsterdam", "Berlin", "Lisbon", "Montreal", "Nairobi", "Osaka", "Pune", "Santiago", "Seattle", "Sydney"}
+	countries  = []string{"Brazil", "Canada", "France", "Germany", "India", "Japan", "Kenya", "Netherlands", "Portugal", "Spain"}
+)
+
+/* Example usage -
+
+var schema map[string]interface{}
+json.Unmarshal([]byte(`{
+    "type": "object",
+    "required": ["id", "email", "role"],
+    "properties": {
+        "id": {"type": "string", "format": "uuid"},
+        "email": {"type": "string", "format": "email"},
+        "role": {"enum": ["admin", "member"]},
+        "age": {"type": "integer", "minimum": 18, "maximum": 99},
+        "code": {"type": "string", "pattern": "^[A-Z]{3}-[0-9]{4}$"}
+    }
+}`), &schema)
+
+value := generator.New(42).Generate(schema)
+body, _ := json.Marshal(value)
+
+*/
@@ -0,0 +1,100 @@
+package generator
+
+import (
+	"regexp/syntax"
+	"strings"
+	"unicode"
+)
+
+// maxRepeat is the number of repetitions added to the minimum of unbounded repeats such as * and +.
+const maxRepeat = 3
+
+// pattern generates a string matching a regular expression. JSON Schema patterns are not anchored,
+// so a string matching the whole expression matches the pattern.
+func (g *Generator) pattern(pattern string) (string, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	g.regexp(&b, re.Simplify())
+	return b.String(), nil
+}
+
+func (g *Generator) regexp(b *strings.Builder, re *syntax.Regexp) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		b.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		b.WriteRune(g.class(re.Rune))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		b.WriteRune(rune('a' + g.rand.Intn(26)))
+	case syntax.OpCapture:
+		g.regexp(b, re.Sub[0])
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			g.regexp(b, sub)
+		}
+	case syntax.OpAlternate:
+		g.regexp(b, re.Sub[g.rand.Intn(len(re.Sub))])
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := re.Min, re.Max
+		switch re.Op {
+		case syntax.OpStar:
+			min, max = 0, maxRepeat
+		case syntax.OpPlus:
+			min, max = 1, 1+maxRepeat
+		case syntax.OpQuest:
+			min, max = 0, 1
+		}
+		if max < 0 {
+			max = min + maxRepeat
+		}
+		for i := min + g.rand.Intn(max-min+1); i > 0; i-- {
+			g.regexp(b, re.Sub[0])
+		}
+	}
+	// Empty matches, anchors and word boundaries generate nothing.
+}
+
+// class picks a rune of a character class, given as pairs of inclusive bounds.
+// Printable ASCII runes are preferred, so that negated classes such as [^,] do not generate control characters.
+func (g *Generator) class(ranges []rune) rune {
+	var printable []rune
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+		if lo < ' ' {
+			lo = ' '
+		}
+		if hi > '~' {
+			hi = '~'
+		}
+		if lo <= hi {
+			printable = append(printable, lo, hi)
+		}
+	}
+	if len(printable) > 0 {
+		ranges = printable
+	}
+	if len(ranges) < 2 {
+		return 'x'
+	}
+
+	total := 0
+	for i := 0; i+1 < len(ranges); i += 2 {
+		total += int(ranges[i+1]-ranges[i]) + 1
+	}
+	n := g.rand.Intn(total)
+	for i := 0; i+1 < len(ranges); i += 2 {
+		size := int(ranges[i+1]-ranges[i]) + 1
+		if n < size {
+			r := ranges[i] + rune(n)
+			if unicode.IsPrint(r) {
+				return r
+			}
+			return ranges[i]
+		}
+		n -= size
+	}
+	return ranges[0]
+}
@@ -0,0 +1,70 @@
+package generator
+
+import (
+	"regexp"
+	"testing"
+	"unicode"
+)
+
+// TestPattern checks that the strings generated for patterns match them, whatever the seed.
+func TestPattern(t *testing.T) {
+	patterns := []string{
+		`^[A-Z]{3}-[0-9]{4}$`,
+		`^\d{5}(-\d{4})?$`,
+		`^(red|green|blue)$`,
+		`^[a-z]+@[a-z]+\.(com|org)$`,
+		`^a*b+c?$`,
+		`^x{2,}y{1,3}$`,
+		`^[^,\s]+$`,
+		`^\w+\b`,
+		`^.{4}$`,
+		`ab`,
+		`^$`,
+		`^[\p{Greek}]{2}$`,
+	}
+	for _, pattern := range patterns {
+		t.Run(pattern, func(t *testing.T) {
+			re := regexp.MustCompile(pattern)
+			for seed := int64(0); seed < 50; seed++ {
+				s, err := New(seed).pattern(pattern)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !re.MatchString(s) {
+					t.Fatalf("seed %d: %q does not match", seed, s)
+				}
+			}
+		})
+	}
+}
+
+// TestPatternPrintable checks that negated classes generate printable runes.
+func TestPatternPrintable(t *testing.T) {
+	for seed := int64(0); seed < 50; seed++ {
+		s, err := New(seed).pattern(`^[^a-z]{20}$`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range s {
+			if !unicode.IsPrint(r) || r > '~' {
+				t.Fatalf("seed %d: expected printable ASCII runes, got %q", seed, s)
+			}
+		}
+	}
+}
+
+// TestPatternDeterministic checks that a seed always generates the same string.
+func TestPatternDeterministic(t *testing.T) {
+	a, _ := New(42).pattern(`^[a-z]{8}-\d+$`)
+	b, _ := New(42).pattern(`^[a-z]{8}-\d+$`)
+	if a != b {
+		t.Errorf("expected the same string for the same seed, got %q and %q", a, b)
+	}
+}
+
+// TestPatternInvalid checks that invalid patterns are reported.
+func TestPatternInvalid(t *testing.T) {
+	if _, err := New(1).pattern(`^[a-z$`); err == nil {
+		t.Error("expected an error")
+	}
+}
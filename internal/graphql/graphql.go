@@ -0,0 +1,151 @@
+// Package graphql sends GraphQL operations over HTTP POST and asserts on their results: GraphQL servers report
+// errors in the errors array of responses with the status 200, which the status of the response cannot tell.
+// Operations may also be validated against the SDL schema of the server before they are sent.
+package graphql
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/qatoolist/RouTest/internal/interfaces"
+	"github.com/qatoolist/RouTest/internal/jsonpath"
+)
+
+// Operation is a GraphQL request: the query document, the name of the operation of the document to execute,
+// which may be empty when it has a single one, and the values of its variables.
+type Operation struct {
+	Query         string
+	OperationName string
+	Variables     map[string]interface{}
+}
+
+// Body returns the JSON body of the operation sent over HTTP POST.
+func (o Operation) Body() ([]byte, error) {
+	return json.Marshal(struct {
+		Query         string                 `json:"query"`
+		OperationName string                 `json:"operationName,omitempty"`
+		Variables     map[string]interface{} `json:"variables,omitempty"`
+	}{o.Query, o.OperationName, o.Variables})
+}
+
+// result is the body of a GraphQL response.
+type result struct {
+	Data   interface{} `json:"data"`
+	Errors []struct {
+		Message string        `json:"message"`
+		Path    []interface{} `json:"path"`
+	} `json:"errors"`
+}
+
+func decode(resp interfaces.Response) (*result, error) {
+	var r result
+	if err := json.Unmarshal(resp.Bytes(), &r); err != nil {
+		return nil, fmt.Errorf("the response is not a GraphQL result: %v", err)
+	}
+	return &r, nil
+}
+
+// messages returns the messages of the errors of the result, prefixed with their paths.
+func (r *result) messages() []string {
+	messages := make([]string, len(r.Errors))
+	for i, e := range r.Errors {
+		messages[i] = e.Message
+		if len(e.Path) > 0 {
+			path := make([]string, len(e.Path))
+			for j, elem := range e.Path {
+				path[j] = fmt.Sprint(elem)
+			}
+			messages[i] = strings.Join(path, ".") + ": " + e.Message
+		}
+	}
+	return messages
+}
+
+// ErrorsHook returns a response hook failing the scenario when the errors array of the GraphQL result is not empty.
+// When expected is set, the operation is instead expected to fail: each expected string must be contained in
+// the message of one of the errors.
+func ErrorsHook(expected []string) interfaces.ContextHook {
+	return func(hc interfaces.HookContext) error {
+		r, err := decode(hc.Response())
+		if err != nil {
+			return err
+		}
+		messages := r.messages()
+
+		if len(expected) == 0 {
+			if len(messages) > 0 {
+				return fmt.Errorf("the GraphQL operation failed: %s", strings.Join(messages, "; "))
+			}
+			return nil
+		}
+
+		if len(messages) == 0 {
+			return fmt.Errorf("expected the GraphQL operation to fail with %q, got no errors", expected)
+		}
+		for _, e := range expected {
+			e = hc.Expand(e)
+			found := false
+			for _, message := range messages {
+				if strings.Contains(message, e) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("expected a GraphQL error containing %q, got: %s", e, strings.Join(messages, "; "))
+			}
+		}
+		return nil
+	}
+}
+
+// DataHook returns a response hook asserting on the data of the GraphQL result. The keys of expected are JSONPath
+// expressions relative to data, e.g. user.email or $.users[0].id, and the values are the expected values.
+// Expected strings are expanded with the variables of the scenario and compared with the text of the values,
+// other values are compared as JSON.
+func DataHook(expected map[string]interface{}) (interfaces.ContextHook, error) {
+	expectations, err := jsonpath.NewExpectations(expected)
+	if err != nil {
+		return nil, fmt.Errorf("data %v", err)
+	}
+
+	return func(hc interfaces.HookContext) error {
+		r, err := decode(hc.Response())
+		if err != nil {
+			return err
+		}
+		if r.Data == nil {
+			return errors.New("the GraphQL result has no data")
+		}
+		if err := expectations.Check(r.Data, hc.Expand); err != nil {
+			return fmt.Errorf("GraphQL data: %v", err)
+		}
+		return nil
+	}, nil
+}
+
+/* Example usage -
+
+op := graphql.Operation{
+    Query:     `query User($id: ID!) { user(id: $id) { email } }`,
+    Variables: map[string]interface{}{"id": "42"},
+}
+
+schema, err := graphql.LoadSchema("schema.graphql")
+if err != nil {
+    log.Fatal(err)
+}
+for _, err := range schema.Validate(op.Query, op.OperationName, op.Variables) {
+    log.Println(err)
+}
+
+body, _ := op.Body()
+scenario.SetBody(body) // sent by a POST route with the header Content-Type: application/json
+
+dataHook, _ := graphql.DataHook(map[string]interface{}{"user.email": "jane@example.com"})
+scenario.GetScenarioHooksRegistry().RegisterResponseHook(graphql.ErrorsHook(nil))
+scenario.GetScenarioHooksRegistry().RegisterResponseHook(dataHook)
+
+*/
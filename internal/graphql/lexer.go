@@ -0,0 +1,191 @@
+package graphql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tokenKind is the kind of a lexical token of a GraphQL document.
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunctuator
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+// token is a lexical token and its position in the document.
+type token struct {
+	kind  tokenKind
+	value string
+	pos   Position
+}
+
+// Position is a line and column in a GraphQL document, both starting at 1.
+type Position struct {
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("line %d, column %d", p.Line, p.Column)
+}
+
+// lexer splits a GraphQL document into tokens. Commas, white space and comments are ignored, as in the specification.
+type lexer struct {
+	src  string
+	i    int
+	line int
+	col  int
+}
+
+func newLexer(src string) *lexer {
+	return &lexer{src: strings.TrimPrefix(src, "\ufeff"), line: 1, col: 1}
+}
+
+func (l *lexer) advance(n int) {
+	for ; n > 0 && l.i < len(l.src); n-- {
+		if l.src[l.i] == '\n' {
+			l.line++
+			l.col = 1
+		} else {
+			l.col++
+		}
+		l.i++
+	}
+}
+
+// next returns the next token.
+func (l *lexer) next() (token, error) {
+	for l.i < len(l.src) {
+		c := l.src[l.i]
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',' {
+			l.advance(1)
+		} else if c == '#' {
+			for l.i < len(l.src) && l.src[l.i] != '\n' {
+				l.advance(1)
+			}
+		} else {
+			break
+		}
+	}
+
+	pos := Position{Line: l.line, Column: l.col}
+	if l.i >= len(l.src) {
+		return token{kind: tokenEOF, pos: pos}, nil
+	}
+
+	c := l.src[l.i]
+	switch {
+	case strings.HasPrefix(l.src[l.i:], "..."):
+		l.advance(3)
+		return token{kind: tokenPunctuator, value: "...", pos: pos}, nil
+	case strings.IndexByte("!$&():=@[]{}|", c) >= 0:
+		l.advance(1)
+		return token{kind: tokenPunctuator, value: string(c), pos: pos}, nil
+	case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+		start := l.i
+		for l.i < len(l.src) && isNameChar(l.src[l.i]) {
+			l.advance(1)
+		}
+		return token{kind: tokenName, value: l.src[start:l.i], pos: pos}, nil
+	case c == '-' || c >= '0' && c <= '9':
+		return l.number(pos)
+	case strings.HasPrefix(l.src[l.i:], `"""`):
+		return l.blockString(pos)
+	case c == '"':
+		return l.string(pos)
+	}
+	return token{}, fmt.Errorf("unexpected character %q (%s)", c, pos)
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+func (l *lexer) number(pos Position) (token, error) {
+	start := l.i
+	kind := tokenInt
+	if l.src[l.i] == '-' {
+		l.advance(1)
+	}
+	digits := func() int {
+		n := 0
+		for l.i < len(l.src) && l.src[l.i] >= '0' && l.src[l.i] <= '9' {
+			l.advance(1)
+			n++
+		}
+		return n
+	}
+	if digits() == 0 {
+		return token{}, fmt.Errorf("invalid number (%s)", pos)
+	}
+	if l.i < len(l.src) && l.src[l.i] == '.' {
+		kind = tokenFloat
+		l.advance(1)
+		if digits() == 0 {
+			return token{}, fmt.Errorf("invalid number (%s)", pos)
+		}
+	}
+	if l.i < len(l.src) && (l.src[l.i] == 'e' || l.src[l.i] == 'E') {
+		kind = tokenFloat
+		l.advance(1)
+		if l.i < len(l.src) && (l.src[l.i] == '+' || l.src[l.i] == '-') {
+			l.advance(1)
+		}
+		if digits() == 0 {
+			return token{}, fmt.Errorf("invalid number (%s)", pos)
+		}
+	}
+	return token{kind: kind, value: l.src[start:l.i], pos: pos}, nil
+}
+
+func (l *lexer) string(pos Position) (token, error) {
+	l.advance(1)
+	var b strings.Builder
+	for l.i < len(l.src) {
+		c := l.src[l.i]
+		switch {
+		case c == '"':
+			l.advance(1)
+			return token{kind: tokenString, value: b.String(), pos: pos}, nil
+		case c == '\n':
+			return token{}, fmt.Errorf("unterminated string (%s)", pos)
+		case c == '\\' && l.i+1 < len(l.src):
+			escapes := map[byte]string{'"': `"`, '\\': `\`, '/': "/", 'b': "\b", 'f': "\f", 'n': "\n", 'r': "\r", 't': "\t"}
+			if e, ok := escapes[l.src[l.i+1]]; ok {
+				b.WriteString(e)
+				l.advance(2)
+			} else {
+				// Unicode escapes are kept as they are, the value of strings is not used for validation.
+				b.WriteByte(c)
+				l.advance(1)
+			}
+		default:
+			b.WriteByte(c)
+			l.advance(1)
+		}
+	}
+	return token{}, fmt.Errorf("unterminated string (%s)", pos)
+}
+
+func (l *lexer) blockString(pos Position) (token, error) {
+	l.advance(3)
+	start := l.i
+	for l.i < len(l.src) {
+		if strings.HasPrefix(l.src[l.i:], `\"""`) {
+			l.advance(4)
+			continue
+		}
+		if strings.HasPrefix(l.src[l.i:], `"""`) {
+			value := l.src[start:l.i]
+			l.advance(3)
+			return token{kind: tokenString, value: value, pos: pos}, nil
+		}
+		l.advance(1)
+	}
+	return token{}, fmt.Errorf("unterminated block string (%s)", pos)
+}
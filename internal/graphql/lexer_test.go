@@ -0,0 +1,92 @@
+package graphql
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestLexer checks the tokens of documents, their kinds and values.
+func TestLexer(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{"punctuators", "{ ...on! $a: [B] @c(d: 1) = | & }", []string{"{", "...", "on", "!", "$", "a", ":", "[", "B", "]", "@", "c", "(", "d", ":", "int 1", ")", "=", "|", "&", "}"}},
+		{"ignored", "\ufeff a,b # comment, c\n\t d", []string{"a", "b", "d"}},
+		{"numbers", "0 -12 1.5 2e10 -3.1E-2", []string{"int 0", "int -12", "float 1.5", "float 2e10", "float -3.1E-2"}},
+		{"strings", `"a\"b\n\u00e9" ""`, []string{"string a\"b\n\\u00e9", "string "}},
+		{"block strings", `"""a "quoted" \""" b"""`, []string{`string a "quoted" \""" b`}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			l := newLexer(test.src)
+			var got []string
+			for {
+				tok, err := l.next()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if tok.kind == tokenEOF {
+					break
+				}
+				switch tok.kind {
+				case tokenInt:
+					got = append(got, "int "+tok.value)
+				case tokenFloat:
+					got = append(got, "float "+tok.value)
+				case tokenString:
+					got = append(got, "string "+tok.value)
+				default:
+					got = append(got, tok.value)
+				}
+			}
+			if strings.Join(got, "|") != strings.Join(test.want, "|") {
+				t.Errorf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
+
+// TestLexerPosition checks the lines and columns of the tokens.
+func TestLexerPosition(t *testing.T) {
+	l := newLexer("query {\n  # user\n  user\n}")
+	var got []string
+	for {
+		tok, err := l.next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tok.kind == tokenEOF {
+			break
+		}
+		got = append(got, fmt.Sprintf("%s %d:%d", tok.value, tok.pos.Line, tok.pos.Column))
+	}
+	want := "query 1:1, { 1:7, user 3:3, } 4:1"
+	if strings.Join(got, ", ") != want {
+		t.Errorf("expected %s, got %s", want, strings.Join(got, ", "))
+	}
+}
+
+// TestLexerInvalid checks the errors of invalid tokens.
+func TestLexerInvalid(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"?", "unexpected character '?' (line 1, column 1)"},
+		{"-a", "invalid number"},
+		{"1.", "invalid number"},
+		{"1e", "invalid number"},
+		{`"abc`, "unterminated string"},
+		{"\"a\nb\"", "unterminated string"},
+		{`"""abc`, "unterminated block string"},
+	}
+	for _, test := range tests {
+		_, err := newLexer(test.src).next()
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: expected an error containing %q, got %v", test.src, test.want, err)
+		}
+	}
+}
@@ -0,0 +1,478 @@
+package graphql
+
+import (
+	"fmt"
+)
+
+// document is a parsed executable GraphQL document: its operations and fragments.
+type document struct {
+	operations []*operation
+	fragments  map[string]*fragment
+}
+
+type operation struct {
+	kind       string // query, mutation or subscription
+	name       string
+	variables  []*variableDefinition
+	directives []*directive
+	selections []*selection
+	pos        Position
+}
+
+type variableDefinition struct {
+	name       string
+	typ        *typeRef
+	hasDefault bool
+	pos        Position
+}
+
+type fragment struct {
+	name          string
+	typeCondition string
+	directives    []*directive
+	selections    []*selection
+	pos           Position
+}
+
+// selectionKind is the kind of a selection: a field, a fragment spread or an inline fragment.
+type selectionKind int
+
+const (
+	selectField selectionKind = iota
+	selectSpread
+	selectInline
+)
+
+type selection struct {
+	kind selectionKind
+
+	// alias, name and arguments are the ones of a field, name is also the name of a spread fragment.
+	alias     string
+	name      string
+	arguments []*argument
+
+	// typeCondition is the type of an inline fragment, empty when it has none.
+	typeCondition string
+
+	directives []*directive
+	selections []*selection
+	pos        Position
+}
+
+type argument struct {
+	name  string
+	value *value
+	pos   Position
+}
+
+type directive struct {
+	name      string
+	arguments []*argument
+	pos       Position
+}
+
+// value is an argument or default value. Only the variables it references matter for validation.
+type value struct {
+	variable string
+	list     []*value
+	fields   []*argument
+	pos      Position
+}
+
+// typeRef is a reference to a type: a named type, or the list of elem, either of which may be non null.
+type typeRef struct {
+	name    string
+	elem    *typeRef
+	nonNull bool
+}
+
+// named returns the name of the type, unwrapping lists.
+func (t *typeRef) named() string {
+	if t.elem != nil {
+		return t.elem.named()
+	}
+	return t.name
+}
+
+func (t *typeRef) String() string {
+	s := t.name
+	if t.elem != nil {
+		s = "[" + t.elem.String() + "]"
+	}
+	if t.nonNull {
+		s += "!"
+	}
+	return s
+}
+
+// parser reads GraphQL documents, executable ones and type system ones, from the tokens of a lexer.
+type parser struct {
+	lexer *lexer
+	tok   token
+}
+
+func newParser(src string) (*parser, error) {
+	p := &parser{lexer: newLexer(src)}
+	return p, p.advance()
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+// peek reports whether the current token is the given punctuator or name.
+func (p *parser) peek(value string) bool {
+	return (p.tok.kind == tokenPunctuator || p.tok.kind == tokenName) && p.tok.value == value
+}
+
+// skip consumes the current token if it is the given punctuator or name.
+func (p *parser) skip(value string) (bool, error) {
+	if !p.peek(value) {
+		return false, nil
+	}
+	return true, p.advance()
+}
+
+func (p *parser) expect(value string) error {
+	if !p.peek(value) {
+		return p.unexpected(fmt.Sprintf("%q", value))
+	}
+	return p.advance()
+}
+
+func (p *parser) name() (string, error) {
+	if p.tok.kind != tokenName {
+		return "", p.unexpected("a name")
+	}
+	name := p.tok.value
+	return name, p.advance()
+}
+
+func (p *parser) unexpected(expected string) error {
+	found := fmt.Sprintf("%q", p.tok.value)
+	if p.tok.kind == tokenEOF {
+		found = "the end of the document"
+	}
+	return fmt.Errorf("expected %s, found %s (%s)", expected, found, p.tok.pos)
+}
+
+// parseDocument parses an executable document.
+func parseDocument(src string) (*document, error) {
+	p, err := newParser(src)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &document{fragments: make(map[string]*fragment)}
+	for p.tok.kind != tokenEOF {
+		pos := p.tok.pos
+		switch {
+		case p.peek("{"):
+			selections, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, &operation{kind: "query", selections: selections, pos: pos})
+		case p.peek("query") || p.peek("mutation") || p.peek("subscription"):
+			op, err := p.operation()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, op)
+		case p.peek("fragment"):
+			f, err := p.fragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := doc.fragments[f.name]; ok {
+				return nil, fmt.Errorf("fragment %s is defined more than once (%s)", f.name, f.pos)
+			}
+			doc.fragments[f.name] = f
+		default:
+			return nil, p.unexpected("an operation or a fragment")
+		}
+	}
+	if len(doc.operations) == 0 {
+		return nil, fmt.Errorf("the document has no operation")
+	}
+	return doc, nil
+}
+
+func (p *parser) operation() (*operation, error) {
+	op := &operation{kind: p.tok.value, pos: p.tok.pos}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	var err error
+	if p.tok.kind == tokenName {
+		if op.name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	if ok, err := p.skip("("); err != nil {
+		return nil, err
+	} else if ok {
+		for !p.peek(")") {
+			def := &variableDefinition{pos: p.tok.pos}
+			if err := p.expect("$"); err != nil {
+				return nil, err
+			}
+			if def.name, err = p.name(); err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			if def.typ, err = p.typeRef(); err != nil {
+				return nil, err
+			}
+			if def.hasDefault, err = p.skip("="); err != nil {
+				return nil, err
+			} else if def.hasDefault {
+				if _, err := p.value(); err != nil {
+					return nil, err
+				}
+			}
+			if _, err := p.directives(); err != nil {
+				return nil, err
+			}
+			op.variables = append(op.variables, def)
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	if op.directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if op.selections, err = p.selectionSet(); err != nil {
+		return nil, err
+	}
+	return op, nil
+}
+
+func (p *parser) fragment() (*fragment, error) {
+	f := &fragment{pos: p.tok.pos}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	var err error
+	if f.name, err = p.name(); err != nil {
+		return nil, err
+	}
+	if err := p.expect("on"); err != nil {
+		return nil, err
+	}
+	if f.typeCondition, err = p.name(); err != nil {
+		return nil, err
+	}
+	if f.directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if f.selections, err = p.selectionSet(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (p *parser) selectionSet() ([]*selection, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var selections []*selection
+	for !p.peek("}") {
+		s, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, s)
+	}
+	if len(selections) == 0 {
+		return nil, fmt.Errorf("empty selection set (%s)", p.tok.pos)
+	}
+	return selections, p.advance()
+}
+
+func (p *parser) selection() (*selection, error) {
+	s := &selection{pos: p.tok.pos}
+	var err error
+
+	if ok, err := p.skip("..."); err != nil {
+		return nil, err
+	} else if ok {
+		s.kind = selectInline
+		if p.tok.kind == tokenName && !p.peek("on") {
+			s.kind = selectSpread
+			if s.name, err = p.name(); err != nil {
+				return nil, err
+			}
+			s.directives, err = p.directives()
+			return s, err
+		}
+		if ok, err := p.skip("on"); err != nil {
+			return nil, err
+		} else if ok {
+			if s.typeCondition, err = p.name(); err != nil {
+				return nil, err
+			}
+		}
+		if s.directives, err = p.directives(); err != nil {
+			return nil, err
+		}
+		s.selections, err = p.selectionSet()
+		return s, err
+	}
+
+	s.kind = selectField
+	if s.name, err = p.name(); err != nil {
+		return nil, err
+	}
+	if ok, err := p.skip(":"); err != nil {
+		return nil, err
+	} else if ok {
+		s.alias = s.name
+		if s.name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	if s.arguments, err = p.arguments(); err != nil {
+		return nil, err
+	}
+	if s.directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if p.peek("{") {
+		s.selections, err = p.selectionSet()
+	}
+	return s, err
+}
+
+func (p *parser) arguments() ([]*argument, error) {
+	if ok, err := p.skip("("); err != nil || !ok {
+		return nil, err
+	}
+	var args []*argument
+	for !p.peek(")") {
+		arg := &argument{pos: p.tok.pos}
+		var err error
+		if arg.name, err = p.name(); err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		if arg.value, err = p.value(); err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	return args, p.advance()
+}
+
+func (p *parser) directives() ([]*directive, error) {
+	var directives []*directive
+	for p.peek("@") {
+		d := &directive{pos: p.tok.pos}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		var err error
+		if d.name, err = p.name(); err != nil {
+			return nil, err
+		}
+		if d.arguments, err = p.arguments(); err != nil {
+			return nil, err
+		}
+		directives = append(directives, d)
+	}
+	return directives, nil
+}
+
+func (p *parser) value() (*value, error) {
+	v := &value{pos: p.tok.pos}
+	switch {
+	case p.peek("$"):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		var err error
+		v.variable, err = p.name()
+		return v, err
+	case p.peek("["):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		for !p.peek("]") {
+			elem, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			v.list = append(v.list, elem)
+		}
+		return v, p.advance()
+	case p.peek("{"):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		for !p.peek("}") {
+			field := &argument{pos: p.tok.pos}
+			var err error
+			if field.name, err = p.name(); err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			if field.value, err = p.value(); err != nil {
+				return nil, err
+			}
+			v.fields = append(v.fields, field)
+		}
+		return v, p.advance()
+	case p.tok.kind == tokenName || p.tok.kind == tokenInt || p.tok.kind == tokenFloat || p.tok.kind == tokenString:
+		return v, p.advance()
+	}
+	return nil, p.unexpected("a value")
+}
+
+func (p *parser) typeRef() (*typeRef, error) {
+	t := &typeRef{}
+	if ok, err := p.skip("["); err != nil {
+		return nil, err
+	} else if ok {
+		if t.elem, err = p.typeRef(); err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+	} else if t.name, err = p.name(); err != nil {
+		return nil, err
+	}
+	var err error
+	t.nonNull, err = p.skip("!")
+	return t, err
+}
+
+// variables returns the references to variables in the value.
+func (v *value) variables() []*value {
+	if v == nil {
+		return nil
+	}
+	if v.variable != "" {
+		return []*value{v}
+	}
+	var refs []*value
+	for _, elem := range v.list {
+		refs = append(refs, elem.variables()...)
+	}
+	for _, field := range v.fields {
+		refs = append(refs, field.value.variables()...)
+	}
+	return refs
+}
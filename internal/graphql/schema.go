@@ -0,0 +1,625 @@
+package graphql
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+// The kinds of the types of a schema.
+const (
+	kindScalar      = "scalar"
+	kindObject      = "type"
+	kindInterface   = "interface"
+	kindUnion       = "union"
+	kindEnum        = "enum"
+	kindInputObject = "input"
+)
+
+// Schema is a GraphQL schema read from its SDL, the type system definition language, that operations are validated against.
+type Schema struct {
+	types map[string]*typeDefinition
+	roots map[string]string // operation kind to root type
+}
+
+type typeDefinition struct {
+	kind       string
+	name       string
+	fields     map[string]*fieldDefinition
+	interfaces []string
+	members    []string
+}
+
+type fieldDefinition struct {
+	name string
+	typ  *typeRef
+	args map[string]*inputValue
+}
+
+type inputValue struct {
+	name       string
+	typ        *typeRef
+	hasDefault bool
+}
+
+// LoadSchema reads the SDL schema at the given path, e.g. schema.graphql.
+func LoadSchema(path string) (*Schema, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := ParseSchema(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return schema, nil
+}
+
+// ParseSchema parses a schema from its SDL. Type extensions are merged into their types, and directives are ignored.
+func ParseSchema(sdl string) (*Schema, error) {
+	p, err := newParser(sdl)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Schema{types: make(map[string]*typeDefinition), roots: make(map[string]string)}
+	for _, scalar := range []string{"Int", "Float", "String", "Boolean", "ID"} {
+		s.types[scalar] = &typeDefinition{kind: kindScalar, name: scalar}
+	}
+
+	for p.tok.kind != tokenEOF {
+		if err := p.skipDescription(); err != nil {
+			return nil, err
+		}
+		extend, err := p.skip("extend")
+		if err != nil {
+			return nil, err
+		}
+		if err := p.definition(s, extend); err != nil {
+			return nil, err
+		}
+	}
+
+	for kind, name := range map[string]string{"query": "Query", "mutation": "Mutation", "subscription": "Subscription"} {
+		if _, ok := s.roots[kind]; !ok {
+			if _, ok := s.types[name]; ok {
+				s.roots[kind] = name
+			}
+		}
+	}
+	if _, ok := s.roots["query"]; !ok {
+		return nil, errors.New("the schema has no query type")
+	}
+	for kind, name := range s.roots {
+		if t, ok := s.types[name]; !ok || t.kind != kindObject {
+			return nil, fmt.Errorf("the %s root type %s is not an object type", kind, name)
+		}
+	}
+	return s, nil
+}
+
+func (p *parser) skipDescription() error {
+	if p.tok.kind == tokenString {
+		return p.advance()
+	}
+	return nil
+}
+
+// definition parses a type system definition, or extension, into the schema.
+func (p *parser) definition(s *Schema, extend bool) error {
+	keyword, err := p.name()
+	if err != nil {
+		return err
+	}
+
+	switch keyword {
+	case "schema":
+		if _, err := p.directives(); err != nil {
+			return err
+		}
+		if !p.peek("{") {
+			return nil
+		}
+		if err := p.advance(); err != nil {
+			return err
+		}
+		for !p.peek("}") {
+			kind, err := p.name()
+			if err != nil {
+				return err
+			}
+			if err := p.expect(":"); err != nil {
+				return err
+			}
+			if s.roots[kind], err = p.name(); err != nil {
+				return err
+			}
+		}
+		return p.advance()
+
+	case "directive":
+		if err := p.expect("@"); err != nil {
+			return err
+		}
+		if _, err := p.name(); err != nil {
+			return err
+		}
+		if _, err := p.inputValues("(", ")"); err != nil {
+			return err
+		}
+		if _, err := p.skip("repeatable"); err != nil {
+			return err
+		}
+		if err := p.expect("on"); err != nil {
+			return err
+		}
+		return p.names("|")
+	}
+
+	kind := map[string]string{
+		"scalar": kindScalar, "type": kindObject, "interface": kindInterface,
+		"union": kindUnion, "enum": kindEnum, "input": kindInputObject,
+	}[keyword]
+	if kind == "" {
+		return fmt.Errorf("unexpected %q (%s)", keyword, p.tok.pos)
+	}
+
+	pos := p.tok.pos
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	t, exists := s.types[name]
+	switch {
+	case exists && !extend:
+		return fmt.Errorf("type %s is defined more than once (%s)", name, pos)
+	case exists && t.kind != kind:
+		return fmt.Errorf("type %s is extended as %s but defined as %s (%s)", name, keyword, t.kind, pos)
+	case !exists:
+		t = &typeDefinition{kind: kind, name: name, fields: make(map[string]*fieldDefinition)}
+		s.types[name] = t
+	}
+
+	if ok, err := p.skip("implements"); err != nil {
+		return err
+	} else if ok {
+		if _, err := p.skip("&"); err != nil {
+			return err
+		}
+		for {
+			iface, err := p.name()
+			if err != nil {
+				return err
+			}
+			t.interfaces = append(t.interfaces, iface)
+			if ok, err := p.skip("&"); err != nil {
+				return err
+			} else if !ok {
+				break
+			}
+		}
+	}
+	if _, err := p.directives(); err != nil {
+		return err
+	}
+
+	switch kind {
+	case kindObject, kindInterface:
+		if !p.peek("{") {
+			return nil
+		}
+		if err := p.advance(); err != nil {
+			return err
+		}
+		for !p.peek("}") {
+			if err := p.skipDescription(); err != nil {
+				return err
+			}
+			field := &fieldDefinition{}
+			if field.name, err = p.name(); err != nil {
+				return err
+			}
+			if field.args, err = p.inputValues("(", ")"); err != nil {
+				return err
+			}
+			if err := p.expect(":"); err != nil {
+				return err
+			}
+			if field.typ, err = p.typeRef(); err != nil {
+				return err
+			}
+			if _, err := p.directives(); err != nil {
+				return err
+			}
+			t.fields[field.name] = field
+		}
+		return p.advance()
+
+	case kindInputObject:
+		fields, err := p.inputValues("{", "}")
+		for name, field := range fields {
+			t.fields[name] = &fieldDefinition{name: name, typ: field.typ}
+		}
+		return err
+
+	case kindEnum:
+		if ok, err := p.skip("{"); err != nil || !ok {
+			return err
+		}
+		for !p.peek("}") {
+			if err := p.skipDescription(); err != nil {
+				return err
+			}
+			if _, err := p.name(); err != nil {
+				return err
+			}
+			if _, err := p.directives(); err != nil {
+				return err
+			}
+		}
+		return p.advance()
+
+	case kindUnion:
+		if ok, err := p.skip("="); err != nil || !ok {
+			return err
+		}
+		if _, err := p.skip("|"); err != nil {
+			return err
+		}
+		for {
+			member, err := p.name()
+			if err != nil {
+				return err
+			}
+			t.members = append(t.members, member)
+			if ok, err := p.skip("|"); err != nil {
+				return err
+			} else if !ok {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// inputValues parses the arguments or input fields between open and close, if any.
+func (p *parser) inputValues(open, close string) (map[string]*inputValue, error) {
+	values := make(map[string]*inputValue)
+	if ok, err := p.skip(open); err != nil || !ok {
+		return values, err
+	}
+	for !p.peek(close) {
+		if err := p.skipDescription(); err != nil {
+			return nil, err
+		}
+		v := &inputValue{}
+		var err error
+		if v.name, err = p.name(); err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		if v.typ, err = p.typeRef(); err != nil {
+			return nil, err
+		}
+		if v.hasDefault, err = p.skip("="); err != nil {
+			return nil, err
+		} else if v.hasDefault {
+			if _, err := p.value(); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := p.directives(); err != nil {
+			return nil, err
+		}
+		values[v.name] = v
+	}
+	return values, p.advance()
+}
+
+// names parses names separated by the separator, which may also lead them.
+func (p *parser) names(separator string) error {
+	if _, err := p.skip(separator); err != nil {
+		return err
+	}
+	for {
+		if _, err := p.name(); err != nil {
+			return err
+		}
+		if ok, err := p.skip(separator); err != nil || !ok {
+			return err
+		}
+	}
+}
+
+// Validate checks the GraphQL document against the schema and returns every error found: syntax errors,
+// fields, arguments and types that are not defined, missing required arguments, selections on leaf fields
+// or missing on composite ones, undefined or unused variables and fragments.
+// The operation named operationName is the one executed, it may be empty when the document has a single operation.
+// Its required variables must be given in variables, and every variable given must be defined by it.
+// The values of the variables are not type checked, as they may still reference the variables of the run.
+func (s *Schema) Validate(query, operationName string, variables map[string]interface{}) []error {
+	doc, err := parseDocument(query)
+	if err != nil {
+		return []error{err}
+	}
+
+	v := &validator{schema: s, doc: doc}
+	for _, op := range doc.operations {
+		v.operation(op)
+	}
+	used := make(map[string]bool)
+	for _, op := range doc.operations {
+		v.spreads(op.selections, used)
+	}
+	for name, f := range doc.fragments {
+		if !used[name] {
+			v.errorf(f.pos, "fragment %s is not used", name)
+		}
+	}
+
+	op, err := doc.operation(operationName)
+	if err != nil {
+		return append(v.errs, err)
+	}
+	defined := make(map[string]bool)
+	for _, def := range op.variables {
+		defined[def.name] = true
+		if value, ok := variables[def.name]; def.typ.nonNull && !def.hasDefault && (!ok || value == nil) {
+			v.errs = append(v.errs, fmt.Errorf("variable $%s of type %s is required", def.name, def.typ))
+		}
+	}
+	var unknown []string
+	for name := range variables {
+		if !defined[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		v.errs = append(v.errs, fmt.Errorf("variable $%s is not defined by operation %s", name, op.displayName()))
+	}
+	return v.errs
+}
+
+// operation returns the operation named name, or the single operation of the document when name is empty.
+func (d *document) operation(name string) (*operation, error) {
+	if name == "" {
+		if len(d.operations) > 1 {
+			return nil, errors.New("the document has several operations, the name of the one to execute is required")
+		}
+		return d.operations[0], nil
+	}
+	for _, op := range d.operations {
+		if op.name == name {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("the document has no operation named %s", name)
+}
+
+func (op *operation) displayName() string {
+	if op.name == "" {
+		return "(anonymous " + op.kind + ")"
+	}
+	return op.name
+}
+
+// validator collects the errors of a document.
+type validator struct {
+	schema *Schema
+	doc    *document
+	errs   []error
+}
+
+func (v *validator) errorf(pos Position, format string, args ...interface{}) {
+	v.errs = append(v.errs, fmt.Errorf(format+" (%s)", append(args, pos)...))
+}
+
+func (v *validator) operation(op *operation) {
+	root, ok := v.schema.roots[op.kind]
+	if !ok {
+		v.errorf(op.pos, "the schema does not support %s operations", op.kind)
+		return
+	}
+
+	defined := make(map[string]bool)
+	for _, def := range op.variables {
+		if defined[def.name] {
+			v.errorf(def.pos, "variable $%s is defined more than once", def.name)
+		}
+		defined[def.name] = true
+		if t, ok := v.schema.types[def.typ.named()]; !ok {
+			v.errorf(def.pos, "type %s of variable $%s is not defined", def.typ.named(), def.name)
+		} else if t.kind != kindScalar && t.kind != kindEnum && t.kind != kindInputObject {
+			v.errorf(def.pos, "variable $%s has the output type %s, expected an input type", def.name, t.name)
+		}
+	}
+
+	used := make(map[string]Position)
+	for _, d := range op.directives {
+		v.arguments(d.arguments, used)
+	}
+	v.selections(op.selections, v.schema.types[root], used, make(map[string]bool))
+
+	names := make([]string, 0, len(used))
+	for name := range used {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !defined[name] {
+			v.errorf(used[name], "variable $%s is not defined by operation %s", name, op.displayName())
+		}
+	}
+	for _, def := range op.variables {
+		if _, ok := used[def.name]; !ok {
+			v.errorf(def.pos, "variable $%s is not used by operation %s", def.name, op.displayName())
+		}
+	}
+}
+
+// selections validates the selections on the parent type, and collects the variables they use with the position
+// of their first use. visited guards against fragments spreading themselves.
+func (v *validator) selections(selections []*selection, parent *typeDefinition, used map[string]Position, visited map[string]bool) {
+	for _, s := range selections {
+		for _, d := range s.directives {
+			v.arguments(d.arguments, used)
+		}
+
+		switch s.kind {
+		case selectSpread:
+			f, ok := v.doc.fragments[s.name]
+			if !ok {
+				v.errorf(s.pos, "fragment %s is not defined", s.name)
+				continue
+			}
+			if visited[s.name] {
+				v.errorf(s.pos, "fragment %s spreads itself", s.name)
+				continue
+			}
+			visited[s.name] = true
+			if t := v.compositeType(f.typeCondition, f.pos); t != nil {
+				v.applies(t, parent, s.pos)
+				v.selections(f.selections, t, used, visited)
+			}
+			delete(visited, s.name)
+
+		case selectInline:
+			t := parent
+			if s.typeCondition != "" {
+				if t = v.compositeType(s.typeCondition, s.pos); t != nil {
+					v.applies(t, parent, s.pos)
+				}
+			}
+			if t != nil {
+				v.selections(s.selections, t, used, visited)
+			}
+
+		case selectField:
+			v.field(s, parent, used, visited)
+		}
+	}
+}
+
+func (v *validator) field(s *selection, parent *typeDefinition, used map[string]Position, visited map[string]bool) {
+	v.arguments(s.arguments, used)
+
+	if s.name == "__typename" || strings.HasPrefix(s.name, "__") && parent.name == v.schema.roots["query"] {
+		// Introspection fields are not part of the schema.
+		return
+	}
+	field, ok := parent.fields[s.name]
+	if !ok || parent.kind == kindUnion {
+		v.errorf(s.pos, "field %s is not defined on type %s", s.name, parent.name)
+		return
+	}
+
+	given := make(map[string]bool)
+	for _, arg := range s.arguments {
+		given[arg.name] = true
+		if _, ok := field.args[arg.name]; !ok {
+			v.errorf(arg.pos, "argument %s is not defined on field %s.%s", arg.name, parent.name, s.name)
+		}
+	}
+	names := make([]string, 0, len(field.args))
+	for name := range field.args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if arg := field.args[name]; arg.typ.nonNull && !arg.hasDefault && !given[name] {
+			v.errorf(s.pos, "argument %s of type %s is required on field %s.%s", name, arg.typ, parent.name, s.name)
+		}
+	}
+
+	t, ok := v.schema.types[field.typ.named()]
+	if !ok {
+		v.errorf(s.pos, "type %s of field %s.%s is not defined in the schema", field.typ.named(), parent.name, s.name)
+		return
+	}
+	switch {
+	case (t.kind == kindScalar || t.kind == kindEnum) && len(s.selections) > 0:
+		v.errorf(s.pos, "field %s of type %s cannot have a selection", s.name, field.typ)
+	case t.kind != kindScalar && t.kind != kindEnum && len(s.selections) == 0:
+		v.errorf(s.pos, "field %s of type %s must have a selection of subfields", s.name, field.typ)
+	case len(s.selections) > 0:
+		v.selections(s.selections, t, used, visited)
+	}
+}
+
+// arguments collects the variables used by the arguments.
+func (v *validator) arguments(args []*argument, used map[string]Position) {
+	for _, arg := range args {
+		for _, ref := range arg.value.variables() {
+			if _, ok := used[ref.variable]; !ok {
+				used[ref.variable] = ref.pos
+			}
+		}
+	}
+}
+
+// applies adds an error unless a fragment on the type can apply to the parent type,
+// that is unless some object type is possible for both.
+func (v *validator) applies(t, parent *typeDefinition, pos Position) {
+	possible := v.possibleTypes(parent)
+	for name := range v.possibleTypes(t) {
+		if possible[name] {
+			return
+		}
+	}
+	v.errorf(pos, "a fragment on %s can never apply to %s", t.name, parent.name)
+}
+
+// possibleTypes returns the object types a value of the composite type may have.
+func (v *validator) possibleTypes(t *typeDefinition) map[string]bool {
+	possible := make(map[string]bool)
+	switch t.kind {
+	case kindObject:
+		possible[t.name] = true
+	case kindUnion:
+		for _, member := range t.members {
+			possible[member] = true
+		}
+	case kindInterface:
+		for name, other := range v.schema.types {
+			for _, iface := range other.interfaces {
+				if iface == t.name && other.kind == kindObject {
+					possible[name] = true
+				}
+			}
+		}
+	}
+	return possible
+}
+
+// compositeType returns the object, interface or union type named name, or nil after adding an error.
+func (v *validator) compositeType(name string, pos Position) *typeDefinition {
+	t, ok := v.schema.types[name]
+	if !ok {
+		v.errorf(pos, "type %s is not defined", name)
+		return nil
+	}
+	if t.kind != kindObject && t.kind != kindInterface && t.kind != kindUnion {
+		v.errorf(pos, "fragments cannot be on the %s type %s", t.kind, name)
+		return nil
+	}
+	return t
+}
+
+// spreads marks the fragments spread by the selections, directly or through other fragments.
+func (v *validator) spreads(selections []*selection, used map[string]bool) {
+	for _, s := range selections {
+		if s.kind == selectSpread {
+			if f, ok := v.doc.fragments[s.name]; ok && !used[s.name] {
+				used[s.name] = true
+				v.spreads(f.selections, used)
+			}
+			continue
+		}
+		v.spreads(s.selections, used)
+	}
+}
@@ -0,0 +1,182 @@
+package graphql
+
+import (
+	"strings"
+	"testing"
+)
+
+const testSchema = `
+"""The root of the queries."""
+type Query {
+  user(id: ID!): User
+  search(term: String!, first: Int = 10): [SearchResult!]!
+  node(id: ID!): Node
+}
+
+type Mutation {
+  createUser(input: CreateUserInput!): User
+}
+
+"A node of the graph."
+interface Node { id: ID! }
+
+type User implements Node @key(fields: "id") {
+  id: ID!
+  name: String
+  role: Role
+  friends(first: Int): [User]
+}
+
+type Post implements Node { id: ID!, title: String }
+
+union SearchResult = | User | Post
+
+enum Role { ADMIN USER }
+
+input CreateUserInput { name: String!, role: Role = USER }
+
+scalar Date
+
+extend type User { birthday: Date }
+
+directive @key(fields: String!) on OBJECT
+`
+
+// TestValidate checks the errors of operations validated against a schema.
+func TestValidate(t *testing.T) {
+	schema, err := ParseSchema(testSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		operation string
+		variables map[string]interface{}
+		want      []string
+	}{
+		{
+			name:      "valid",
+			query:     `query GetUser($id: ID!) { user(id: $id) { id name role birthday friends(first: 2) { ...userFields } } } fragment userFields on User { id __typename }`,
+			variables: map[string]interface{}{"id": "1"},
+		},
+		{
+			name:  "shorthand and introspection",
+			query: `{ __schema { types { name } } search(term: "a") { ... on User { name } ... on Post { title } } }`,
+		},
+		{
+			name:  "interface",
+			query: `{ node(id: "1") { id ... on Post { title } } }`,
+		},
+		{
+			name:      "mutation",
+			query:     `mutation($input: CreateUserInput!) { createUser(input: $input) { id } }`,
+			variables: map[string]interface{}{"input": map[string]interface{}{"name": "bob"}},
+		},
+		{
+			name:      "several operations",
+			query:     `query A { user(id: "1") { id } } query B { user(id: "2") { name } }`,
+			operation: "B",
+		},
+		{
+			name:  "unknown field and argument",
+			query: `{ user(id: "1", active: true) { email } }`,
+			want:  []string{"argument active is not defined on field Query.user", "field email is not defined on type User"},
+		},
+		{
+			name:  "missing argument",
+			query: `{ search { __typename } }`,
+			want:  []string{"argument term of type String! is required on field Query.search"},
+		},
+		{
+			name:  "selections",
+			query: `{ user(id: "1") { name { first } friends } }`,
+			want:  []string{"field name of type String cannot have a selection", "field friends of type [User] must have a selection of subfields"},
+		},
+		{
+			name:  "union fields",
+			query: `{ search(term: "a") { id } }`,
+			want:  []string{"field id is not defined on type SearchResult"},
+		},
+		{
+			name:  "fragments",
+			query: `{ user(id: "1") { ...missing ... on Post { id } ... on Role { x } } } fragment unused on User { id }`,
+			want: []string{
+				"fragment missing is not defined", "a fragment on Post can never apply to User",
+				"fragments cannot be on the enum type Role", "fragment unused is not used",
+			},
+		},
+		{
+			name:  "fragment cycle",
+			query: `{ user(id: "1") { ...a } } fragment a on User { friends { ...a } }`,
+			want:  []string{"fragment a spreads itself"},
+		},
+		{
+			name:      "variables",
+			query:     `query($id: ID!, $id: ID, $unused: Int, $user: User) { user(id: $other) { id } }`,
+			variables: map[string]interface{}{"extra": 1},
+			want: []string{
+				"variable $id is defined more than once", "variable $user has the output type User, expected an input type",
+				"variable $other is not defined by operation (anonymous query)", "variable $id is not used",
+				"variable $id is not used", "variable $unused is not used", "variable $user is not used",
+				"variable $id of type ID! is required", "variable $extra is not defined by operation (anonymous query)",
+			},
+		},
+		{
+			name:  "subscription",
+			query: `subscription { user(id: "1") { id } }`,
+			want:  []string{"the schema does not support subscription operations"},
+		},
+		{
+			name:  "operation name",
+			query: `query A { user(id: "1") { id } } query B { user(id: "2") { id } }`,
+			want:  []string{"the name of the one to execute is required"},
+		},
+		{
+			name:      "unknown operation",
+			query:     `query A { user(id: "1") { id } }`,
+			operation: "B",
+			want:      []string{"the document has no operation named B"},
+		},
+		{
+			name:  "syntax",
+			query: `{ user(id: "1") { id }`,
+			want:  []string{`expected a name, found the end of the document (line 1, column 23)`},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			errs := schema.Validate(test.query, test.operation, test.variables)
+			if len(errs) != len(test.want) {
+				t.Fatalf("expected %d errors, got %v", len(test.want), errs)
+			}
+			for i, err := range errs {
+				if !strings.Contains(err.Error(), test.want[i]) {
+					t.Errorf("expected error %d to contain %q, got %v", i, test.want[i], err)
+				}
+			}
+		})
+	}
+}
+
+// TestParseSchemaInvalid checks the errors of schemas that cannot be validated against.
+func TestParseSchemaInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		sdl  string
+		want string
+	}{
+		{"no query", "type User { id: ID }", "the schema has no query type"},
+		{"root kind", "schema { query: Role } enum Role { A }", "the query root type Role is not an object type"},
+		{"syntax", "type Query { id: }", "expected"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseSchema(test.sdl)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("expected an error containing %q, got %v", test.want, err)
+			}
+		})
+	}
+}
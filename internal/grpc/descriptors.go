@@ -0,0 +1,177 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	// The well-known types complete the descriptor sets written without their imports.
+	_ "google.golang.org/protobuf/types/known/anypb"
+	_ "google.golang.org/protobuf/types/known/durationpb"
+	_ "google.golang.org/protobuf/types/known/emptypb"
+	_ "google.golang.org/protobuf/types/known/fieldmaskpb"
+	_ "google.golang.org/protobuf/types/known/structpb"
+	_ "google.golang.org/protobuf/types/known/timestamppb"
+	_ "google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// LoadDescriptorSet reads the FileDescriptorSet of a .protoset file, as written by
+// protoc --descriptor_set_out --include_imports or buf build -o. The well-known types imported by the files
+// of the set may be missing from it.
+func LoadDescriptorSet(path string) (*protoregistry.Files, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	set := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(data, set); err != nil {
+		return nil, fmt.Errorf("%s is not a descriptor set: %v", path, err)
+	}
+	files, err := newFiles(set.File)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return files, nil
+}
+
+// newFiles returns the registry of the files, completed with the well-known files they import that are missing.
+func newFiles(files []*descriptorpb.FileDescriptorProto) (*protoregistry.Files, error) {
+	known := make(map[string]bool, len(files))
+	for _, file := range files {
+		known[file.GetName()] = true
+	}
+	// The files appended are visited too, for their own imports.
+	for i := 0; i < len(files); i++ {
+		for _, dep := range files[i].GetDependency() {
+			if known[dep] {
+				continue
+			}
+			fd, err := protoregistry.GlobalFiles.FindFileByPath(dep)
+			if err != nil {
+				return nil, fmt.Errorf("%s imports %s, which is missing", files[i].GetName(), dep)
+			}
+			known[dep] = true
+			files = append(files, protodesc.ToFileDescriptorProto(fd))
+		}
+	}
+	return protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: files})
+}
+
+// reflectFiles returns the registry of the file defining the service and the files it imports, as resolved by
+// the server reflection service of the server.
+func reflectFiles(ctx context.Context, conn *grpc.ClientConn, service string) (*protoregistry.Files, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := rpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, reflectionError(err)
+	}
+
+	var files []*descriptorpb.FileDescriptorProto
+	requested := make(map[string]bool)
+	pending := []*rpb.ServerReflectionRequest{{
+		MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: service},
+	}}
+	for len(pending) > 0 {
+		req := pending[0]
+		pending = pending[1:]
+		if err := stream.Send(req); err != nil {
+			return nil, reflectionError(err)
+		}
+		resp, err := stream.Recv()
+		if err != nil {
+			return nil, reflectionError(err)
+		}
+
+		if e := resp.GetErrorResponse(); e != nil {
+			if req.GetFileContainingSymbol() != "" {
+				return nil, fmt.Errorf("server reflection: service %s: %s", service, e.GetErrorMessage())
+			}
+			// A missing import may still be a well-known type, which newFiles completes.
+			continue
+		}
+		for _, data := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			file := &descriptorpb.FileDescriptorProto{}
+			if err := proto.Unmarshal(data, file); err != nil {
+				return nil, fmt.Errorf("server reflection: %v", err)
+			}
+			if containsFile(files, file.GetName()) {
+				continue
+			}
+			requested[file.GetName()] = true
+			files = append(files, file)
+			for _, dep := range file.GetDependency() {
+				if !requested[dep] {
+					requested[dep] = true
+					pending = append(pending, &rpb.ServerReflectionRequest{
+						MessageRequest: &rpb.ServerReflectionRequest_FileByFilename{FileByFilename: dep},
+					})
+				}
+			}
+		}
+	}
+	return newFiles(files)
+}
+
+func containsFile(files []*descriptorpb.FileDescriptorProto, name string) bool {
+	for _, file := range files {
+		if file.GetName() == name {
+			return true
+		}
+	}
+	return false
+}
+
+func reflectionError(err error) error {
+	if status.Code(err) == codes.Unimplemented {
+		return fmt.Errorf("the server does not support server reflection: %v", status.Convert(err).Message())
+	}
+	return fmt.Errorf("server reflection: %v", err)
+}
+
+// ParseMethod splits the full name of a method, e.g. shop.v1.Orders/GetOrder or shop.v1.Orders.GetOrder,
+// into the full name of its service and its name.
+func ParseMethod(name string) (service, method string, err error) {
+	name = strings.TrimPrefix(name, "/")
+	i := strings.LastIndexAny(name, "/.")
+	if i <= 0 || i == len(name)-1 {
+		return "", "", fmt.Errorf("invalid method %q, expected the form package.Service/Method", name)
+	}
+	service, method = name[:i], name[i+1:]
+	if !protoreflect.FullName(service).IsValid() || !protoreflect.Name(method).IsValid() {
+		return "", "", fmt.Errorf("invalid method %q, expected the form package.Service/Method", name)
+	}
+	return service, method, nil
+}
+
+// findMethod returns the descriptor of the unary method of the service.
+func findMethod(files *protoregistry.Files, service, method string) (protoreflect.MethodDescriptor, error) {
+	d, err := files.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, fmt.Errorf("service %s not found", service)
+	}
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a service", service)
+	}
+	md := sd.Methods().ByName(protoreflect.Name(method))
+	if md == nil {
+		return nil, fmt.Errorf("service %s has no method %s", service, method)
+	}
+	if md.IsStreamingClient() || md.IsStreamingServer() {
+		return nil, fmt.Errorf("%s/%s is a streaming method, only unary methods are supported", service, method)
+	}
+	return md, nil
+}
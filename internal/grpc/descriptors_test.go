@@ -0,0 +1,121 @@
+package grpc
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestParseMethod(t *testing.T) {
+	tests := []struct {
+		name        string
+		wantService string
+		wantMethod  string
+		wantErr     bool
+	}{
+		{name: "shop.v1.Orders/GetOrder", wantService: "shop.v1.Orders", wantMethod: "GetOrder"},
+		{name: "/shop.v1.Orders/GetOrder", wantService: "shop.v1.Orders", wantMethod: "GetOrder"},
+		{name: "shop.v1.Orders.GetOrder", wantService: "shop.v1.Orders", wantMethod: "GetOrder"},
+		{name: "Orders/GetOrder", wantService: "Orders", wantMethod: "GetOrder"},
+		{name: "GetOrder", wantErr: true},
+		{name: "shop.v1.Orders/", wantErr: true},
+		{name: "/GetOrder", wantErr: true},
+		{name: "shop.v1.Orders/Get-Order", wantErr: true},
+		{name: "shop..Orders/GetOrder", wantErr: true},
+	}
+	for _, test := range tests {
+		service, method, err := ParseMethod(test.name)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %s and %s", test.name, service, method)
+			}
+			continue
+		}
+		if err != nil || service != test.wantService || method != test.wantMethod {
+			t.Errorf("%s: expected %s and %s, got %s and %s, %v", test.name, test.wantService, test.wantMethod, service, method, err)
+		}
+	}
+}
+
+// ordersFile returns a file of the shop.v1.Orders service importing the given files.
+func ordersFile(imports ...string) *descriptorpb.FileDescriptorProto {
+	return &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("shop/v1/orders.proto"),
+		Package:    proto.String("shop.v1"),
+		Syntax:     proto.String("proto3"),
+		Dependency: imports,
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("GetOrderRequest"),
+			Field: []*descriptorpb.FieldDescriptorProto{{
+				Name:     proto.String("id"),
+				JsonName: proto.String("id"),
+				Number:   proto.Int32(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+			}},
+		}},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Orders"),
+			Method: []*descriptorpb.MethodDescriptorProto{{
+				Name:       proto.String("GetOrder"),
+				InputType:  proto.String(".shop.v1.GetOrderRequest"),
+				OutputType: proto.String(".google.protobuf.Empty"),
+			}},
+		}},
+	}
+}
+
+// TestLoadDescriptorSet checks the loading of descriptor sets, completed with the well-known files they import.
+func TestLoadDescriptorSet(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	set := func(files ...*descriptorpb.FileDescriptorProto) []byte {
+		data, err := proto.Marshal(&descriptorpb.FileDescriptorSet{File: files})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr string
+	}{
+		{name: "well-known import", path: write("orders.protoset", set(ordersFile("google/protobuf/empty.proto")))},
+		{name: "missing import", path: write("missing.protoset", set(ordersFile("google/protobuf/empty.proto", "shop/v1/money.proto"))),
+			wantErr: "imports shop/v1/money.proto, which is missing"},
+		{name: "not a descriptor set", path: write("orders.proto", []byte("syntax = \"proto3\";")), wantErr: "is not a descriptor set"},
+		{name: "no file", path: filepath.Join(dir, "none.protoset"), wantErr: "no such file"},
+	}
+	for _, test := range tests {
+		files, err := LoadDescriptorSet(test.path)
+		if test.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("%s: expected the error %q, got %v", test.name, test.wantErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		md, err := findMethod(files, "shop.v1.Orders", "GetOrder")
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if md.Output().FullName() != protoreflect.FullName("google.protobuf.Empty") {
+			t.Errorf("%s: expected the output google.protobuf.Empty, got %s", test.name, md.Output().FullName())
+		}
+	}
+}
@@ -0,0 +1,423 @@
+// Package grpc performs the scenarios of gRPC routes: their JSON request body is converted to the protobuf
+// input message of a unary method, described by a descriptor set or resolved with server reflection, and
+// the output message is converted back to JSON, so that the assertions of HTTP routes apply to it.
+package grpc
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/qatoolist/RouTest/internal/interfaces"
+	"github.com/qatoolist/RouTest/internal/jsonpath"
+	"github.com/qatoolist/RouTest/internal/models"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Header names of the status of the responses of the exchanges.
+const (
+	HeaderStatus  = "Grpc-Status"
+	HeaderMessage = "Grpc-Message"
+)
+
+// Options describe the method invoked by the scenarios of a gRPC route.
+type Options struct {
+	// Method is the full name of the unary method, e.g. shop.v1.Orders/GetOrder.
+	Method string
+
+	// Files are the descriptors defining the method, e.g. loaded with LoadDescriptorSet. Nil resolves them
+	// with the server reflection service of the server, once per host.
+	Files *protoregistry.Files
+
+	// Clients are the connections and reflected descriptors the exchange shares with others, e.g. those of
+	// the routes of a suite. Nil gives the exchange clients of its own.
+	Clients *Clients
+}
+
+// Clients hold the connections of exchanges, one per target, and the descriptors they resolved with server
+// reflection, once per target and service. The connections are kept open for the life of the process,
+// like the idle connections of an HTTP client.
+type Clients struct {
+	mu        sync.Mutex
+	conns     map[string]*grpc.ClientConn
+	reflected map[string]*protoregistry.Files
+}
+
+// NewClients returns empty clients.
+func NewClients() *Clients {
+	return &Clients{conns: make(map[string]*grpc.ClientConn), reflected: make(map[string]*protoregistry.Files)}
+}
+
+// conn returns the connection to the target, creating it on first use. The TLS configuration is that of
+// the first scenario connecting over TLS.
+func (c *Clients) conn(target string, secure bool, scenario interfaces.Scenario) (*grpc.ClientConn, error) {
+	key := "grpc://" + target
+	creds := insecure.NewCredentials()
+	if secure {
+		key = "grpcs://" + target
+		creds = credentials.NewTLS(tlsConfig(scenario))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if conn, ok := c.conns[key]; ok {
+		return conn, nil
+	}
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+	c.conns[key] = conn
+	return conn, nil
+}
+
+// Exchange returns the exchange invoking the method with the request of the scenario: its JSON body, empty
+// for an empty message, is converted to the input message, and its headers are sent as metadata. The method
+// is invoked on the host of the request, with TLS when its scheme is https and the TLS configuration of the
+// HTTP client of the application.
+//
+// The response of the exchange has the HTTP status matching the gRPC status, as given by HTTPStatus, with
+// the code of the gRPC status in the Grpc-Status header and its message in Grpc-Message, the header and
+// trailer metadata of the server as headers and, as its body, the output message as JSON, fields with default
+// values included, or the status as {"code": 5, "message": "..."} when it is not OK.
+func Exchange(opts Options) (interfaces.Exchange, error) {
+	service, method, err := ParseMethod(opts.Method)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Files != nil {
+		if _, err := findMethod(opts.Files, service, method); err != nil {
+			return nil, err
+		}
+	}
+	clients := opts.Clients
+	if clients == nil {
+		clients = NewClients()
+	}
+	x := &exchange{service: service, method: method, files: opts.Files, clients: clients}
+	return x.invoke, nil
+}
+
+// exchange holds the method invoked by an exchange and the clients invoking it.
+type exchange struct {
+	service string
+	method  string
+	files   *protoregistry.Files
+	clients *Clients
+}
+
+func (x *exchange) invoke(hc interfaces.HookContext) (interfaces.Response, error) {
+	req := hc.Request()
+	target := req.URL.Host
+	if req.URL.Port() == "" {
+		port := "80"
+		if req.URL.Scheme == "https" {
+			port = "443"
+		}
+		target = net.JoinHostPort(req.URL.Hostname(), port)
+	}
+	conn, err := x.clients.conn(target, req.URL.Scheme == "https", hc.Scenario())
+	if err != nil {
+		return nil, err
+	}
+
+	md, err := x.resolve(hc, conn, target)
+	if err != nil {
+		return nil, err
+	}
+
+	in := dynamicpb.NewMessage(md.Input())
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err := protojson.Unmarshal(body, in); err != nil {
+			return nil, fmt.Errorf("request body to %s: %v", md.Input().FullName(), err)
+		}
+	}
+
+	out := dynamicpb.NewMessage(md.Output())
+	var header, trailer metadata.MD
+	ctx := metadata.NewOutgoingContext(hc, requestMetadata(req.Header))
+	err = conn.Invoke(ctx, "/"+x.service+"/"+x.method, in, out, grpc.Header(&header), grpc.Trailer(&trailer))
+	st, ok := status.FromError(err)
+	if !ok {
+		return nil, err
+	}
+
+	headers := map[string]string{"Content-Type": "application/json"}
+	for _, md := range []metadata.MD{header, trailer} {
+		for key, values := range md {
+			// The body is the message as JSON, not the gRPC payload the content-type of the call describes.
+			if key == "content-type" {
+				continue
+			}
+			if strings.HasSuffix(key, "-bin") {
+				for i, value := range values {
+					values[i] = base64.StdEncoding.EncodeToString([]byte(value))
+				}
+			}
+			headers[http.CanonicalHeaderKey(key)] = strings.Join(values, ", ")
+		}
+	}
+	headers[HeaderStatus] = strconv.Itoa(int(st.Code()))
+	if st.Message() != "" {
+		headers[HeaderMessage] = st.Message()
+	}
+
+	if st.Code() == codes.OK {
+		body, err = marshal(out)
+	} else {
+		body, err = json.Marshal(map[string]interface{}{"code": int(st.Code()), "message": st.Message()})
+	}
+	return models.NewStaticResponse(HTTPStatus(st.Code()), headers, body), err
+}
+
+// resolve returns the descriptor of the method, resolving it with server reflection on the target if the
+// exchange has no descriptors.
+func (x *exchange) resolve(hc interfaces.HookContext, conn *grpc.ClientConn, target string) (protoreflect.MethodDescriptor, error) {
+	if x.files != nil {
+		return findMethod(x.files, x.service, x.method)
+	}
+
+	key := target + "/" + x.service
+	x.clients.mu.Lock()
+	files := x.clients.reflected[key]
+	x.clients.mu.Unlock()
+	if files == nil {
+		var err error
+		if files, err = reflectFiles(hc, conn, x.service); err != nil {
+			return nil, err
+		}
+		x.clients.mu.Lock()
+		x.clients.reflected[key] = files
+		x.clients.mu.Unlock()
+	}
+	return findMethod(files, x.service, x.method)
+}
+
+// marshal returns the message as compact JSON. protojson varies its spacing on purpose, which would break
+// the snapshots and body_contains assertions.
+func marshal(message *dynamicpb.Message) ([]byte, error) {
+	data, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, data); err != nil {
+		return nil, err
+	}
+	return compact.Bytes(), nil
+}
+
+func readBody(req *http.Request) ([]byte, error) {
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return ioutil.ReadAll(body)
+	}
+	if req.Body == nil {
+		return nil, nil
+	}
+	return ioutil.ReadAll(req.Body)
+}
+
+// connectionHeaders are the headers of the request that are not sent as metadata, as they describe
+// the HTTP request rather than the call. gRPC drops its reserved headers, e.g. Content-Type, itself.
+var connectionHeaders = map[string]bool{
+	"host":              true,
+	"connection":        true,
+	"content-length":    true,
+	"accept-encoding":   true,
+	"transfer-encoding": true,
+}
+
+// requestMetadata returns the headers of the request as metadata. The values of -bin headers are decoded
+// from base64.
+func requestMetadata(header http.Header) metadata.MD {
+	md := metadata.MD{}
+	for key, values := range header {
+		key = strings.ToLower(key)
+		if connectionHeaders[key] {
+			continue
+		}
+		for _, value := range values {
+			if strings.HasSuffix(key, "-bin") {
+				if decoded, err := base64.StdEncoding.DecodeString(value); err == nil {
+					value = string(decoded)
+				}
+			}
+			md.Append(key, value)
+		}
+	}
+	return md
+}
+
+// codeNames are the names of the status codes, as in the gRPC specification.
+var codeNames = []string{
+	"OK", "CANCELLED", "UNKNOWN", "INVALID_ARGUMENT", "DEADLINE_EXCEEDED", "NOT_FOUND", "ALREADY_EXISTS",
+	"PERMISSION_DENIED", "RESOURCE_EXHAUSTED", "FAILED_PRECONDITION", "ABORTED", "OUT_OF_RANGE",
+	"UNIMPLEMENTED", "INTERNAL", "UNAVAILABLE", "DATA_LOSS", "UNAUTHENTICATED",
+}
+
+// CodeName returns the name of the status code, e.g. NOT_FOUND.
+func CodeName(code codes.Code) string {
+	if int(code) < len(codeNames) {
+		return codeNames[code]
+	}
+	return strconv.Itoa(int(code))
+}
+
+// ParseCode returns the status code of the name, e.g. NOT_FOUND or not_found, or of the number, e.g. 5.
+func ParseCode(s string) (codes.Code, error) {
+	if n, err := strconv.Atoi(s); err == nil && n >= 0 {
+		return codes.Code(n), nil
+	}
+	for i, name := range codeNames {
+		if strings.EqualFold(s, name) {
+			return codes.Code(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown gRPC status %q", s)
+}
+
+// HTTPStatus returns the HTTP status matching the gRPC status code, as mapped by the gRPC-HTTP gateways.
+func HTTPStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// StatusHook returns the Response Hook checking the gRPC status of the response of an exchange against
+// the expected one, given by name or number. It may reference the columns of the data row or the captured
+// variables as {{name}}.
+func StatusHook(expected string) interfaces.ContextHook {
+	return func(hc interfaces.HookContext) error {
+		want, err := ParseCode(hc.Expand(expected))
+		if err != nil {
+			return err
+		}
+		value, err := hc.Response().HeaderValue(HeaderStatus)
+		if err != nil {
+			return errors.New("the response has no gRPC status")
+		}
+		got, err := ParseCode(value)
+		if err != nil {
+			return err
+		}
+		if got != want {
+			message, _ := hc.Response().HeaderValue(HeaderMessage)
+			if message != "" {
+				return fmt.Errorf("expected gRPC status %s, got %s: %s", CodeName(want), CodeName(got), message)
+			}
+			return fmt.Errorf("expected gRPC status %s, got %s", CodeName(want), CodeName(got))
+		}
+		return nil
+	}
+}
+
+// MessageHook returns the Response Hook asserting on the output message of the response of an exchange.
+// The keys of expected are JSONPath expressions, e.g. order.status or $.items[0].sku, and the values are
+// the expected values. Expected strings are expanded with the variables of the scenario and compared with
+// the text of the values, other values are compared as JSON.
+func MessageHook(expected map[string]interface{}) (interfaces.ContextHook, error) {
+	expectations, err := jsonpath.NewExpectations(expected)
+	if err != nil {
+		return nil, fmt.Errorf("data %v", err)
+	}
+
+	return func(hc interfaces.HookContext) error {
+		var message interface{}
+		if err := json.Unmarshal(hc.Response().Bytes(), &message); err != nil {
+			return fmt.Errorf("the response is not a JSON message: %v", err)
+		}
+		if err := expectations.Check(message, hc.Expand); err != nil {
+			return fmt.Errorf("message: %v", err)
+		}
+		return nil
+	}, nil
+}
+
+// tlsConfig returns the TLS configuration of the HTTP client of the application of the scenario, if it has one,
+// so that the calls trust the same certificates as the HTTP requests.
+func tlsConfig(scenario interfaces.Scenario) *tls.Config {
+	route := scenario.GetParentRoute()
+	if route == nil || route.GetParentApplication() == nil {
+		return nil
+	}
+	client := route.GetParentApplication().GetHTTPClient()
+	if client == nil {
+		return nil
+	}
+	if transport, ok := client.Transport.(*http.Transport); ok {
+		return transport.TLSClientConfig
+	}
+	return nil
+}
+
+/* Example usage -
+
+files, err := grpc.LoadDescriptorSet("orders.protoset")
+if err != nil {
+    log.Fatal(err)
+}
+clients := grpc.NewClients() // shared by the exchanges of the routes
+exchange, err := grpc.Exchange(grpc.Options{Method: "shop.v1.Orders/GetOrder", Files: files, Clients: clients})
+if err != nil {
+    log.Fatal(err)
+}
+scenario.SetBody([]byte(`{"id": "42"}`))
+scenario.SetExchange(exchange)
+scenario.GetScenarioHooksRegistry().RegisterResponseHook(grpc.StatusHook("OK"))
+
+hook, err := grpc.MessageHook(map[string]interface{}{"order.status": "SHIPPED"})
+if err != nil {
+    log.Fatal(err)
+}
+scenario.GetScenarioHooksRegistry().RegisterResponseHook(hook)
+
+*/
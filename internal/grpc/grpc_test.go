@@ -0,0 +1,313 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/qatoolist/RouTest/internal/interfaces"
+	"github.com/qatoolist/RouTest/internal/models"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// startServer starts an in-process server of the health service, with server reflection. It echoes
+// the x-tenant metadata of the calls in their header metadata and sets a binary trailer.
+func startServer(t *testing.T) (host string, port int) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		grpc.SetHeader(ctx, metadata.Pairs("x-tenant", strings.Join(md.Get("x-tenant"), ",")))
+		grpc.SetTrailer(ctx, metadata.Pairs("trace-bin", "\x01\x02"))
+		return handler(ctx, req)
+	}))
+	checker := health.NewServer()
+	checker.SetServingStatus("shop.v1.Orders", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(server, checker)
+	reflection.Register(server)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	host, portString, _ := net.SplitHostPort(lis.Addr().String())
+	port, _ = strconv.Atoi(portString)
+	return host, port
+}
+
+// newScenario returns a scenario of a route of an application of the host, sending the body and the header
+// x-tenant: acme.
+func newScenario(t *testing.T, host string, port int, body string) (interfaces.Route, interfaces.Scenario) {
+	t.Helper()
+
+	const testMeta = "automation_status: automated\nimportance: medium\n"
+	meta, _ := models.NewMetaFromString(testMeta)
+	app, err := models.NewApplication("", models.NewConfig(), models.NewRequirements(), meta, models.NewHost("http", host, port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &models.Info{}
+	info.SetName("health")
+	info.SetPath("/grpc.health.v1.Health/Check")
+	info.SetMethod(models.Method{Name: http.MethodPost})
+	route := app.NewRoute(info, testMeta)
+	app.AddRoute("health", &route)
+
+	scenarioInfo := &models.Info{}
+	scenarioInfo.SetName("check")
+	scenario := route.NewScenario(scenarioInfo, testMeta)
+	scenario.SetBody([]byte(body))
+	scenario.GetScenarioParametersRegistry().RegisterHeader("X-Tenant", "acme")
+	return route, scenario
+}
+
+// healthFiles returns the descriptors of the health service, as a descriptor set would give them.
+func healthFiles(t *testing.T) *descriptorpb.FileDescriptorSet {
+	return &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{
+		protodesc.ToFileDescriptorProto(healthpb.File_grpc_health_v1_health_proto),
+	}}
+}
+
+// TestExchange invokes the health service with descriptors from a descriptor set and from server reflection,
+// and checks the conversion of the JSON body to the input message and of the output message back to JSON.
+func TestExchange(t *testing.T) {
+	host, port := startServer(t)
+	files, err := newFiles(healthFiles(t).File)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		reflection  bool
+		body        string
+		wantStatus  int
+		wantBody    string
+		wantHeaders map[string]string
+		wantErr     string
+	}{
+		{
+			name:       "descriptor set",
+			body:       `{"service": "shop.v1.Orders"}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"status":"NOT_SERVING"}`,
+			wantHeaders: map[string]string{
+				HeaderStatus: "0", "Content-Type": "application/json", "X-Tenant": "acme", "Trace-Bin": "AQI=",
+			},
+		},
+		{
+			name:       "reflection",
+			reflection: true,
+			body:       `{"service": "shop.v1.Orders"}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"status":"NOT_SERVING"}`,
+		},
+		{
+			name:       "empty body",
+			reflection: true,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"status":"SERVING"}`,
+		},
+		{
+			name:        "error status",
+			body:        `{"service": "shop.v1.Payments"}`,
+			wantStatus:  http.StatusNotFound,
+			wantBody:    `{"code":5,"message":"unknown service"}`,
+			wantHeaders: map[string]string{HeaderStatus: "5", HeaderMessage: "unknown service"},
+		},
+		{
+			name:    "invalid body",
+			body:    `{"services": "shop.v1.Orders"}`,
+			wantErr: "request body to grpc.health.v1.HealthCheckRequest",
+		},
+	}
+	clients := NewClients()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := Options{Method: "grpc.health.v1.Health/Check", Files: files, Clients: clients}
+			if test.reflection {
+				opts.Files = nil
+			}
+			exchange, err := Exchange(opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			route, scenario := newScenario(t, host, port, test.body)
+			scenario.SetExchange(exchange)
+
+			resp, err := route.GetScenarioRegistry().Execute(context.Background(), scenario)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("expected the error %q, got %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.GetStatusCode() != test.wantStatus {
+				t.Errorf("expected the status %d, got %d", test.wantStatus, resp.GetStatusCode())
+			}
+			if resp.String() != test.wantBody {
+				t.Errorf("expected the body %s, got %s", test.wantBody, resp.String())
+			}
+			for name, want := range test.wantHeaders {
+				if got, _ := resp.HeaderValue(name); got != want {
+					t.Errorf("expected the header %s to be %q, got %q", name, want, got)
+				}
+			}
+		})
+	}
+
+	if len(clients.conns) != 1 {
+		t.Errorf("expected a single connection to the server, got %d", len(clients.conns))
+	}
+	if len(clients.reflected) != 1 {
+		t.Errorf("expected the service to be reflected once, got %d", len(clients.reflected))
+	}
+}
+
+// TestExchangeInvalid checks the methods refused before any call.
+func TestExchangeInvalid(t *testing.T) {
+	files, err := newFiles(healthFiles(t).File)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		method  string
+		wantErr string
+	}{
+		{"Check", `invalid method "Check"`},
+		{"grpc.health.v1.Orders/Check", "service grpc.health.v1.Orders not found"},
+		{"grpc.health.v1.HealthCheckRequest/Check", "grpc.health.v1.HealthCheckRequest is not a service"},
+		{"grpc.health.v1.Health/List", "service grpc.health.v1.Health has no method List"},
+		{"grpc.health.v1.Health/Watch", "grpc.health.v1.Health/Watch is a streaming method"},
+	}
+	for _, test := range tests {
+		_, err := Exchange(Options{Method: test.method, Files: files})
+		if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+			t.Errorf("%s: expected the error %q, got %v", test.method, test.wantErr, err)
+		}
+	}
+}
+
+func TestHTTPStatus(t *testing.T) {
+	tests := []struct {
+		code codes.Code
+		want int
+	}{
+		{codes.OK, http.StatusOK},
+		{codes.Canceled, 499},
+		{codes.InvalidArgument, http.StatusBadRequest},
+		{codes.FailedPrecondition, http.StatusBadRequest},
+		{codes.DeadlineExceeded, http.StatusGatewayTimeout},
+		{codes.NotFound, http.StatusNotFound},
+		{codes.Aborted, http.StatusConflict},
+		{codes.PermissionDenied, http.StatusForbidden},
+		{codes.Unauthenticated, http.StatusUnauthorized},
+		{codes.ResourceExhausted, http.StatusTooManyRequests},
+		{codes.Unimplemented, http.StatusNotImplemented},
+		{codes.Unavailable, http.StatusServiceUnavailable},
+		{codes.DataLoss, http.StatusInternalServerError},
+		{codes.Code(42), http.StatusInternalServerError},
+	}
+	for _, test := range tests {
+		if got := HTTPStatus(test.code); got != test.want {
+			t.Errorf("%s: expected %d, got %d", CodeName(test.code), test.want, got)
+		}
+	}
+}
+
+// hookContext returns the context of a scenario that received the response, with the variable id set to 42.
+func hookContext(t *testing.T, resp interfaces.Response) *models.HookContext {
+	_, scenario := newScenario(t, "localhost", 50051, "")
+	hc := models.NewHookContext(context.Background(), scenario)
+	hc.Variables().Set("id", "42")
+	hc.SetResponse(resp)
+	return hc
+}
+
+func TestStatusHook(t *testing.T) {
+	notFound := models.NewStaticResponse(http.StatusNotFound, map[string]string{HeaderStatus: "5", HeaderMessage: "order 42 not found"}, nil)
+	tests := []struct {
+		name     string
+		expected string
+		resp     interfaces.Response
+		wantErr  string
+	}{
+		{name: "name", expected: "NOT_FOUND", resp: notFound},
+		{name: "lower case name", expected: "not_found", resp: notFound},
+		{name: "number", expected: "5", resp: notFound},
+		{name: "mismatch with message", expected: "OK", resp: notFound, wantErr: "expected gRPC status OK, got NOT_FOUND: order 42 not found"},
+		{
+			name: "mismatch", expected: "UNAVAILABLE",
+			resp:    models.NewStaticResponse(http.StatusOK, map[string]string{HeaderStatus: "0"}, nil),
+			wantErr: "expected gRPC status UNAVAILABLE, got OK",
+		},
+		{name: "unknown name", expected: "MISSING", resp: notFound, wantErr: `unknown gRPC status "MISSING"`},
+		{
+			name: "no status", expected: "OK",
+			resp:    models.NewStaticResponse(http.StatusOK, nil, nil),
+			wantErr: "the response has no gRPC status",
+		},
+	}
+	for _, test := range tests {
+		err := StatusHook(test.expected)(hookContext(t, test.resp))
+		if test.wantErr == "" && err != nil {
+			t.Errorf("%s: expected no error, got %v", test.name, err)
+		}
+		if test.wantErr != "" && (err == nil || err.Error() != test.wantErr) {
+			t.Errorf("%s: expected the error %q, got %v", test.name, test.wantErr, err)
+		}
+	}
+}
+
+func TestMessageHook(t *testing.T) {
+	body := []byte(`{"order": {"id": "42", "status": "SHIPPED", "total": 12.5, "paid": true}, "items": [{"sku": "a-1"}]}`)
+	tests := []struct {
+		name     string
+		expected map[string]interface{}
+		wantErr  string
+	}{
+		{name: "strings", expected: map[string]interface{}{"order.status": "SHIPPED", "$.items[0].sku": "a-1"}},
+		{name: "variables", expected: map[string]interface{}{"order.id": "{{id}}"}},
+		{name: "other values", expected: map[string]interface{}{"order.total": 12.5, "order.paid": true}},
+		{name: "number as text", expected: map[string]interface{}{"order.total": "12.5"}},
+		{name: "mismatch", expected: map[string]interface{}{"order.status": "PENDING"}, wantErr: `message: expected order.status to be "PENDING", got SHIPPED`},
+		{name: "value mismatch", expected: map[string]interface{}{"order.paid": false}, wantErr: "message: expected order.paid to be false, got true"},
+		{name: "missing", expected: map[string]interface{}{"order.carrier": "ups"}, wantErr: "message: order.carrier not found"},
+	}
+	for _, test := range tests {
+		hook, err := MessageHook(test.expected)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		err = hook(hookContext(t, models.NewStaticResponse(http.StatusOK, nil, body)))
+		if test.wantErr == "" && err != nil {
+			t.Errorf("%s: expected no error, got %v", test.name, err)
+		}
+		if test.wantErr != "" && (err == nil || err.Error() != test.wantErr) {
+			t.Errorf("%s: expected the error %q, got %v", test.name, test.wantErr, err)
+		}
+	}
+
+	if _, err := MessageHook(map[string]interface{}{"items[": "a"}); err == nil {
+		t.Error("expected an invalid expression to be refused")
+	}
+	hook, _ := MessageHook(map[string]interface{}{"order.status": "SHIPPED"})
+	if err := hook(hookContext(t, models.NewStaticResponse(http.StatusOK, nil, []byte("not JSON")))); err == nil {
+		t.Error("expected a body that is not JSON to fail")
+	}
+}
@@ -0,0 +1,248 @@
+package importer
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// curlIgnored are the options of cURL without effect on the request, and whether they take a value.
+var curlIgnored = map[string]bool{
+	"-s": false, "--silent": false, "-S": false, "--show-error": false, "-v": false, "--verbose": false,
+	"-i": false, "--include": false, "-L": false, "--location": false, "-k": false, "--insecure": false,
+	"--compressed": false, "-f": false, "--fail": false, "-g": false, "--globoff": false, "--http1.1": false,
+	"--http2": false, "-N": false, "--no-buffer": false, "-o": true, "--output": true, "-m": true,
+	"--max-time": true, "--connect-timeout": true, "--retry": true, "-w": true, "--write-out": true,
+}
+
+// Curl returns the request of a cURL command, given as a single string, e.g. copied from the developer tools
+// of a browser, or as the arguments of the command already split by the shell.
+// Options that cannot be converted, e.g. multipart forms, are returned as warnings.
+func Curl(args []string) (Request, []string, error) {
+	if len(args) == 1 {
+		var err error
+		if args, err = splitShell(args[0]); err != nil {
+			return Request{}, nil, err
+		}
+	}
+	if len(args) > 0 && (args[0] == "curl" || strings.HasSuffix(args[0], "/curl")) {
+		args = args[1:]
+	}
+
+	req := Request{Name: "curl"}
+	var data []string
+	var warnings []string
+	get, head := false, false
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		value := func() (string, error) {
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("option %s requires a value", arg)
+			}
+			i++
+			return args[i], nil
+		}
+
+		// Short options may be joined to their value, e.g. -XPOST.
+		if len(arg) > 2 && arg[0] == '-' && arg[1] != '-' && strings.ContainsRune("XHdbuAe", rune(arg[1])) {
+			args = append(args[:i+1], append([]string{arg[2:]}, args[i+1:]...)...)
+			arg = arg[:2]
+		}
+
+		var err error
+		var v string
+		switch arg {
+		case "-X", "--request":
+			v, err = value()
+			req.Method = strings.ToUpper(v)
+		case "-H", "--header":
+			v, err = value()
+			if name, headerValue, ok := strings.Cut(v, ":"); ok {
+				req.Headers = append(req.Headers, [2]string{strings.TrimSpace(name), strings.TrimSpace(headerValue)})
+			}
+		case "-d", "--data", "--data-raw", "--data-binary", "--data-ascii":
+			v, err = value()
+			if strings.HasPrefix(v, "@") && arg != "--data-raw" {
+				warnings = append(warnings, fmt.Sprintf("the body is read from the file %s, set the body of the scenario", v[1:]))
+				continue
+			}
+			data = append(data, v)
+		case "--data-urlencode":
+			v, err = value()
+			if name, content, ok := strings.Cut(v, "="); ok {
+				data = append(data, name+"="+url.QueryEscape(content))
+			} else {
+				data = append(data, url.QueryEscape(v))
+			}
+		case "--json":
+			v, err = value()
+			data = append(data, v)
+			req.Headers = append(req.Headers, [2]string{"Content-Type", "application/json"}, [2]string{"Accept", "application/json"})
+		case "-u", "--user":
+			v, err = value()
+			req.Headers = append(req.Headers, [2]string{"Authorization", "Basic " + base64.StdEncoding.EncodeToString([]byte(v))})
+		case "-b", "--cookie":
+			v, err = value()
+			req.Headers = append(req.Headers, [2]string{"Cookie", v})
+		case "-A", "--user-agent":
+			v, err = value()
+			req.Headers = append(req.Headers, [2]string{"User-Agent", v})
+		case "-e", "--referer":
+			v, err = value()
+			req.Headers = append(req.Headers, [2]string{"Referer", v})
+		case "-F", "--form", "--form-string":
+			v, err = value()
+			warnings = append(warnings, fmt.Sprintf("multipart form field %s not converted, set the body of the scenario", strings.SplitN(v, "=", 2)[0]))
+		case "-G", "--get":
+			get = true
+		case "-I", "--head":
+			head = true
+		case "--url":
+			req.URL, err = value()
+		default:
+			if takesValue, ok := curlIgnored[arg]; ok {
+				if takesValue {
+					_, err = value()
+				}
+			} else if strings.HasPrefix(arg, "-") {
+				warnings = append(warnings, fmt.Sprintf("option %s not converted", arg))
+			} else if req.URL == "" {
+				req.URL = arg
+			}
+		}
+		if err != nil {
+			return Request{}, nil, err
+		}
+	}
+
+	if req.URL == "" {
+		return Request{}, nil, errors.New("the cURL command has no URL")
+	}
+	body := strings.Join(data, "&")
+	switch {
+	case get && body != "":
+		separator := "?"
+		if strings.Contains(req.URL, "?") {
+			separator = "&"
+		}
+		req.URL += separator + body
+	case body != "":
+		req.Body = body
+		if !hasHeaderPair(req.Headers, "Content-Type") {
+			req.Headers = append(req.Headers, [2]string{"Content-Type", "application/x-www-form-urlencoded"})
+		}
+	}
+	if req.Method == "" {
+		switch {
+		case head:
+			req.Method = "HEAD"
+		case req.Body != "":
+			req.Method = "POST"
+		default:
+			req.Method = "GET"
+		}
+	}
+	return req, warnings, nil
+}
+
+// splitShell splits a command line into its arguments, following the quoting rules of POSIX shells,
+// and $'...' strings as written by browsers.
+func splitShell(line string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\' && i+1 < len(line) && (line[i+1] == '\n' || line[i+1] == '\r'):
+			// A line continuation.
+			i++
+			if line[i] == '\r' && i+1 < len(line) && line[i+1] == '\n' {
+				i++
+			}
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		case c == '\\' && i+1 < len(line):
+			i++
+			current.WriteByte(line[i])
+			inArg = true
+		case c == '\'':
+			end := strings.IndexByte(line[i+1:], '\'')
+			if end < 0 {
+				return nil, errors.New("unterminated single quote")
+			}
+			current.WriteString(line[i+1 : i+1+end])
+			i += end + 1
+			inArg = true
+		case c == '$' && i+1 < len(line) && line[i+1] == '\'':
+			n, err := ansiC(line[i+2:], &current)
+			if err != nil {
+				return nil, err
+			}
+			i += n + 1
+			inArg = true
+		case c == '"':
+			i++
+			for ; i < len(line) && line[i] != '"'; i++ {
+				if line[i] == '\\' && i+1 < len(line) && strings.IndexByte("\"\\$`\n", line[i+1]) >= 0 {
+					i++
+					if line[i] == '\n' {
+						continue
+					}
+				}
+				current.WriteByte(line[i])
+			}
+			if i >= len(line) {
+				return nil, errors.New("unterminated double quote")
+			}
+			inArg = true
+		default:
+			current.WriteByte(c)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// ansiC decodes the content of a $'...' string up to its closing quote and returns the number of bytes read.
+func ansiC(s string, out *strings.Builder) (int, error) {
+	escapes := map[byte]byte{'n': '\n', 't': '\t', 'r': '\r', '\\': '\\', '\'': '\'', '"': '"', '0': 0}
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\'':
+			return i + 1, nil
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				if e, ok := escapes[s[i]]; ok {
+					out.WriteByte(e)
+				} else {
+					out.WriteByte('\\')
+					out.WriteByte(s[i])
+				}
+			}
+		default:
+			out.WriteByte(s[i])
+		}
+	}
+	return 0, errors.New("unterminated $' quote")
+}
+
+/* Example usage -
+
+req, warnings, err := importer.Curl([]string{`curl -X POST https://api.example.com/users -H 'Content-Type: application/json' -d '{"name":"bob"}'`})
+if err != nil {
+    log.Fatal(err)
+}
+result, err := importer.FromRequests([]importer.Request{req}, importer.RequestOptions{})
+
+*/
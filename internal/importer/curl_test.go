@@ -0,0 +1,133 @@
+package importer
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestSplitShell checks the splitting of command lines following the quoting rules of shells.
+func TestSplitShell(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want []string
+	}{
+		{"words", "curl  -X\tGET url", []string{"curl", "-X", "GET", "url"}},
+		{"single quotes", `curl -d '{"a": "b c"}'`, []string{"curl", "-d", `{"a": "b c"}`}},
+		{"double quotes", `curl -H "X-Name: \"bob\" \$HOME \n"`, []string{"curl", "-H", `X-Name: "bob" $HOME \n`}},
+		{"escaped space", `curl a\ b`, []string{"curl", "a b"}},
+		{"line continuations", "curl url \\\n  -H 'A: 1' \\\r\n  -I", []string{"curl", "url", "-H", "A: 1", "-I"}},
+		{"ansi-c quotes", `curl -d $'{"a":\n\t"it\'s"}'`, []string{"curl", "-d", "{\"a\":\n\t\"it's\"}"}},
+		{"ansi-c unknown escape", `curl $'é'`, []string{"curl", `é`}},
+		{"joined quotes", `curl 'a'"b"c`, []string{"curl", "abc"}},
+		{"empty quotes", `curl ''`, []string{"curl", ""}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := splitShell(test.line)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
+
+// TestSplitShellInvalid checks that unterminated quotes are reported.
+func TestSplitShellInvalid(t *testing.T) {
+	for _, line := range []string{`curl 'a`, `curl "a`, `curl $'a`} {
+		if _, err := splitShell(line); err == nil {
+			t.Errorf("%s: expected an error", line)
+		}
+	}
+}
+
+// TestCurl checks the conversion of cURL commands to requests.
+func TestCurl(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		want     Request
+		warnings int
+	}{
+		{
+			name: "get",
+			args: []string{"curl https://api.example.com/users -s --compressed -o out.json"},
+			want: Request{Name: "curl", Method: "GET", URL: "https://api.example.com/users"},
+		},
+		{
+			name: "json body",
+			args: []string{`curl -XPOST 'https://api.example.com/users' -H 'Content-Type: application/json' --data-raw '{"name":"bob"}'`},
+			want: Request{Name: "curl", Method: "POST", URL: "https://api.example.com/users",
+				Headers: [][2]string{{"Content-Type", "application/json"}}, Body: `{"name":"bob"}`},
+		},
+		{
+			name: "form body",
+			args: []string{"curl", "https://api.example.com/login", "-d", "user=bob", "--data-urlencode", "note=a b"},
+			want: Request{Name: "curl", Method: "POST", URL: "https://api.example.com/login",
+				Headers: [][2]string{{"Content-Type", "application/x-www-form-urlencoded"}}, Body: "user=bob&note=a+b"},
+		},
+		{
+			name: "get with data",
+			args: []string{"curl -G 'https://api.example.com/search?q=a' -d page=2"},
+			want: Request{Name: "curl", Method: "GET", URL: "https://api.example.com/search?q=a&page=2"},
+		},
+		{
+			name: "head and user",
+			args: []string{"curl -I -u admin:s3cr3t --url https://api.example.com/health"},
+			want: Request{Name: "curl", Method: "HEAD", URL: "https://api.example.com/health",
+				Headers: [][2]string{{"Authorization", "Basic YWRtaW46czNjcjN0"}}},
+		},
+		{
+			name:     "unconverted options",
+			args:     []string{"curl -F file=@a.txt -d @body.json --tr-encoding https://api.example.com/upload"},
+			want:     Request{Name: "curl", Method: "GET", URL: "https://api.example.com/upload"},
+			warnings: 3,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, warnings, err := Curl(test.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %+v, got %+v", test.want, got)
+			}
+			if len(warnings) != test.warnings {
+				t.Errorf("expected %d warnings, got %q", test.warnings, warnings)
+			}
+		})
+	}
+}
+
+// TestCurlInvalid checks the errors of commands that cannot be converted.
+func TestCurlInvalid(t *testing.T) {
+	for _, line := range []string{"curl -s", "curl https://api.example.com -H", "curl 'https://api.example.com"} {
+		if _, _, err := Curl([]string{line}); err == nil {
+			t.Errorf("%s: expected an error", line)
+		}
+	}
+}
+
+// TestCurlParse checks that the suite of cURL commands is read back by the parser.
+func TestCurlParse(t *testing.T) {
+	var requests []Request
+	for _, line := range []string{
+		"curl 'https://api.example.com/users?page=2' -H 'Accept: application/json'",
+		`curl -X POST https://api.example.com/users --json '{"name":"bob"}'`,
+	} {
+		req, _, err := Curl([]string{line})
+		if err != nil {
+			t.Fatal(err)
+		}
+		requests = append(requests, req)
+	}
+	result, err := FromRequests(requests, RequestOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertParses(t, result.Suite)
+}
@@ -0,0 +1,138 @@
+package importer
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// har is an HTTP Archive, as exported by browsers and proxies.
+type har struct {
+	Log struct {
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+type harEntry struct {
+	Request struct {
+		Method   string    `json:"method"`
+		URL      string    `json:"url"`
+		Headers  []harPair `json:"headers"`
+		PostData *struct {
+			MimeType string    `json:"mimeType"`
+			Text     string    `json:"text"`
+			Params   []harPair `json:"params"`
+		} `json:"postData"`
+	} `json:"request"`
+	Response struct {
+		Status  int `json:"status"`
+		Content struct {
+			MimeType string `json:"mimeType"`
+			Text     string `json:"text"`
+			Encoding string `json:"encoding"`
+		} `json:"content"`
+	} `json:"response"`
+}
+
+type harPair struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HAR returns the requests of an HTTP Archive sent to one of the hosts, every request when hosts is empty.
+// A host matches itself and its subdomains, e.g. example.com matches api.example.com.
+// Every request is named after its position in the archive, e.g. "entry 12", so that it can be found there.
+func HAR(data []byte, hosts []string) ([]Request, error) {
+	var archive har
+	if err := json.Unmarshal(data, &archive); err != nil {
+		return nil, fmt.Errorf("invalid HAR: %v", err)
+	}
+
+	var requests []Request
+	for i, entry := range archive.Log.Entries {
+		u, err := url.Parse(entry.Request.URL)
+		if err != nil || !matchHost(hosts, u.Hostname()) {
+			continue
+		}
+
+		req := Request{
+			Name:   fmt.Sprintf("entry %d", i+1),
+			Method: entry.Request.Method,
+			URL:    entry.Request.URL,
+		}
+		for _, header := range entry.Request.Headers {
+			req.Headers = append(req.Headers, [2]string{header.Name, header.Value})
+		}
+		if post := entry.Request.PostData; post != nil {
+			req.Body = post.Text
+			if req.Body == "" && len(post.Params) > 0 {
+				values := url.Values{}
+				for _, param := range post.Params {
+					values.Add(param.Name, param.Value)
+				}
+				req.Body = values.Encode()
+			}
+			if post.MimeType != "" && !hasHeaderPair(req.Headers, "Content-Type") {
+				req.Headers = append(req.Headers, [2]string{"Content-Type", post.MimeType})
+			}
+		}
+
+		// Aborted and blocked requests have no status.
+		if status := entry.Response.Status; status > 0 {
+			body := []byte(entry.Response.Content.Text)
+			if entry.Response.Content.Encoding == "base64" {
+				if body, err = base64.StdEncoding.DecodeString(entry.Response.Content.Text); err != nil {
+					body = nil
+				}
+			}
+			req.Response = &RecordedResponse{Status: status, Body: body}
+		}
+		requests = append(requests, req)
+	}
+
+	if len(requests) == 0 {
+		if len(hosts) > 0 {
+			return nil, fmt.Errorf("no request of the HAR is sent to %s", strings.Join(hosts, ", "))
+		}
+		return nil, fmt.Errorf("the HAR has no request")
+	}
+	return requests, nil
+}
+
+func matchHost(hosts []string, hostname string) bool {
+	if len(hosts) == 0 {
+		return true
+	}
+	for _, host := range hosts {
+		if strings.EqualFold(hostname, host) || strings.HasSuffix(strings.ToLower(hostname), "."+strings.ToLower(host)) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasHeaderPair(headers [][2]string, name string) bool {
+	for _, header := range headers {
+		if strings.EqualFold(header[0], name) {
+			return true
+		}
+	}
+	return false
+}
+
+/* Example usage -
+
+data, _ := ioutil.ReadFile("session.har")
+requests, err := importer.HAR(data, []string{"api.example.com"})
+if err != nil {
+    log.Fatal(err)
+}
+result, err := importer.FromRequests(requests, importer.RequestOptions{Snapshot: true})
+if err != nil {
+    log.Fatal(err)
+}
+result.Write("suites/session.yaml")
+
+*/
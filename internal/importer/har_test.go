@@ -0,0 +1,108 @@
+package importer
+
+import (
+	"testing"
+)
+
+const testHAR = `{"log": {"entries": [
+  {"request": {"method": "GET", "url": "https://api.example.com/users?page=2",
+    "headers": [{"name": ":authority", "value": "api.example.com"}, {"name": "accept", "value": "application/json"},
+      {"name": "user-agent", "value": "Mozilla/5.0"}, {"name": "authorization", "value": "Bearer s3cr3t"}]},
+   "response": {"status": 200, "content": {"mimeType": "application/json", "text": "eyJpZCI6MX0=", "encoding": "base64"}}},
+  {"request": {"method": "GET", "url": "https://cdn.example.net/app.js", "headers": []},
+   "response": {"status": 200, "content": {"text": "x"}}},
+  {"request": {"method": "POST", "url": "https://api.example.com/login", "headers": [],
+    "postData": {"mimeType": "application/x-www-form-urlencoded", "params": [{"name": "user", "value": "bob"}]}},
+   "response": {"status": 0, "content": {}}},
+  {"request": {"method": "GET", "url": "https://api.example.com/users?page=3", "headers": []},
+   "response": {"status": 200, "content": {"text": "{\"id\":2}"}}}
+]}}`
+
+// TestHAR checks the requests read from an archive and the filtering of their hosts.
+func TestHAR(t *testing.T) {
+	tests := []struct {
+		name  string
+		hosts []string
+		want  []string
+	}{
+		{"every host", nil, []string{"entry 1", "entry 2", "entry 3", "entry 4"}},
+		{"subdomains", []string{"example.com"}, []string{"entry 1", "entry 3", "entry 4"}},
+		{"case", []string{"CDN.example.NET"}, []string{"entry 2"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			requests, err := HAR([]byte(testHAR), test.hosts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, req := range requests {
+				names = append(names, req.Name)
+			}
+			if len(names) != len(test.want) {
+				t.Fatalf("expected %v, got %v", test.want, names)
+			}
+			for i := range names {
+				if names[i] != test.want[i] {
+					t.Errorf("expected %v, got %v", test.want, names)
+				}
+			}
+		})
+	}
+
+	if _, err := HAR([]byte(testHAR), []string{"example.org"}); err == nil {
+		t.Error("expected an error when no request is sent to the hosts")
+	}
+	if _, err := HAR([]byte("{"), nil); err == nil {
+		t.Error("expected an error for an invalid archive")
+	}
+}
+
+// TestHARRequests checks the bodies and responses of the requests of an archive.
+func TestHARRequests(t *testing.T) {
+	requests, err := HAR([]byte(testHAR), []string{"api.example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp := requests[0].Response; resp == nil || resp.Status != 200 || string(resp.Body) != `{"id":1}` {
+		t.Errorf("expected the decoded response of the first request, got %+v", resp)
+	}
+	login := requests[1]
+	if login.Body != "user=bob" || !hasHeaderPair(login.Headers, "Content-Type") {
+		t.Errorf("expected the form body and its content type, got %+v", login)
+	}
+	if login.Response != nil {
+		t.Errorf("expected no response for an aborted request, got %+v", login.Response)
+	}
+}
+
+// TestHARSuite checks the suite of an archive: grouped routes, filtered headers and snapshots.
+func TestHARSuite(t *testing.T) {
+	requests, err := HAR([]byte(testHAR), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := FromRequests(requests, RequestOptions{Snapshot: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	suite := result.Suite
+	if suite.Host.Hostname != "api.example.com" || len(suite.Routes) != 2 {
+		t.Fatalf("expected 2 routes of api.example.com, got %+v", suite)
+	}
+	users := suite.Routes[0]
+	if users.Path != "/users" || len(users.Scenarios) != 2 {
+		t.Fatalf("expected the 2 requests of /users to share a route, got %+v", users)
+	}
+	first := users.Scenarios[0]
+	if len(first.Headers) != 1 || first.Headers["Accept"] != "application/json" {
+		t.Errorf("expected only the Accept header to be kept, got %v", first.Headers)
+	}
+	if first.Query["page"] != "2" || !first.Snapshot || string(first.Baseline) != `{"id":1}` {
+		t.Errorf("expected the query and snapshot of the request, got %+v", first)
+	}
+	if !containsWarning(result.Warnings, "1 requests to https://cdn.example.net skipped") {
+		t.Errorf("expected a warning about the other host, got %v", result.Warnings)
+	}
+	assertParses(t, suite)
+}
@@ -0,0 +1,705 @@
+package importer
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// postmanCollection is a Postman collection, in the v2.0 or v2.1 format.
+type postmanCollection struct {
+	Info struct {
+		Name        string          `json:"name"`
+		Description json.RawMessage `json:"description"`
+		Schema      string          `json:"schema"`
+	} `json:"info"`
+	Item     []postmanItem     `json:"item"`
+	Auth     *postmanAuth      `json:"auth"`
+	Event    []postmanEvent    `json:"event"`
+	Variable []postmanVariable `json:"variable"`
+}
+
+// postmanItem is a folder, when it has items, or a request.
+type postmanItem struct {
+	Name        string          `json:"name"`
+	Description json.RawMessage `json:"description"`
+	Item        []postmanItem   `json:"item"`
+	Request     *postmanRequest `json:"request"`
+	Auth        *postmanAuth    `json:"auth"`
+	Event       []postmanEvent  `json:"event"`
+}
+
+type postmanRequest struct {
+	Method      string          `json:"method"`
+	URL         json.RawMessage `json:"url"`
+	Header      []postmanPair   `json:"header"`
+	Body        *postmanBody    `json:"body"`
+	Auth        *postmanAuth    `json:"auth"`
+	Description json.RawMessage `json:"description"`
+}
+
+type postmanURL struct {
+	Raw      string            `json:"raw"`
+	Query    []postmanPair     `json:"query"`
+	Variable []postmanVariable `json:"variable"`
+}
+
+type postmanPair struct {
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Disabled bool   `json:"disabled"`
+	Type     string `json:"type"`
+}
+
+type postmanBody struct {
+	Mode       string          `json:"mode"`
+	Raw        string          `json:"raw"`
+	URLEncoded []postmanPair   `json:"urlencoded"`
+	FormData   []postmanPair   `json:"formdata"`
+	GraphQL    *postmanGraphQL `json:"graphql"`
+	Disabled   bool            `json:"disabled"`
+}
+
+type postmanGraphQL struct {
+	Query     string `json:"query"`
+	Variables string `json:"variables"`
+}
+
+// postmanAuth is an auth block. Its parameters are a list of key-value pairs in v2.1 and an object in v2.0.
+type postmanAuth struct {
+	Type   string
+	Params map[string]string
+}
+
+func (a *postmanAuth) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw["type"], &a.Type); err != nil {
+		return err
+	}
+	a.Params = make(map[string]string)
+	params, ok := raw[a.Type]
+	if !ok {
+		return nil
+	}
+	var pairs []struct {
+		Key   string      `json:"key"`
+		Value interface{} `json:"value"`
+	}
+	if err := json.Unmarshal(params, &pairs); err == nil {
+		for _, pair := range pairs {
+			a.Params[pair.Key] = fmt.Sprint(pair.Value)
+		}
+		return nil
+	}
+	var object map[string]interface{}
+	if err := json.Unmarshal(params, &object); err != nil {
+		return err
+	}
+	for key, value := range object {
+		a.Params[key] = fmt.Sprint(value)
+	}
+	return nil
+}
+
+type postmanEvent struct {
+	Listen string `json:"listen"`
+	Script struct {
+		Exec json.RawMessage `json:"exec"`
+	} `json:"script"`
+}
+
+type postmanVariable struct {
+	Key      string      `json:"key"`
+	Value    interface{} `json:"value"`
+	Disabled bool        `json:"disabled"`
+}
+
+// postmanEnvironment is a Postman environment export.
+type postmanEnvironment struct {
+	Name   string `json:"name"`
+	Values []struct {
+		Key     string      `json:"key"`
+		Value   interface{} `json:"value"`
+		Enabled *bool       `json:"enabled"`
+	} `json:"values"`
+}
+
+var (
+	postmanVariableRef = regexp.MustCompile(`\{\{([^{}]+)\}\}`)
+	expectStatus       = []*regexp.Regexp{
+		regexp.MustCompile(`to\.have\.status\(\s*(\d{3})\s*\)`),
+		regexp.MustCompile(`response\.code\s*\)\.to\.(?:eql|equal|be)\(\s*(\d{3})\s*\)`),
+	}
+)
+
+// postmanImport holds the state of the conversion of a collection.
+type postmanImport struct {
+	result    *Result
+	variables map[string]string
+
+	// auth is the auth block of the collection, converted to the headers and query parameters of the suite.
+	auth *postmanAuth
+
+	// hostVariable is set when the host of the suite comes from a variable, e.g. {{baseUrl}},
+	// in which case it belongs to the configuration of the environment.
+	hostVariable bool
+}
+
+// Postman converts a Postman collection, and optionally an environment, to a suite.
+// Requests sharing a method and a path become the scenarios of the same route, named after the first of them,
+// and their folders become the component and tags of the route. The variables of the collection and of the
+// environment are written to the configuration of the environment, where {{name}} references find them,
+// together with the host when it comes from a variable. Auth blocks become headers or query parameters,
+// the simple status checks of test scripts become expectations, and scripts are copied to TODO comments.
+func Postman(collection, environment []byte) (*Result, error) {
+	var c postmanCollection
+	if err := json.Unmarshal(collection, &c); err != nil {
+		return nil, fmt.Errorf("collection: %v", err)
+	}
+	if c.Info.Name == "" && len(c.Item) == 0 {
+		return nil, fmt.Errorf("collection: not a Postman collection")
+	}
+	if c.Info.Schema != "" && !strings.Contains(c.Info.Schema, "v2.") {
+		return nil, fmt.Errorf("collection: unsupported schema %s, export the collection in the v2.1 format", c.Info.Schema)
+	}
+
+	p := &postmanImport{
+		result:    &Result{Suite: &Suite{}, Configs: map[string]map[string]interface{}{}},
+		variables: map[string]string{},
+		auth:      c.Auth,
+	}
+	suite := p.result.Suite
+	suite.Comment = fmt.Sprintf("Imported from the Postman collection %q.", c.Info.Name)
+
+	config := map[string]interface{}{}
+	for _, v := range c.Variable {
+		if !v.Disabled {
+			p.variables[v.Key] = fmt.Sprint(v.Value)
+		}
+	}
+	env := "postman"
+	if len(environment) > 0 {
+		var e postmanEnvironment
+		if err := json.Unmarshal(environment, &e); err != nil {
+			return nil, fmt.Errorf("environment: %v", err)
+		}
+		if e.Name != "" {
+			env = slug(e.Name)
+		}
+		for _, v := range e.Values {
+			if v.Enabled == nil || *v.Enabled {
+				p.variables[v.Key] = fmt.Sprint(v.Value)
+			}
+		}
+	}
+
+	if c.Auth != nil {
+		headers, query := p.convertAuth(c.Auth, "the collection")
+		suite.Headers, suite.Query = headers, query
+	}
+	suite.TODO = append(suite.TODO, p.scripts(c.Event, "the collection")...)
+
+	p.items(c.Item, nil)
+	if len(suite.Routes) == 0 {
+		return nil, fmt.Errorf("collection: no request to import")
+	}
+	p.warnTODO()
+
+	if len(p.variables) > 0 {
+		variables := make(map[string]interface{}, len(p.variables))
+		for key, value := range p.variables {
+			variables[key] = value
+		}
+		config["variables"] = variables
+	}
+	if p.hostVariable {
+		config["host"] = ConfigHost(suite.Host)
+	}
+	if len(config) > 0 {
+		p.result.Configs[env] = config
+	}
+	return p.result, nil
+}
+
+// items converts the requests of a folder and of its sub-folders.
+func (p *postmanImport) items(items []postmanItem, folders []string) {
+	for _, item := range items {
+		if item.Request == nil {
+			path := append(append([]string(nil), folders...), item.Name)
+			suite := p.result.Suite
+			suite.TODO = append(suite.TODO, p.scripts(item.Event, "the folder "+strings.Join(path, "/"))...)
+			p.items(markAuth(item.Item, item.Auth), path)
+			continue
+		}
+		p.request(item, folders)
+	}
+}
+
+// markAuth gives the auth of a folder to the items of the folder that do not have their own.
+func markAuth(items []postmanItem, auth *postmanAuth) []postmanItem {
+	if auth == nil {
+		return items
+	}
+	marked := make([]postmanItem, len(items))
+	for i, item := range items {
+		marked[i] = item
+		if item.Request == nil && item.Auth == nil {
+			marked[i].Auth = auth
+		} else if item.Request != nil && item.Request.Auth == nil {
+			request := *item.Request
+			request.Auth = auth
+			marked[i].Request = &request
+		}
+	}
+	return marked
+}
+
+// request converts a request to a scenario of the route with its method and path.
+func (p *postmanImport) request(item postmanItem, folders []string) {
+	req := item.Request
+	name := item.Name
+	where := "request " + strings.Join(append(append([]string(nil), folders...), name), "/")
+
+	u, err := p.url(req.URL)
+	if err != nil {
+		p.result.warn("%s: %v, skipped", where, err)
+		return
+	}
+	host, path, err := p.split(u.Raw)
+	if err != nil {
+		p.result.warn("%s: %v, skipped", where, err)
+		return
+	}
+	suite := p.result.Suite
+	if suite.Host.Hostname == "" {
+		suite.Host = host
+	} else if host != suite.Host {
+		p.result.warn("%s: sent to %s://%s rather than to the host of the suite, %s://%s", where, host.Protocol, host.Hostname, suite.Host.Protocol, suite.Host.Hostname)
+	}
+
+	scenario := &Scenario{Name: name, Description: description(item.Description)}
+	if scenario.Description == "" {
+		scenario.Description = description(req.Description)
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") && len(segment) > 1 {
+			segments[i] = "{" + segment[1:] + "}"
+			if scenario.PathVariables == nil {
+				scenario.PathVariables = map[string]string{}
+			}
+			scenario.PathVariables[segment[1:]] = ""
+		}
+	}
+	path = strings.Join(segments, "/")
+	for _, v := range u.Variable {
+		if _, ok := scenario.PathVariables[v.Key]; ok {
+			scenario.PathVariables[v.Key] = fmt.Sprint(v.Value)
+		}
+	}
+
+	for _, q := range u.Query {
+		if q.Disabled {
+			continue
+		}
+		if scenario.Query == nil {
+			scenario.Query = map[string]string{}
+		}
+		if _, ok := scenario.Query[q.Key]; ok {
+			p.result.warn("%s: query parameter %s is repeated, only its last value is kept", where, q.Key)
+		}
+		scenario.Query[q.Key] = q.Value
+	}
+	for _, h := range req.Header {
+		if h.Disabled {
+			continue
+		}
+		if scenario.Headers == nil {
+			scenario.Headers = map[string]string{}
+		}
+		scenario.Headers[h.Key] = h.Value
+	}
+
+	if req.Auth != nil && !sameAuth(req.Auth, p.auth) {
+		if req.Auth.Type == "noauth" && p.auth != nil && p.auth.Type != "noauth" {
+			p.result.warn("%s: has no auth, but the auth of the collection is sent with every request", where)
+		}
+		headers, query := p.convertAuth(req.Auth, where)
+		scenario.Headers = merge(scenario.Headers, headers)
+		scenario.Query = merge(scenario.Query, query)
+	}
+
+	p.body(req.Body, scenario, where)
+
+	var status string
+	for _, event := range item.Event {
+		if event.Listen == "test" {
+			status = statusCheck(scriptText(event.Script.Exec))
+		}
+	}
+	if status != "" {
+		scenario.Expect = &Expect{Status: status}
+	}
+	scenario.TODO = append(scenario.TODO, p.scripts(item.Event, where)...)
+
+	p.dynamicVariables(where, u.Raw, path, scenario)
+
+	meta := &Meta{AutomationStatus: "automated", Importance: "medium"}
+	if len(folders) > 0 {
+		meta.Component = strings.Join(folders, "/")
+		tags := make([]string, len(folders))
+		for i, folder := range folders {
+			tags[i] = slug(folder)
+		}
+		meta.Tags = strings.Join(tags, ",")
+	}
+	suite.Add(name, strings.ToUpper(req.Method), path, meta, scenario)
+}
+
+// url decodes the URL of a request, given as a string or as an object.
+func (p *postmanImport) url(raw json.RawMessage) (*postmanURL, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		u := &postmanURL{Raw: s}
+		if i := strings.Index(s, "?"); i >= 0 {
+			values, _ := url.ParseQuery(s[i+1:])
+			keys := make([]string, 0, len(values))
+			for key := range values {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				u.Query = append(u.Query, postmanPair{Key: key, Value: values.Get(key)})
+			}
+		}
+		return u, nil
+	}
+	var u postmanURL
+	if err := json.Unmarshal(raw, &u); err != nil {
+		return nil, fmt.Errorf("invalid URL: %v", err)
+	}
+	if u.Raw == "" {
+		return nil, fmt.Errorf("URL without raw form")
+	}
+	return &u, nil
+}
+
+// split returns the host and the path of a raw URL. The host, and any base path, may come from variables,
+// e.g. {{baseUrl}}/users/:id; the variables of the path itself are kept as {{name}} references.
+func (p *postmanImport) split(raw string) (Host, string, error) {
+	if i := strings.IndexAny(raw, "?#"); i >= 0 {
+		raw = raw[:i]
+	}
+
+	var base, rest string
+	if i := strings.Index(raw, "://"); i >= 0 {
+		end := strings.Index(raw[i+3:], "/")
+		if end < 0 {
+			base, rest = raw, ""
+		} else {
+			base, rest = raw[:i+3+end], raw[i+3+end:]
+		}
+	} else if strings.HasPrefix(raw, "{{") {
+		end := strings.Index(raw, "}}") + 2
+		base, rest = raw[:end], raw[end:]
+		if i := strings.Index(rest, "/"); i > 0 {
+			base, rest = base+rest[:i], rest[i:]
+		}
+	} else {
+		end := strings.Index(raw, "/")
+		if end < 0 {
+			base, rest = raw, ""
+		} else {
+			base, rest = raw[:end], raw[end:]
+		}
+	}
+
+	if p.result.Suite.Host.Hostname == "" && strings.Contains(base, "{{") {
+		p.hostVariable = true
+	}
+	resolved := p.expand(base)
+	if strings.Contains(resolved, "{{") {
+		return Host{}, "", fmt.Errorf("the host %s refers to an undefined variable", base)
+	}
+	host, basePath, err := URL(resolved)
+	if err != nil {
+		return Host{}, "", err
+	}
+	path := strings.TrimSuffix(basePath, "/") + rest
+	if path == "" {
+		path = "/"
+	}
+	return host, path, nil
+}
+
+// warnTODO reports every TODO item of the suite as a warning.
+func (p *postmanImport) warnTODO() {
+	suite := p.result.Suite
+	items := append([]string(nil), suite.TODO...)
+	for _, route := range suite.Routes {
+		items = append(items, route.TODO...)
+		for _, scenario := range route.Scenarios {
+			items = append(items, scenario.TODO...)
+		}
+	}
+	for _, item := range items {
+		p.result.warn("%s", strings.TrimSuffix(strings.SplitN(item, "\n", 2)[0], ":"))
+	}
+}
+
+// expand replaces the {{name}} references to the variables of the collection and the environment.
+func (p *postmanImport) expand(s string) string {
+	for i := 0; i < 10 && strings.Contains(s, "{{"); i++ {
+		s = postmanVariableRef.ReplaceAllStringFunc(s, func(ref string) string {
+			if value, ok := p.variables[ref[2:len(ref)-2]]; ok {
+				return value
+			}
+			return ref
+		})
+	}
+	return s
+}
+
+// body converts the body of a request.
+func (p *postmanImport) body(body *postmanBody, scenario *Scenario, where string) {
+	if body == nil || body.Disabled {
+		return
+	}
+	switch body.Mode {
+	case "", "none":
+	case "raw":
+		scenario.Body = Body(body.Raw)
+	case "urlencoded":
+		values := url.Values{}
+		for _, pair := range body.URLEncoded {
+			if !pair.Disabled {
+				values.Add(pair.Key, pair.Value)
+			}
+		}
+		scenario.Body = Body(values.Encode())
+		if !hasHeader(scenario.Headers, "Content-Type") {
+			scenario.Headers = merge(scenario.Headers, map[string]string{"Content-Type": "application/x-www-form-urlencoded"})
+		}
+	case "graphql":
+		if body.GraphQL == nil {
+			return
+		}
+		query, _ := json.Marshal(body.GraphQL.Query)
+		variables := strings.TrimSpace(body.GraphQL.Variables)
+		if variables == "" {
+			variables = "{}"
+		}
+		scenario.Body = Body(fmt.Sprintf(`{"query": %s, "variables": %s}`, query, variables))
+		if !hasHeader(scenario.Headers, "Content-Type") {
+			scenario.Headers = merge(scenario.Headers, map[string]string{"Content-Type": "application/json"})
+		}
+	default:
+		scenario.TODO = append(scenario.TODO, fmt.Sprintf("%s body of %s not converted, set the body of the scenario", body.Mode, where))
+	}
+}
+
+// convertAuth converts an auth block to headers or query parameters. Credentials are never written to the suite:
+// those given as literals are moved to variables of the environment configuration.
+func (p *postmanImport) convertAuth(auth *postmanAuth, where string) (map[string]string, map[string]string) {
+	switch auth.Type {
+	case "noauth", "":
+		return nil, nil
+	case "bearer":
+		return map[string]string{"Authorization": "Bearer " + p.credential("bearer_token", auth.Params["token"], where, "bearer token")}, nil
+	case "basic":
+		credentials := p.expand(auth.Params["username"] + ":" + auth.Params["password"])
+		if strings.Contains(credentials, "{{") {
+			p.result.warn("%s: basic auth refers to undefined variables, set the Authorization header by hand", where)
+			return nil, nil
+		}
+		encoded := base64.StdEncoding.EncodeToString([]byte(credentials))
+		return map[string]string{"Authorization": "Basic " + p.credential("basic_auth", encoded, where, "basic auth credentials")}, nil
+	case "apikey":
+		key, name := auth.Params["key"], "api_key"
+		if key != "" {
+			name = strings.ReplaceAll(slug(key), "-", "_")
+		}
+		value := p.credential(name, auth.Params["value"], where, "API key "+key)
+		if auth.Params["in"] == "query" {
+			return nil, map[string]string{key: value}
+		}
+		return map[string]string{key: value}, nil
+	}
+	p.result.warn("%s: %s auth not converted, set its credentials as headers by hand", where, auth.Type)
+	return nil, nil
+}
+
+// credential returns a reference to a variable of the environment configuration holding the value, unless the value
+// already is a reference. The variable is named after name, suffixed with a number when another credential holds it,
+// and shared by the auth blocks with the same credential.
+func (p *postmanImport) credential(name, value, where, what string) string {
+	if value == "" || postmanVariableRef.MatchString(value) {
+		return value
+	}
+	for i := 1; ; i++ {
+		candidate := name
+		if i > 1 {
+			candidate = fmt.Sprintf("%s_%d", name, i)
+		}
+		existing, taken := p.variables[candidate]
+		if taken && existing != value {
+			continue
+		}
+		if !taken {
+			p.variables[candidate] = value
+			p.result.warn("%s: %s moved to the %s variable of the environment configuration", where, what, candidate)
+		}
+		return "{{" + candidate + "}}"
+	}
+}
+
+// scripts returns the TODO items of the scripts of a collection, folder or request, with their code.
+func (p *postmanImport) scripts(events []postmanEvent, where string) []string {
+	var todos []string
+	for _, event := range events {
+		code := strings.TrimSpace(scriptText(event.Script.Exec))
+		if code == "" {
+			continue
+		}
+		kind := "test script"
+		hook := "an after hook"
+		if event.Listen == "prerequest" {
+			kind, hook = "pre-request script", "a before hook"
+		}
+		todos = append(todos, fmt.Sprintf("%s of %s not converted, write %s:\n%s", kind, where, hook, code))
+	}
+	return todos
+}
+
+// dynamicVariables warns about the dynamic variables of Postman, e.g. {{$guid}}, which have no equivalent.
+func (p *postmanImport) dynamicVariables(where string, raw, path string, scenario *Scenario) {
+	texts := []string{raw, path}
+	for _, m := range []map[string]string{scenario.Headers, scenario.Query, scenario.PathVariables} {
+		for _, v := range m {
+			texts = append(texts, v)
+		}
+	}
+	if scenario.Body != nil {
+		texts = append(texts, nodeText(scenario.Body))
+	}
+	seen := map[string]bool{}
+	for _, text := range texts {
+		for _, match := range postmanVariableRef.FindAllStringSubmatch(text, -1) {
+			if strings.HasPrefix(match[1], "$") && !seen[match[1]] {
+				seen[match[1]] = true
+				p.result.warn("%s: dynamic variable {{%s}} has no equivalent, set it in a before hook", where, match[1])
+			}
+		}
+	}
+}
+
+// statusCheck returns the status a test script checks with pm.response.to.have.status, if any.
+func statusCheck(script string) string {
+	for _, re := range expectStatus {
+		if match := re.FindStringSubmatch(script); match != nil {
+			return match[1]
+		}
+	}
+	return ""
+}
+
+// scriptText returns the code of a script, given as a list of lines or as a string.
+func scriptText(exec json.RawMessage) string {
+	var lines []string
+	if err := json.Unmarshal(exec, &lines); err == nil {
+		return strings.Join(lines, "\n")
+	}
+	var s string
+	json.Unmarshal(exec, &s)
+	return s
+}
+
+// description returns a description given as a string or as an object with its content.
+func description(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var d struct {
+		Content string `json:"content"`
+	}
+	json.Unmarshal(raw, &d)
+	return d.Content
+}
+
+func sameAuth(a, b *postmanAuth) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Type != b.Type || len(a.Params) != len(b.Params) {
+		return false
+	}
+	for key, value := range a.Params {
+		if b.Params[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+func merge(m, other map[string]string) map[string]string {
+	if len(other) == 0 {
+		return m
+	}
+	if m == nil {
+		m = map[string]string{}
+	}
+	for key, value := range other {
+		m[key] = value
+	}
+	return m
+}
+
+func hasHeader(headers map[string]string, name string) bool {
+	for key := range headers {
+		if strings.EqualFold(key, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeText returns the scalars of a node, joined by spaces.
+func nodeText(node *yaml.Node) string {
+	if node.Kind == yaml.ScalarNode {
+		return node.Value
+	}
+	var parts []string
+	for _, child := range node.Content {
+		parts = append(parts, nodeText(child))
+	}
+	return strings.Join(parts, " ")
+}
+
+/* Example usage -
+
+collection, _ := ioutil.ReadFile("collection.json")
+environment, _ := ioutil.ReadFile("staging.postman_environment.json")
+
+result, err := importer.Postman(collection, environment)
+if err != nil {
+    log.Fatal(err)
+}
+for _, warning := range result.Warnings {
+    fmt.Println("not converted:", warning)
+}
+if _, err := result.Write("suites/users.yaml"); err != nil {
+    log.Fatal(err)
+}
+
+*/
@@ -0,0 +1,167 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/qatoolist/RouTest/internal/parser"
+)
+
+const testCollection = `{
+  "info": {"name": "Shop", "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"},
+  "auth": {"type": "bearer", "bearer": [{"key": "token", "value": "collection-token"}]},
+  "variable": [{"key": "baseUrl", "value": "https://api.example.com"}],
+  "item": [
+    {"name": "Users", "item": [
+      {"name": "Get user", "request": {"method": "GET", "url": "{{baseUrl}}/users/:id?fields=name",
+        "header": [{"key": "Accept", "value": "application/json"}]},
+       "event": [{"listen": "test", "script": {"exec": ["pm.response.to.have.status(200);"]}}]},
+      {"name": "Create user", "request": {"method": "POST", "url": "{{baseUrl}}/users",
+        "body": {"mode": "raw", "raw": "{\"name\": \"bob\"}"},
+        "auth": {"type": "basic", "basic": [{"key": "username", "value": "admin"}, {"key": "password", "value": "{{password}}"}]}}}
+    ]},
+    {"name": "Orders", "item": [
+      {"name": "List orders", "request": {"method": "GET", "url": "{{baseUrl}}/orders",
+        "auth": {"type": "basic", "basic": [{"key": "username", "value": "viewer"}, {"key": "password", "value": "{{password}}"}]}}},
+      {"name": "Export orders", "request": {"method": "GET", "url": "{{baseUrl}}/orders/export",
+        "auth": {"type": "apikey", "apikey": [{"key": "key", "value": "X-Api-Key"}, {"key": "value", "value": "k-123"}, {"key": "in", "value": "header"}]}}},
+      {"name": "Orders feed", "request": {"method": "GET", "url": "{{baseUrl}}/orders/feed",
+        "auth": {"type": "basic", "basic": [{"key": "username", "value": "admin"}, {"key": "password", "value": "{{password}}"}]}}}
+    ]}
+  ]
+}`
+
+const testEnvironment = `{"name": "Staging", "values": [{"key": "password", "value": "s3cr3t", "enabled": true}]}`
+
+// TestPostman checks the conversion of a collection and its environment, credentials included.
+func TestPostman(t *testing.T) {
+	result, err := Postman([]byte(testCollection), []byte(testEnvironment))
+	if err != nil {
+		t.Fatal(err)
+	}
+	suite := result.Suite
+	if suite.Host != (Host{Protocol: "https", Hostname: "api.example.com"}) {
+		t.Errorf("unexpected host %+v", suite.Host)
+	}
+	if suite.Headers["Authorization"] != "Bearer {{bearer_token}}" {
+		t.Errorf("expected the bearer token of the collection to be a variable, got %v", suite.Headers)
+	}
+
+	routes := make(map[string]*Route)
+	for _, route := range suite.Routes {
+		routes[route.Name] = route
+	}
+	tests := []struct {
+		route   string
+		method  string
+		path    string
+		headers map[string]string
+	}{
+		{"get-user", "GET", "/users/{id}", map[string]string{"Accept": "application/json"}},
+		{"create-user", "POST", "/users", map[string]string{"Authorization": "Basic {{basic_auth}}"}},
+		{"list-orders", "GET", "/orders", map[string]string{"Authorization": "Basic {{basic_auth_2}}"}},
+		{"export-orders", "GET", "/orders/export", map[string]string{"X-Api-Key": "{{x_api_key}}"}},
+		{"orders-feed", "GET", "/orders/feed", map[string]string{"Authorization": "Basic {{basic_auth}}"}},
+	}
+	for _, test := range tests {
+		t.Run(test.route, func(t *testing.T) {
+			route, ok := routes[test.route]
+			if !ok {
+				t.Fatalf("route not found among %d routes", len(suite.Routes))
+			}
+			if route.Method != test.method || route.Path != test.path {
+				t.Errorf("expected %s %s, got %s %s", test.method, test.path, route.Method, route.Path)
+			}
+			for key, value := range test.headers {
+				if got := route.Scenarios[0].Headers[key]; got != value {
+					t.Errorf("expected header %s to be %q, got %q", key, value, got)
+				}
+			}
+		})
+	}
+	if expect := routes["get-user"].Scenarios[0].Expect; expect == nil || expect.Status != "200" {
+		t.Errorf("expected the status check of the test script, got %+v", expect)
+	}
+
+	variables, _ := result.Configs["staging"]["variables"].(map[string]interface{})
+	want := map[string]string{
+		"bearer_token": "collection-token",
+		"basic_auth":   "YWRtaW46czNjcjN0",     // admin:s3cr3t
+		"basic_auth_2": "dmlld2VyOnMzY3IzdA==", // viewer:s3cr3t
+		"x_api_key":    "k-123",
+	}
+	for key, value := range want {
+		if variables[key] != value {
+			t.Errorf("expected variable %s to be %q, got %v", key, value, variables[key])
+		}
+	}
+	data, err := suite.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"collection-token", "k-123", "YWRtaW46czNjcjN0"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("expected the credential %s to be left out of the suite", secret)
+		}
+	}
+	if !containsWarning(result.Warnings, "bearer token moved to the bearer_token variable") {
+		t.Errorf("expected a warning about the bearer token, got %v", result.Warnings)
+	}
+}
+
+// TestPostmanParse checks that the imported suite is read back by the parser.
+func TestPostmanParse(t *testing.T) {
+	result, err := Postman([]byte(testCollection), []byte(testEnvironment))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertParses(t, result.Suite)
+}
+
+// assertParses checks that the suite is read back by the parser, routes, scenarios and parameters included,
+// since the importer has its own copy of the types of suites.
+func assertParses(t *testing.T, suite *Suite) {
+	t.Helper()
+	data, err := suite.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := parser.ParseSuite(data)
+	if err != nil {
+		t.Fatalf("%v\n%s", err, data)
+	}
+	if parsed.Host.Hostname != suite.Host.Hostname || parsed.Host.Protocol != suite.Host.Protocol || parsed.Host.Port != suite.Host.Port {
+		t.Errorf("expected host %+v, got %+v", suite.Host, parsed.Host)
+	}
+	if len(parsed.Routes) != len(suite.Routes) {
+		t.Fatalf("expected %d routes, got %d", len(suite.Routes), len(parsed.Routes))
+	}
+	for i, route := range suite.Routes {
+		got := parsed.Routes[i]
+		if got.Name != route.Name || got.Method != route.Method || got.Path != route.Path || len(got.Scenarios) != len(route.Scenarios) {
+			t.Errorf("expected route %s %s %s with %d scenarios, got %s %s %s with %d", route.Name, route.Method, route.Path, len(route.Scenarios), got.Name, got.Method, got.Path, len(got.Scenarios))
+			continue
+		}
+		for j, scenario := range route.Scenarios {
+			s := got.Scenarios[j]
+			if s.Name != scenario.Name || len(s.Parameters.Headers) != len(scenario.Headers) || len(s.Parameters.Query) != len(scenario.Query) {
+				t.Errorf("route %s: expected scenario %+v, got %+v", route.Name, scenario, s)
+			}
+			if scenario.Expect != nil && s.Expect.Status != scenario.Expect.Status {
+				t.Errorf("route %s: expected status %s, got %s", route.Name, scenario.Expect.Status, s.Expect.Status)
+			}
+		}
+	}
+	if _, err := parsed.Build(); err != nil {
+		t.Errorf("expected the suite to build, got %v", err)
+	}
+}
+
+func containsWarning(warnings []string, s string) bool {
+	for _, w := range warnings {
+		if strings.Contains(w, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,176 @@
+package importer
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultDeniedHeaders are the request headers left out of imported scenarios unless allowed explicitly:
+// those set by browsers and HTTP clients rather than by the application, and the credentials,
+// which do not belong in a suite. A trailing * matches any suffix.
+var DefaultDeniedHeaders = []string{
+	"Accept-Encoding", "Accept-Language", "Cache-Control", "Connection", "Content-Length", "Cookie", "Authorization",
+	"DNT", "Host", "If-Modified-Since", "If-None-Match", "Keep-Alive", "Origin", "Pragma", "Priority", "Proxy-*", "Referer",
+	"Sec-*", "TE", "Upgrade-Insecure-Requests", "User-Agent",
+}
+
+// HeaderFilter selects the request headers of imported scenarios.
+type HeaderFilter struct {
+	// Allow keeps only these headers when it is not empty, whether they are denied or not.
+	Allow []string
+
+	// Deny leaves these headers out. Nil defaults to DefaultDeniedHeaders.
+	Deny []string
+}
+
+// Keep reports whether a header is kept. HTTP/2 pseudo-headers, e.g. :authority, are never kept.
+func (f HeaderFilter) Keep(name string) bool {
+	if strings.HasPrefix(name, ":") {
+		return false
+	}
+	if len(f.Allow) > 0 {
+		return matchHeader(f.Allow, name)
+	}
+	deny := f.Deny
+	if deny == nil {
+		deny = DefaultDeniedHeaders
+	}
+	return !matchHeader(deny, name)
+}
+
+func matchHeader(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if strings.HasSuffix(pattern, "*") {
+			if len(name) >= len(pattern)-1 && strings.EqualFold(name[:len(pattern)-1], pattern[:len(pattern)-1]) {
+				return true
+			}
+		} else if strings.EqualFold(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Request is a captured request, e.g. an entry of a HAR file or a cURL command, and the response it received if known.
+type Request struct {
+	// Name is the name of the scenario of the request.
+	Name    string
+	Method  string
+	URL     string
+	Headers [][2]string
+	Body    string
+
+	// Response is the response received, or nil if it is not known.
+	Response *RecordedResponse
+}
+
+// RecordedResponse is the response received by a captured request.
+type RecordedResponse struct {
+	Status int
+	Body   []byte
+}
+
+// RequestOptions configures the conversion of captured requests.
+type RequestOptions struct {
+	Headers HeaderFilter
+
+	// Snapshot asserts that the body of the responses stays the same as the recorded one,
+	// written as the golden file of the scenario.
+	Snapshot bool
+}
+
+// FromRequests converts captured requests to a suite. The host of the suite is the one of the first request,
+// and the requests sent to other hosts are skipped. Requests sharing a method and a path become the scenarios
+// of the same route. Recorded responses become the expected status of the scenarios and, with Snapshot,
+// the golden files of their snapshot assertions.
+func FromRequests(requests []Request, opts RequestOptions) (*Result, error) {
+	result := &Result{Suite: &Suite{}}
+	suite := result.Suite
+
+	skipped := map[string]int{}
+	for _, req := range requests {
+		host, path, err := URL(strings.SplitN(req.URL, "?", 2)[0])
+		if err != nil {
+			result.warn("%s: %v, skipped", req.Name, err)
+			continue
+		}
+		if suite.Host.Hostname == "" {
+			suite.Host = host
+		} else if host != suite.Host {
+			skipped[fmt.Sprintf("%s://%s", host.Protocol, hostPort(host))]++
+			continue
+		}
+
+		scenario := &Scenario{Name: req.Name}
+		if u, err := url.Parse(req.URL); err == nil && u.RawQuery != "" {
+			query, err := url.ParseQuery(u.RawQuery)
+			if err != nil {
+				result.warn("%s: invalid query string, left out: %v", req.Name, err)
+			}
+			for key, values := range query {
+				if scenario.Query == nil {
+					scenario.Query = map[string]string{}
+				}
+				scenario.Query[key] = values[len(values)-1]
+				if len(values) > 1 {
+					result.warn("%s: query parameter %s is repeated, only its last value is kept", req.Name, key)
+				}
+			}
+		}
+		for _, header := range req.Headers {
+			if !opts.Headers.Keep(header[0]) {
+				continue
+			}
+			if scenario.Headers == nil {
+				scenario.Headers = map[string]string{}
+			}
+			scenario.Headers[canonical(header[0])] = header[1]
+		}
+		scenario.Body = Body(req.Body)
+
+		if resp := req.Response; resp != nil {
+			scenario.Expect = &Expect{Status: strconv.Itoa(resp.Status)}
+			if opts.Snapshot && len(resp.Body) > 0 {
+				scenario.Snapshot = true
+				scenario.Baseline = resp.Body
+			}
+		}
+
+		suite.Add(strings.ToLower(req.Method)+" "+path, strings.ToUpper(req.Method), path, nil, scenario)
+	}
+
+	if len(suite.Routes) == 0 {
+		return nil, fmt.Errorf("no request to import")
+	}
+
+	hosts := make([]string, 0, len(skipped))
+	for host := range skipped {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	for _, host := range hosts {
+		result.warn("%d requests to %s skipped, a suite has a single host", skipped[host], host)
+	}
+	return result, nil
+}
+
+// canonical returns the canonical form of a header name, e.g. Content-Type for content-type as sent by HTTP/2 clients.
+func canonical(name string) string {
+	parts := strings.Split(strings.ToLower(name), "-")
+	for i, part := range parts {
+		if part != "" {
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+func hostPort(h Host) string {
+	if h.Port == 0 {
+		return h.Hostname
+	}
+	return fmt.Sprintf("%s:%d", h.Hostname, h.Port)
+}
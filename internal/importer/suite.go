@@ -0,0 +1,324 @@
+// Package importer converts the assets of other API tools, e.g. Postman collections, to declarative suites.
+// Everything that could not be converted is reported as a warning, and marked with a TODO comment in the suite.
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/qatoolist/RouTest/internal/snapshot"
+	"gopkg.in/yaml.v3"
+)
+
+// todo marks the comments of the parts of a suite that need to be completed by hand.
+const todo = "TODO(import): "
+
+// Suite is an imported suite, written in the format read by the parser.
+type Suite struct {
+	Host    Host              `yaml:"host"`
+	Headers map[string]string `yaml:"headers,omitempty"`
+	Query   map[string]string `yaml:"query,omitempty"`
+	Routes  []*Route          `yaml:"routes"`
+
+	// Comment is written above the suite.
+	Comment string `yaml:"-"`
+
+	// TODO lists what could not be converted at the level of the whole suite.
+	TODO []string `yaml:"-"`
+}
+
+// Host is the server of the suite.
+type Host struct {
+	Protocol string `yaml:"protocol"`
+	Hostname string `yaml:"hostname"`
+	Port     int    `yaml:"port,omitempty"`
+}
+
+// Route is an imported route, the scenarios sharing its method and path.
+type Route struct {
+	Name        string      `yaml:"name"`
+	Description string      `yaml:"description,omitempty"`
+	Method      string      `yaml:"method"`
+	Path        string      `yaml:"path"`
+	Meta        *Meta       `yaml:"meta,omitempty"`
+	Scenarios   []*Scenario `yaml:"scenarios"`
+
+	// TODO lists what could not be converted at the level of the route.
+	TODO []string `yaml:"-"`
+}
+
+// Scenario is an imported request.
+type Scenario struct {
+	Name          string            `yaml:"name"`
+	Description   string            `yaml:"description,omitempty"`
+	Headers       map[string]string `yaml:"headers,omitempty"`
+	Query         map[string]string `yaml:"query,omitempty"`
+	PathVariables map[string]string `yaml:"path_variables,omitempty"`
+	Body          *yaml.Node        `yaml:"body,omitempty"`
+	Expect        *Expect           `yaml:"expect,omitempty"`
+	Snapshot      bool              `yaml:"snapshot,omitempty"`
+
+	// Baseline is the recorded response body written as the golden file of the snapshot assertion.
+	Baseline []byte `yaml:"-"`
+
+	// TODO lists what could not be converted at the level of the scenario.
+	TODO []string `yaml:"-"`
+}
+
+// Meta is the metadata of an imported route.
+type Meta struct {
+	AutomationStatus string `yaml:"automation_status"`
+	Importance       string `yaml:"importance"`
+	Component        string `yaml:"component,omitempty"`
+	Tags             string `yaml:"tags,omitempty"`
+}
+
+// Expect is the expectation of an imported scenario.
+type Expect struct {
+	Status string `yaml:"status,omitempty"`
+}
+
+// Result is the outcome of an import: the suite, the environment configurations and everything not converted.
+type Result struct {
+	Suite *Suite
+
+	// Configs are the environment configurations, by environment name, written to the config directory of the suite.
+	Configs map[string]map[string]interface{}
+
+	// Warnings describe what could not be converted, or was converted approximately.
+	Warnings []string
+}
+
+func (r *Result) warn(format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// Add adds a request to the suite as a scenario of the route with the same method and path,
+// creating the route, named after the request, if there is none.
+func (s *Suite) Add(name, method, path string, meta *Meta, scenario *Scenario) *Route {
+	var route *Route
+	for _, r := range s.Routes {
+		if r.Method == method && r.Path == path {
+			route = r
+			break
+		}
+	}
+	if route == nil {
+		route = &Route{Name: s.uniqueRoute(slug(name)), Method: method, Path: path, Meta: meta}
+		s.Routes = append(s.Routes, route)
+	}
+
+	names := make(map[string]bool, len(route.Scenarios))
+	for _, sc := range route.Scenarios {
+		names[sc.Name] = true
+	}
+	scenario.Name = unique(scenario.Name, names)
+	route.Scenarios = append(route.Scenarios, scenario)
+	return route
+}
+
+func (s *Suite) uniqueRoute(name string) string {
+	names := make(map[string]bool, len(s.Routes))
+	for _, r := range s.Routes {
+		names[r.Name] = true
+	}
+	return unique(name, names)
+}
+
+// Marshal returns the suite in YAML, the parts that could not be converted marked with TODO comments.
+func (s *Suite) Marshal() ([]byte, error) {
+	var doc yaml.Node
+	if err := doc.Encode(s); err != nil {
+		return nil, err
+	}
+	doc.HeadComment = comment(s.Comment, s.TODO)
+
+	routes := value(&doc, "routes")
+	for i, route := range s.Routes {
+		node := routes.Content[i]
+		node.HeadComment = comment("", route.TODO)
+		scenarios := value(node, "scenarios")
+		for j, scenario := range route.Scenarios {
+			scenarios.Content[j].HeadComment = comment("", scenario.TODO)
+		}
+	}
+	return yaml.Marshal(&doc)
+}
+
+// Write writes the suite to the given path, the environment configurations to the config directory next to it
+// and the recorded baselines to its snapshots directory. It returns the paths of the files written.
+func (r *Result) Write(path string) ([]string, error) {
+	data, err := r.Suite.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(path, data, 0o644); err != nil {
+		return nil, err
+	}
+	written := []string{path}
+
+	envs := make([]string, 0, len(r.Configs))
+	for env := range r.Configs {
+		envs = append(envs, env)
+	}
+	sort.Strings(envs)
+	for _, env := range envs {
+		data, err := yaml.Marshal(r.Configs[env])
+		if err != nil {
+			return written, err
+		}
+		configPath := filepath.Join(filepath.Dir(path), "config", env+".yaml")
+		if err := os.MkdirAll(filepath.Dir(configPath), 0o755); err != nil {
+			return written, err
+		}
+		if err := ioutil.WriteFile(configPath, data, 0o644); err != nil {
+			return written, err
+		}
+		written = append(written, configPath)
+	}
+
+	for _, route := range r.Suite.Routes {
+		for _, scenario := range route.Scenarios {
+			if !scenario.Snapshot || scenario.Baseline == nil {
+				continue
+			}
+			s, err := snapshot.New(snapshot.PathFor(filepath.Join(filepath.Dir(path), "__snapshots__"), route.Name, scenario.Name), nil, true)
+			if err != nil {
+				return written, err
+			}
+			if _, err := s.Assert(scenario.Baseline); err != nil {
+				return written, err
+			}
+			written = append(written, s.Path())
+		}
+	}
+	return written, nil
+}
+
+// value returns the value of a key of the mapping of a node, or of the document it is the root of.
+func value(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind == yaml.DocumentNode {
+		node = node.Content[0]
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return &yaml.Node{}
+}
+
+// comment returns a comment made of a text and of TODO items.
+func comment(text string, todos []string) string {
+	lines := []string{}
+	if text != "" {
+		lines = append(lines, strings.Split(text, "\n")...)
+	}
+	for _, item := range todos {
+		itemLines := strings.Split(strings.TrimRight(item, "\n"), "\n")
+		lines = append(lines, todo+itemLines[0])
+		for _, line := range itemLines[1:] {
+			lines = append(lines, "  "+line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Body returns the node of a request body: JSON bodies are written as YAML, with their order preserved,
+// and other bodies as strings.
+func Body(body string) *yaml.Node {
+	if strings.TrimSpace(body) == "" {
+		return nil
+	}
+	trimmed := strings.TrimSpace(body)
+	if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		var node yaml.Node
+		if err := yaml.Unmarshal([]byte(trimmed), &node); err == nil && len(node.Content) == 1 && json.Valid([]byte(trimmed)) {
+			clearStyle(node.Content[0])
+			return node.Content[0]
+		}
+	}
+	node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: body}
+	if strings.Contains(body, "\n") {
+		node.Style = yaml.LiteralStyle
+	} else {
+		// A plain "generate" would generate the body from the request schema.
+		node.Style = yaml.DoubleQuotedStyle
+	}
+	return node
+}
+
+// clearStyle resets the flow style and the quotes of JSON, so that the body is written as block YAML.
+// Strings stay strings: they are only quoted again where YAML requires it.
+func clearStyle(node *yaml.Node) {
+	node.Style &^= yaml.FlowStyle | yaml.DoubleQuotedStyle
+	for _, child := range node.Content {
+		clearStyle(child)
+	}
+}
+
+// URL splits a URL into the host of the suite and the path of a route, the base path of the server included.
+func URL(raw string) (Host, string, error) {
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return Host{}, "", err
+	}
+	host := Host{Protocol: u.Scheme, Hostname: u.Hostname()}
+	if port := u.Port(); port != "" {
+		host.Port, _ = strconv.Atoi(port)
+	}
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	return host, path, nil
+}
+
+// ConfigHost returns the host as written in an environment configuration, where the port is required.
+func ConfigHost(h Host) map[string]interface{} {
+	port := h.Port
+	if port == 0 {
+		port = 80
+		if h.Protocol == "https" {
+			port = 443
+		}
+	}
+	return map[string]interface{}{"protocol": h.Protocol, "hostname": h.Hostname, "port": port}
+}
+
+var notSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slug returns a route name made of the lowercase words of a name, e.g. get-user for "Get User".
+func slug(name string) string {
+	s := strings.Trim(notSlug.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if s == "" {
+		return "request"
+	}
+	return s
+}
+
+// unique returns the name, suffixed with a number if it is already taken.
+func unique(name string, taken map[string]bool) string {
+	if !taken[name] {
+		return name
+	}
+	for i := 2; ; i++ {
+		if candidate := fmt.Sprintf("%s-%d", name, i); !taken[candidate] {
+			return candidate
+		}
+	}
+}
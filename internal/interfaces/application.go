@@ -1,5 +1,11 @@
 package interfaces
 
+import (
+	"context"
+	"log"
+	"net/http"
+)
+
 type Application interface {
 	GetRouteByName(name string) (Route, bool)
 	AddRoute(name string, route *Route) Route
@@ -12,4 +18,15 @@ type Application interface {
 	GetApplicationHooksRegistry() HooksRegistry
 	GetMeta() Meta
 	NewRoute(info Info, meta string) Route
+	GetHost() Host
+	GetRetryPolicy() RetryPolicy
+	SetRetryPolicy(policy RetryPolicy)
+	Run(ctx context.Context) Report
+	GetVariables() Variables
+	GetLogger() *log.Logger
+	SetLogger(logger *log.Logger)
+	GetHTTPClient() *http.Client
+	SetHTTPClient(client *http.Client)
+	GetSpecValidator() SpecValidator
+	SetSpecValidator(validator SpecValidator)
 }
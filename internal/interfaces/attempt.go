@@ -0,0 +1,15 @@
+package interfaces
+
+import "time"
+
+// Attempt records a single try of sending a request, including the ones that were retried.
+type Attempt interface {
+	Number() int
+	StatusCode() int
+	Status() string
+	Body() []byte
+	Err() error
+	Duration() time.Duration
+	Wait() time.Duration
+	Timing() Timing
+}
@@ -8,5 +8,6 @@ type Config interface {
 	Get(keys ...string) (interface{}, error)
 	Set(keys []string, value interface{})
 	GetHost() (Host, error)
+	GetRetryPolicy() (RetryPolicy, error)
 	CopyFromTemp(cnf *loaders.Config) Config
 }
@@ -0,0 +1,16 @@
+package interfaces
+
+// Dataset is a table of rows a data-driven scenario is executed with, once per row.
+type Dataset interface {
+	// Len returns the number of rows.
+	Len() int
+
+	// Row returns the values of the row at the given index, by column name.
+	Row(i int) map[string]string
+
+	// RowName returns the name of the test case of the row at the given index.
+	RowName(i int) string
+
+	// RowTags returns the comma-separated tags of the row at the given index.
+	RowTags(i int) string
+}
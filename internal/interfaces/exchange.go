@@ -0,0 +1,7 @@
+package interfaces
+
+// Exchange performs the execution of a scenario over a protocol built on an HTTP request, e.g. WebSocket,
+// instead of sending the request and reading a single response. It receives the hook context holding
+// the request built for the scenario, once the Request Hooks ran, and returns the Response seen by
+// the Response Hooks. A Response returned together with an error is still stored on the scenario.
+type Exchange func(hc HookContext) (Response, error)
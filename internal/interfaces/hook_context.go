@@ -0,0 +1,51 @@
+package interfaces
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+)
+
+// HookContext is the execution context given to the Request and Response Hooks of a scenario.
+// It is a context.Context, cancelled when the scenario or the run times out or is interrupted.
+type HookContext interface {
+	context.Context
+
+	// Scenario returns the scenario being executed.
+	Scenario() Scenario
+
+	// Meta returns the effective meta of the scenario, merged from the application, route and scenario metas.
+	Meta() Meta
+
+	// Request returns the outgoing HTTP request. Request Hooks may modify it in place.
+	Request() *http.Request
+
+	// SetRequest replaces the outgoing HTTP request. It has no effect once the request was sent.
+	SetRequest(req *http.Request)
+
+	// Response returns the received response, or nil in the Request Hooks.
+	Response() Response
+
+	// SetResponse replaces the received response seen by the following hooks and stored on the scenario.
+	SetResponse(resp Response)
+
+	// StartTime returns the time the execution of the scenario started.
+	StartTime() time.Time
+
+	// Elapsed returns the time since the execution of the scenario started.
+	Elapsed() time.Duration
+
+	// ResponseTime returns the time between sending the request and receiving the response, including retries.
+	ResponseTime() time.Duration
+
+	// Variables returns the store of values captured during the run.
+	Variables() Variables
+
+	// Expand replaces the {{name}} references in s with the values of the data row of the scenario
+	// or, failing that, with the captured variables. Unknown references are left unchanged.
+	Expand(s string) string
+
+	// Logger returns a logger whose lines are prefixed with the route and scenario names.
+	Logger() *log.Logger
+}
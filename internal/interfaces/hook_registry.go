@@ -1,8 +1,36 @@
 package interfaces
 
+import "context"
+
 type HooksRegistry interface {
 	RegisterBeforeHook(hook BeforeHook)
 	RegisterAfterHook(hook AfterHook)
-	RunBeforeHooks(route Route) (Route, error)
-	RunAfterHooks(resp Response) (Response, error)
+	RunBeforeHooks(ctx context.Context, route Route) (Route, error)
+	RunAfterHooks(ctx context.Context, resp Response) (Response, error)
+
+	// RegisterRequestHook registers a hook run on the outgoing request of every scenario in the scope of the registry.
+	RegisterRequestHook(hook ContextHook)
+	// RegisterResponseHook registers a hook run on the response of every scenario in the scope of the registry.
+	RegisterResponseHook(hook ContextHook)
+	RunRequestHooks(hc HookContext) error
+	RunResponseHooks(hc HookContext) error
+
+	// RegisterBeforeAllHook registers a hook run once before the scenarios in the scope of the registry.
+	RegisterBeforeAllHook(hook LifecycleHook)
+	// RegisterAfterAllHook registers a hook run once after the scenarios in the scope of the registry,
+	// even when a BeforeAll hook or a scenario failed or panicked.
+	RegisterAfterAllHook(hook LifecycleHook)
+	// RegisterBeforeEachHook registers a hook run before every scenario in the scope of the registry.
+	RegisterBeforeEachHook(hook EachHook)
+	// RegisterAfterEachHook registers a hook run after every scenario in the scope of the registry,
+	// even when a BeforeEach hook or the scenario failed or panicked.
+	RegisterAfterEachHook(hook EachHook)
+	// RegisterAroundHook registers a hook wrapping the execution of every scenario in the scope of the registry.
+	RegisterAroundHook(hook AroundHook)
+
+	RunBeforeAllHooks(ctx context.Context) error
+	RunAfterAllHooks(ctx context.Context) error
+	RunBeforeEachHooks(ctx context.Context, scenario Scenario) error
+	RunAfterEachHooks(ctx context.Context, scenario Scenario) error
+	RunAroundHooks(ctx context.Context, scenario Scenario, next func(context.Context) error) error
 }
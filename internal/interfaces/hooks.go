@@ -1,7 +1,28 @@
 package interfaces
 
-// BeforeHook is a function that takes a Route object as argument and returns a modified Route object with or without error.
-type BeforeHook func(Route) (Route, error)
+import "context"
 
-// AfterHook is a function type that takes a Response pointer as argument and returns a Response pointer and an error.
-type AfterHook func(Response) (Response, error)
+// BeforeHook is a function that takes the context of the run and a Route object as arguments and returns a modified Route object with or without error.
+// The context is cancelled when the scenario or the run times out or is interrupted.
+type BeforeHook func(context.Context, Route) (Route, error)
+
+// AfterHook is a function type that takes the context of the run and a Response as arguments and returns a Response and an error.
+type AfterHook func(context.Context, Response) (Response, error)
+
+// ContextHook is a function that receives the full execution context of a scenario.
+// Request Hooks run on the outgoing request once it is built and may modify it;
+// Response Hooks run once the response is received.
+type ContextHook func(HookContext) error
+
+// LifecycleHook is a function that sets up or tears down state shared by every scenario in its scope,
+// e.g. creating a test tenant before the suite and deleting it afterwards.
+// It is used for the BeforeAll and AfterAll hooks.
+type LifecycleHook func(context.Context) error
+
+// EachHook is a function that sets up or tears down state for a single scenario.
+// It is used for the BeforeEach and AfterEach hooks.
+type EachHook func(context.Context, Scenario) error
+
+// AroundHook is a function that wraps the execution of a scenario.
+// It must call next to execute the scenario and return its error, or return an error without calling next to skip it.
+type AroundHook func(ctx context.Context, scenario Scenario, next func(context.Context) error) error
@@ -0,0 +1,15 @@
+package interfaces
+
+import "time"
+
+// Condition is a function that takes the Response of a poll and returns an error while the expected state is not reached yet.
+type Condition func(Response) error
+
+// PollPolicy defines how often and for how long a scenario is re-sent until its Condition is met.
+type PollPolicy interface {
+	// GetTimeout returns the maximum time spent polling.
+	GetTimeout() time.Duration
+
+	// NextInterval returns the delay before the next poll, poll being the number of the poll that just finished.
+	NextInterval(poll int) time.Duration
+}
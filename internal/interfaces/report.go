@@ -0,0 +1,30 @@
+package interfaces
+
+import (
+	"net/http"
+	"time"
+)
+
+// ScenarioResult is the outcome of executing a single scenario.
+type ScenarioResult interface {
+	RouteName() string
+	ScenarioName() string
+	Request() *http.Request
+	Response() Response
+	Err() error
+	Duration() time.Duration
+	Passed() bool
+}
+
+// Report collects the results of all the scenarios executed in a run.
+type Report interface {
+	AddResult(result ScenarioResult)
+	Results() []ScenarioResult
+	Passed() int
+	Failed() int
+	Duration() time.Duration
+	Interrupt(err error)
+	Interrupted() error
+	AddError(err error)
+	Errors() []error
+}
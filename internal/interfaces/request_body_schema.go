@@ -2,4 +2,10 @@ package interfaces
 
 type RequestBodySchema interface {
 	Validate(interface{}) error
+
+	// Example returns a value matching the schema.
+	Example() (interface{}, error)
+
+	// Generate returns a realistic value matching the schema, the same for the same seed.
+	Generate(seed int64) (interface{}, error)
 }
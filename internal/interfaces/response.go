@@ -1,10 +1,19 @@
 package interfaces
 
+import "time"
+
 type Response interface {
 	String() string
+	GetStatusCode() int
 	Bytes() []byte
 	HeaderValue(key string) (string, error)
+	GetHeaders() []Parameter
 	ContentType() (string, error)
 	IsSuccess() bool
 	ValidateBody(schema ResponseBodySchema) error
+	GetAttempts() []Attempt
+	GetPolls() int
+	GetPollDuration() time.Duration
+	GetTiming() Timing
+	GetSpecDrift() []string
 }
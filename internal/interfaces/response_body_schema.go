@@ -2,4 +2,6 @@ package interfaces
 
 type ResponseBodySchema interface {
 	Validate(interface{}) error
+	Example() (interface{}, error)
+	Generate(seed int64) (interface{}, error)
 }
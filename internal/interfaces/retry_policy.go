@@ -0,0 +1,18 @@
+package interfaces
+
+import (
+	"net/http"
+	"time"
+)
+
+// RetryPolicy decides whether a failed attempt should be retried and how long to wait before the next one.
+type RetryPolicy interface {
+	// MaxAttempts returns the maximum number of attempts, including the first one.
+	MaxAttempts() int
+
+	// ShouldRetry reports whether the attempt that produced resp or err should be retried for the given method.
+	ShouldRetry(method string, resp *http.Response, err error) bool
+
+	// Backoff returns the delay before the next attempt, attempt being the number of the attempt that just failed.
+	Backoff(attempt int, resp *http.Response) time.Duration
+}
@@ -1,13 +1,24 @@
 package interfaces
 
-import "net/http"
+import (
+	"context"
+	"net/http"
+)
 
 // Route represents a custom HTTP request with additional fields.
 type Route interface {
-	Send() (*http.Response, error)
+	Send(ctx context.Context) (*http.Response, error)
 	SetReqBodySchema(schema string) error
 	SetResBodySchema(schema string) error
 	GetName() string
+	GetInfo() Info
+	GetMeta() Meta
+	GetBody() []byte
+	SetBody(body []byte)
+	NewRequest(ctx context.Context, body []byte) (*http.Request, error)
+	NewScenario(info Info, meta string) Scenario
+	GetRetryPolicy() RetryPolicy
+	SetRetryPolicy(policy RetryPolicy)
 	GetParentApplication() Application
 	GetRouteParametersRegistry() ParametersRegistry
 	GetRouteHooksRegistry() HooksRegistry
@@ -8,3 +8,11 @@ type RouteRegistry interface {
 	Length() int
 	GetRegistry() map[string]Route
 }
+
+// OrderedRouteRegistry is a RouteRegistry that remembers the order in which its routes were added.
+type OrderedRouteRegistry interface {
+	RouteRegistry
+
+	// GetRoutes returns the routes in the order in which they were added.
+	GetRoutes() []Route
+}
@@ -1,6 +1,14 @@
 package interfaces
 
+import (
+	"net/http"
+	"time"
+)
+
 type Scenario interface {
+	// GetName returns the name of the scenario.
+	GetName() string
+
 	// GetParentRoute returns the reference to the parent route.
 	GetParentRoute() Route
 
@@ -30,6 +38,58 @@ type Scenario interface {
 	// The Order of After Hooks Execution is -  AfterApplicationHooks, AfterRouteHooks, AfterScenarioHooks
 	GetScenarioHooksRegistry() HooksRegistry
 
+	// GetRequest returns the request of the last execution of the scenario, as resolved before the Request Hooks ran,
+	// or nil if it was not built.
+	GetRequest() *http.Request
+
+	// SetRequest sets the request of the last execution of the scenario.
+	SetRequest(req *http.Request)
+
 	// GetResponse returns the HTTP Response received after sending the request for this scenario
 	GetResponse() Response
+
+	// SetResponse sets the HTTP Response received after sending the request for this scenario
+	SetResponse(resp Response)
+
+	// GetBody returns the request body of the scenario, or nil to send the body of the parent route.
+	GetBody() []byte
+
+	// SetBody sets the request body of the scenario.
+	SetBody(body []byte)
+
+	// GetRetryPolicy returns the retry policy defined at scenario level, or nil if none is set.
+	GetRetryPolicy() RetryPolicy
+
+	// SetRetryPolicy sets the retry policy for this scenario, overriding the route and application ones.
+	SetRetryPolicy(policy RetryPolicy)
+
+	// GetTimeout returns the deadline of a single execution of the scenario, or zero if it has none.
+	GetTimeout() time.Duration
+
+	// SetTimeout sets the deadline of a single execution of the scenario, including hooks, retries and polls.
+	SetTimeout(timeout time.Duration)
+
+	// GetDataset returns the dataset of a data-driven scenario, or nil if the scenario is executed once.
+	GetDataset() Dataset
+
+	// SetDataset makes the scenario data-driven: it is executed once per row of the dataset,
+	// and every execution is reported as its own test case.
+	SetDataset(dataset Dataset)
+
+	// GetDataRow returns the row of the dataset the scenario is executed with, or nil.
+	GetDataRow() map[string]string
+
+	// GetWaitUntil returns the condition the scenario polls for and its poll policy, or nil if the scenario is sent once.
+	GetWaitUntil() (Condition, PollPolicy)
+
+	// SetWaitUntil makes the scenario re-send its request until the condition is met or the poll policy times out.
+	// The Before Hooks run once before the first poll and the After Hooks run once on the last response.
+	SetWaitUntil(condition Condition, policy PollPolicy)
+
+	// GetExchange returns the exchange performing the scenario, or nil if its request is sent over plain HTTP.
+	GetExchange() Exchange
+
+	// SetExchange makes the scenario perform the exchange instead of sending its request.
+	// A scenario with an exchange is neither retried nor polled.
+	SetExchange(exchange Exchange)
 }
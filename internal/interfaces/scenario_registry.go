@@ -1,6 +1,9 @@
 package interfaces
 
-import "net/http"
+import (
+	"context"
+	"net/http"
+)
 
 type ScenarioRegistry interface {
 	// AddScenario adds a scenario to the registry.
@@ -10,10 +13,19 @@ type ScenarioRegistry interface {
 	ExportToRequest(req *http.Request, scenario Scenario) (*http.Request, error)
 
 	// RunBeforeHooks executes the Before Hooks of all the scenarios in the registry in the order defined in the comment of Scenario struct.
-	RunBeforeHooks(scenario Scenario) (Route, error)
+	RunBeforeHooks(ctx context.Context, scenario Scenario) (Route, error)
 
 	// RunAfterHooks executes the After Hooks of all the scenarios in the registry in the order defined in the comment of Scenario struct.
-	RunAfterHooks(scenario Scenario) (Response, error)
+	RunAfterHooks(ctx context.Context, scenario Scenario) (Response, error)
+
+	// Execute runs the Before Hooks, sends the request of the scenario with its effective retry policy,
+	// stores the received response on the scenario and runs the After Hooks.
+	// The context bounds the whole execution, together with the timeout of the scenario if one is set.
+	Execute(ctx context.Context, scenario Scenario) (Response, error)
+
+	// Run executes every scenario in the registry and adds their results to the report.
+	// It stops starting new scenarios once the context is done.
+	Run(ctx context.Context, report Report)
 
 	// GetScenarios returns a pointer to the scenarios slice registered in the registry.
 	GetScenarios() *[]Scenario
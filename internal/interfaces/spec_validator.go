@@ -0,0 +1,10 @@
+package interfaces
+
+import "net/http"
+
+// SpecValidator checks the responses of the scenarios against the specification of the API, e.g. an OpenAPI document.
+// The differences it finds are spec drift: they are reported apart from the functional failures of the scenarios.
+type SpecValidator interface {
+	// Validate returns the spec drift of the response to the request, one message per difference.
+	Validate(req *http.Request, resp Response) []string
+}
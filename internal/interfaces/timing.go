@@ -0,0 +1,16 @@
+package interfaces
+
+import "time"
+
+// Timing is the breakdown of the time spent sending a request and receiving its response.
+// The phases that did not happen, e.g. DNS and connect on a reused connection, are zero.
+type Timing interface {
+	DNS() time.Duration
+	Connect() time.Duration
+	TLSHandshake() time.Duration
+	TimeToFirstByte() time.Duration
+	Total() time.Duration
+	BytesSent() int64
+	BytesReceived() int64
+	ConnectionReused() bool
+}
@@ -0,0 +1,9 @@
+package interfaces
+
+// Variables is a store of values captured while the scenarios run, e.g. an id returned by one scenario
+// and sent by the following ones.
+type Variables interface {
+	Get(key string) (string, bool)
+	Set(key string, value string)
+	All() map[string]string
+}
@@ -0,0 +1,184 @@
+// Package jsondiff compares decoded JSON documents structurally and describes their differences
+// with the JSONPath of every differing value.
+package jsondiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// Difference is a value that differs between the expected and the actual document.
+type Difference struct {
+	// Path is the JSONPath of the value, e.g. $.items[2].name.
+	Path string
+
+	// Expected and Actual are the differing values. Missing is set when the actual document lacks the value,
+	// Unexpected when the expected document lacks it.
+	Expected   interface{}
+	Actual     interface{}
+	Missing    bool
+	Unexpected bool
+}
+
+// String describes the difference on a single line.
+func (d Difference) String() string {
+	switch {
+	case d.Missing:
+		return fmt.Sprintf("%s: missing, expected %s", d.Path, FormatValue(d.Expected))
+	case d.Unexpected:
+		return fmt.Sprintf("%s: unexpected %s", d.Path, FormatValue(d.Actual))
+	}
+	return fmt.Sprintf("%s: expected %s, got %s", d.Path, FormatValue(d.Expected), FormatValue(d.Actual))
+}
+
+// Compare returns the differences between the expected and the actual document, sorted by path.
+// Objects are compared member by member and arrays element by element.
+func Compare(expected, actual interface{}) []Difference {
+	var diffs []Difference
+	compare("$", expected, actual, &diffs)
+	sort.SliceStable(diffs, func(i, j int) bool {
+		return diffs[i].Path < diffs[j].Path
+	})
+	return diffs
+}
+
+// Format describes the differences one per line, each prefixed with the given indentation.
+func Format(diffs []Difference, indent string) string {
+	lines := make([]string, len(diffs))
+	for i, d := range diffs {
+		lines[i] = indent + d.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+func compare(path string, expected, actual interface{}, diffs *[]Difference) {
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			break
+		}
+		for key, ev := range e {
+			av, ok := a[key]
+			if !ok {
+				*diffs = append(*diffs, Difference{Path: member(path, key), Expected: ev, Missing: true})
+				continue
+			}
+			compare(member(path, key), ev, av, diffs)
+		}
+		for key, av := range a {
+			if _, ok := e[key]; !ok {
+				*diffs = append(*diffs, Difference{Path: member(path, key), Actual: av, Unexpected: true})
+			}
+		}
+		return
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < len(e) || i < len(a); i++ {
+			elem := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(a):
+				*diffs = append(*diffs, Difference{Path: elem, Expected: e[i], Missing: true})
+			case i >= len(e):
+				*diffs = append(*diffs, Difference{Path: elem, Actual: a[i], Unexpected: true})
+			default:
+				compare(elem, e[i], a[i], diffs)
+			}
+		}
+		return
+	default:
+		if equalScalars(expected, actual) {
+			return
+		}
+	}
+	*diffs = append(*diffs, Difference{Path: path, Expected: expected, Actual: actual})
+}
+
+// equalScalars compares two decoded JSON scalars, numbers by value whatever their Go type and notation,
+// e.g. 1 and 1.0.
+func equalScalars(expected, actual interface{}) bool {
+	if _, ok := actual.(map[string]interface{}); ok {
+		return false
+	}
+	if _, ok := actual.([]interface{}); ok {
+		return false
+	}
+	if e, ok := number(expected); ok {
+		a, ok := number(actual)
+		return ok && e.Cmp(a) == 0
+	}
+	return FormatValue(expected) == FormatValue(actual)
+}
+
+// number returns the exact value of a decoded JSON number, either a json.Number or a Go number.
+func number(v interface{}) (*big.Rat, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		return new(big.Rat).SetString(string(n))
+	case float64:
+		if math.IsNaN(n) || math.IsInf(n, 0) {
+			return nil, false
+		}
+		return new(big.Rat).SetFloat64(n), true
+	case float32:
+		return number(float64(n))
+	case int:
+		return new(big.Rat).SetInt64(int64(n)), true
+	case int64:
+		return new(big.Rat).SetInt64(n), true
+	case uint64:
+		return new(big.Rat).SetInt(new(big.Int).SetUint64(n)), true
+	}
+	return nil, false
+}
+
+// member returns the path of a member of the object at path, quoting names that are not identifiers
+// and escaping the quotes and backslashes in them.
+func member(path, key string) string {
+	for _, r := range key {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return fmt.Sprintf("%s['%s']", path, memberEscaper.Replace(key))
+		}
+	}
+	if key == "" {
+		return path + "['']"
+	}
+	return path + "." + key
+}
+
+var memberEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+
+// maxValueLength is the maximum length of a value printed in a difference.
+const maxValueLength = 80
+
+// FormatValue describes a value in a difference: its JSON, truncated after maxValueLength bytes.
+func FormatValue(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	if len(data) > maxValueLength {
+		return string(data[:maxValueLength]) + "..."
+	}
+	return string(data)
+}
+
+/* Example usage -
+
+var expected, actual interface{}
+json.Unmarshal([]byte(`{"name": "bob", "tags": ["a", "b"]}`), &expected)
+json.Unmarshal([]byte(`{"name": "alice", "tags": ["a"], "age": 3}`), &actual)
+
+fmt.Println(jsondiff.Format(jsondiff.Compare(expected, actual), "  "))
+//   $.age: unexpected 3
+//   $.name: expected "bob", got "alice"
+//   $.tags[1]: missing, expected "b"
+
+*/
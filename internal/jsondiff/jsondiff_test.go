@@ -0,0 +1,60 @@
+package jsondiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// TestCompare checks the differences found between documents and their descriptions.
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		actual   string
+		want     []string
+	}{
+		{"equal", `{"a": 1, "b": [true, null, "x"]}`, `{"b": [true, null, "x"], "a": 1}`, nil},
+		{"integer and decimal", `{"n": 1}`, `{"n": 1.0}`, nil},
+		{"exponent", `{"n": 1500}`, `{"n": 1.5e3}`, nil},
+		{"large integers", `{"n": 9007199254740993}`, `{"n": 9007199254740992}`, []string{"$.n: expected 9007199254740993, got 9007199254740992"}},
+		{"number and string", `{"n": 1}`, `{"n": "1"}`, []string{`$.n: expected 1, got "1"`}},
+		{"changed value", `{"name": "bob"}`, `{"name": "alice"}`, []string{`$.name: expected "bob", got "alice"`}},
+		{"missing and unexpected", `{"a": 1}`, `{"b": 2}`, []string{"$.a: missing, expected 1", "$.b: unexpected 2"}},
+		{"array lengths", `[1, 2]`, `[1, 2, 3]`, []string{"$[2]: unexpected 3"}},
+		{"type change", `{"a": {"b": 1}}`, `{"a": [1]}`, []string{`$.a: expected {"b":1}, got [1]`}},
+		{"quoted member", `{"a-b": 1}`, `{"a-b": 2}`, []string{"$['a-b']: expected 1, got 2"}},
+		{"escaped member", `{"it's": 1, "a\\b": 1}`, `{"it's": 2, "a\\b": 2}`, []string{`$['a\\b']: expected 1, got 2`, `$['it\'s']: expected 1, got 2`}},
+		{"long value", `"x"`, `"` + strings.Repeat("y", 100) + `"`, []string{`$: expected "x", got "` + strings.Repeat("y", 79) + "..."}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diffs := Compare(decode(t, test.expected), decode(t, test.actual))
+			if got := Format(diffs, ""); got != strings.Join(test.want, "\n") {
+				t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(test.want, "\n"), got)
+			}
+		})
+	}
+}
+
+// TestCompareGoNumbers checks that numbers are compared by value whatever their Go type.
+func TestCompareGoNumbers(t *testing.T) {
+	if diffs := Compare(map[string]interface{}{"n": 2}, decode(t, `{"n": 2.0}`)); len(diffs) != 0 {
+		t.Errorf("expected no differences, got %v", diffs)
+	}
+	if diffs := Compare(float64(2), json.Number("2.5")); len(diffs) != 1 {
+		t.Errorf("expected 1 difference, got %v", diffs)
+	}
+}
+
+func decode(t *testing.T, s string) interface{} {
+	t.Helper()
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader([]byte(s)))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
@@ -0,0 +1,97 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Expectations are values expected in a JSON document by JSONPath expression, as written in suites,
+// e.g. {user.email: jane@example.com, $.items[0].count: 2}.
+type Expectations []expectation
+
+type expectation struct {
+	expr  string
+	path  *Path
+	value interface{}
+}
+
+// NewExpectations parses the expected values by expression. Expressions without the leading $ are relative
+// to the root, e.g. user.email for $.user.email. The values, e.g. decoded from YAML, are converted to the types
+// of the values decoded from JSON.
+func NewExpectations(expected map[string]interface{}) (Expectations, error) {
+	exprs := make([]string, 0, len(expected))
+	for expr := range expected {
+		exprs = append(exprs, expr)
+	}
+	sort.Strings(exprs)
+
+	expectations := make(Expectations, 0, len(exprs))
+	for _, expr := range exprs {
+		full := expr
+		if !strings.HasPrefix(full, "$") {
+			full = "$." + full
+		}
+		path, err := Parse(full)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", expr, err)
+		}
+		value, err := normalize(expected[expr])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", expr, err)
+		}
+		expectations = append(expectations, expectation{expr, path, value})
+	}
+	return expectations, nil
+}
+
+// Check returns an error for the first expectation, in the order of the expressions, that the document does
+// not meet. The first value selected by an expression is compared: expected strings with the text of the value,
+// once expanded by expand if it is not nil, and other values as JSON.
+func (e Expectations) Check(doc interface{}, expand func(string) string) error {
+	for _, x := range e {
+		values := x.path.Get(doc)
+		if len(values) == 0 {
+			return fmt.Errorf("%s not found", x.expr)
+		}
+		actual := values[0]
+
+		if s, ok := x.value.(string); ok {
+			if expand != nil {
+				s = expand(s)
+			}
+			if Text(actual) != s {
+				return fmt.Errorf("expected %s to be %q, got %s", x.expr, s, Text(actual))
+			}
+			continue
+		}
+		if !reflect.DeepEqual(actual, x.value) {
+			return fmt.Errorf("expected %s to be %s, got %s", x.expr, Text(x.value), Text(actual))
+		}
+	}
+	return nil
+}
+
+// normalize converts a value decoded from YAML to the types of the values decoded from JSON.
+func normalize(value interface{}) (interface{}, error) {
+	if _, ok := value.(string); ok {
+		return value, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var normalized interface{}
+	return normalized, json.Unmarshal(data, &normalized)
+}
+
+// Text returns strings as they are and other values as JSON.
+func Text(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
@@ -0,0 +1,53 @@
+package jsonpath
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestExpectations checks the expected values of documents, as written in suites.
+func TestExpectations(t *testing.T) {
+	doc := decode(t, `{"user": {"email": "jane@example.com", "age": 30, "tags": ["a"]}, "items": [{"count": 2}], "ok": true}`)
+	expand := func(s string) string {
+		return strings.ReplaceAll(s, "{{domain}}", "example.com")
+	}
+
+	tests := []struct {
+		name     string
+		expected map[string]interface{}
+		want     string
+	}{
+		{"relative", map[string]interface{}{"user.email": "jane@example.com", "ok": true}, ""},
+		{"absolute", map[string]interface{}{"$.items[0].count": 2}, ""},
+		{"text of numbers", map[string]interface{}{"user.age": "30"}, ""},
+		{"expanded", map[string]interface{}{"user.email": "jane@{{domain}}"}, ""},
+		{"YAML values", map[string]interface{}{"user.tags": []interface{}{"a"}, "items[0]": map[string]interface{}{"count": 2}}, ""},
+		{"not found", map[string]interface{}{"user.name": "jane"}, "user.name not found"},
+		{"string", map[string]interface{}{"user.email": "bob@example.com"}, `expected user.email to be "bob@example.com", got jane@example.com`},
+		{"number", map[string]interface{}{"user.age": 31}, "expected user.age to be 31, got 30"},
+		{"first failure", map[string]interface{}{"user.age": 31, "ok": false}, "expected ok to be false, got true"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expectations, err := NewExpectations(test.expected)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = expectations.Check(doc, expand)
+			switch {
+			case test.want == "" && err != nil:
+				t.Errorf("expected no error, got %v", err)
+			case test.want != "" && (err == nil || err.Error() != test.want):
+				t.Errorf("expected %q, got %v", test.want, err)
+			}
+		})
+	}
+}
+
+// TestNewExpectationsInvalid checks that invalid expressions are reported with their expression.
+func TestNewExpectationsInvalid(t *testing.T) {
+	_, err := NewExpectations(map[string]interface{}{"items[": 1})
+	if err == nil || !strings.HasPrefix(err.Error(), "items[: ") {
+		t.Errorf("expected an error prefixed with the expression, got %v", err)
+	}
+}
@@ -0,0 +1,235 @@
+// Package jsonpath implements the subset of JSONPath used to point at fields of decoded JSON documents:
+// the root $, child members .name and ['name'], indexes [0], wildcards .* and [*], and recursive descent ..name.
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// step is one selector of a path.
+type step struct {
+	// name is the member selected, or "*" for every member or element.
+	name string
+
+	// index is the element selected when isIndex is set.
+	index   int
+	isIndex bool
+
+	// recursive selects the member at any depth below the current node.
+	recursive bool
+}
+
+// Path is a parsed JSONPath expression.
+type Path struct {
+	expr  string
+	steps []step
+}
+
+// Parse parses a JSONPath expression such as $.items[*].id or $..created_at.
+func Parse(expr string) (*Path, error) {
+	if !strings.HasPrefix(expr, "$") {
+		return nil, fmt.Errorf("jsonpath %q: must start with $", expr)
+	}
+
+	p := &Path{expr: expr}
+	rest := expr[1:]
+	for rest != "" {
+		var s step
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			s.recursive = true
+			rest = rest[2:]
+			s.name, rest = readName(rest)
+		case strings.HasPrefix(rest, "."):
+			s.name, rest = readName(rest[1:])
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("jsonpath %q: missing ]", expr)
+			}
+			selector := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			switch {
+			case selector == "*":
+				s.name = "*"
+			case len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0]:
+				s.name = selector[1 : len(selector)-1]
+			default:
+				index, err := strconv.Atoi(selector)
+				if err != nil {
+					return nil, fmt.Errorf("jsonpath %q: invalid selector [%s]", expr, selector)
+				}
+				s.index, s.isIndex = index, true
+			}
+		default:
+			return nil, fmt.Errorf("jsonpath %q: unexpected %q", expr, rest)
+		}
+		if s.name == "" && !s.isIndex {
+			return nil, fmt.Errorf("jsonpath %q: empty member name", expr)
+		}
+		p.steps = append(p.steps, s)
+	}
+	return p, nil
+}
+
+// MustParse parses a JSONPath expression and panics if it is invalid.
+func MustParse(expr string) *Path {
+	p, err := Parse(expr)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// String returns the expression the path was parsed from.
+func (p *Path) String() string {
+	return p.expr
+}
+
+// readName reads a member name up to the next selector.
+func readName(s string) (string, string) {
+	end := strings.IndexAny(s, ".[")
+	if end < 0 {
+		return s, ""
+	}
+	return s[:end], s[end:]
+}
+
+// Get returns the values the path selects in the document.
+func (p *Path) Get(doc interface{}) []interface{} {
+	nodes := []interface{}{doc}
+	for _, s := range p.steps {
+		var next []interface{}
+		for _, node := range nodes {
+			next = append(next, s.selectFrom(node)...)
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+// Replace replaces the values the path selects in the document with the result of fn, in place.
+// The root itself cannot be replaced; a path without selectors leaves the document unchanged.
+func (p *Path) Replace(doc interface{}, fn func(interface{}) interface{}) {
+	if len(p.steps) == 0 {
+		return
+	}
+	parents := []interface{}{doc}
+	for _, s := range p.steps[:len(p.steps)-1] {
+		var next []interface{}
+		for _, node := range parents {
+			next = append(next, s.selectFrom(node)...)
+		}
+		parents = next
+	}
+
+	last := p.steps[len(p.steps)-1]
+	for _, parent := range parents {
+		if last.recursive {
+			replaceRecursive(parent, last.name, fn)
+			continue
+		}
+		last.replaceIn(parent, fn)
+	}
+}
+
+// selectFrom returns the children of the node the step selects.
+func (s step) selectFrom(node interface{}) []interface{} {
+	if s.recursive {
+		var found []interface{}
+		walk(node, func(n interface{}) {
+			found = append(found, step{name: s.name, index: s.index, isIndex: s.isIndex}.selectFrom(n)...)
+		})
+		return found
+	}
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if s.isIndex {
+			return nil
+		}
+		if s.name == "*" {
+			values := make([]interface{}, 0, len(n))
+			for _, v := range n {
+				values = append(values, v)
+			}
+			return values
+		}
+		if v, ok := n[s.name]; ok {
+			return []interface{}{v}
+		}
+	case []interface{}:
+		if s.name == "*" {
+			return n
+		}
+		if s.isIndex {
+			i := s.index
+			if i < 0 {
+				i += len(n)
+			}
+			if i >= 0 && i < len(n) {
+				return []interface{}{n[i]}
+			}
+		}
+	}
+	return nil
+}
+
+// replaceIn replaces the children of the node the step selects.
+func (s step) replaceIn(node interface{}, fn func(interface{}) interface{}) {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if s.isIndex {
+			return
+		}
+		for key, v := range n {
+			if s.name == "*" || key == s.name {
+				n[key] = fn(v)
+			}
+		}
+	case []interface{}:
+		for i, v := range n {
+			if s.name == "*" || (s.isIndex && (i == s.index || i == s.index+len(n))) {
+				n[i] = fn(v)
+			}
+		}
+	}
+}
+
+// replaceRecursive replaces the members with the given name at any depth below the node.
+func replaceRecursive(node interface{}, name string, fn func(interface{}) interface{}) {
+	walk(node, func(n interface{}) {
+		step{name: name}.replaceIn(n, fn)
+	})
+}
+
+// walk calls fn for the node and every object or array below it.
+func walk(node interface{}, fn func(interface{})) {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		fn(n)
+		for _, v := range n {
+			walk(v, fn)
+		}
+	case []interface{}:
+		fn(n)
+		for _, v := range n {
+			walk(v, fn)
+		}
+	}
+}
+
+/* Example usage -
+
+var doc interface{}
+json.Unmarshal([]byte(`{"id": 7, "items": [{"id": 1, "created_at": "..."}]}`), &doc)
+
+ids := jsonpath.MustParse("$..id").Get(doc)    // [7, 1] in any order
+
+jsonpath.MustParse("$.items[*].created_at").Replace(doc, func(interface{}) interface{} {
+    return "<ignored>"
+})
+
+*/
@@ -0,0 +1,112 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const testDocument = `{
+  "id": 7,
+  "user": {"name": "bob", "first-name": "Bob", "id": 3},
+  "items": [{"id": 1, "tags": ["a", "b"]}, {"id": 2, "tags": []}],
+  "empty": null
+}`
+
+// TestGet checks the values selected by paths.
+func TestGet(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{"$", []string{`{"empty":null,"id":7,"items":[{"id":1,"tags":["a","b"]},{"id":2,"tags":[]}],"user":{"first-name":"Bob","id":3,"name":"bob"}}`}},
+		{"$.user.name", []string{`"bob"`}},
+		{"$['user']['first-name']", []string{`"Bob"`}},
+		{`$["user"].id`, []string{"3"}},
+		{"$.items[1].id", []string{"2"}},
+		{"$.items[-1].id", []string{"2"}},
+		{"$.items[ 0 ].tags[*]", []string{`"a"`, `"b"`}},
+		{"$.items[*].id", []string{"1", "2"}},
+		{"$.user.*", []string{`"Bob"`, `"bob"`, "3"}},
+		{"$..id", []string{"1", "2", "3", "7"}},
+		{"$..tags[0]", []string{`"a"`}},
+		{"$.empty", []string{"null"}},
+		{"$.missing", nil},
+		{"$.items[5]", nil},
+		{"$.items.id", nil},
+		{"$.user[0]", nil},
+	}
+	doc := decode(t, testDocument)
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			var got []string
+			for _, v := range MustParse(test.expr).Get(doc) {
+				data, _ := json.Marshal(v)
+				got = append(got, string(data))
+			}
+			// Members are selected in the random order of maps.
+			sort.Strings(got)
+			if fmt.Sprint(got) != fmt.Sprint(test.want) {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+// TestParseInvalid checks the errors of invalid expressions.
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"user.name", "must start with $"},
+		{"$.items[0", "missing ]"},
+		{"$.items[a]", "invalid selector [a]"},
+		{"$.user.", "empty member name"},
+		{"$..", "empty member name"},
+		{"$user", `unexpected "user"`},
+	}
+	for _, test := range tests {
+		_, err := Parse(test.expr)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: expected an error containing %q, got %v", test.expr, test.want, err)
+		}
+	}
+}
+
+// TestReplace checks the values replaced in place by paths.
+func TestReplace(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"$", `{"id":7,"items":[{"id":1,"tags":["a"]},{"id":2,"tags":[]}],"user":{"id":3,"name":"bob"}}`},
+		{"$.id", `{"id":"x","items":[{"id":1,"tags":["a"]},{"id":2,"tags":[]}],"user":{"id":3,"name":"bob"}}`},
+		{"$.items[-1].id", `{"id":7,"items":[{"id":1,"tags":["a"]},{"id":"x","tags":[]}],"user":{"id":3,"name":"bob"}}`},
+		{"$.items[*].tags", `{"id":7,"items":[{"id":1,"tags":"x"},{"id":2,"tags":"x"}],"user":{"id":3,"name":"bob"}}`},
+		{"$.items[0].tags[0]", `{"id":7,"items":[{"id":1,"tags":["x"]},{"id":2,"tags":[]}],"user":{"id":3,"name":"bob"}}`},
+		{"$.user.*", `{"id":7,"items":[{"id":1,"tags":["a"]},{"id":2,"tags":[]}],"user":{"id":"x","name":"x"}}`},
+		{"$..id", `{"id":"x","items":[{"id":"x","tags":["a"]},{"id":"x","tags":[]}],"user":{"id":"x","name":"bob"}}`},
+		{"$.missing", `{"id":7,"items":[{"id":1,"tags":["a"]},{"id":2,"tags":[]}],"user":{"id":3,"name":"bob"}}`},
+	}
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			doc := decode(t, `{"id": 7, "user": {"name": "bob", "id": 3}, "items": [{"id": 1, "tags": ["a"]}, {"id": 2, "tags": []}]}`)
+			MustParse(test.expr).Replace(doc, func(interface{}) interface{} { return "x" })
+			if data, _ := json.Marshal(doc); string(data) != test.want {
+				t.Errorf("expected %s, got %s", test.want, data)
+			}
+		})
+	}
+}
+
+func decode(t *testing.T, s string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
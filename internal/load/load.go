@@ -0,0 +1,271 @@
+// Package load drives the scenarios of a suite concurrently, with a number of virtual users or at a target rate,
+// and reports latency percentiles, throughput and failures.
+package load
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/qatoolist/RouTest/internal/models"
+)
+
+// DefaultMaxVUs is the number of virtual users available to a rate-driven test when none is given.
+const DefaultMaxVUs = 50
+
+// Options configures a load test. Either VUs or RPS must be set.
+type Options struct {
+	// VUs is the number of virtual users, each executing the scenarios one after the other as fast as it can.
+	// With RPS, it is the maximum number of executions in flight instead.
+	VUs int
+
+	// RPS is the target number of scenario executions per second.
+	RPS float64
+
+	// Duration is how long the load is applied, ramp-up included.
+	Duration time.Duration
+
+	// RampUp is the time over which the virtual users are started, or the rate increased, linearly.
+	RampUp time.Duration
+}
+
+func (o *Options) validate() error {
+	if o.Duration <= 0 {
+		return errors.New("duration must be positive")
+	}
+	if o.RampUp < 0 || o.RampUp > o.Duration {
+		return errors.New("ramp-up must be between zero and the duration")
+	}
+	if o.RPS < 0 || o.VUs < 0 {
+		return errors.New("rps and vus cannot be negative")
+	}
+	if o.RPS == 0 && o.VUs == 0 {
+		return errors.New("either rps or vus must be set")
+	}
+	if o.RPS > 0 && o.VUs == 0 {
+		o.VUs = DefaultMaxVUs
+	}
+	return nil
+}
+
+// Factory builds a new application for a virtual user, so that virtual users never share scenario state.
+type Factory func() (*models.Application, error)
+
+// Run applies the load and returns its results. Every virtual user gets its own application and session:
+// the BeforeAll hooks run once per virtual user, and every execution runs the same hooks, parameters and
+// assertions as a functional run. Each virtual user executes the scenarios of its session in turn.
+// The applications sending with http.DefaultClient share a client keeping an idle connection per virtual user
+// instead, so that connections are reused rather than opened for most requests.
+// Cancelling the context stops the test early; the results collected so far are returned.
+func Run(ctx context.Context, factory Factory, opts Options) (*Result, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	client := newHTTPClient(opts.VUs)
+	defer client.CloseIdleConnections()
+
+	sessions := make([]*models.Session, opts.VUs)
+	for i := range sessions {
+		app, err := factory()
+		if err != nil {
+			return nil, err
+		}
+		if c := app.GetHTTPClient(); c == nil || c == http.DefaultClient {
+			app.SetHTTPClient(client)
+		}
+		sessions[i] = models.NewSession(app)
+		if len(sessions[i].Scenarios()) == 0 {
+			return nil, errors.New("no scenario to execute")
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Duration)
+	defer cancel()
+
+	result := newResult(opts)
+	var wg sync.WaitGroup
+	if opts.RPS > 0 {
+		runOpen(ctx, sessions, opts, result, &wg)
+	} else {
+		runClosed(ctx, sessions, opts, result, &wg)
+	}
+	wg.Wait()
+	result.finish()
+
+	if err := ctx.Err(); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		result.Interrupted = err
+	}
+	return result, nil
+}
+
+// newHTTPClient returns a client with the settings of http.DefaultTransport, but keeping up to one idle
+// connection per virtual user and host instead of two per host.
+func newHTTPClient(vus int) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = vus
+	if transport.MaxIdleConns < vus {
+		transport.MaxIdleConns = vus
+	}
+	return &http.Client{Transport: transport}
+}
+
+// runClosed starts the virtual users evenly over the ramp-up; each executes scenarios back to back until the end.
+func runClosed(ctx context.Context, sessions []*models.Session, opts Options, result *Result, wg *sync.WaitGroup) {
+	for i, session := range sessions {
+		delay := time.Duration(0)
+		if len(sessions) > 1 {
+			delay = opts.RampUp * time.Duration(i) / time.Duration(len(sessions))
+		}
+
+		wg.Add(1)
+		go func(session *models.Session, delay time.Duration) {
+			defer wg.Done()
+			if !wait(ctx, delay) {
+				return
+			}
+			vu := newVirtualUser(session, result)
+			defer vu.close(ctx)
+			if !vu.start(ctx) {
+				return
+			}
+			for ctx.Err() == nil {
+				vu.iterate(ctx)
+			}
+		}(session, delay)
+	}
+}
+
+// runOpen dispatches executions at the target rate, increased linearly over the ramp-up, to idle virtual users.
+// Executions due while every virtual user is busy are dropped and counted, as waiting would lower the rate.
+func runOpen(ctx context.Context, sessions []*models.Session, opts Options, result *Result, wg *sync.WaitGroup) {
+	work := make(chan struct{})
+	for _, session := range sessions {
+		wg.Add(1)
+		go func(session *models.Session) {
+			defer wg.Done()
+			vu := newVirtualUser(session, result)
+			defer vu.close(ctx)
+			if !vu.start(ctx) {
+				return
+			}
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-work:
+					vu.iterate(ctx)
+				}
+			}
+		}(session)
+	}
+
+	start := time.Now()
+	for k := 1; ; k++ {
+		if !wait(ctx, time.Until(start.Add(dueAt(k, opts)))) {
+			return
+		}
+		select {
+		case work <- struct{}{}:
+		default:
+			result.drop()
+		}
+	}
+}
+
+// dueAt returns when the k-th execution of a rate-driven test is due, relative to its start.
+// During the ramp-up the rate grows linearly, so that k executions are due after sqrt(2*k*rampUp/rps);
+// after it, they are due every 1/rps.
+func dueAt(k int, opts Options) time.Duration {
+	ramp := opts.RampUp.Seconds()
+	rampExecutions := opts.RPS * ramp / 2
+	var seconds float64
+	if float64(k) <= rampExecutions {
+		seconds = math.Sqrt(2 * float64(k) * ramp / opts.RPS)
+	} else {
+		seconds = ramp + (float64(k)-rampExecutions)/opts.RPS
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// wait waits for the given delay and reports whether the context is still active.
+func wait(ctx context.Context, delay time.Duration) bool {
+	if delay <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// virtualUser executes the scenarios of its session in turn.
+type virtualUser struct {
+	session *models.Session
+	result  *Result
+	next    int
+}
+
+func newVirtualUser(session *models.Session, result *Result) *virtualUser {
+	return &virtualUser{session: session, result: result}
+}
+
+// start starts the session, recording its failure.
+func (vu *virtualUser) start(ctx context.Context) bool {
+	if err := vu.session.Start(ctx); err != nil {
+		if ctx.Err() == nil {
+			vu.result.addError(err)
+		}
+		return false
+	}
+	return true
+}
+
+// iterate executes the next scenario of the session and records the outcome.
+func (vu *virtualUser) iterate(ctx context.Context) {
+	scenarios := vu.session.Scenarios()
+	scenario := scenarios[vu.next%len(scenarios)]
+	vu.next++
+
+	start := time.Now()
+	response, err := vu.session.Execute(ctx, scenario)
+	duration := time.Since(start)
+
+	// Executions cut short by the end of the test are not representative.
+	if ctx.Err() != nil {
+		return
+	}
+	vu.result.record(scenario, response, err, duration)
+}
+
+func (vu *virtualUser) close(ctx context.Context) {
+	if err := vu.session.Close(ctx); err != nil {
+		vu.result.addError(err)
+	}
+}
+
+/* Example usage -
+
+result, err := load.Run(ctx, func() (*models.Application, error) {
+    return suite.Build()
+}, load.Options{RPS: 50, Duration: time.Minute, RampUp: 10 * time.Second})
+if err != nil {
+    log.Fatal(err)
+}
+
+thresholds, err := load.ParseThresholds([]string{"p99<500ms", "error_rate<1%"})
+if err != nil {
+    log.Fatal(err)
+}
+for _, failure := range result.Check(thresholds) {
+    log.Println(failure)
+}
+
+*/
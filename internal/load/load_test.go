@@ -0,0 +1,71 @@
+package load
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/qatoolist/RouTest/internal/models"
+	"github.com/qatoolist/RouTest/internal/parser"
+)
+
+const loadSuite = `
+host: {protocol: http, hostname: 127.0.0.1, port: %s}
+routes:
+  - name: ping
+    method: GET
+    path: /ping
+    scenarios:
+      - name: ok
+        expect: {status: 200}
+`
+
+// TestRunHTTPClient checks that the virtual users share a client keeping an idle connection for each of them,
+// and that applications with a client of their own keep it.
+func TestRunHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("pong"))
+	}))
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	suite, err := parser.ParseSuite([]byte(fmt.Sprintf(loadSuite, port)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const vus = 8
+	own := server.Client()
+	var apps []*models.Application
+	result, err := Run(context.Background(), func() (*models.Application, error) {
+		app, err := suite.Build()
+		if len(apps) == 0 && err == nil {
+			app.SetHTTPClient(own)
+		}
+		apps = append(apps, app)
+		return app, err
+	}, Options{VUs: vus, Duration: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Executions() == 0 || result.Failures+result.Errors > 0 {
+		t.Errorf("expected successful executions, got %d with %d failures and %d errors", result.Executions(), result.Failures, result.Errors)
+	}
+
+	if apps[0].GetHTTPClient() != own {
+		t.Error("expected the client of the application to be kept")
+	}
+	shared := apps[1].GetHTTPClient()
+	transport, ok := shared.Transport.(*http.Transport)
+	if !ok || transport.MaxIdleConnsPerHost < vus {
+		t.Fatalf("expected a transport keeping %d idle connections per host, got %#v", vus, shared.Transport)
+	}
+	for _, app := range apps[2:] {
+		if app.GetHTTPClient() != shared {
+			t.Fatal("expected the virtual users to share the client")
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package load
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/qatoolist/RouTest/internal/interfaces"
+)
+
+// Stats are the latency statistics of a set of executions.
+type Stats struct {
+	Count int
+	Min   time.Duration
+	Avg   time.Duration
+	Max   time.Duration
+	P50   time.Duration
+	P90   time.Duration
+	P95   time.Duration
+	P99   time.Duration
+}
+
+// newStats computes the statistics of the durations, which are sorted in place.
+func newStats(durations []time.Duration) Stats {
+	s := Stats{Count: len(durations)}
+	if len(durations) == 0 {
+		return s
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+	var total time.Duration
+	for _, d := range durations {
+		total += d
+	}
+	s.Min, s.Max = durations[0], durations[len(durations)-1]
+	s.Avg = total / time.Duration(len(durations))
+	s.P50 = percentile(durations, 50)
+	s.P90 = percentile(durations, 90)
+	s.P95 = percentile(durations, 95)
+	s.P99 = percentile(durations, 99)
+	return s
+}
+
+// percentile returns the nearest-rank percentile of the sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// ScenarioResult is the outcome of the executions of a single scenario.
+type ScenarioResult struct {
+	RouteName    string
+	ScenarioName string
+	Latency      Stats
+
+	// Failures is the number of executions that received a response but failed an assertion or a hook.
+	Failures int
+
+	// Errors is the number of executions that received no response, e.g. timeouts and connection errors.
+	Errors int
+
+	durations []time.Duration
+}
+
+// Result is the outcome of a load test.
+type Result struct {
+	mu sync.Mutex
+
+	Options  Options
+	Duration time.Duration
+
+	// Latency is the total time of the requests, from their start to the end of their response body,
+	// or the time of the whole execution for the executions that received no response.
+	Latency Stats
+
+	// TimeToFirstByte is the time between the start of the requests and the first byte of their response.
+	TimeToFirstByte Stats
+
+	// BytesSent and BytesReceived are the sizes of all the requests and responses.
+	BytesSent     int64
+	BytesReceived int64
+
+	// Statuses counts the executions by response status code; executions without a response are counted as 0.
+	Statuses map[int]int
+
+	// Failures counts the executions that failed an assertion or a hook, Errors those that received no response.
+	Failures int
+	Errors   int
+
+	// Dropped counts the executions of a rate-driven test that were due while every virtual user was busy.
+	Dropped int
+
+	// Scenarios are the results by scenario, in the order they were first executed.
+	Scenarios []*ScenarioResult
+
+	// SessionErrors are the failures of the BeforeAll and AfterAll hooks of the virtual users.
+	SessionErrors []error
+
+	// Interrupted is the error that stopped the test early, or nil if it ran for its whole duration.
+	Interrupted error
+
+	start     time.Time
+	durations []time.Duration
+	ttfbs     []time.Duration
+	byName    map[string]*ScenarioResult
+}
+
+func newResult(opts Options) *Result {
+	return &Result{
+		Options:  opts,
+		Statuses: make(map[int]int),
+		start:    time.Now(),
+		byName:   make(map[string]*ScenarioResult),
+	}
+}
+
+// Executions returns the number of scenario executions.
+func (r *Result) Executions() int {
+	return r.Latency.Count
+}
+
+// Throughput returns the number of executions per second.
+func (r *Result) Throughput() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Executions()) / r.Duration.Seconds()
+}
+
+// ErrorRate returns the fraction of executions that failed, with or without a response.
+func (r *Result) ErrorRate() float64 {
+	if r.Executions() == 0 {
+		return 0
+	}
+	return float64(r.Failures+r.Errors) / float64(r.Executions())
+}
+
+// record records an execution. Its latency is the total time of its request rather than the duration
+// of the whole execution, so that the hooks and assertions do not skew the percentiles.
+func (r *Result) record(scenario interfaces.Scenario, response interfaces.Response, err error, duration time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var route string
+	if parent := scenario.GetParentRoute(); parent != nil {
+		route = parent.GetName()
+	}
+	key := route + "/" + scenario.GetName()
+	s, ok := r.byName[key]
+	if !ok {
+		s = &ScenarioResult{RouteName: route, ScenarioName: scenario.GetName()}
+		r.byName[key] = s
+		r.Scenarios = append(r.Scenarios, s)
+	}
+
+	latency := duration
+	if response != nil && response.GetTiming() != nil {
+		timing := response.GetTiming()
+		latency = timing.Total()
+		r.ttfbs = append(r.ttfbs, timing.TimeToFirstByte())
+		r.BytesSent += timing.BytesSent()
+		r.BytesReceived += timing.BytesReceived()
+	}
+	r.durations = append(r.durations, latency)
+	s.durations = append(s.durations, latency)
+
+	status := 0
+	if response != nil {
+		status = response.GetStatusCode()
+	}
+	r.Statuses[status]++
+
+	switch {
+	case err != nil && response == nil:
+		r.Errors++
+		s.Errors++
+	case err != nil:
+		r.Failures++
+		s.Failures++
+	}
+}
+
+func (r *Result) drop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Dropped++
+}
+
+func (r *Result) addError(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.SessionErrors = append(r.SessionErrors, err)
+}
+
+// finish computes the statistics once every virtual user stopped.
+func (r *Result) finish() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Duration = time.Since(r.start)
+	r.Latency = newStats(r.durations)
+	r.TimeToFirstByte = newStats(r.ttfbs)
+	for _, s := range r.Scenarios {
+		s.Latency = newStats(s.durations)
+	}
+}
+
+// StatusName returns the label of a status code counted in Statuses.
+func StatusName(status int) string {
+	if status == 0 {
+		return "no response"
+	}
+	return strconv.Itoa(status)
+}
@@ -0,0 +1,141 @@
+package load
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Threshold is a gate on a metric of a load test, such as p99<500ms or error_rate<1%.
+//
+// The metrics are the latency statistics min, avg, max, p50, p90, p95 and p99 and the time-to-first-byte
+// statistics ttfb_p50, ttfb_p90, ttfb_p95 and ttfb_p99, compared with durations;
+// error_rate, compared with a fraction or a percentage; rps, the throughput in executions per second;
+// and failures, errors and dropped, compared with counts.
+type Threshold struct {
+	expr     string
+	metric   string
+	operator string
+	value    float64
+}
+
+// operators are the comparison operators of thresholds, the two-character ones first so that they are matched first.
+var operators = []string{"<=", ">=", "<", ">"}
+
+// ParseThresholds parses a list of threshold expressions.
+func ParseThresholds(exprs []string) ([]Threshold, error) {
+	thresholds := make([]Threshold, 0, len(exprs))
+	for _, expr := range exprs {
+		t, err := parseThreshold(expr)
+		if err != nil {
+			return nil, err
+		}
+		thresholds = append(thresholds, t)
+	}
+	return thresholds, nil
+}
+
+func parseThreshold(expr string) (Threshold, error) {
+	compact := strings.ReplaceAll(expr, " ", "")
+	for _, op := range operators {
+		i := strings.Index(compact, op)
+		if i < 0 {
+			continue
+		}
+		t := Threshold{expr: expr, metric: compact[:i], operator: op}
+		raw := compact[i+len(op):]
+
+		var err error
+		switch t.metric {
+		case "min", "avg", "max", "p50", "p90", "p95", "p99", "ttfb_p50", "ttfb_p90", "ttfb_p95", "ttfb_p99":
+			var d time.Duration
+			d, err = time.ParseDuration(raw)
+			t.value = float64(d)
+		case "error_rate":
+			if strings.HasSuffix(raw, "%") {
+				t.value, err = strconv.ParseFloat(strings.TrimSuffix(raw, "%"), 64)
+				t.value /= 100
+			} else {
+				t.value, err = strconv.ParseFloat(raw, 64)
+			}
+		case "rps", "failures", "errors", "dropped":
+			t.value, err = strconv.ParseFloat(raw, 64)
+		default:
+			return Threshold{}, fmt.Errorf("threshold %q: unknown metric %q", expr, t.metric)
+		}
+		if err != nil {
+			return Threshold{}, fmt.Errorf("threshold %q: invalid value %q", expr, raw)
+		}
+		return t, nil
+	}
+	return Threshold{}, fmt.Errorf("threshold %q: expected a metric, an operator among <, <=, >, >= and a value", expr)
+}
+
+// String returns the expression of the threshold.
+func (t Threshold) String() string {
+	return t.expr
+}
+
+// Check returns a description of every threshold the result does not satisfy.
+func (r *Result) Check(thresholds []Threshold) []string {
+	var failures []string
+	for _, t := range thresholds {
+		actual, formatted := r.metric(t.metric)
+		var ok bool
+		switch t.operator {
+		case "<":
+			ok = actual < t.value
+		case "<=":
+			ok = actual <= t.value
+		case ">":
+			ok = actual > t.value
+		case ">=":
+			ok = actual >= t.value
+		}
+		if !ok {
+			failures = append(failures, fmt.Sprintf("threshold %s failed: %s is %s", t, t.metric, formatted))
+		}
+	}
+	return failures
+}
+
+// metric returns the value of a metric, in the unit thresholds are compared with, and formatted for people.
+func (r *Result) metric(name string) (float64, string) {
+	var d time.Duration
+	switch name {
+	case "min":
+		d = r.Latency.Min
+	case "avg":
+		d = r.Latency.Avg
+	case "max":
+		d = r.Latency.Max
+	case "p50":
+		d = r.Latency.P50
+	case "p90":
+		d = r.Latency.P90
+	case "p95":
+		d = r.Latency.P95
+	case "p99":
+		d = r.Latency.P99
+	case "ttfb_p50":
+		d = r.TimeToFirstByte.P50
+	case "ttfb_p90":
+		d = r.TimeToFirstByte.P90
+	case "ttfb_p95":
+		d = r.TimeToFirstByte.P95
+	case "ttfb_p99":
+		d = r.TimeToFirstByte.P99
+	case "error_rate":
+		return r.ErrorRate(), fmt.Sprintf("%.2f%%", r.ErrorRate()*100)
+	case "rps":
+		return r.Throughput(), fmt.Sprintf("%.1f/s", r.Throughput())
+	case "failures":
+		return float64(r.Failures), strconv.Itoa(r.Failures)
+	case "errors":
+		return float64(r.Errors), strconv.Itoa(r.Errors)
+	case "dropped":
+		return float64(r.Dropped), strconv.Itoa(r.Dropped)
+	}
+	return float64(d), d.Round(time.Microsecond).String()
+}
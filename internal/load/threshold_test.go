@@ -0,0 +1,79 @@
+package load
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestParseThresholds checks the metrics, operators and values accepted in thresholds.
+func TestParseThresholds(t *testing.T) {
+	tests := []struct {
+		expr    string
+		want    Threshold
+		wantErr string
+	}{
+		{expr: "p99<500ms", want: Threshold{"p99<500ms", "p99", "<", float64(500 * time.Millisecond)}},
+		{expr: "ttfb_p95 <= 1s", want: Threshold{"ttfb_p95 <= 1s", "ttfb_p95", "<=", float64(time.Second)}},
+		{expr: "error_rate<1%", want: Threshold{"error_rate<1%", "error_rate", "<", 0.01}},
+		{expr: "error_rate<0.05", want: Threshold{"error_rate<0.05", "error_rate", "<", 0.05}},
+		{expr: "rps>=100", want: Threshold{"rps>=100", "rps", ">=", 100}},
+		{expr: "dropped>0", want: Threshold{"dropped>0", "dropped", ">", 0}},
+		{expr: "p42<1s", wantErr: `unknown metric "p42"`},
+		{expr: "p99<fast", wantErr: `invalid value "fast"`},
+		{expr: "p99=1s", wantErr: "expected a metric, an operator"},
+	}
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			thresholds, err := ParseThresholds([]string{test.expr})
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Errorf("expected an error containing %q, got %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if thresholds[0] != test.want {
+				t.Errorf("expected %+v, got %+v", test.want, thresholds[0])
+			}
+		})
+	}
+}
+
+// TestCheck checks the thresholds failed by a result and their descriptions.
+func TestCheck(t *testing.T) {
+	result := &Result{
+		Duration: 10 * time.Second,
+		Latency:  Stats{Count: 200, P99: 700 * time.Millisecond, Max: time.Second},
+		Failures: 3,
+		Errors:   1,
+	}
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"p99<500ms", "threshold p99<500ms failed: p99 is 700ms"},
+		{"p99<=700ms", ""},
+		{"max<1s", "threshold max<1s failed: max is 1s"},
+		{"error_rate<1%", "threshold error_rate<1% failed: error_rate is 2.00%"},
+		{"error_rate<5%", ""},
+		{"rps>=20", ""},
+		{"rps>20", "threshold rps>20 failed: rps is 20.0/s"},
+		{"failures<=3", ""},
+		{"errors<1", "threshold errors<1 failed: errors is 1"},
+	}
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			thresholds, err := ParseThresholds([]string{test.expr})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := strings.Join(result.Check(thresholds), "\n")
+			if got != test.want {
+				t.Errorf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
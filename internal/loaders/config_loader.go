@@ -103,6 +103,27 @@ func ConfigLoaderFactory(env string, path string) (ConfigLoader, error) {
 	return nil, fmt.Errorf("no loader found for environment %s", runEnv)
 }
 
+// LoadEnvironmentConfig loads the configuration of the given environment from the file "<env>.json", "<env>.yaml"
+// or "<env>.env" in the given directory, whichever exists first. Unlike ConfigLoaderFactory, the environment
+// is given explicitly rather than through the RunEnv environment variable, so that the configurations
+// of several environments can be loaded in the same process.
+func LoadEnvironmentConfig(env string, path string) (*Config, error) {
+	candidates := []struct {
+		extension string
+		loader    ConfigLoader
+	}{
+		{".json", &JSONConfigLoader{}},
+		{".yaml", &YAMLConfigLoader{}},
+		{".env", &ENVConfigLoader{}},
+	}
+	for _, candidate := range candidates {
+		if _, err := os.Stat(filepath.Join(path, env+candidate.extension)); err == nil {
+			return candidate.loader.LoadConfig(env, path)
+		}
+	}
+	return nil, fmt.Errorf("no configuration found for environment %s in %s", env, path)
+}
+
 /* Example -
 func main() {
 	// Specify the environment and config directory
@@ -0,0 +1,114 @@
+package loaders
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DataRow is a row of a dataset, mapping column names to values.
+type DataRow map[string]string
+
+// LoadDataset loads the rows of a dataset file. The format is chosen from the file extension:
+// ".csv" files must start with a header row, ".json" files must hold an array of objects
+// and ".yaml" or ".yml" files a list of mappings.
+// Values that are not strings are converted to their JSON representation.
+func LoadDataset(path string) ([]DataRow, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return loadCSVDataset(path)
+	case ".json":
+		return loadJSONDataset(path)
+	case ".yaml", ".yml":
+		return loadYAMLDataset(path)
+	default:
+		return nil, fmt.Errorf("unsupported dataset format: %s", path)
+	}
+}
+
+func loadCSVDataset(path string) ([]DataRow, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("dataset has no header row")
+	}
+
+	header := records[0]
+	rows := make([]DataRow, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(DataRow, len(header))
+		for i, column := range header {
+			row[strings.TrimSpace(column)] = record[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func loadJSONDataset(path string) ([]DataRow, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []map[string]interface{}
+	if err := json.Unmarshal(contents, &records); err != nil {
+		return nil, err
+	}
+	return toDataRows(records)
+}
+
+func loadYAMLDataset(path string) ([]DataRow, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []map[string]interface{}
+	if err := yaml.Unmarshal(contents, &records); err != nil {
+		return nil, err
+	}
+	return toDataRows(records)
+}
+
+func toDataRows(records []map[string]interface{}) ([]DataRow, error) {
+	rows := make([]DataRow, 0, len(records))
+	for _, record := range records {
+		row := make(DataRow, len(record))
+		for column, value := range record {
+			switch v := value.(type) {
+			case string:
+				row[column] = v
+			case nil:
+				row[column] = ""
+			default:
+				data, err := json.Marshal(v)
+				if err != nil {
+					return nil, fmt.Errorf("column %s: %v", column, err)
+				}
+				row[column] = string(data)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
@@ -0,0 +1,86 @@
+package loaders
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestLoadDataset checks the rows read from CSV, JSON and YAML files, values that are not strings included.
+func TestLoadDataset(t *testing.T) {
+	tests := []struct {
+		file     string
+		contents string
+		want     string
+	}{
+		{
+			file:     "users.csv",
+			contents: "name, id,tags\nadmin,1,smoke\n\"doe, jane\",2,\n",
+			want:     "[map[id:1 name:admin tags:smoke] map[id:2 name:doe, jane tags:]]",
+		},
+		{
+			file:     "users.json",
+			contents: `[{"name": "admin", "id": 1, "ratio": 0.5, "active": true, "manager": null, "roles": ["a", "b"], "address": {"city": "Pune"}}]`,
+			want:     `[map[active:true address:{"city":"Pune"} id:1 manager: name:admin ratio:0.5 roles:["a","b"]]]`,
+		},
+		{
+			file:     "users.YML",
+			contents: "- name: admin\n  id: 1\n  active: false\n  manager: ~\n  roles: [a]\n  address: {city: Pune}\n",
+			want:     `[map[active:false address:{"city":"Pune"} id:1 manager: name:admin roles:["a"]]]`,
+		},
+		{
+			file:     "empty.json",
+			contents: "[]",
+			want:     "[]",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.file, func(t *testing.T) {
+			rows, err := LoadDataset(writeDataset(t, test.file, test.contents))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := fmt.Sprint(rows); got != test.want {
+				t.Errorf("expected %s, got %s", test.want, got)
+			}
+		})
+	}
+}
+
+// TestLoadDatasetInvalid checks the errors of files that cannot be read as datasets.
+func TestLoadDatasetInvalid(t *testing.T) {
+	tests := []struct {
+		file     string
+		contents string
+		want     string
+	}{
+		{"users.txt", "name\nadmin\n", "unsupported dataset format"},
+		{"empty.csv", "", "no header row"},
+		{"ragged.csv", "name,id\nadmin\n", "wrong number of fields"},
+		{"object.json", `{"name": "admin"}`, "cannot unmarshal object"},
+		{"scalars.yaml", "- admin\n", "cannot unmarshal"},
+	}
+	for _, test := range tests {
+		t.Run(test.file, func(t *testing.T) {
+			_, err := LoadDataset(writeDataset(t, test.file, test.contents))
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("expected an error containing %q, got %v", test.want, err)
+			}
+		})
+	}
+
+	if _, err := LoadDataset(filepath.Join(t.TempDir(), "missing.csv")); !os.IsNotExist(err) {
+		t.Errorf("expected a missing file to be reported, got %v", err)
+	}
+}
+
+func writeDataset(t *testing.T, name string, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
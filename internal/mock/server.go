@@ -0,0 +1,258 @@
+// Package mock serves stub responses for the routes of an application, so that clients can be developed
+// and tested before the real API exists.
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/qatoolist/RouTest/internal/interfaces"
+	"github.com/qatoolist/RouTest/internal/models"
+)
+
+// ScenarioHeader selects the scenario whose example response is served when a route has several.
+const ScenarioHeader = "X-Mock-Scenario"
+
+// Server is an http.Handler answering the requests that match the method and path of a route
+// with the example response of one of its scenarios, or with a response generated from its response body schema.
+// Request bodies are validated against the request body schema of the route, and every mismatch is logged.
+type Server struct {
+	// Seed seeds the bodies generated from response body schemas; the same seed serves the same bodies.
+	Seed int64
+
+	routes []mockRoute
+	logger *log.Logger
+}
+
+// mockRoute is a route together with its path template split in segments.
+type mockRoute struct {
+	route    interfaces.Route
+	segments []string
+}
+
+// NewServer creates a new Server for the routes of the registry.
+// Routes with more literal path segments are matched first, so that /users/me wins over /users/{id},
+// and routes with as many in the order of models.Routes.
+func NewServer(registry interfaces.RouteRegistry, logger *log.Logger) *Server {
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	s := &Server{logger: logger}
+	for _, route := range models.Routes(registry) {
+		s.routes = append(s.routes, mockRoute{
+			route:    route,
+			segments: splitPath(route.GetInfo().GetPath()),
+		})
+	}
+	sort.SliceStable(s.routes, func(i, j int) bool {
+		return literalSegments(s.routes[i].segments) > literalSegments(s.routes[j].segments)
+	})
+	return s
+}
+
+// ServeHTTP answers the request with the example response of the matching route.
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	route, pathMatched := s.match(req)
+	if route == nil {
+		if pathMatched {
+			s.mismatch(w, req, http.StatusMethodNotAllowed, "no route matches the method")
+			return
+		}
+		s.mismatch(w, req, http.StatusNotFound, "no route matches the path")
+		return
+	}
+
+	if err := validateRequestBody(route, req); err != nil {
+		s.mismatch(w, req, http.StatusBadRequest, fmt.Sprintf("route %s: %v", route.GetName(), err))
+		return
+	}
+
+	status, headers, body, err := exampleResponse(route, req.Header.Get(ScenarioHeader), s.Seed)
+	if err != nil {
+		s.mismatch(w, req, http.StatusInternalServerError, fmt.Sprintf("route %s: %v", route.GetName(), err))
+		return
+	}
+
+	for key, value := range headers {
+		w.Header().Set(key, value)
+	}
+	if w.Header().Get("Content-Type") == "" && len(body) > 0 {
+		w.Header().Set("Content-Type", contentType(body))
+	}
+	w.WriteHeader(status)
+	w.Write(body)
+
+	s.logger.Printf("%s %s -> %s (%d)", req.Method, req.URL.Path, route.GetName(), status)
+}
+
+// match returns the route matching the method and path of the request.
+// pathMatched reports whether a route matched the path but not the method.
+func (s *Server) match(req *http.Request) (route interfaces.Route, pathMatched bool) {
+	segments := splitPath(req.URL.Path)
+	for _, r := range s.routes {
+		if !matchSegments(r.segments, segments) {
+			continue
+		}
+		if strings.EqualFold(r.route.GetInfo().GetMethod().String(), req.Method) {
+			return r.route, true
+		}
+		pathMatched = true
+	}
+	return nil, pathMatched
+}
+
+// mismatch logs a request the mock cannot answer and replies with the given status and a JSON error.
+func (s *Server) mismatch(w http.ResponseWriter, req *http.Request, status int, reason string) {
+	s.logger.Printf("MISMATCH %s %s: %s", req.Method, req.URL.Path, reason)
+
+	body, _ := json.Marshal(map[string]string{"error": reason})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// validateRequestBody validates the JSON body of the request against the request body schema of the route.
+func validateRequestBody(route interfaces.Route, req *http.Request) error {
+	if route.GetInfo().GetRequestBodySchema() == nil {
+		return nil
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	if len(body) == 0 {
+		return fmt.Errorf("request body is empty")
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return fmt.Errorf("request body is not valid JSON: %v", err)
+	}
+	if err := route.ValidateReqBody(data); err != nil {
+		return fmt.Errorf("request body does not match the schema: %v", err)
+	}
+	return nil
+}
+
+// exampleResponse returns the response to serve for the route.
+// The response recorded for a scenario is preferred: the one named by the scenario argument if any,
+// otherwise the first successful one. Without recorded responses, the body is generated from the response body schema
+// with the given seed.
+func exampleResponse(route interfaces.Route, scenario string, seed int64) (int, map[string]string, []byte, error) {
+	var recorded interfaces.Response
+	for _, s := range *route.GetScenarioRegistry().GetScenarios() {
+		resp := s.GetResponse()
+		if resp == nil {
+			continue
+		}
+		if scenario != "" {
+			if s.GetName() == scenario {
+				recorded = resp
+				break
+			}
+			continue
+		}
+		if recorded == nil || (!recorded.IsSuccess() && resp.IsSuccess()) {
+			recorded = resp
+		}
+	}
+	if scenario != "" && recorded == nil {
+		return 0, nil, nil, fmt.Errorf("scenario %q has no example response", scenario)
+	}
+
+	if recorded != nil {
+		headers := make(map[string]string)
+		for _, header := range recorded.GetHeaders() {
+			headers[header.Key()] = header.Value()
+		}
+		// The length of the recorded body may not match the body served, the server computes it.
+		delete(headers, "Content-Length")
+		return recorded.GetStatusCode(), headers, recorded.Bytes(), nil
+	}
+
+	status := http.StatusOK
+	if route.GetInfo().GetMethod().String() == http.MethodPost {
+		status = http.StatusCreated
+	}
+
+	schema := route.GetInfo().GetResponseBodySchema()
+	if schema == nil {
+		return status, nil, nil, nil
+	}
+	example, err := schema.Generate(seed)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	body, err := json.Marshal(example)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	return status, nil, body, nil
+}
+
+// contentType guesses the content type of a body served without one.
+func contentType(body []byte) string {
+	if json.Valid(body) {
+		return "application/json"
+	}
+	return http.DetectContentType(body)
+}
+
+func splitPath(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+// matchSegments reports whether the path segments match the template segments, where {name} matches any segment.
+func matchSegments(template, path []string) bool {
+	if len(template) != len(path) {
+		return false
+	}
+	for i, segment := range template {
+		if isVariable(segment) {
+			if path[i] == "" {
+				return false
+			}
+			continue
+		}
+		if segment != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func literalSegments(segments []string) int {
+	n := 0
+	for _, segment := range segments {
+		if !isVariable(segment) {
+			n++
+		}
+	}
+	return n
+}
+
+func isVariable(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+/* Example usage -
+
+app, err := suite.Build()
+if err != nil {
+    log.Fatal(err)
+}
+
+server := mock.NewServer(app.RouteRegistry, app.GetLogger())
+log.Fatal(http.ListenAndServe("127.0.0.1:8080", server))
+
+// Ask for the example response of a given scenario
+// curl -H 'X-Mock-Scenario: missing user' http://127.0.0.1:8080/users/0
+
+*/
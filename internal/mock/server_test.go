@@ -0,0 +1,135 @@
+package mock
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/qatoolist/RouTest/internal/models"
+	"github.com/qatoolist/RouTest/internal/parser"
+)
+
+const testSuite = `
+host: {protocol: http, hostname: 127.0.0.1, port: %s}
+routes:
+  - name: get-user
+    method: GET
+    path: /users/{id}
+    response_schema: '{"type": "object", "properties": {"id": {"type": "integer"}}, "required": ["id"]}'
+    scenarios:
+      - name: generated from the schema
+        path_variables: {id: "1"}
+        expect: {status: 200}
+  - name: me
+    method: GET
+    path: /users/me
+    scenarios:
+      - name: example
+        example: {status: 401, body: {error: unauthorized}}
+        expect: {status: 401, body_contains: unauthorized}
+  - name: create-user
+    method: POST
+    path: /users
+    request_schema: '{"type": "object", "required": ["name"]}'
+    scenarios:
+      - name: valid body
+        body: {name: bob}
+        expect: {status: 201}
+`
+
+// TestServer runs a suite against the mock server built from the same suite.
+func TestServer(t *testing.T) {
+	mockApp := buildSuite(t, "0")
+	server := httptest.NewServer(NewServer(mockApp.RouteRegistry, log.New(ioutil.Discard, "", 0)))
+	defer server.Close()
+
+	_, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := buildSuite(t, port).Run(context.Background())
+	for _, result := range report.Results() {
+		if !result.Passed() {
+			t.Errorf("%s/%s: %v", result.RouteName(), result.ScenarioName(), result.Err())
+		}
+	}
+	if report.Passed() != 3 {
+		t.Errorf("expected 3 passed scenarios, got %d", report.Passed())
+	}
+
+	// The runner refuses to send a body that does not match the request schema, so it is sent directly.
+	resp, err := http.Post(server.URL+"/users", "application/json", strings.NewReader(`{"nom": "bob"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an invalid body, got %d", resp.StatusCode)
+	}
+}
+
+func buildSuite(t *testing.T, port string) *models.Application {
+	t.Helper()
+
+	suite, err := parser.ParseSuite([]byte(fmt.Sprintf(testSuite, port)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	app, err := suite.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.SetLogger(log.New(ioutil.Discard, "", 0))
+	return app
+}
+
+// TestServerMatchOrder checks that the route with the most literal segments wins,
+// and that among routes with as many the one added first wins.
+func TestServerMatchOrder(t *testing.T) {
+	route := func(name string, path string, status int) string {
+		return fmt.Sprintf(`
+  - name: %s
+    method: GET
+    path: %s
+    scenarios:
+      - name: example
+        example: {status: %d}
+`, name, path, status)
+	}
+	byName, byID, me := route("by-name", "/users/{name}", 200), route("by-id", "/users/{id}", 201), route("me", "/users/me", 401)
+
+	tests := []struct {
+		name   string
+		routes string
+		path   string
+		want   int
+	}{
+		{"literal segment first", byName + byID + me, "/users/me", 401},
+		{"first added", byName + byID + me, "/users/7", 200},
+		{"first added swapped", byID + byName + me, "/users/7", 201},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			suite, err := parser.ParseSuite([]byte("host: {hostname: localhost}\nroutes:" + test.routes))
+			if err != nil {
+				t.Fatal(err)
+			}
+			app, err := suite.Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			recorder := httptest.NewRecorder()
+			NewServer(app.RouteRegistry, log.New(ioutil.Discard, "", 0)).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, test.path, nil))
+			if recorder.Code != test.want {
+				t.Errorf("expected status %d, got %d", test.want, recorder.Code)
+			}
+		})
+	}
+}
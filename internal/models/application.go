@@ -2,7 +2,11 @@
 package models
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"log"
+	"net/http"
 
 	"github.com/qatoolist/RouTest/internal/interfaces"
 )
@@ -40,6 +44,24 @@ type Application struct {
 	// Register specifies the register of Fixed Parameters and Responses that you many want to
 	// Refer later just by using the human redable names of the parameters.
 	Register interfaces.Register
+
+	// RetryPolicy is the retry policy applied to every scenario of the application,
+	// unless overridden at route or scenario level.
+	RetryPolicy interfaces.RetryPolicy
+
+	// Variables is the store of values captured while the scenarios run, shared by every scenario of the application.
+	Variables interfaces.Variables
+
+	// Logger is the logger given to the hooks of the scenarios.
+	Logger *log.Logger
+
+	// HTTPClient is the client sending the requests of every route of the application.
+	// Its transport can be replaced, e.g. to record or replay the traffic.
+	HTTPClient *http.Client
+
+	// SpecValidator checks every response against the specification of the API when set.
+	// Its findings are reported as spec drift, apart from the functional failures of the scenarios.
+	SpecValidator interfaces.SpecValidator
 }
 
 // NewApplication creates a new Application object.
@@ -56,6 +78,9 @@ func NewApplication(env string, config interfaces.Config, requirements interface
 		ApplicationParametersRegistry: NewParameterRegistry(),
 		ApplicationHooksRegistry:      NewHooksRegistry(),
 		Register:                      NewRegister(),
+		Variables:                     NewVariableStore(),
+		Logger:                        log.Default(),
+		HTTPClient:                    http.DefaultClient,
 	}, nil
 }
 
@@ -65,7 +90,7 @@ func (a *Application) NewRoute(info interfaces.Info, meta string) interfaces.Rou
 		panic(err)
 	}
 	route := Route{
-		Info:                    Info{},
+		Info:                    info,
 		ParentApplication:       a,
 		Meta:                    new_meta,
 		ScenarioRegistry:        NewScenarioRegistry(),
@@ -134,10 +159,10 @@ func (app *Application) GetApplicationHooksRegistry() interfaces.HooksRegistry {
 	return app.ApplicationHooksRegistry
 }
 
-// GetScenarios retrieves all Scenarios for the application.
+// GetScenarios retrieves all Scenarios for the application, in the order of their routes.
 func (app *Application) GetScenarios() []interfaces.Scenario {
 	var scenarios []interfaces.Scenario
-	for _, route := range app.RouteRegistry.GetRegistry() {
+	for _, route := range Routes(app.RouteRegistry) {
 		scenarios = append(scenarios, *route.GetScenarioRegistry().GetScenarios()...)
 	}
 	return scenarios
@@ -151,3 +176,93 @@ func (app *Application) GetScenariosByRoute(name string) ([]interfaces.Scenario,
 	}
 	return *route.GetScenarioRegistry().GetScenarios(), nil
 }
+
+// GetHost returns the host the application under test is served from.
+func (app *Application) GetHost() interfaces.Host {
+	return app.Host
+}
+
+// GetRetryPolicy returns the application-level retry policy, or nil if none is set.
+func (app *Application) GetRetryPolicy() interfaces.RetryPolicy {
+	return app.RetryPolicy
+}
+
+// SetRetryPolicy sets the retry policy applied to every scenario of the application.
+func (app *Application) SetRetryPolicy(policy interfaces.RetryPolicy) {
+	app.RetryPolicy = policy
+}
+
+// GetVariables returns the store of values captured while the scenarios run.
+func (app *Application) GetVariables() interfaces.Variables {
+	return app.Variables
+}
+
+// GetLogger returns the logger given to the hooks of the scenarios.
+func (app *Application) GetLogger() *log.Logger {
+	return app.Logger
+}
+
+// SetLogger sets the logger given to the hooks of the scenarios.
+func (app *Application) SetLogger(logger *log.Logger) {
+	app.Logger = logger
+}
+
+// GetHTTPClient returns the client sending the requests of every route of the application.
+func (app *Application) GetHTTPClient() *http.Client {
+	return app.HTTPClient
+}
+
+// SetHTTPClient sets the client sending the requests of every route of the application.
+func (app *Application) SetHTTPClient(client *http.Client) {
+	app.HTTPClient = client
+}
+
+// GetSpecValidator returns the validator checking every response against the specification of the API, or nil.
+func (app *Application) GetSpecValidator() interfaces.SpecValidator {
+	return app.SpecValidator
+}
+
+// SetSpecValidator sets the validator checking every response against the specification of the API.
+func (app *Application) SetSpecValidator(validator interfaces.SpecValidator) {
+	app.SpecValidator = validator
+}
+
+// httpClient returns the client of the application, or the default client when there is none.
+func httpClient(app interfaces.Application) *http.Client {
+	if app == nil || app.GetHTTPClient() == nil {
+		return http.DefaultClient
+	}
+	return app.GetHTTPClient()
+}
+
+// Run executes every scenario of every route of the application and returns the report of the run.
+// The routes run between the BeforeAll and AfterAll hooks of the application. The AfterAll hooks always run,
+// even when a BeforeAll hook or a scenario failed or panicked, and their failures are added to the report.
+// When the context is done the scenarios in flight are cancelled, no new scenario is started
+// and the partial report is returned, marked as interrupted.
+func (app *Application) Run(ctx context.Context) interfaces.Report {
+	report := NewReport()
+
+	hooks := app.ApplicationHooksRegistry
+	if err := hooks.RunBeforeAllHooks(ctx); err != nil {
+		skipScenarios(report, app.GetScenarios(), fmt.Errorf("before all hooks of the application failed: %v", err))
+	} else {
+		for _, route := range Routes(app.RouteRegistry) {
+			if ctx.Err() != nil {
+				break
+			}
+			route.GetScenarioRegistry().Run(ctx, report)
+		}
+	}
+
+	tctx, cancel := teardownContext(ctx)
+	defer cancel()
+	if err := hooks.RunAfterAllHooks(tctx); err != nil {
+		report.AddError(fmt.Errorf("after all hooks of the application failed: %v", err))
+	}
+
+	if err := ctx.Err(); err != nil {
+		report.Interrupt(err)
+	}
+	return report
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/qatoolist/RouTest/internal/interfaces"
+)
+
+// addTestRoute adds a route with a single scenario to the application.
+func addTestRoute(app *Application, name string, method Method) interfaces.Scenario {
+	route := app.NewRoute(&Info{name: name, path: "/" + name, method: method}, testMeta)
+	app.AddRoute(name, &route)
+	return route.NewScenario(&Info{name: name, path: "/" + name, method: method}, testMeta)
+}
+
+// TestRunInterrupted checks that cancelling the context of a run fails the scenario in flight,
+// does not start the remaining ones, still runs the AfterAll hooks and marks the partial report as interrupted.
+func TestRunInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		cancel()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	app, _ := newTestScenario(t, server, GET)
+	addTestRoute(app, "users", GET)
+	addTestRoute(app, "zones", GET)
+	var teardown error = errors.New("not run")
+	app.ApplicationHooksRegistry.RegisterAfterAllHook(func(ctx context.Context) error {
+		teardown = ctx.Err()
+		return nil
+	})
+
+	report := app.Run(ctx)
+	if calls != 1 {
+		t.Errorf("expected the remaining scenarios not to be sent, got %d requests", calls)
+	}
+	results := report.Results()
+	if len(results) != 1 || results[0].ScenarioName() != "job" {
+		t.Fatalf("expected only the result of the scenario in flight, got %d results", len(results))
+	}
+	if err := results[0].Err(); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the scenario in flight to fail with the cancellation, got %v", err)
+	}
+	if teardown != nil {
+		t.Errorf("expected the after all hooks to run with a context that is not cancelled, got %v", teardown)
+	}
+	if err := report.Interrupted(); err != context.Canceled {
+		t.Errorf("expected the report to be interrupted, got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"github.com/qatoolist/RouTest/internal/interfaces"
+)
+
+// Attempt records a single try of sending a request, including the ones that were retried.
+type Attempt struct {
+	number     int
+	statusCode int
+	status     string
+	body       []byte
+	err        error
+	duration   time.Duration
+	wait       time.Duration
+	timing     interfaces.Timing
+}
+
+// Number returns the 1-based number of the attempt.
+func (a *Attempt) Number() int {
+	return a.number
+}
+
+// StatusCode returns the status code of the attempt, or 0 if no response was received.
+func (a *Attempt) StatusCode() int {
+	return a.statusCode
+}
+
+// Status returns the status message of the attempt.
+func (a *Attempt) Status() string {
+	return a.status
+}
+
+// Body returns the response body received by the attempt.
+func (a *Attempt) Body() []byte {
+	return a.body
+}
+
+// Err returns the error the attempt failed with, if any.
+func (a *Attempt) Err() error {
+	return a.err
+}
+
+// Duration returns how long the attempt took.
+func (a *Attempt) Duration() time.Duration {
+	return a.duration
+}
+
+// Wait returns the backoff waited after the attempt before the next one.
+func (a *Attempt) Wait() time.Duration {
+	return a.wait
+}
+
+// Timing returns the timing breakdown of the attempt.
+func (a *Attempt) Timing() interfaces.Timing {
+	return a.timing
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/qatoolist/RouTest/internal/interfaces"
 	"github.com/qatoolist/RouTest/internal/loaders"
+	"gopkg.in/yaml.v3"
 )
 
 type ConfigImpl struct {
@@ -76,6 +77,10 @@ func (c *ConfigImpl) GetHost() (interfaces.Host, error) {
 	}
 
 	port, ok := hostValues["port"].(int)
+	if number, isFloat := hostValues["port"].(float64); isFloat {
+		// JSON configurations decode every number as a float64
+		port, ok = int(number), number == float64(int(number))
+	}
 	if !ok {
 		return nil, fmt.Errorf("invalid host configuration: port must be a number")
 	}
@@ -83,6 +88,25 @@ func (c *ConfigImpl) GetHost() (interfaces.Host, error) {
 	return NewHost(protocol, hostname, port), nil
 }
 
+// GetRetryPolicy returns the retry policy configured under the "retry" key, or nil if none is configured.
+func (c *ConfigImpl) GetRetryPolicy() (interfaces.RetryPolicy, error) {
+	retry, err := c.Get("retry")
+	if err != nil {
+		return nil, nil
+	}
+
+	data, err := yaml.Marshal(retry)
+	if err != nil {
+		return nil, fmt.Errorf("invalid retry configuration: %v", err)
+	}
+
+	policy, err := NewRetryPolicyFromString(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid retry configuration: %v", err)
+	}
+	return policy, nil
+}
+
 func (c *ConfigImpl) CopyFromTemp(cnf *loaders.Config) interfaces.Config {
 	c.Lock()
 	defer c.Unlock()
@@ -92,8 +116,33 @@ func (c *ConfigImpl) CopyFromTemp(cnf *loaders.Config) interfaces.Config {
 		temp[key] = value
 	}
 	for key, value := range temp {
-		c.config[key] = value
+		c.config[key] = plainValue(value)
 	}
 
 	return c
 }
+
+// plainValue converts the nested loaders.Config maps decoded from YAML into plain maps, so that they can be traversed by Get.
+func plainValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case loaders.Config:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[key] = plainValue(value)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[key] = plainValue(value)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, value := range v {
+			s[i] = plainValue(value)
+		}
+		return s
+	}
+	return value
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"context"
+
+	"github.com/qatoolist/RouTest/internal/interfaces"
+)
+
+// scenarioKey is the context key of the scenario being executed.
+type scenarioKey struct{}
+
+// ContextWithScenario returns a copy of the context carrying the scenario being executed,
+// so that the transport of the HTTP client can tell which scenario a request belongs to.
+func ContextWithScenario(ctx context.Context, scenario interfaces.Scenario) context.Context {
+	return context.WithValue(ctx, scenarioKey{}, scenario)
+}
+
+// ScenarioFromContext returns the scenario carried by the context, if any.
+func ScenarioFromContext(ctx context.Context) (interfaces.Scenario, bool) {
+	scenario, ok := ctx.Value(scenarioKey{}).(interfaces.Scenario)
+	return scenario, ok
+}
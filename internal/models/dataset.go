@@ -0,0 +1,116 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/qatoolist/RouTest/internal/interfaces"
+	"github.com/qatoolist/RouTest/internal/loaders"
+)
+
+// Dataset is a table of rows a data-driven scenario is executed with, once per row.
+type Dataset struct {
+	rows []loaders.DataRow
+
+	// NameColumn is the column naming the test case of every row.
+	NameColumn string
+
+	// TagsColumn is the column holding the comma-separated tags of every row.
+	TagsColumn string
+}
+
+// NewDataset creates a new Dataset from the given rows.
+// Empty column names default to "name" and "tags".
+func NewDataset(rows []loaders.DataRow, nameColumn string, tagsColumn string) interfaces.Dataset {
+	if nameColumn == "" {
+		nameColumn = "name"
+	}
+	if tagsColumn == "" {
+		tagsColumn = "tags"
+	}
+	return &Dataset{
+		rows:       rows,
+		NameColumn: nameColumn,
+		TagsColumn: tagsColumn,
+	}
+}
+
+// NewDatasetFromFile loads a Dataset from a CSV, JSON or YAML file.
+func NewDatasetFromFile(path string, nameColumn string, tagsColumn string) (interfaces.Dataset, error) {
+	rows, err := loaders.LoadDataset(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewDataset(rows, nameColumn, tagsColumn), nil
+}
+
+// Len returns the number of rows.
+func (d *Dataset) Len() int {
+	return len(d.rows)
+}
+
+// Row returns the values of the row at the given index, by column name.
+func (d *Dataset) Row(i int) map[string]string {
+	return d.rows[i]
+}
+
+// RowName returns the value of the name column of the row, or "row <n>" if the row has none.
+func (d *Dataset) RowName(i int) string {
+	if name := d.rows[i][d.NameColumn]; name != "" {
+		return name
+	}
+	return fmt.Sprintf("row %d", i+1)
+}
+
+// RowTags returns the value of the tags column of the row.
+func (d *Dataset) RowTags(i int) string {
+	return d.rows[i][d.TagsColumn]
+}
+
+// DataRowScenario is the execution of a data-driven scenario with a single row of its dataset.
+// It shares everything with the scenario but its name, its data row and the tags of the row.
+type DataRowScenario struct {
+	interfaces.Scenario
+
+	name string
+	row  map[string]string
+	meta interfaces.Meta
+}
+
+// NewDataRowScenario creates the execution of the scenario with the row of its dataset at the given index.
+func NewDataRowScenario(scenario interfaces.Scenario, i int) interfaces.Scenario {
+	dataset := scenario.GetDataset()
+
+	var meta interfaces.Meta
+	if m := scenario.GetMeta(); m != nil && *m != nil {
+		meta = (*m).Copy()
+		if copy, ok := meta.(*Meta); ok && dataset.RowTags(i) != "" {
+			if copy.Tags != "" {
+				copy.Tags = copy.Tags + "," + dataset.RowTags(i)
+			} else {
+				copy.Tags = dataset.RowTags(i)
+			}
+		}
+	}
+
+	return &DataRowScenario{
+		Scenario: scenario,
+		name:     fmt.Sprintf("%s [%s]", scenario.GetName(), dataset.RowName(i)),
+		row:      dataset.Row(i),
+		meta:     meta,
+	}
+}
+
+// GetName returns the name of the scenario followed by the name of the row.
+func (s *DataRowScenario) GetName() string {
+	return s.name
+}
+
+// GetMeta returns the meta of the scenario with the tags of the row appended.
+func (s *DataRowScenario) GetMeta() *interfaces.Meta {
+	return &s.meta
+}
+
+// GetDataRow returns the row the scenario is executed with.
+func (s *DataRowScenario) GetDataRow() map[string]string {
+	return s.row
+}
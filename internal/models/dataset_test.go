@@ -0,0 +1,122 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/qatoolist/RouTest/internal/interfaces"
+	"github.com/qatoolist/RouTest/internal/loaders"
+)
+
+// TestDataRowScenario checks the names and tags of the executions of a data-driven scenario.
+func TestDataRowScenario(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	_, scenario := newTestScenario(t, server, GET)
+	scenario.(*Scenario).Meta = &Meta{AutomationStatus: Automated, Importance: High, Tags: "regression"}
+
+	tests := []struct {
+		name       string
+		dataset    *Dataset
+		wantNames  []string
+		wantTagsOf []string
+	}{
+		{
+			name:       "default columns",
+			dataset:    NewDataset([]loaders.DataRow{{"name": "admin", "tags": "smoke"}, {"id": "2"}}, "", "").(*Dataset),
+			wantNames:  []string{"job [admin]", "job [row 2]"},
+			wantTagsOf: []string{"regression,smoke", "regression"},
+		},
+		{
+			name:       "named columns",
+			dataset:    NewDataset([]loaders.DataRow{{"case": "guest", "labels": "a,b", "name": "ignored"}}, "case", "labels").(*Dataset),
+			wantNames:  []string{"job [guest]"},
+			wantTagsOf: []string{"regression,a,b"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			scenario.SetDataset(test.dataset)
+			for i := 0; i < test.dataset.Len(); i++ {
+				row := NewDataRowScenario(scenario, i)
+				if row.GetName() != test.wantNames[i] {
+					t.Errorf("row %d: expected name %q, got %q", i, test.wantNames[i], row.GetName())
+				}
+				if tags := (*row.GetMeta()).GetTags(); tags != test.wantTagsOf[i] {
+					t.Errorf("row %d: expected tags %q, got %q", i, test.wantTagsOf[i], tags)
+				}
+				if fmt.Sprint(row.GetDataRow()) != fmt.Sprint(test.dataset.Row(i)) {
+					t.Errorf("row %d: expected the values of the row, got %v", i, row.GetDataRow())
+				}
+			}
+		})
+	}
+	if tags := (*scenario.GetMeta()).GetTags(); tags != "regression" {
+		t.Errorf("expected the meta of the scenario to be left unchanged, got %q", tags)
+	}
+}
+
+// TestRunDataset checks that a data-driven scenario is executed once per row, with the {{column}} references
+// of its path, query, headers and body replaced by the values of the row, and reported once per row.
+func TestRunDataset(t *testing.T) {
+	requests := make(chan string, 3)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests <- fmt.Sprintf("%s %s %s %s", r.URL.Path, r.URL.RawQuery, r.Header.Get("X-Role"), body)
+		if r.Header.Get("X-Role") == "guest" {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	app, _ := newTestScenario(t, server, GET)
+	route := app.NewRoute(&Info{name: "users", path: "/users/{{id}}", method: PUT}, testMeta)
+	app.AddRoute("users", &route)
+	route.SetBody([]byte(`{"name": "{{name}}", "team": "{{team}}"}`))
+	scenario := route.NewScenario(&Info{name: "update"}, testMeta)
+	scenario.GetScenarioParametersRegistry().RegisterQueryParameter("notify", "{{ notify }}")
+	scenario.GetScenarioParametersRegistry().RegisterHeader("X-Role", "{{role}}")
+	app.GetVariables().Set("team", "core")
+	scenario.SetDataset(NewDataset([]loaders.DataRow{
+		{"name": "admin", "id": "1", "role": "admin", "notify": "true"},
+		{"name": "guest", "id": "2", "role": "guest", "notify": "false", "team": "web"},
+	}, "", ""))
+	scenario.GetScenarioHooksRegistry().RegisterResponseHook(func(hc interfaces.HookContext) error {
+		if hc.Response().GetStatusCode() != http.StatusOK {
+			return fmt.Errorf("status %d", hc.Response().GetStatusCode())
+		}
+		return nil
+	})
+
+	report := NewReport()
+	route.GetScenarioRegistry().Run(context.Background(), report)
+	close(requests)
+
+	var got []string
+	for request := range requests {
+		got = append(got, request)
+	}
+	want := []string{
+		`/users/1 notify=true admin {"name": "admin", "team": "core"}`,
+		`/users/2 notify=false guest {"name": "guest", "team": "web"}`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected the requests:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+
+	results := report.Results()
+	if len(results) != 2 {
+		t.Fatalf("expected a result per row, got %d", len(results))
+	}
+	if results[0].ScenarioName() != "update [admin]" || results[0].Err() != nil {
+		t.Errorf("expected the admin row to pass, got %s: %v", results[0].ScenarioName(), results[0].Err())
+	}
+	if results[1].ScenarioName() != "update [guest]" || results[1].Err() == nil {
+		t.Errorf("expected the guest row to fail on its own, got %s: %v", results[1].ScenarioName(), results[1].Err())
+	}
+}
@@ -0,0 +1,14 @@
+package models
+
+import "fmt"
+
+// safely calls fn and turns a panic into an error, so that a panicking hook or scenario
+// does not prevent the teardown hooks from running.
+func safely(fn func() (err error)) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return fn()
+}
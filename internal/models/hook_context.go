@@ -0,0 +1,155 @@
+package models
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/qatoolist/RouTest/internal/interfaces"
+)
+
+// HookContext is the execution context given to the Request and Response Hooks of a scenario.
+type HookContext struct {
+	context.Context
+
+	scenario     interfaces.Scenario
+	meta         interfaces.Meta
+	request      *http.Request
+	response     interfaces.Response
+	start        time.Time
+	responseTime time.Duration
+	variables    interfaces.Variables
+	logger       *log.Logger
+}
+
+// NewHookContext creates the HookContext of an execution of the scenario starting now.
+func NewHookContext(ctx context.Context, scenario interfaces.Scenario) *HookContext {
+	hc := &HookContext{
+		Context:  ctx,
+		scenario: scenario,
+		meta:     EffectiveMeta(scenario),
+		start:    time.Now(),
+	}
+
+	prefix := "[" + scenario.GetName() + "] "
+	logger := log.Default()
+	if route := scenario.GetParentRoute(); route != nil {
+		prefix = "[" + route.GetName() + "/" + scenario.GetName() + "] "
+		if app := route.GetParentApplication(); app != nil {
+			hc.variables = app.GetVariables()
+			logger = app.GetLogger()
+		}
+	}
+	if hc.variables == nil {
+		hc.variables = NewVariableStore()
+	}
+	hc.logger = log.New(logger.Writer(), prefix, logger.Flags())
+
+	return hc
+}
+
+// Scenario returns the scenario being executed.
+func (hc *HookContext) Scenario() interfaces.Scenario {
+	return hc.scenario
+}
+
+// Meta returns the effective meta of the scenario.
+func (hc *HookContext) Meta() interfaces.Meta {
+	return hc.meta
+}
+
+// Request returns the outgoing HTTP request.
+func (hc *HookContext) Request() *http.Request {
+	return hc.request
+}
+
+// SetRequest replaces the outgoing HTTP request.
+func (hc *HookContext) SetRequest(req *http.Request) {
+	hc.request = req
+}
+
+// Response returns the received response, or nil before the request is sent.
+func (hc *HookContext) Response() interfaces.Response {
+	return hc.response
+}
+
+// SetResponse replaces the received response.
+func (hc *HookContext) SetResponse(resp interfaces.Response) {
+	hc.response = resp
+}
+
+// StartTime returns the time the execution of the scenario started.
+func (hc *HookContext) StartTime() time.Time {
+	return hc.start
+}
+
+// Elapsed returns the time since the execution of the scenario started.
+func (hc *HookContext) Elapsed() time.Duration {
+	return time.Since(hc.start)
+}
+
+// ResponseTime returns the time between sending the request and receiving the response, including retries.
+func (hc *HookContext) ResponseTime() time.Duration {
+	return hc.responseTime
+}
+
+// Variables returns the store of values captured during the run.
+func (hc *HookContext) Variables() interfaces.Variables {
+	return hc.variables
+}
+
+// Expand replaces the {{name}} references in s with the values of the data row of the scenario
+// or, failing that, with the captured variables.
+func (hc *HookContext) Expand(s string) string {
+	row := hc.scenario.GetDataRow()
+	return ExpandTemplate(s, func(name string) (string, bool) {
+		if value, ok := row[name]; ok {
+			return value, true
+		}
+		return hc.variables.Get(name)
+	})
+}
+
+// Logger returns a logger whose lines are prefixed with the route and scenario names.
+func (hc *HookContext) Logger() *log.Logger {
+	return hc.logger
+}
+
+// IsNegative reports whether the effective meta of the scenario marks it as negative,
+// i.e. whether it sends an invalid request and expects it to be rejected.
+func IsNegative(scenario interfaces.Scenario) bool {
+	if scenario == nil {
+		return false
+	}
+	meta, ok := EffectiveMeta(scenario).(*Meta)
+	return ok && meta.Negative
+}
+
+// EffectiveMeta returns the meta of the scenario merged over the metas of its route and application.
+// Fields set at the innermost level win and the tags of every level are combined.
+func EffectiveMeta(scenario interfaces.Scenario) interfaces.Meta {
+	var metas []interfaces.Meta
+	if route := scenario.GetParentRoute(); route != nil {
+		if app := route.GetParentApplication(); app != nil {
+			metas = append(metas, app.GetMeta())
+		}
+		metas = append(metas, route.GetMeta())
+	}
+	if meta := scenario.GetMeta(); meta != nil {
+		metas = append(metas, *meta)
+	}
+
+	var effective interfaces.Meta
+	for _, meta := range metas {
+		if meta == nil {
+			continue
+		}
+		if effective == nil {
+			effective = meta.Copy()
+			continue
+		}
+		effective.OverrideMeta(meta)
+	}
+	return effective
+}
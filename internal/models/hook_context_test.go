@@ -0,0 +1,198 @@
+package models
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qatoolist/RouTest/internal/interfaces"
+)
+
+// TestEffectiveMeta checks that the scenario meta overrides the route and application ones and that tags are combined.
+func TestEffectiveMeta(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	app, scenario := newTestScenario(t, server, GET)
+	app.Meta = &Meta{AutomationStatus: Automated, Importance: High, Component: "cart", Tags: "app"}
+	route, _ := app.GetRouteByName("jobs")
+	route.(*Route).Meta = &Meta{AutomationStatus: Automated, Importance: High, Component: "cart", Tags: "jobs"}
+	scenario.(*Scenario).Meta = &Meta{AutomationStatus: Automated, Importance: Low, Component: "cart", Negative: true, Tags: "smoke"}
+
+	meta, ok := EffectiveMeta(scenario).(*Meta)
+	if !ok {
+		t.Fatalf("expected a *Meta, got %T", EffectiveMeta(scenario))
+	}
+	if meta.Importance != Low || !meta.Negative || meta.Component != "cart" {
+		t.Errorf("expected the fields of the scenario meta, got %+v", meta)
+	}
+	if meta.Tags != "app,jobs,smoke" {
+		t.Errorf("expected the tags of every level, got %q", meta.Tags)
+	}
+	if app.Meta.GetTags() != "app" {
+		t.Errorf("expected the application meta to be left unchanged, got %q", app.Meta.GetTags())
+	}
+}
+
+// TestHookContext checks the request, response, timing, variables and logger given to the Request and Response Hooks.
+func TestHookContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		fmt.Fprintf(w, `{"trace": %q}`, r.Header.Get("X-Trace"))
+	}))
+	defer server.Close()
+
+	app, scenario := newTestScenario(t, server, GET)
+	var logs bytes.Buffer
+	app.Logger = log.New(&logs, "", 0)
+
+	hooks := scenario.GetScenarioHooksRegistry()
+	hooks.RegisterRequestHook(func(hc interfaces.HookContext) error {
+		if hc.Response() != nil || hc.ResponseTime() != 0 {
+			t.Errorf("expected no response before the request is sent, got %v after %s", hc.Response(), hc.ResponseTime())
+		}
+		hc.Request().Header.Set("X-Trace", "t-1")
+		hc.Variables().Set("sent", "yes")
+		return nil
+	})
+	hooks.RegisterResponseHook(func(hc interfaces.HookContext) error {
+		if hc.ResponseTime() < 10*time.Millisecond || hc.Elapsed() < hc.ResponseTime() {
+			t.Errorf("expected the response time of the request within the elapsed time, got %s and %s", hc.ResponseTime(), hc.Elapsed())
+		}
+		if !strings.Contains(hc.Response().String(), `"t-1"`) {
+			t.Errorf("expected the response to the modified request, got %s", hc.Response().String())
+		}
+		hc.Logger().Print("received")
+		return nil
+	})
+
+	route, _ := app.GetRouteByName("jobs")
+	if _, err := route.GetScenarioRegistry().Execute(context.Background(), scenario); err != nil {
+		t.Fatal(err)
+	}
+	if sent, _ := app.GetVariables().Get("sent"); sent != "yes" {
+		t.Errorf("expected the variables of the application to be shared with the hooks, got %q", sent)
+	}
+	if logs.String() != "[jobs/job] received\n" {
+		t.Errorf("expected a line prefixed with the route and scenario names, got %q", logs.String())
+	}
+}
+
+// TestAdaptBeforeHook checks that an adapted BeforeHook receives the route of the scenario, that the route it
+// returns is ignored and that its error fails the scenario.
+func TestAdaptBeforeHook(t *testing.T) {
+	methods := make(chan string, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods <- r.Method
+	}))
+	defer server.Close()
+	app, scenario := newTestScenario(t, server, GET)
+	_, other := newTestScenario(t, server, POST)
+
+	var received interfaces.Route
+	scenario.GetScenarioHooksRegistry().RegisterRequestHook(AdaptBeforeHook(func(ctx context.Context, route interfaces.Route) (interfaces.Route, error) {
+		received = route
+		return other.GetParentRoute(), nil
+	}))
+	route, _ := app.GetRouteByName("jobs")
+	if _, err := route.GetScenarioRegistry().Execute(context.Background(), scenario); err != nil {
+		t.Fatal(err)
+	}
+	if received != route {
+		t.Errorf("expected the parent route of the scenario, got %v", received)
+	}
+	if len(methods) != 1 {
+		t.Fatalf("expected a single request, got %d", len(methods))
+	}
+	if method := <-methods; method != http.MethodGet {
+		t.Errorf("expected the request of the scenario to be sent, not the one of the returned route, got %s", method)
+	}
+
+	hc := NewHookContext(context.Background(), scenario)
+	failing := AdaptBeforeHook(func(ctx context.Context, route interfaces.Route) (interfaces.Route, error) {
+		return route, errors.New("no token")
+	})
+	if err := failing(hc); err == nil || err.Error() != "no token" {
+		t.Errorf("expected the error of the hook, got %v", err)
+	}
+}
+
+// TestAdaptAfterHook checks that the response returned by an adapted AfterHook replaces the response of the scenario,
+// unless it is nil.
+func TestAdaptAfterHook(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	_, scenario := newTestScenario(t, server, GET)
+
+	received := &Response{StatusCode: http.StatusOK}
+	replaced := &Response{StatusCode: http.StatusAccepted}
+	tests := []struct {
+		name     string
+		returned interfaces.Response
+		err      error
+		want     interfaces.Response
+	}{
+		{"replaced", replaced, nil, replaced},
+		{"nil response", nil, nil, received},
+		{"error", replaced, errors.New("bad body"), received},
+	}
+	for _, test := range tests {
+		hc := NewHookContext(context.Background(), scenario)
+		hc.SetResponse(received)
+		hook := AdaptAfterHook(func(ctx context.Context, resp interfaces.Response) (interfaces.Response, error) {
+			if resp != received {
+				t.Errorf("%s: expected the received response, got %v", test.name, resp)
+			}
+			return test.returned, test.err
+		})
+		if err := hook(hc); err != test.err {
+			t.Errorf("%s: expected error %v, got %v", test.name, test.err, err)
+		}
+		if hc.Response() != test.want {
+			t.Errorf("%s: expected response %v, got %v", test.name, test.want, hc.Response())
+		}
+	}
+}
+
+// TestIsNegative checks that a scenario is negative when its effective meta says so: the meta of the scenario,
+// or the one of its route when the scenario has none.
+func TestIsNegative(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	positive := &Meta{AutomationStatus: Automated, Importance: High}
+	negative := &Meta{AutomationStatus: Automated, Importance: High, Negative: true}
+	tests := []struct {
+		name     string
+		route    *Meta
+		scenario *Meta
+		want     bool
+	}{
+		{"positive", positive, nil, false},
+		{"negative route", negative, nil, true},
+		{"negative scenario", positive, negative, true},
+		{"positive scenario of a negative route", negative, positive, false},
+	}
+	for _, test := range tests {
+		app, scenario := newTestScenario(t, server, POST)
+		route, _ := app.GetRouteByName("jobs")
+		route.(*Route).Meta = test.route
+		scenario.(*Scenario).Meta = nil
+		if test.scenario != nil {
+			scenario.(*Scenario).Meta = test.scenario
+		}
+		if got := IsNegative(scenario); got != test.want {
+			t.Errorf("%s: expected %v, got %v", test.name, test.want, got)
+		}
+	}
+	if IsNegative(nil) {
+		t.Error("expected a nil scenario not to be negative")
+	}
+}
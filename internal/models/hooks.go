@@ -1,46 +1,112 @@
 package models
 
-import "github.com/qatoolist/RouTest/internal/interfaces"
+import (
+	"context"
+
+	"github.com/qatoolist/RouTest/internal/interfaces"
+)
 
-// BeforeHookFunc is a function type that wraps around BeforeHook and enforces its signature.
-type BeforeHookFunc func(*interfaces.Route) (*interfaces.Route, error)
+// BeforeHookFunc is a Before Hook that does not need the context of the run.
+// It is adapted to the BeforeHook signature with Hook.
+type BeforeHookFunc func(interfaces.Route) (interfaces.Route, error)
 
-// Execute method executes the BeforeHook function with the given Route as argument.
+// Execute method executes the BeforeHookFunc function with the given Route as argument.
 // It returns the modified Route object and any error occurred during the execution of BeforeHook.
-func (bh BeforeHookFunc) Execute(route *interfaces.Route) (*interfaces.Route, error) {
+func (bh BeforeHookFunc) Execute(route interfaces.Route) (interfaces.Route, error) {
 	return bh(route)
 }
 
-// AfterHookFunc is a function type that wraps around AfterHook and enforces its signature.
-type AfterHookFunc func(*interfaces.Response) (*interfaces.Response, error)
+// Hook adapts the function to a BeforeHook that ignores the context.
+func (bh BeforeHookFunc) Hook() interfaces.BeforeHook {
+	return func(_ context.Context, route interfaces.Route) (interfaces.Route, error) {
+		return bh(route)
+	}
+}
+
+// AfterHookFunc is an After Hook that does not need the context of the run.
+// It is adapted to the AfterHook signature with Hook.
+type AfterHookFunc func(interfaces.Response) (interfaces.Response, error)
 
-// Execute executes the AfterHook with the given Response as argument.
-func (ah AfterHookFunc) Execute(resp *interfaces.Response) (*interfaces.Response, error) {
+// Execute executes the AfterHookFunc with the given Response as argument.
+func (ah AfterHookFunc) Execute(resp interfaces.Response) (interfaces.Response, error) {
 	return ah(resp)
 }
 
+// Hook adapts the function to an AfterHook that ignores the context.
+func (ah AfterHookFunc) Hook() interfaces.AfterHook {
+	return func(_ context.Context, resp interfaces.Response) (interfaces.Response, error) {
+		return ah(resp)
+	}
+}
+
+// AdaptBeforeHook adapts a BeforeHook to a Request Hook.
+// The hook receives the parent route of the scenario; the route it returns is ignored,
+// since the request was already built from it. A hook that changes the request must be
+// written as a Request Hook and modify HookContext.Request instead.
+func AdaptBeforeHook(hook interfaces.BeforeHook) interfaces.ContextHook {
+	return func(hc interfaces.HookContext) error {
+		_, err := hook(hc, hc.Scenario().GetParentRoute())
+		return err
+	}
+}
+
+// AdaptAfterHook adapts an AfterHook to a Response Hook.
+// The response returned by the hook replaces the response of the scenario.
+func AdaptAfterHook(hook interfaces.AfterHook) interfaces.ContextHook {
+	return func(hc interfaces.HookContext) error {
+		resp, err := hook(hc, hc.Response())
+		if err != nil {
+			return err
+		}
+		if resp != nil {
+			hc.SetResponse(resp)
+		}
+		return nil
+	}
+}
+
 /* Example usage -
 
 package mypackage
 
 import (
-    "path/to/types"
+    "path/to/models"
     "path/to/interfaces"
 )
 
 func main() {
-    bhFunc := types.BeforeHookFunc(myBeforeHook)
-    route := &interfaces.Route{}
-    modifiedRoute, err := bhFunc.Execute(route)
-    if err != nil {
-        // Handle error
-    }
-    // Use modifiedRoute
+    app := routest.NewApplication(meta)
+
+    // Hooks written before the context was introduced are adapted with Hook
+    app.GetApplicationHooksRegistry().RegisterBeforeHook(models.BeforeHookFunc(myBeforeHook).Hook())
+
+    // Hooks that make their own calls should honour the context
+    app.GetApplicationHooksRegistry().RegisterBeforeHook(myContextBeforeHook)
 }
 
-func myBeforeHook(route *interfaces.Route) (*interfaces.Route, error) {
+func myBeforeHook(route interfaces.Route) (interfaces.Route, error) {
     // Implement BeforeHook logic
     return route, nil
 }
 
+func myContextBeforeHook(ctx context.Context, route interfaces.Route) (interfaces.Route, error) {
+    req, err := http.NewRequestWithContext(ctx, "POST", tokenURL, nil)
+    // ...
+    return route, nil
+}
+
+// Hooks that need the outgoing request, the response or the captured variables use the HookContext
+app.GetApplicationHooksRegistry().RegisterRequestHook(func(hc interfaces.HookContext) error {
+    token, _ := hc.Variables().Get("token")
+    hc.Request().Header.Set("Authorization", "Bearer "+token)
+    return nil
+})
+app.GetApplicationHooksRegistry().RegisterResponseHook(func(hc interfaces.HookContext) error {
+    hc.Logger().Printf("%s %s took %s", hc.Request().Method, hc.Request().URL, hc.ResponseTime())
+    return nil
+})
+
+// The older hook types can be registered as context hooks too
+app.GetApplicationHooksRegistry().RegisterResponseHook(models.AdaptAfterHook(myAfterHook))
+
 */
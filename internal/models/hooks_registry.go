@@ -1,6 +1,8 @@
 package models
 
 import (
+	"context"
+	"errors"
 	"sync"
 
 	"github.com/qatoolist/RouTest/internal/interfaces"
@@ -8,17 +10,31 @@ import (
 
 func NewHooksRegistry() interfaces.HooksRegistry {
 	return &HooksRegistryImpl{
-		beforeHooks: make([]interfaces.BeforeHook, 0),
-		afterHooks:  make([]interfaces.AfterHook, 0),
-		mutex:       &sync.RWMutex{},
+		beforeHooks:     make([]interfaces.BeforeHook, 0),
+		afterHooks:      make([]interfaces.AfterHook, 0),
+		requestHooks:    make([]interfaces.ContextHook, 0),
+		responseHooks:   make([]interfaces.ContextHook, 0),
+		beforeAllHooks:  make([]interfaces.LifecycleHook, 0),
+		afterAllHooks:   make([]interfaces.LifecycleHook, 0),
+		beforeEachHooks: make([]interfaces.EachHook, 0),
+		afterEachHooks:  make([]interfaces.EachHook, 0),
+		aroundHooks:     make([]interfaces.AroundHook, 0),
+		mutex:           &sync.RWMutex{},
 	}
 }
 
 // HooksRegistry holds a list of hooks that can be executed in a specific order.
 type HooksRegistryImpl struct {
-	beforeHooks []interfaces.BeforeHook
-	afterHooks  []interfaces.AfterHook
-	mutex       *sync.RWMutex
+	beforeHooks     []interfaces.BeforeHook
+	afterHooks      []interfaces.AfterHook
+	requestHooks    []interfaces.ContextHook
+	responseHooks   []interfaces.ContextHook
+	beforeAllHooks  []interfaces.LifecycleHook
+	afterAllHooks   []interfaces.LifecycleHook
+	beforeEachHooks []interfaces.EachHook
+	afterEachHooks  []interfaces.EachHook
+	aroundHooks     []interfaces.AroundHook
+	mutex           *sync.RWMutex
 }
 
 // RegisterBeforeHook registers a new BeforeHook function to the hooks registry.
@@ -36,12 +52,16 @@ func (hr *HooksRegistryImpl) RegisterAfterHook(hook interfaces.AfterHook) {
 }
 
 // RunBeforeHooks executes all the registered BeforeHook functions in the order they were added.
-func (hr *HooksRegistryImpl) RunBeforeHooks(route interfaces.Route) (interfaces.Route, error) {
+// It stops with the error of the context once the context is done.
+func (hr *HooksRegistryImpl) RunBeforeHooks(ctx context.Context, route interfaces.Route) (interfaces.Route, error) {
 	hr.mutex.RLock()
 	defer hr.mutex.RUnlock()
 	for _, hook := range hr.beforeHooks {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		var err error
-		route, err = hook(route)
+		route, err = hook(ctx, route)
 		if err != nil {
 			return nil, err
 		}
@@ -50,15 +70,165 @@ func (hr *HooksRegistryImpl) RunBeforeHooks(route interfaces.Route) (interfaces.
 }
 
 // RunAfterHooks executes all the registered AfterHook functions in the order they were added.
-func (hr *HooksRegistryImpl) RunAfterHooks(resp interfaces.Response) (interfaces.Response, error) {
+// It stops with the error of the context once the context is done.
+func (hr *HooksRegistryImpl) RunAfterHooks(ctx context.Context, resp interfaces.Response) (interfaces.Response, error) {
 	hr.mutex.RLock()
 	defer hr.mutex.RUnlock()
 	for _, hook := range hr.afterHooks {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		var err error
-		resp, err = hook(resp)
+		resp, err = hook(ctx, resp)
 		if err != nil {
 			return nil, err
 		}
 	}
 	return resp, nil
 }
+
+// RegisterRequestHook registers a hook run on the outgoing request of every scenario in the scope of the registry.
+func (hr *HooksRegistryImpl) RegisterRequestHook(hook interfaces.ContextHook) {
+	hr.mutex.Lock()
+	defer hr.mutex.Unlock()
+	hr.requestHooks = append(hr.requestHooks, hook)
+}
+
+// RegisterResponseHook registers a hook run on the response of every scenario in the scope of the registry.
+func (hr *HooksRegistryImpl) RegisterResponseHook(hook interfaces.ContextHook) {
+	hr.mutex.Lock()
+	defer hr.mutex.Unlock()
+	hr.responseHooks = append(hr.responseHooks, hook)
+}
+
+// RunRequestHooks executes all the registered Request Hooks in the order they were added.
+func (hr *HooksRegistryImpl) RunRequestHooks(hc interfaces.HookContext) error {
+	hr.mutex.RLock()
+	defer hr.mutex.RUnlock()
+	return runContextHooks(hc, hr.requestHooks)
+}
+
+// RunResponseHooks executes all the registered Response Hooks in the order they were added.
+func (hr *HooksRegistryImpl) RunResponseHooks(hc interfaces.HookContext) error {
+	hr.mutex.RLock()
+	defer hr.mutex.RUnlock()
+	return runContextHooks(hc, hr.responseHooks)
+}
+
+func runContextHooks(hc interfaces.HookContext, hooks []interfaces.ContextHook) error {
+	for _, hook := range hooks {
+		if err := hc.Err(); err != nil {
+			return err
+		}
+		if err := hook(hc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RegisterBeforeAllHook registers a hook run once before the scenarios in the scope of the registry.
+func (hr *HooksRegistryImpl) RegisterBeforeAllHook(hook interfaces.LifecycleHook) {
+	hr.mutex.Lock()
+	defer hr.mutex.Unlock()
+	hr.beforeAllHooks = append(hr.beforeAllHooks, hook)
+}
+
+// RegisterAfterAllHook registers a hook run once after the scenarios in the scope of the registry.
+func (hr *HooksRegistryImpl) RegisterAfterAllHook(hook interfaces.LifecycleHook) {
+	hr.mutex.Lock()
+	defer hr.mutex.Unlock()
+	hr.afterAllHooks = append(hr.afterAllHooks, hook)
+}
+
+// RegisterBeforeEachHook registers a hook run before every scenario in the scope of the registry.
+func (hr *HooksRegistryImpl) RegisterBeforeEachHook(hook interfaces.EachHook) {
+	hr.mutex.Lock()
+	defer hr.mutex.Unlock()
+	hr.beforeEachHooks = append(hr.beforeEachHooks, hook)
+}
+
+// RegisterAfterEachHook registers a hook run after every scenario in the scope of the registry.
+func (hr *HooksRegistryImpl) RegisterAfterEachHook(hook interfaces.EachHook) {
+	hr.mutex.Lock()
+	defer hr.mutex.Unlock()
+	hr.afterEachHooks = append(hr.afterEachHooks, hook)
+}
+
+// RegisterAroundHook registers a hook wrapping the execution of every scenario in the scope of the registry.
+func (hr *HooksRegistryImpl) RegisterAroundHook(hook interfaces.AroundHook) {
+	hr.mutex.Lock()
+	defer hr.mutex.Unlock()
+	hr.aroundHooks = append(hr.aroundHooks, hook)
+}
+
+// RunBeforeAllHooks executes the BeforeAll hooks in the order they were added and stops at the first failure.
+func (hr *HooksRegistryImpl) RunBeforeAllHooks(ctx context.Context) error {
+	hr.mutex.RLock()
+	defer hr.mutex.RUnlock()
+	for _, hook := range hr.beforeAllHooks {
+		hook := hook
+		if err := safely(func() error { return hook(ctx) }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunAfterAllHooks executes every AfterAll hook in the reverse order they were added,
+// even when some of them fail or panic, and returns their errors combined.
+func (hr *HooksRegistryImpl) RunAfterAllHooks(ctx context.Context) error {
+	hr.mutex.RLock()
+	defer hr.mutex.RUnlock()
+	var errs []error
+	for i := len(hr.afterAllHooks) - 1; i >= 0; i-- {
+		hook := hr.afterAllHooks[i]
+		if err := safely(func() error { return hook(ctx) }); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RunBeforeEachHooks executes the BeforeEach hooks in the order they were added and stops at the first failure.
+func (hr *HooksRegistryImpl) RunBeforeEachHooks(ctx context.Context, scenario interfaces.Scenario) error {
+	hr.mutex.RLock()
+	defer hr.mutex.RUnlock()
+	for _, hook := range hr.beforeEachHooks {
+		hook := hook
+		if err := safely(func() error { return hook(ctx, scenario) }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunAfterEachHooks executes every AfterEach hook in the reverse order they were added,
+// even when some of them fail or panic, and returns their errors combined.
+func (hr *HooksRegistryImpl) RunAfterEachHooks(ctx context.Context, scenario interfaces.Scenario) error {
+	hr.mutex.RLock()
+	defer hr.mutex.RUnlock()
+	var errs []error
+	for i := len(hr.afterEachHooks) - 1; i >= 0; i-- {
+		hook := hr.afterEachHooks[i]
+		if err := safely(func() error { return hook(ctx, scenario) }); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RunAroundHooks executes next wrapped in the Around hooks, the first hook added being the outermost one.
+func (hr *HooksRegistryImpl) RunAroundHooks(ctx context.Context, scenario interfaces.Scenario, next func(context.Context) error) error {
+	hr.mutex.RLock()
+	hooks := hr.aroundHooks
+	hr.mutex.RUnlock()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hook, inner := hooks[i], next
+		next = func(ctx context.Context) error {
+			return safely(func() error { return hook(ctx, scenario, inner) })
+		}
+	}
+	return next(ctx)
+}
@@ -0,0 +1,161 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/qatoolist/RouTest/internal/interfaces"
+)
+
+// teardownTimeout bounds the AfterAll and AfterEach hooks that run after the run was cancelled.
+const teardownTimeout = 30 * time.Second
+
+// detachedContext keeps the values of its parent but is never cancelled with it.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+
+func (d detachedContext) Value(key interface{}) interface{} {
+	return d.parent.Value(key)
+}
+
+// teardownContext returns the context the teardown hooks run with.
+// Once the run was cancelled or timed out the teardown still gets teardownTimeout to clean up.
+func teardownContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if ctx.Err() == nil {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(detachedContext{parent: ctx}, teardownTimeout)
+}
+
+// lifecycleRegistries returns the hooks registries that apply to the scenario,
+// from the outermost application registry to the innermost scenario registry.
+func lifecycleRegistries(scenario interfaces.Scenario) []interfaces.HooksRegistry {
+	var registries []interfaces.HooksRegistry
+	if route := scenario.GetParentRoute(); route != nil {
+		if app := route.GetParentApplication(); app != nil {
+			registries = append(registries, app.GetApplicationHooksRegistry())
+		}
+		registries = append(registries, route.GetRouteHooksRegistry())
+	}
+	return append(registries, scenario.GetScenarioHooksRegistry())
+}
+
+// skipScenarios reports the scenarios as failed with the given error without executing them.
+func skipScenarios(report interfaces.Report, scenarios []interfaces.Scenario, err error) {
+	for _, scenario := range scenarios {
+		report.AddResult(NewScenarioResult(scenario, nil, err, 0))
+	}
+}
+
+// runScenario executes the scenario between its own BeforeAll and AfterAll hooks and adds its result to the report.
+// A data-driven scenario is executed once per row of its dataset, every row being reported as its own result.
+func (sr *ScenarioRegistryImpl) runScenario(ctx context.Context, scenario interfaces.Scenario, report interfaces.Report) {
+	if scenario.GetDataset() != nil {
+		sr.runDataset(ctx, scenario, report)
+		return
+	}
+
+	start := time.Now()
+	hooks := scenario.GetScenarioHooksRegistry()
+
+	var response interfaces.Response
+	err := hooks.RunBeforeAllHooks(ctx)
+	if err != nil {
+		err = fmt.Errorf("before all hooks failed: %v", err)
+	} else {
+		response, err = sr.runEach(ctx, scenario)
+	}
+
+	tctx, cancel := teardownContext(ctx)
+	defer cancel()
+	if teardownErr := hooks.RunAfterAllHooks(tctx); teardownErr != nil {
+		err = errors.Join(err, fmt.Errorf("after all hooks failed: %v", teardownErr))
+	}
+
+	if response == nil {
+		response = scenario.GetResponse()
+	}
+	report.AddResult(NewScenarioResult(scenario, response, err, time.Since(start)))
+}
+
+// runDataset executes a data-driven scenario once per row of its dataset, between the BeforeAll and AfterAll hooks
+// of the scenario, and adds the result of every row to the report.
+func (sr *ScenarioRegistryImpl) runDataset(ctx context.Context, scenario interfaces.Scenario, report interfaces.Report) {
+	dataset := scenario.GetDataset()
+	rows := make([]interfaces.Scenario, 0, dataset.Len())
+	for i := 0; i < dataset.Len(); i++ {
+		rows = append(rows, NewDataRowScenario(scenario, i))
+	}
+
+	hooks := scenario.GetScenarioHooksRegistry()
+	if err := hooks.RunBeforeAllHooks(ctx); err != nil {
+		skipScenarios(report, rows, fmt.Errorf("before all hooks failed: %v", err))
+	} else {
+		for _, row := range rows {
+			if ctx.Err() != nil {
+				break
+			}
+			start := time.Now()
+			response, err := sr.runEach(ctx, row)
+			report.AddResult(NewScenarioResult(row, response, err, time.Since(start)))
+		}
+	}
+
+	tctx, cancel := teardownContext(ctx)
+	defer cancel()
+	if err := hooks.RunAfterAllHooks(tctx); err != nil {
+		report.AddError(fmt.Errorf("after all hooks of scenario %s failed: %v", scenario.GetName(), err))
+	}
+}
+
+// runEach executes the scenario once, wrapped in the BeforeEach, Around and AfterEach hooks of the application,
+// route and scenario. The AfterEach hooks of every level whose BeforeEach hooks ran are always executed,
+// even when a hook or the scenario fails or panics.
+func (sr *ScenarioRegistryImpl) runEach(ctx context.Context, scenario interfaces.Scenario) (interfaces.Response, error) {
+	registries := lifecycleRegistries(scenario)
+
+	var err error
+	ran := 0
+	for _, registry := range registries {
+		ran++
+		if err = registry.RunBeforeEachHooks(ctx, scenario); err != nil {
+			err = fmt.Errorf("before each hooks failed: %v", err)
+			break
+		}
+	}
+
+	var response interfaces.Response
+	if err == nil {
+		execute := func(ctx context.Context) error {
+			return safely(func() error {
+				var err error
+				response, err = sr.Execute(ctx, scenario)
+				return err
+			})
+		}
+		for i := len(registries) - 1; i >= 0; i-- {
+			registry, inner := registries[i], execute
+			execute = func(ctx context.Context) error {
+				return registry.RunAroundHooks(ctx, scenario, inner)
+			}
+		}
+		err = execute(ctx)
+	}
+
+	tctx, cancel := teardownContext(ctx)
+	defer cancel()
+	for i := ran - 1; i >= 0; i-- {
+		if teardownErr := registries[i].RunAfterEachHooks(tctx, scenario); teardownErr != nil {
+			err = errors.Join(err, fmt.Errorf("after each hooks failed: %v", teardownErr))
+		}
+	}
+
+	return response, err
+}
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/qatoolist/RouTest/internal/interfaces"
+)
+
+// TestLifecycleTeardown checks that the AfterEach and AfterAll hooks run, with a context that is not cancelled,
+// after a panicking hook, a failing request and a cancelled run.
+func TestLifecycleTeardown(t *testing.T) {
+	tests := []struct {
+		name    string
+		panicIn string
+		closed  bool
+		cancel  bool
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "panicking before each hook",
+			panicIn: "scenario before each",
+			want:    "app before all, scenario before all, app before each, scenario before each, scenario after each, app after each, scenario after all, app after all",
+			wantErr: "before each hooks failed: panic: boom",
+		},
+		{
+			name:    "panicking after each hook",
+			panicIn: "scenario after each",
+			want:    "app before all, scenario before all, app before each, scenario before each, execute, scenario after each, app after each, scenario after all, app after all",
+			wantErr: "after each hooks failed: panic: boom",
+		},
+		{
+			name:    "failing request",
+			closed:  true,
+			want:    "app before all, scenario before all, app before each, scenario before each, execute, scenario after each, app after each, scenario after all, app after all",
+			wantErr: "connection refused",
+		},
+		{
+			name:    "cancelled run",
+			cancel:  true,
+			want:    "app before all, scenario before all, app before each, scenario before each, execute, scenario after each, app after each, scenario after all, app after all",
+			wantErr: "context canceled",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var events []string
+			record := func(ctx context.Context, event string) {
+				events = append(events, event)
+				if ctx.Err() != nil {
+					t.Errorf("%s: expected a context that is not cancelled, got %v", event, ctx.Err())
+				}
+				if event == test.panicIn {
+					panic("boom")
+				}
+			}
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if test.cancel {
+					cancel()
+					<-r.Context().Done()
+				}
+			}))
+			defer server.Close()
+			app, scenario := newTestScenario(t, server, GET)
+			if test.closed {
+				server.Close()
+			}
+
+			scenario.GetScenarioHooksRegistry().RegisterBeforeHook(func(ctx context.Context, route interfaces.Route) (interfaces.Route, error) {
+				record(ctx, "execute")
+				return route, nil
+			})
+			registries := map[string]interfaces.HooksRegistry{"app": app.ApplicationHooksRegistry, "scenario": scenario.GetScenarioHooksRegistry()}
+			for level, hooks := range registries {
+				level := level
+				hooks.RegisterBeforeAllHook(func(ctx context.Context) error {
+					record(ctx, level+" before all")
+					return nil
+				})
+				hooks.RegisterAfterAllHook(func(ctx context.Context) error {
+					record(ctx, level+" after all")
+					return nil
+				})
+				hooks.RegisterBeforeEachHook(func(ctx context.Context, _ interfaces.Scenario) error {
+					record(ctx, level+" before each")
+					return nil
+				})
+				hooks.RegisterAfterEachHook(func(ctx context.Context, _ interfaces.Scenario) error {
+					record(ctx, level+" after each")
+					return nil
+				})
+			}
+
+			results := app.Run(ctx).Results()
+			if got := strings.Join(events, ", "); got != test.want {
+				t.Errorf("expected the hooks to run in order:\n%s\ngot:\n%s", test.want, got)
+			}
+			if len(results) != 1 || results[0].Err() == nil || !strings.Contains(results[0].Err().Error(), test.wantErr) {
+				t.Fatalf("expected the scenario to fail with %q, got %v", test.wantErr, results)
+			}
+		})
+	}
+}
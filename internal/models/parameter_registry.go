@@ -26,10 +26,8 @@ func NewParameterRegistry() interfaces.ParametersRegistry {
 }
 
 // ImportFromHTTPResponse imports parameters from an HTTP response.
+// The registry is locked by the Register methods called for every imported parameter.
 func (pr *ParameterRegistry) ImportFromHTTPResponse(httpResp *http.Response) error {
-	pr.mu.Lock()
-	defer pr.mu.Unlock()
-
 	// Query parameters
 	qp := httpResp.Request.URL.Query()
 	for key, values := range qp {
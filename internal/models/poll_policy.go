@@ -0,0 +1,95 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/qatoolist/RouTest/internal/interfaces"
+	"gopkg.in/yaml.v3"
+)
+
+// PollPolicy defines how often and for how long a scenario is re-sent until its condition is met.
+type PollPolicy struct {
+	// Interval is the delay between the first and the second poll.
+	Interval time.Duration `json:"interval" yaml:"interval"`
+
+	// MaxInterval caps the interval when it grows with Multiplier. Zero means no cap.
+	MaxInterval time.Duration `json:"max_interval" yaml:"max_interval"`
+
+	// Multiplier is the factor the interval grows by after every poll. 1 keeps a fixed interval.
+	Multiplier float64 `json:"multiplier" yaml:"multiplier"`
+
+	// Timeout is the maximum time spent polling before the scenario fails.
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+}
+
+// NewPollPolicy creates a PollPolicy polling every second for up to 30 seconds.
+func NewPollPolicy() *PollPolicy {
+	return &PollPolicy{
+		Interval:   time.Second,
+		Multiplier: 1,
+		Timeout:    30 * time.Second,
+	}
+}
+
+// NewPollPolicyFromString creates a PollPolicy from its YAML representation.
+// Fields that are not set keep the defaults of NewPollPolicy.
+func NewPollPolicyFromString(s string) (interfaces.PollPolicy, error) {
+	if s == "" {
+		return nil, errors.New("empty input string")
+	}
+
+	p := NewPollPolicy()
+	err := yaml.Unmarshal([]byte(s), p)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.Interval <= 0 {
+		return nil, errors.New("invalid interval value")
+	}
+	if p.Multiplier < 1 {
+		return nil, errors.New("invalid multiplier value")
+	}
+	if p.Timeout <= 0 {
+		return nil, errors.New("invalid timeout value")
+	}
+
+	return p, nil
+}
+
+// GetTimeout returns the maximum time spent polling.
+func (p *PollPolicy) GetTimeout() time.Duration {
+	return p.Timeout
+}
+
+// NextInterval returns the delay before the next poll, poll being the number of the poll that just finished.
+func (p *PollPolicy) NextInterval(poll int) time.Duration {
+	interval := float64(p.Interval) * math.Pow(p.Multiplier, float64(poll-1))
+	if p.MaxInterval > 0 && interval > float64(p.MaxInterval) {
+		interval = float64(p.MaxInterval)
+	}
+	return time.Duration(interval)
+}
+
+/* Example usage -
+
+policy, err := models.NewPollPolicyFromString(`
+interval: 500ms
+max_interval: 5s
+multiplier: 1.5
+timeout: 1m
+`)
+if err != nil {
+    log.Fatal(err)
+}
+
+scenario.SetWaitUntil(func(resp interfaces.Response) error {
+    if !strings.Contains(resp.String(), `"status":"done"`) {
+        return errors.New("job is not done yet")
+    }
+    return nil
+}, policy)
+
+*/
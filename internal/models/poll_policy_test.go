@@ -0,0 +1,144 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/qatoolist/RouTest/internal/interfaces"
+)
+
+const testMeta = "automation_status: automated\nimportance: high\n"
+
+// newTestScenario creates an application with a single route and scenario sending their requests to the server.
+func newTestScenario(t *testing.T, server *httptest.Server, method Method) (*Application, interfaces.Scenario) {
+	t.Helper()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, _ := strconv.Atoi(u.Port())
+	meta, err := NewMetaFromString(testMeta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app, err := NewApplication("test", NewConfig(), NewRequirements(), meta, NewHost(u.Scheme, u.Hostname(), port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	route := app.NewRoute(&Info{name: "jobs", path: "/jobs", method: method}, testMeta)
+	app.AddRoute(route.GetName(), &route)
+	return app, route.NewScenario(&Info{name: "job", path: "/jobs", method: method}, testMeta)
+}
+
+// TestNextInterval checks the fixed, growing and capped intervals between polls.
+func TestNextInterval(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy PollPolicy
+		poll   int
+		want   time.Duration
+	}{
+		{"fixed", PollPolicy{Interval: time.Second, Multiplier: 1}, 5, time.Second},
+		{"growing", PollPolicy{Interval: time.Second, Multiplier: 2}, 3, 4 * time.Second},
+		{"capped", PollPolicy{Interval: time.Second, Multiplier: 2, MaxInterval: 3 * time.Second}, 3, 3 * time.Second},
+	}
+	for _, test := range tests {
+		if got := test.policy.NextInterval(test.poll); got != test.want {
+			t.Errorf("%s: expected %s, got %s", test.name, test.want, got)
+		}
+	}
+}
+
+// TestNewPollPolicyFromString checks the defaults and the invalid values of poll policies.
+func TestNewPollPolicyFromString(t *testing.T) {
+	policy, err := NewPollPolicyFromString("interval: 200ms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.GetTimeout() != 30*time.Second || policy.NextInterval(2) != 200*time.Millisecond {
+		t.Errorf("expected the default timeout and multiplier, got %+v", policy)
+	}
+
+	for _, s := range []string{"", "interval: 0s", "multiplier: 0.5", "timeout: -1s"} {
+		if _, err := NewPollPolicyFromString(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
+
+// TestPoll checks that a wait-until scenario is re-sent until its condition is met, with its hooks run once,
+// and that it fails with the last response when the poll policy times out.
+func TestPoll(t *testing.T) {
+	tests := []struct {
+		name      string
+		doneAfter int32
+		wantPolls int
+		wantErr   string
+	}{
+		{"condition met", 3, 3, ""},
+		{"timed out", 100, 0, "condition not met after"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&calls, 1) < test.doneAfter {
+					fmt.Fprint(w, `{"status": "pending"}`)
+					return
+				}
+				fmt.Fprint(w, `{"status": "done"}`)
+			}))
+			defer server.Close()
+
+			app, scenario := newTestScenario(t, server, GET)
+			var before, after int
+			scenario.GetScenarioHooksRegistry().RegisterBeforeHook(func(ctx context.Context, route interfaces.Route) (interfaces.Route, error) {
+				before++
+				return route, nil
+			})
+			scenario.GetScenarioHooksRegistry().RegisterAfterHook(func(ctx context.Context, resp interfaces.Response) (interfaces.Response, error) {
+				after++
+				return resp, nil
+			})
+			scenario.SetWaitUntil(func(resp interfaces.Response) error {
+				if !strings.Contains(resp.String(), `"done"`) {
+					return errors.New("job is not done yet")
+				}
+				return nil
+			}, &PollPolicy{Interval: 10 * time.Millisecond, Multiplier: 1, Timeout: 35 * time.Millisecond})
+
+			route, _ := app.GetRouteByName("jobs")
+			response, err := route.GetScenarioRegistry().Execute(context.Background(), scenario)
+			if test.wantErr == "" && err != nil {
+				t.Fatalf("expected the condition to be met, got %v", err)
+			}
+			if test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr) || !strings.Contains(err.Error(), "job is not done yet")) {
+				t.Fatalf("expected an error containing %q and the condition error, got %v", test.wantErr, err)
+			}
+			wantPolls := test.wantPolls
+			if wantPolls == 0 {
+				// The number of polls made before the timeout depends on the time taken by the requests.
+				wantPolls = int(atomic.LoadInt32(&calls))
+			}
+			if response == nil || response.GetPolls() != wantPolls {
+				t.Fatalf("expected the last response after %d polls, got %v", wantPolls, response)
+			}
+			wantAfter := 1
+			if test.wantErr != "" {
+				wantAfter = 0
+			}
+			if before != 1 || after != wantAfter {
+				t.Errorf("expected the before hooks to run once and the after hooks %d times, got %d and %d", wantAfter, before, after)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package models
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/qatoolist/RouTest/internal/interfaces"
+)
+
+// ScenarioResult is the outcome of executing a single scenario.
+type ScenarioResult struct {
+	routeName    string
+	scenarioName string
+	request      *http.Request
+	response     interfaces.Response
+	err          error
+	duration     time.Duration
+}
+
+// NewScenarioResult creates a new ScenarioResult for the given scenario.
+func NewScenarioResult(scenario interfaces.Scenario, response interfaces.Response, err error, duration time.Duration) interfaces.ScenarioResult {
+	result := &ScenarioResult{
+		scenarioName: scenario.GetName(),
+		response:     response,
+		err:          err,
+		duration:     duration,
+	}
+	// The request of an exchange only describes the call, e.g. a gRPC method or a WebSocket handshake,
+	// so it cannot be replayed as an HTTP request.
+	if scenario.GetExchange() == nil {
+		result.request = scenario.GetRequest()
+	}
+	if route := scenario.GetParentRoute(); route != nil {
+		result.routeName = route.GetName()
+	}
+	return result
+}
+
+// RouteName returns the name of the route the scenario belongs to.
+func (r *ScenarioResult) RouteName() string {
+	return r.routeName
+}
+
+// ScenarioName returns the name of the scenario.
+func (r *ScenarioResult) ScenarioName() string {
+	return r.scenarioName
+}
+
+// Request returns the request of the scenario as resolved before the Request Hooks ran, if it was built
+// and sent over plain HTTP, or nil for the scenarios performed by an exchange.
+func (r *ScenarioResult) Request() *http.Request {
+	return r.request
+}
+
+// Response returns the response received for the scenario, if any.
+func (r *ScenarioResult) Response() interfaces.Response {
+	return r.response
+}
+
+// Err returns the error the scenario failed with, if any.
+func (r *ScenarioResult) Err() error {
+	return r.err
+}
+
+// Duration returns how long the scenario took, including hooks and retries.
+func (r *ScenarioResult) Duration() time.Duration {
+	return r.duration
+}
+
+// Passed returns true if the scenario finished without error.
+func (r *ScenarioResult) Passed() bool {
+	return r.err == nil
+}
+
+// Report collects the results of all the scenarios executed in a run.
+type Report struct {
+	mu      sync.RWMutex
+	start   time.Time
+	end     time.Time
+	results []interfaces.ScenarioResult
+	errors  []error
+	err     error
+}
+
+// NewReport creates a new empty Report starting now.
+func NewReport() *Report {
+	return &Report{
+		start:   time.Now(),
+		results: make([]interfaces.ScenarioResult, 0),
+	}
+}
+
+// AddResult appends the result of a scenario to the report.
+func (r *Report) AddResult(result interfaces.ScenarioResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.results = append(r.results, result)
+	r.end = time.Now()
+}
+
+// Results returns the results in the order they were added.
+func (r *Report) Results() []interfaces.ScenarioResult {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.results
+}
+
+// Passed returns the number of scenarios that passed.
+func (r *Report) Passed() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.passedLocked()
+}
+
+// Failed returns the number of scenarios that failed.
+func (r *Report) Failed() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.results) - r.passedLocked()
+}
+
+// Duration returns the time between the start of the run and the last result.
+func (r *Report) Duration() time.Duration {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.end.IsZero() {
+		return 0
+	}
+	return r.end.Sub(r.start)
+}
+
+// AddError records a failure that does not belong to a single scenario, e.g. a failing AfterAll hook.
+func (r *Report) AddError(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errors = append(r.errors, err)
+}
+
+// Errors returns the failures that do not belong to a single scenario.
+func (r *Report) Errors() []error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.errors
+}
+
+// Interrupt marks the report as partial because the run was stopped by the given error.
+func (r *Report) Interrupt(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.err = err
+}
+
+// Interrupted returns the error that stopped the run, or nil if every scenario was executed.
+func (r *Report) Interrupted() error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.err
+}
+
+func (r *Report) passedLocked() int {
+	passed := 0
+	for _, result := range r.results {
+		if result.Passed() {
+			passed++
+		}
+	}
+	return passed
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/qatoolist/RouTest/internal/interfaces"
+)
+
+// TestScenarioResultRequest checks that the results keep the request of the scenarios sent over plain HTTP only.
+func TestScenarioResultRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		exchange    interfaces.Exchange
+		wantRequest bool
+	}{
+		{name: "plain HTTP", wantRequest: true},
+		{
+			name: "exchange",
+			exchange: func(hc interfaces.HookContext) (interfaces.Response, error) {
+				return NewStaticResponse(http.StatusOK, nil, nil), nil
+			},
+		},
+	}
+	for _, test := range tests {
+		app, scenario := newTestScenario(t, server, GET)
+		if test.exchange != nil {
+			scenario.SetExchange(test.exchange)
+		}
+		results := app.Run(context.Background()).Results()
+		if len(results) != 1 {
+			t.Fatalf("%s: expected a single result, got %d", test.name, len(results))
+		}
+		if got := results[0].Request() != nil; got != test.wantRequest {
+			t.Errorf("%s: expected a request %v, got %v", test.name, test.wantRequest, got)
+		}
+	}
+}
@@ -50,6 +50,18 @@ func (r *RequestBodySchema) Validate(data interface{}) error {
 	return errors.New("validation error: " + validationErrors[0])
 }
 
+// Example returns a value matching the schema, e.g. as the valid payload negative payloads are derived from.
+// It is the value generated with the seed 0; the example values declared in the schema are used when present.
+func (r *RequestBodySchema) Example() (interface{}, error) {
+	return r.Generate(0)
+}
+
+// Generate returns a realistic value matching the schema, honouring its types, formats, enums, ranges and patterns.
+// The same seed always generates the same value.
+func (r *RequestBodySchema) Generate(seed int64) (interface{}, error) {
+	return generateFromSchema(r.SchemaLoader, seed, r.Validate)
+}
+
 func (r *RequestBodySchema) MarshalJSON() ([]byte, error) {
 	// Marshal the JSON schema to a JSON string
 	return json.Marshal(r.SchemaLoader.JsonSource())
@@ -2,8 +2,10 @@ package models
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
+	"time"
 
 	"github.com/qatoolist/RouTest/internal/interfaces"
 )
@@ -21,6 +23,18 @@ type Response struct {
 
 	// Body is the body of the response.
 	Body []byte
+
+	// Attempts records every attempt made to obtain the response, including the retried ones.
+	Attempts []interfaces.Attempt
+
+	// Polls is the number of times the request was sent for a wait-until scenario.
+	Polls int
+
+	// PollDuration is the time spent polling until the condition was met or the poll policy timed out.
+	PollDuration time.Duration
+
+	// SpecDrift lists the differences between the response and the specification of the API, if it was checked.
+	SpecDrift []string
 }
 
 // NewResponse creates a new instance of the Response struct with default values for its fields.
@@ -48,18 +62,35 @@ func HandleResponse(httpResp *http.Response) (interfaces.Response, error) {
 	}
 
 	resp.ResponseParametersRegistry = NewParameterRegistry()
-	resp.ResponseParametersRegistry.RegisterHeader("Content-Type", "Content-Type")
-
 	resp.ResponseParametersRegistry.ImportFromHTTPResponse(httpResp)
 
 	return interfaces.Response(resp), nil
 }
 
+// NewStaticResponse creates a Response that was not received from a server, e.g. an example response served by the mock server.
+func NewStaticResponse(statusCode int, headers map[string]string, body []byte) interfaces.Response {
+	resp := &Response{
+		StatusCode:                 statusCode,
+		Status:                     fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		ResponseParametersRegistry: NewParameterRegistry(),
+		Body:                       body,
+	}
+	for key, value := range headers {
+		resp.ResponseParametersRegistry.RegisterHeader(key, value)
+	}
+	return resp
+}
+
 // String returns the response body as a string.
 func (r *Response) String() string {
 	return string(r.Body)
 }
 
+// GetStatusCode returns the HTTP status code of the response.
+func (r *Response) GetStatusCode() int {
+	return r.StatusCode
+}
+
 // Bytes returns the response body as a byte slice.
 func (r *Response) Bytes() []byte {
 	return r.Body
@@ -70,6 +101,11 @@ func (r *Response) HeaderValue(key string) (string, error) {
 	return r.ResponseParametersRegistry.GetParameterByKey(key, "Header")
 }
 
+// GetHeaders returns all the headers of the response.
+func (r *Response) GetHeaders() []interfaces.Parameter {
+	return r.ResponseParametersRegistry.GetHeaders()
+}
+
 // ContentType returns the Content-Type header value.
 func (r *Response) ContentType() (string, error) {
 	return r.HeaderValue("Content-Type")
@@ -94,3 +130,31 @@ func (r *Response) ValidateBody(schema interfaces.ResponseBodySchema) error {
 
 	return nil
 }
+
+// GetAttempts returns every attempt made to obtain the response, including the retried ones.
+func (r *Response) GetAttempts() []interfaces.Attempt {
+	return r.Attempts
+}
+
+// GetPolls returns the number of times the request was sent for a wait-until scenario.
+func (r *Response) GetPolls() int {
+	return r.Polls
+}
+
+// GetTiming returns the timing breakdown of the last attempt, or nil if the response was not received from a server.
+func (r *Response) GetTiming() interfaces.Timing {
+	if len(r.Attempts) == 0 {
+		return nil
+	}
+	return r.Attempts[len(r.Attempts)-1].Timing()
+}
+
+// GetSpecDrift returns the differences between the response and the specification of the API.
+func (r *Response) GetSpecDrift() []string {
+	return r.SpecDrift
+}
+
+// GetPollDuration returns the time spent polling for a wait-until scenario.
+func (r *Response) GetPollDuration() time.Duration {
+	return r.PollDuration
+}
@@ -53,6 +53,18 @@ func (r *ResponseBodySchema) Validate(data interface{}) error {
 	return errors.New("validation error: " + validationErrors[0])
 }
 
+// Example returns a value matching the schema, e.g. to serve as a stub response.
+// It is the value generated with the seed 0; the example values declared in the schema are used when present.
+func (r *ResponseBodySchema) Example() (interface{}, error) {
+	return r.Generate(0)
+}
+
+// Generate returns a realistic value matching the schema, honouring its types, formats, enums, ranges and patterns.
+// The same seed always generates the same value.
+func (r *ResponseBodySchema) Generate(seed int64) (interface{}, error) {
+	return generateFromSchema(r.SchemaLoader, seed, r.Validate)
+}
+
 // MarshalJSON marshals the JSON schema to a JSON string.
 func (r *ResponseBodySchema) MarshalJSON() ([]byte, error) {
 	return json.Marshal(r.SchemaLoader.JsonSource())
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/qatoolist/RouTest/internal/interfaces"
+)
+
+// SendWithRetry sends the request with the given client and retries it as long as the policy allows.
+// It returns the response of the last attempt together with a record of every attempt made.
+// The bodies of the intermediate responses are read and closed; the body of the returned response is buffered
+// so it can still be read by the caller.
+// The request and the backoff between attempts are cancelled when the context is done. When the backoff would
+// end after the deadline of the context, the response of the attempt is returned instead of waiting for it.
+// Every attempt is traced with net/http/httptrace to record its timing breakdown.
+func SendWithRetry(ctx context.Context, client *http.Client, req *http.Request, policy interfaces.RetryPolicy) (*http.Response, []interfaces.Attempt, error) {
+	if policy == nil {
+		policy = NewRetryPolicy()
+	}
+	req = req.WithContext(ctx)
+
+	var attempts []interfaces.Attempt
+	for number := 1; ; number++ {
+		if number > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, attempts, err
+			}
+			req.Body = body
+		}
+
+		start := time.Now()
+		traced, trace := traceRequest(req)
+		resp, err := client.Do(traced)
+		attempt := &Attempt{
+			number:   number,
+			err:      err,
+			duration: time.Since(start),
+		}
+		attempts = append(attempts, attempt)
+
+		if resp != nil {
+			attempt.statusCode = resp.StatusCode
+			attempt.status = resp.Status
+		}
+
+		final := number >= policy.MaxAttempts() || ctx.Err() != nil || !policy.ShouldRetry(req.Method, resp, err)
+		var wait time.Duration
+		if !final {
+			wait = policy.Backoff(number, resp)
+			// Waiting past the deadline would only fail the scenario with the error of the context,
+			// e.g. for a Retry-After longer than its timeout.
+			if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+				final = true
+			}
+		}
+
+		if final {
+			if resp != nil {
+				body, readErr := ioutil.ReadAll(resp.Body)
+				resp.Body.Close()
+				if readErr != nil {
+					return nil, attempts, readErr
+				}
+				attempt.body = body
+				resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+			}
+			attempt.timing = trace.done(resp, attempt.body)
+			return resp, attempts, err
+		}
+
+		if resp != nil {
+			attempt.body, _ = ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+		}
+		attempt.timing = trace.done(resp, attempt.body)
+
+		attempt.wait = wait
+		if err := sleep(ctx, attempt.wait); err != nil {
+			return nil, attempts, err
+		}
+	}
+}
+
+// sleep pauses for the given duration, or returns the error of the context if it is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
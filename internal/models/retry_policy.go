@@ -0,0 +1,166 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/qatoolist/RouTest/internal/interfaces"
+	"gopkg.in/yaml.v3"
+)
+
+// RetryPolicy describes when and how often a request is retried after a transient failure.
+type RetryPolicy struct {
+	// MaxAttemptsCount is the maximum number of attempts, including the first one.
+	MaxAttemptsCount int `json:"max_attempts" yaml:"max_attempts"`
+
+	// RetryOnStatus lists the response status codes that should be retried.
+	RetryOnStatus []int `json:"retry_on_status" yaml:"retry_on_status"`
+
+	// RetryOnNetworkErrors retries attempts that failed without a response, e.g. connection resets.
+	RetryOnNetworkErrors bool `json:"retry_on_network_errors" yaml:"retry_on_network_errors"`
+
+	// InitialBackoff is the delay before the second attempt.
+	InitialBackoff time.Duration `json:"initial_backoff" yaml:"initial_backoff"`
+
+	// MaxBackoff caps the exponential backoff and the Retry-After delays. Zero means no cap.
+	MaxBackoff time.Duration `json:"max_backoff" yaml:"max_backoff"`
+
+	// Multiplier is the factor the backoff grows by after every attempt.
+	Multiplier float64 `json:"multiplier" yaml:"multiplier"`
+
+	// Jitter randomizes the backoff by up to the given fraction, e.g. 0.2 for +/-20%.
+	Jitter float64 `json:"jitter" yaml:"jitter"`
+
+	// RespectRetryAfter uses the Retry-After response header as the delay when present, up to MaxBackoff.
+	RespectRetryAfter bool `json:"respect_retry_after" yaml:"respect_retry_after"`
+
+	// RetryNonIdempotent allows retrying POST and PATCH requests, which are never retried by default.
+	RetryNonIdempotent bool `json:"retry_non_idempotent" yaml:"retry_non_idempotent"`
+}
+
+// NewRetryPolicy creates a RetryPolicy that sends every request exactly once.
+func NewRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttemptsCount:  1,
+		InitialBackoff:    100 * time.Millisecond,
+		Multiplier:        2,
+		RespectRetryAfter: true,
+	}
+}
+
+// NewRetryPolicyFromString creates a RetryPolicy from its YAML representation.
+// Fields that are not set keep the defaults of NewRetryPolicy.
+func NewRetryPolicyFromString(s string) (interfaces.RetryPolicy, error) {
+	if s == "" {
+		return nil, errors.New("empty input string")
+	}
+
+	p := NewRetryPolicy()
+	err := yaml.Unmarshal([]byte(s), p)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.MaxAttemptsCount < 1 {
+		return nil, errors.New("invalid max_attempts value")
+	}
+	if p.Multiplier < 1 {
+		return nil, errors.New("invalid multiplier value")
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		return nil, errors.New("invalid jitter value")
+	}
+
+	return p, nil
+}
+
+// MaxAttempts returns the maximum number of attempts, including the first one.
+func (p *RetryPolicy) MaxAttempts() int {
+	return p.MaxAttemptsCount
+}
+
+// ShouldRetry reports whether the attempt that produced resp or err should be retried for the given method.
+func (p *RetryPolicy) ShouldRetry(method string, resp *http.Response, err error) bool {
+	if !p.RetryNonIdempotent && (method == POST.String() || method == PATCH.String()) {
+		return false
+	}
+
+	if err != nil {
+		return p.RetryOnNetworkErrors
+	}
+
+	if resp == nil {
+		return false
+	}
+	for _, code := range p.RetryOnStatus {
+		if resp.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Backoff returns the delay before the next attempt, attempt being the number of the attempt that just failed.
+func (p *RetryPolicy) Backoff(attempt int, resp *http.Response) time.Duration {
+	if p.RespectRetryAfter && resp != nil {
+		if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+				wait = p.MaxBackoff
+			}
+			return wait
+		}
+	}
+
+	backoff := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		backoff += backoff * p.Jitter * (2*rand.Float64() - 1)
+	}
+
+	return time.Duration(backoff)
+}
+
+// parseRetryAfter parses a Retry-After header given either in seconds or as an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+/* Example usage -
+
+policy, err := models.NewRetryPolicyFromString(`
+max_attempts: 4
+retry_on_status: [502, 503, 504]
+retry_on_network_errors: true
+initial_backoff: 200ms
+max_backoff: 5s
+multiplier: 2
+jitter: 0.2
+`)
+if err != nil {
+    log.Fatal(err)
+}
+
+app.SetRetryPolicy(policy)          // every scenario of the application
+route.SetRetryPolicy(policy)        // every scenario of the route
+scenario.SetRetryPolicy(policy)     // only this scenario
+
+*/
@@ -0,0 +1,169 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestBackoff checks the exponential backoff, its cap and the Retry-After delays.
+func TestBackoff(t *testing.T) {
+	retryAfter := func(value string) *http.Response {
+		return &http.Response{Header: http.Header{"Retry-After": []string{value}}}
+	}
+
+	tests := []struct {
+		name    string
+		policy  RetryPolicy
+		attempt int
+		resp    *http.Response
+		want    time.Duration
+	}{
+		{"first attempt", RetryPolicy{InitialBackoff: 100 * time.Millisecond, Multiplier: 2}, 1, nil, 100 * time.Millisecond},
+		{"third attempt", RetryPolicy{InitialBackoff: 100 * time.Millisecond, Multiplier: 2}, 3, nil, 400 * time.Millisecond},
+		{"capped", RetryPolicy{InitialBackoff: 100 * time.Millisecond, Multiplier: 10, MaxBackoff: time.Second}, 4, nil, time.Second},
+		{"retry-after in seconds", RetryPolicy{InitialBackoff: time.Millisecond, Multiplier: 2, RespectRetryAfter: true}, 1, retryAfter("3"), 3 * time.Second},
+		{"retry-after capped", RetryPolicy{InitialBackoff: time.Millisecond, Multiplier: 2, MaxBackoff: 5 * time.Second, RespectRetryAfter: true}, 1, retryAfter("3600"), 5 * time.Second},
+		{"retry-after in the past", RetryPolicy{InitialBackoff: time.Millisecond, Multiplier: 2, RespectRetryAfter: true}, 1, retryAfter("Mon, 02 Jan 2006 15:04:05 GMT"), 0},
+		{"retry-after ignored", RetryPolicy{InitialBackoff: time.Millisecond, Multiplier: 2}, 1, retryAfter("3"), time.Millisecond},
+		{"invalid retry-after", RetryPolicy{InitialBackoff: time.Millisecond, Multiplier: 2, RespectRetryAfter: true}, 1, retryAfter("soon"), time.Millisecond},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.policy.Backoff(test.attempt, test.resp); got != test.want {
+				t.Errorf("expected %s, got %s", test.want, got)
+			}
+		})
+	}
+}
+
+// TestBackoffJitter checks that the jitter stays within its fraction of the backoff.
+func TestBackoffJitter(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: time.Second, Multiplier: 2, Jitter: 0.2}
+	for i := 0; i < 100; i++ {
+		if got := policy.Backoff(1, nil); got < 800*time.Millisecond || got > 1200*time.Millisecond {
+			t.Fatalf("expected a backoff between 800ms and 1.2s, got %s", got)
+		}
+	}
+}
+
+// TestSendWithRetry checks which failures are retried and the attempts recorded.
+func TestSendWithRetry(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		policy       string
+		retryAfter   string
+		timeout      time.Duration
+		wantAttempts int32
+		wantStatus   int
+	}{
+		{"retried until success", http.MethodGet, "max_attempts: 3\nretry_on_status: [503]\ninitial_backoff: 1ms", "", 0, 3, http.StatusOK},
+		{"not retried without policy", http.MethodGet, "max_attempts: 1", "", 0, 1, http.StatusServiceUnavailable},
+		{"status not retried", http.MethodGet, "max_attempts: 3\nretry_on_status: [502]\ninitial_backoff: 1ms", "", 0, 1, http.StatusServiceUnavailable},
+		{"POST not retried", http.MethodPost, "max_attempts: 3\nretry_on_status: [503]\ninitial_backoff: 1ms", "", 0, 1, http.StatusServiceUnavailable},
+		{"POST retried when allowed", http.MethodPost, "max_attempts: 3\nretry_on_status: [503]\ninitial_backoff: 1ms\nretry_non_idempotent: true", "", 0, 3, http.StatusOK},
+		{"retry-after past the deadline", http.MethodGet, "max_attempts: 3\nretry_on_status: [503]", "3600", 100 * time.Millisecond, 1, http.StatusServiceUnavailable},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				if string(body) != "payload" {
+					t.Errorf("attempt %d: expected the body to be sent again, got %q", atomic.LoadInt32(&calls)+1, body)
+				}
+				if atomic.AddInt32(&calls, 1) < 3 {
+					if test.retryAfter != "" {
+						w.Header().Set("Retry-After", test.retryAfter)
+					}
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.Write([]byte("ok"))
+			}))
+			defer server.Close()
+
+			policy, err := NewRetryPolicyFromString(test.policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx := context.Background()
+			if test.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, test.timeout)
+				defer cancel()
+			}
+			req, err := http.NewRequest(test.method, server.URL, strings.NewReader("payload"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			start := time.Now()
+			resp, attempts, err := SendWithRetry(ctx, server.Client(), req, policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.timeout > 0 && time.Since(start) >= test.timeout {
+				t.Errorf("expected the response before the deadline, got it after %s", time.Since(start))
+			}
+			if resp.StatusCode != test.wantStatus {
+				t.Errorf("expected status %d, got %d", test.wantStatus, resp.StatusCode)
+			}
+			if calls != test.wantAttempts || int32(len(attempts)) != test.wantAttempts {
+				t.Errorf("expected %d attempts, got %d requests and %d attempts", test.wantAttempts, calls, len(attempts))
+			}
+			if body, _ := ioutil.ReadAll(resp.Body); test.wantStatus == http.StatusOK && string(body) != "ok" {
+				t.Errorf("expected the body of the last response to be readable, got %q", body)
+			}
+		})
+	}
+}
+
+// TestSendWithRetryCancelled checks that a cancelled context stops the backoff.
+func TestSendWithRetryCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	policy, err := NewRetryPolicyFromString("max_attempts: 3\nretry_on_status: [503]\ninitial_backoff: 1h")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	if _, attempts, err := SendWithRetry(ctx, server.Client(), req, policy); err != context.Canceled || len(attempts) != 1 {
+		t.Errorf("expected the cancellation after 1 attempt, got %v after %d", err, len(attempts))
+	}
+}
+
+// TestShouldRetry checks the network errors and statuses retried by a policy.
+func TestShouldRetry(t *testing.T) {
+	policy := &RetryPolicy{RetryOnStatus: []int{502, 503}, RetryOnNetworkErrors: true}
+	tests := []struct {
+		name   string
+		method string
+		resp   *http.Response
+		err    error
+		want   bool
+	}{
+		{"listed status", http.MethodGet, &http.Response{StatusCode: 503}, nil, true},
+		{"other status", http.MethodGet, &http.Response{StatusCode: 500}, nil, false},
+		{"network error", http.MethodDelete, nil, errors.New("connection reset"), true},
+		{"PATCH", http.MethodPatch, &http.Response{StatusCode: 503}, nil, false},
+	}
+	for _, test := range tests {
+		if got := policy.ShouldRetry(test.method, test.resp, test.err); got != test.want {
+			t.Errorf("%s: expected %v, got %v", test.name, test.want, got)
+		}
+	}
+}
@@ -2,6 +2,9 @@ package models
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"io/ioutil"
 	"net/http"
 
@@ -11,7 +14,7 @@ import (
 // Route represents a custom HTTP request with additional fields.
 type Route struct {
 	// Info contains the request information.
-	Info Info
+	Info interfaces.Info
 
 	// ParentRoute provides the reference to the parent route.
 	ParentApplication interfaces.Application
@@ -37,6 +40,9 @@ type Route struct {
 
 	// Response is the channel for the HTTP response.
 	Response <-chan *http.Response
+
+	// RetryPolicy overrides the retry policy of the parent application for every scenario of the route.
+	RetryPolicy interfaces.RetryPolicy
 }
 
 // SetReqBodySchema sets the request body schema for the route.
@@ -61,7 +67,7 @@ func (r *Route) SetResBodySchema(schema string) error {
 
 // ValidateReqBody validates the request body against the request body schema.
 func (r *Route) ValidateReqBody(body interface{}) error {
-	if r.Info.GetRequestBodySchema() == interfaces.RequestBodySchema(&RequestBodySchema{}) {
+	if r.Info.GetRequestBodySchema() == nil {
 		return nil
 	}
 	return r.Info.GetRequestBodySchema().Validate(body)
@@ -69,37 +75,67 @@ func (r *Route) ValidateReqBody(body interface{}) error {
 
 // ValidateResBody validates the response body against the response body schema.
 func (r *Route) ValidateResBody(body interface{}) error {
-	if r.Info.GetResponseBodySchema() == interfaces.ResponseBodySchema(&ResponseBodySchema{}) {
+	if r.Info.GetResponseBodySchema() == nil {
 		return nil
 	}
 	return r.Info.GetResponseBodySchema().Validate(body)
 }
 
+// NewRequest creates the HTTP request of the route against the host of the parent application.
+// The given body is validated against the request body schema of the route, if any,
+// unless the request is sent by a negative scenario, whose body is invalid on purpose.
+func (r *Route) NewRequest(ctx context.Context, body []byte) (*http.Request, error) {
+	if r.Info == nil || r.Info.GetMethod() == nil {
+		return nil, errors.New("route method is not defined")
+	}
+
+	scenario, _ := ScenarioFromContext(ctx)
+	if r.Info.GetRequestBodySchema() != nil && len(body) > 0 && !IsNegative(scenario) {
+		var data interface{}
+		if err := json.Unmarshal(body, &data); err != nil {
+			return nil, err
+		}
+		if err := r.ValidateReqBody(data); err != nil {
+			return nil, err
+		}
+	}
+
+	url := r.Info.GetPath()
+	if r.ParentApplication != nil && r.ParentApplication.GetHost() != nil {
+		url = r.ParentApplication.GetHost().BaseURL() + url
+	}
+
+	return http.NewRequestWithContext(ctx, r.Info.GetMethod().String(), url, bytes.NewReader(body))
+}
+
 // Send sends the HTTP request and returns the HTTP response.
-func (r *Route) Send() (*http.Response, error) {
-	client := http.DefaultClient
-	req, err := http.NewRequest(r.Info.GetMethod().String(), r.Info.GetPath(), bytes.NewReader(r.Body))
+// The request is retried according to the retry policy of the route, or of the parent application if the route has none.
+// The request is cancelled when the context is done.
+func (r *Route) Send(ctx context.Context) (*http.Response, error) {
+	req, err := r.NewRequest(ctx, r.Body)
 	if err != nil {
 		return nil, err
 	}
 
-	if r.Info.GetRequestBodySchema() != interfaces.RequestBodySchema(&RequestBodySchema{}) {
-		err := r.ValidateReqBody(req.Body)
-		if err != nil {
-			return nil, err
-		}
+	policy := r.RetryPolicy
+	if policy == nil && r.ParentApplication != nil {
+		policy = r.ParentApplication.GetRetryPolicy()
 	}
-	resp, err := client.Do(req)
+
+	resp, _, err := SendWithRetry(ctx, httpClient(r.ParentApplication), req, policy)
 	if err != nil {
 		return nil, err
 	}
-	if r.Info.GetResponseBodySchema() != interfaces.ResponseBodySchema(&ResponseBodySchema{}) {
-		defer resp.Body.Close()
+	if r.Info.GetResponseBodySchema() != nil {
 		body, err := ioutil.ReadAll(resp.Body)
 		if err != nil {
 			return nil, err
 		}
-		err = r.ValidateResBody(body)
+		var data interface{}
+		if err := json.Unmarshal(body, &data); err != nil {
+			return nil, err
+		}
+		err = r.ValidateResBody(data)
 		if err != nil {
 			return nil, err
 		}
@@ -108,6 +144,16 @@ func (r *Route) Send() (*http.Response, error) {
 	return resp, nil
 }
 
+// NewScenario creates a new scenario for the route and adds it to the scenario registry of the route.
+func (r *Route) NewScenario(info interfaces.Info, meta string) interfaces.Scenario {
+	scenario, err := NewScenario(r, info, meta)
+	if err != nil {
+		panic(err)
+	}
+	r.ScenarioRegistry.AddScenario(scenario)
+	return scenario
+}
+
 func (r *Route) GetScenarioRegistry() interfaces.ScenarioRegistry {
 	return r.ScenarioRegistry
 }
@@ -127,3 +173,29 @@ func (r *Route) GetParentApplication() interfaces.Application {
 func (r *Route) GetName() string {
 	return r.Info.GetName()
 }
+
+func (r *Route) GetInfo() interfaces.Info {
+	return r.Info
+}
+
+func (r *Route) GetMeta() interfaces.Meta {
+	return r.Meta
+}
+
+func (r *Route) GetBody() []byte {
+	return r.Body
+}
+
+func (r *Route) SetBody(body []byte) {
+	r.Body = body
+}
+
+// GetRetryPolicy returns the retry policy defined at route level, or nil if none is set.
+func (r *Route) GetRetryPolicy() interfaces.RetryPolicy {
+	return r.RetryPolicy
+}
+
+// SetRetryPolicy sets the retry policy for every scenario of the route.
+func (r *Route) SetRetryPolicy(policy interfaces.RetryPolicy) {
+	r.RetryPolicy = policy
+}
@@ -1,15 +1,18 @@
 package models
 
 import (
+	"sort"
 	"sync"
 
 	"github.com/qatoolist/RouTest/internal/interfaces"
 )
 
 // RoutesRegistry represents a mapping of route name to the corresponding Route instance.
+// The routes keep the order in which they were added.
 type RouteRegistry struct {
 	sync.RWMutex
 	registry map[string]interfaces.Route
+	names    []string
 }
 
 func NewRouteRegistry() interfaces.RouteRegistry {
@@ -33,8 +36,11 @@ func (r *RouteRegistry) Length() int {
 
 // AddRoute adds the given Route to the Routes map.
 func (r *RouteRegistry) AddRoute(name string, route interfaces.Route) interfaces.Route {
-	r.Lock()
-	defer r.Unlock()
+	r.RWMutex.Lock()
+	defer r.RWMutex.Unlock()
+	if _, ok := r.registry[name]; !ok {
+		r.names = append(r.names, name)
+	}
 	r.registry[name] = route
 	return route
 }
@@ -61,3 +67,36 @@ func (r *RouteRegistry) GetRegistry() map[string]interfaces.Route {
 	// return the copy
 	return copy
 }
+
+// GetRoutes returns the routes in the order in which they were added. A route replaced by AddRoute
+// keeps the place of the route it replaced.
+func (r *RouteRegistry) GetRoutes() []interfaces.Route {
+	r.RLock()
+	defer r.RUnlock()
+	routes := make([]interfaces.Route, 0, len(r.names))
+	for _, name := range r.names {
+		routes = append(routes, r.registry[name])
+	}
+	return routes
+}
+
+// Routes returns the routes of the registry in the order in which they were added when the registry remembers it,
+// as RouteRegistry does, and sorted by name otherwise.
+func Routes(registry interfaces.RouteRegistry) []interfaces.Route {
+	if ordered, ok := registry.(interfaces.OrderedRouteRegistry); ok {
+		return ordered.GetRoutes()
+	}
+
+	routes := registry.GetRegistry()
+	names := make([]string, 0, len(routes))
+	for name := range routes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	sorted := make([]interfaces.Route, 0, len(names))
+	for _, name := range names {
+		sorted = append(sorted, routes[name])
+	}
+	return sorted
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/qatoolist/RouTest/internal/interfaces"
+)
+
+// unorderedRegistry is a registry that does not remember the order of its routes.
+type unorderedRegistry struct {
+	interfaces.RouteRegistry
+}
+
+// TestRoutes checks that the routes keep the order in which they were added, a replaced route keeping its place,
+// and that the routes of registries without an order are sorted by name.
+func TestRoutes(t *testing.T) {
+	app, err := NewApplication("test", NewConfig(), NewRequirements(), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := NewRouteRegistry()
+	for _, name := range []string{"zones", "jobs", "users"} {
+		registry.AddRoute(name, app.NewRoute(&Info{name: name, path: "/" + name, method: GET}, testMeta))
+	}
+	registry.AddRoute("jobs", app.NewRoute(&Info{name: "jobs", path: "/v2/jobs", method: GET}, testMeta))
+
+	paths := func(routes []interfaces.Route) string {
+		var paths []string
+		for _, route := range routes {
+			paths = append(paths, route.GetInfo().GetPath())
+		}
+		return strings.Join(paths, " ")
+	}
+	if got := paths(Routes(registry)); got != "/zones /v2/jobs /users" {
+		t.Errorf("expected the order in which the routes were added, got %s", got)
+	}
+	if got := paths(Routes(unorderedRegistry{registry})); got != "/v2/jobs /users /zones" {
+		t.Errorf("expected the routes sorted by name, got %s", got)
+	}
+
+	var iterated []interfaces.Route
+	for it := NewRouteIterator(registry); ; {
+		route := it.Next()
+		if route == nil {
+			break
+		}
+		iterated = append(iterated, route)
+	}
+	if got := paths(iterated); got != "/zones /v2/jobs /users" {
+		t.Errorf("expected the iterator to follow the order of the routes, got %s", got)
+	}
+}
+
+// TestRunOrder checks that the scenarios of a run are executed in the order in which their routes were added.
+func TestRunOrder(t *testing.T) {
+	paths := make(chan string, 3)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths <- r.URL.Path
+	}))
+	defer server.Close()
+
+	app, _ := newTestScenario(t, server, GET)
+	addTestRoute(app, "zones", GET)
+	addTestRoute(app, "apps", GET)
+	app.Run(context.Background())
+	close(paths)
+
+	var got []string
+	for path := range paths {
+		got = append(got, path)
+	}
+	if strings.Join(got, " ") != "/jobs /zones /apps" {
+		t.Errorf("expected the routes in the order they were added, got %v", got)
+	}
+}
@@ -16,21 +16,12 @@ func NewRouteIterator(registry interfaces.RouteRegistry) *RouteIterator {
 	}
 }
 
-// Next returns the next Route in the registry, or nil if there are no more routes.
+// Next returns the next Route in the registry, in the order the routes were added, or nil if there are no more routes.
 func (i *RouteIterator) Next() interfaces.Route {
 	i.index++
-	i.registry.Lock()
-	defer i.registry.Unlock()
-	if i.index >= i.registry.Length() {
+	routes := Routes(i.registry)
+	if i.index >= len(routes) {
 		return nil
 	}
-	var route interfaces.Route
-	for _, r := range i.registry.GetRegistry() {
-		if i.index == 0 {
-			route = r
-		} else {
-			i.index--
-		}
-	}
-	return route
+	return routes[i.index]
 }
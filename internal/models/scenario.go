@@ -1,8 +1,14 @@
 package models
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/qatoolist/RouTest/internal/interfaces"
 )
@@ -38,15 +44,206 @@ type Scenario struct {
 	// The Order of After Hooks Execution is -  AfterApplicationHooks, AfterRouteHooks, AfterScenarioHooks
 	ScenarioHooksRegistry interfaces.HooksRegistry
 
+	// Request is the request of the last execution of the scenario, as resolved before the Request Hooks ran.
+	Request *http.Request
+
 	// Response represents the HTTP Response received after sending the request for this scenario
 	Response interfaces.Response
+
+	// Body is the request body of the scenario. When nil the body of the parent route is sent.
+	Body []byte
+
+	// RetryPolicy overrides the retry policy of the parent route and application for this scenario.
+	RetryPolicy interfaces.RetryPolicy
+
+	// WaitCondition is the condition the scenario polls for. When nil the request is sent once.
+	WaitCondition interfaces.Condition
+
+	// PollPolicy defines how often and for how long the scenario polls for WaitCondition.
+	PollPolicy interfaces.PollPolicy
+
+	// Exchange performs the scenario instead of sending its request over plain HTTP, e.g. over WebSocket.
+	Exchange interfaces.Exchange
+
+	// Timeout is the deadline of a single execution of the scenario. Zero means no deadline.
+	Timeout time.Duration
+
+	// Dataset makes the scenario data-driven: it is executed once per row of the dataset.
+	Dataset interfaces.Dataset
 }
 
-// AddScenario adds a scenario to the registry.
+// NewScenario creates a new Scenario under the given route.
+// The scenario meta is parsed from its YAML representation and is linked to the meta of the route.
+func NewScenario(route interfaces.Route, info interfaces.Info, meta string) (interfaces.Scenario, error) {
+	new_meta, err := NewMetaFromString(meta)
+	if err != nil {
+		return nil, err
+	}
+	if m, ok := new_meta.(*Meta); ok && route != nil {
+		m.ParentMeta = route.GetMeta()
+	}
+
+	return &Scenario{
+		ParentRoute:                route,
+		Info:                       info,
+		Meta:                       new_meta,
+		ScenarioParametersRegistry: NewParameterRegistry(),
+		ScenarioHooksRegistry:      NewHooksRegistry(),
+	}, nil
+}
+
+// GetName returns the name of the scenario.
+func (s *Scenario) GetName() string {
+	if s.Info == nil {
+		return ""
+	}
+	return s.Info.GetName()
+}
+
+// GetParentRoute returns the reference to the parent route.
 func (s *Scenario) GetParentRoute() interfaces.Route {
 	return s.ParentRoute
 }
 
+// GetInfo returns Information about the scenario.
+func (s *Scenario) GetInfo() interfaces.Info {
+	return s.Info
+}
+
+// GetMeta returns metadata about the scenario.
+func (s *Scenario) GetMeta() *interfaces.Meta {
+	return &s.Meta
+}
+
+// GetRequestBodySchema returns the schema for the request body.
+func (s *Scenario) GetRequestBodySchema() *interfaces.RequestBodySchema {
+	return &s.RequestBodySchema
+}
+
+// GetResponseBodySchema returns the schema for the response body.
+func (s *Scenario) GetResponseBodySchema() *interfaces.ResponseBodySchema {
+	return &s.ResponseBodySchema
+}
+
+// GetScenarioParametersRegistry returns the scenario level parameters.
+func (s *Scenario) GetScenarioParametersRegistry() interfaces.ParametersRegistry {
+	return s.ScenarioParametersRegistry
+}
+
+// GetScenarioHooksRegistry returns the registry of Before and After Hooks defined at scenario level.
+func (s *Scenario) GetScenarioHooksRegistry() interfaces.HooksRegistry {
+	return s.ScenarioHooksRegistry
+}
+
+// GetRequest returns the request of the last execution of the scenario, as resolved before the Request Hooks ran.
+func (s *Scenario) GetRequest() *http.Request {
+	return s.Request
+}
+
+// SetRequest sets the request of the last execution of the scenario.
+func (s *Scenario) SetRequest(req *http.Request) {
+	s.Request = req
+}
+
+// GetResponse returns the HTTP Response received after sending the request for this scenario.
+func (s *Scenario) GetResponse() interfaces.Response {
+	return s.Response
+}
+
+// SetResponse sets the HTTP Response received after sending the request for this scenario.
+func (s *Scenario) SetResponse(resp interfaces.Response) {
+	s.Response = resp
+}
+
+// GetBody returns the request body of the scenario.
+func (s *Scenario) GetBody() []byte {
+	return s.Body
+}
+
+// SetBody sets the request body of the scenario.
+func (s *Scenario) SetBody(body []byte) {
+	s.Body = body
+}
+
+// GetRetryPolicy returns the retry policy defined at scenario level, or nil if none is set.
+func (s *Scenario) GetRetryPolicy() interfaces.RetryPolicy {
+	return s.RetryPolicy
+}
+
+// SetRetryPolicy sets the retry policy for this scenario.
+func (s *Scenario) SetRetryPolicy(policy interfaces.RetryPolicy) {
+	s.RetryPolicy = policy
+}
+
+// GetTimeout returns the deadline of a single execution of the scenario, or zero if it has none.
+func (s *Scenario) GetTimeout() time.Duration {
+	return s.Timeout
+}
+
+// SetTimeout sets the deadline of a single execution of the scenario, including hooks, retries and polls.
+func (s *Scenario) SetTimeout(timeout time.Duration) {
+	s.Timeout = timeout
+}
+
+// GetDataset returns the dataset of a data-driven scenario, or nil.
+func (s *Scenario) GetDataset() interfaces.Dataset {
+	return s.Dataset
+}
+
+// SetDataset makes the scenario data-driven: it is executed once per row of the dataset.
+func (s *Scenario) SetDataset(dataset interfaces.Dataset) {
+	s.Dataset = dataset
+}
+
+// GetDataRow returns nil, since a scenario is executed with a single row through a DataRowScenario.
+func (s *Scenario) GetDataRow() map[string]string {
+	return nil
+}
+
+// GetWaitUntil returns the condition the scenario polls for and its poll policy.
+func (s *Scenario) GetWaitUntil() (interfaces.Condition, interfaces.PollPolicy) {
+	return s.WaitCondition, s.PollPolicy
+}
+
+// SetWaitUntil makes the scenario re-send its request until the condition is met or the poll policy times out.
+// A nil policy polls with the defaults of NewPollPolicy.
+func (s *Scenario) SetWaitUntil(condition interfaces.Condition, policy interfaces.PollPolicy) {
+	if policy == nil {
+		policy = NewPollPolicy()
+	}
+	s.WaitCondition = condition
+	s.PollPolicy = policy
+}
+
+// GetExchange returns the exchange performing the scenario, or nil.
+func (s *Scenario) GetExchange() interfaces.Exchange {
+	return s.Exchange
+}
+
+// SetExchange makes the scenario perform the exchange instead of sending its request.
+func (s *Scenario) SetExchange(exchange interfaces.Exchange) {
+	s.Exchange = exchange
+}
+
+// EffectiveRetryPolicy returns the retry policy that applies to the scenario.
+// The scenario policy takes precedence over the route policy, which takes precedence over the application policy.
+func EffectiveRetryPolicy(scenario interfaces.Scenario) interfaces.RetryPolicy {
+	if policy := scenario.GetRetryPolicy(); policy != nil {
+		return policy
+	}
+	route := scenario.GetParentRoute()
+	if route == nil {
+		return nil
+	}
+	if policy := route.GetRetryPolicy(); policy != nil {
+		return policy
+	}
+	if app := route.GetParentApplication(); app != nil {
+		return app.GetRetryPolicy()
+	}
+	return nil
+}
+
 // ScenarioRegistryImpl represents the implementation of the ScenarioRegistry interface.
 type ScenarioRegistryImpl struct {
 	mux       sync.Mutex
@@ -72,14 +269,19 @@ func (s *ScenarioRegistryImpl) AddScenario(scenario interfaces.Scenario) {
 func (s *ScenarioRegistryImpl) ExportToRequest(req *http.Request, scenario interfaces.Scenario) (*http.Request, error) {
 	s.mux.Lock()
 	defer s.mux.Unlock()
+	return exportParameters(req, scenario)
+}
 
+// exportParameters exports the parameters of the application, route and scenario to the HTTP request, in this order,
+// so that the parameters of the scenario override the ones of its route and application.
+func exportParameters(req *http.Request, scenario interfaces.Scenario) (*http.Request, error) {
 	route := scenario.GetParentRoute()
-	application := route.GetParentApplication()
+	if route == nil {
+		return scenario.GetScenarioParametersRegistry().ExportToRequest(req)
+	}
 
 	// Export application parameters
-	if application != nil {
-		// Dereference the *interfaces.Application pointer to get the actual interface value
-
+	if application := route.GetParentApplication(); application != nil {
 		req, err := application.GetApplicationParametersRegistry().ExportToRequest(req)
 		if err != nil {
 			return req, err
@@ -87,20 +289,13 @@ func (s *ScenarioRegistryImpl) ExportToRequest(req *http.Request, scenario inter
 	}
 
 	// Export route parameters
-	if route != nil {
-		req, err := route.GetRouteParametersRegistry().ExportToRequest(req)
-		if err != nil {
-			return req, err
-		}
-	}
-
-	// Export scenario parameters
-	req, err := scenario.GetScenarioParametersRegistry().ExportToRequest(req)
+	req, err := route.GetRouteParametersRegistry().ExportToRequest(req)
 	if err != nil {
 		return req, err
 	}
 
-	return req, nil
+	// Export scenario parameters
+	return scenario.GetScenarioParametersRegistry().ExportToRequest(req)
 }
 
 // RunBeforeHooks executes all the Before hooks defined at the application, route, and scenario level.
@@ -108,7 +303,7 @@ func (s *ScenarioRegistryImpl) ExportToRequest(req *http.Request, scenario inter
 // 1. Before application hooks
 // 2. Before route hooks
 // 3. Before scenario hooks
-func (sr *ScenarioRegistryImpl) RunBeforeHooks(scenario interfaces.Scenario) (interfaces.Route, error) {
+func (sr *ScenarioRegistryImpl) RunBeforeHooks(ctx context.Context, scenario interfaces.Scenario) (interfaces.Route, error) {
 
 	route := scenario.GetParentRoute()
 	app := route.GetParentApplication()
@@ -118,19 +313,19 @@ func (sr *ScenarioRegistryImpl) RunBeforeHooks(scenario interfaces.Scenario) (in
 
 	// Run Before Application Hooks
 	var err error
-	route, err = appHooksRegistry.RunBeforeHooks(route)
+	route, err = appHooksRegistry.RunBeforeHooks(ctx, route)
 	if err != nil {
 		return nil, err
 	}
 
 	// Run Before Route Hooks
-	route, err = routeHooksRegistry.RunBeforeHooks(route)
+	route, err = routeHooksRegistry.RunBeforeHooks(ctx, route)
 	if err != nil {
 		return nil, err
 	}
 
 	// Run Before Scenario Hooks
-	route, err = scenarioHooksRegistry.RunBeforeHooks(route)
+	route, err = scenarioHooksRegistry.RunBeforeHooks(ctx, route)
 	if err != nil {
 		return nil, err
 	}
@@ -144,7 +339,7 @@ func (sr *ScenarioRegistryImpl) RunBeforeHooks(scenario interfaces.Scenario) (in
 // 2. After route hooks
 // 3. After scenario hooks
 
-func (sr *ScenarioRegistryImpl) RunAfterHooks(scenario interfaces.Scenario) (interfaces.Response, error) {
+func (sr *ScenarioRegistryImpl) RunAfterHooks(ctx context.Context, scenario interfaces.Scenario) (interfaces.Response, error) {
 
 	route := scenario.GetParentRoute()
 	app := route.GetParentApplication()
@@ -153,26 +348,298 @@ func (sr *ScenarioRegistryImpl) RunAfterHooks(scenario interfaces.Scenario) (int
 	scenarioHooksRegistry := scenario.GetScenarioHooksRegistry()
 
 	response := scenario.GetResponse()
-	response, err := scenarioHooksRegistry.RunAfterHooks(response)
+	response, err := scenarioHooksRegistry.RunAfterHooks(ctx, response)
 	if err != nil {
 		return response, err
 	}
 
 	// Run After Route Hooks
-	response, err = routeHooksRegistry.RunAfterHooks(response)
+	response, err = routeHooksRegistry.RunAfterHooks(ctx, response)
 	if err != nil {
 		return response, err
 	}
 
 	// Run After Application Hooks
-	response, err = appHooksRegistry.RunAfterHooks(response)
+	response, err = appHooksRegistry.RunAfterHooks(ctx, response)
+	if err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// Execute runs the Before Hooks, builds the request of the scenario and runs the Request Hooks on it,
+// sends it with its effective retry policy, runs the Response Hooks, stores the received response
+// on the scenario and runs the After Hooks.
+// A wait-until scenario re-sends a copy of the same request until its condition is met; the hooks still run only once,
+// so that their side effects do not pile up across polls. A scenario with an exchange performs it instead of sending the request.
+// The context bounds the whole execution, together with the timeout of the scenario if one is set.
+func (sr *ScenarioRegistryImpl) Execute(ctx context.Context, scenario interfaces.Scenario) (interfaces.Response, error) {
+	scenario.SetRequest(nil)
+	scenario.SetResponse(nil)
+	ctx = ContextWithScenario(ctx, scenario)
+
+	if timeout := scenario.GetTimeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	route, err := sr.RunBeforeHooks(ctx, scenario)
+	if err != nil {
+		return nil, err
+	}
+
+	hc := NewHookContext(ctx, scenario)
+	if err := sr.buildRequest(hc, route, scenario); err != nil {
+		return nil, err
+	}
+
+	var response interfaces.Response
+	if exchange := scenario.GetExchange(); exchange != nil {
+		start := time.Now()
+		response, err = exchange(hc)
+		hc.responseTime = time.Since(start)
+	} else if condition, policy := scenario.GetWaitUntil(); condition != nil {
+		response, err = sr.poll(hc, scenario, condition, policy)
+	} else {
+		response, err = sr.send(hc, scenario)
+	}
+	if response != nil {
+		scenario.SetResponse(response)
+	}
 	if err != nil {
 		return response, err
 	}
 
+	hc.SetResponse(response)
+	registries := lifecycleRegistries(scenario)
+	for i := len(registries) - 1; i >= 0; i-- {
+		if err := registries[i].RunResponseHooks(hc); err != nil {
+			scenario.SetResponse(hc.Response())
+			return hc.Response(), err
+		}
+	}
+	response = hc.Response()
+	scenario.SetResponse(response)
+
+	if schema := *scenario.GetResponseBodySchema(); schema != nil {
+		if err := response.ValidateBody(schema); err != nil {
+			return response, err
+		}
+	}
+
+	return sr.RunAfterHooks(ctx, scenario)
+}
+
+// buildRequest resolves the request of the scenario from the given route, records a copy of it on the scenario
+// and runs the Request Hooks of the application, route and scenario on it.
+// The body of the resulting request is buffered so that it can be sent again by retries and polls.
+func (sr *ScenarioRegistryImpl) buildRequest(hc *HookContext, route interfaces.Route, scenario interfaces.Scenario) error {
+	sr.mux.Lock()
+	req, err := resolveRequest(hc, route, scenario)
+	sr.mux.Unlock()
+	if err != nil {
+		return err
+	}
+	scenario.SetRequest(req.Clone(context.Background()))
+
+	hc.SetRequest(req)
+	for _, registry := range lifecycleRegistries(scenario) {
+		if err := registry.RunRequestHooks(hc); err != nil {
+			return err
+		}
+	}
+	return bufferBody(hc.Request())
+}
+
+// ResolveRequest returns the request of the scenario as it is built before any hook runs: the body of the scenario
+// or of its route, the parameters of the application, route and scenario merged, and the {{name}} references
+// expanded with the data row of the scenario and the variables of the application.
+// References to variables that are not set, e.g. captured by an earlier scenario, are left as they are.
+// The body of the request can be read again through its GetBody.
+func ResolveRequest(ctx context.Context, scenario interfaces.Scenario) (*http.Request, error) {
+	route := scenario.GetParentRoute()
+	if route == nil {
+		return nil, fmt.Errorf("scenario %s has no route", scenario.GetName())
+	}
+	return resolveRequest(NewHookContext(ctx, scenario), route, scenario)
+}
+
+func resolveRequest(hc *HookContext, route interfaces.Route, scenario interfaces.Scenario) (*http.Request, error) {
+	body := scenario.GetBody()
+	if body == nil {
+		body = route.GetBody()
+	}
+	if body != nil {
+		body = []byte(hc.Expand(string(body)))
+	}
+
+	req, err := route.NewRequest(hc, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err = exportParameters(req, scenario)
+	if err != nil {
+		return nil, err
+	}
+	expandRequest(hc, req)
+	return req, bufferBody(req)
+}
+
+// bufferBody reads the body of the request into memory and sets its GetBody, so that it can be read more than once.
+func bufferBody(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+	buffered, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	req.Body.Close()
+	req.ContentLength = int64(len(buffered))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(buffered)), nil
+	}
+	req.Body, _ = req.GetBody()
+	return nil
+}
+
+// expandRequest replaces the {{name}} references in the path, query parameters and headers of the request.
+func expandRequest(hc *HookContext, req *http.Request) {
+	req.URL.Path = hc.Expand(req.URL.Path)
+
+	query := req.URL.Query()
+	for key, values := range query {
+		for i, value := range values {
+			values[i] = hc.Expand(value)
+		}
+		query[key] = values
+	}
+	req.URL.RawQuery = query.Encode()
+
+	for key, values := range req.Header {
+		for i, value := range values {
+			values[i] = hc.Expand(value)
+		}
+		req.Header[key] = values
+	}
+}
+
+// send sends a copy of the request of the hook context with the effective retry policy of the scenario
+// and returns the handled response, checked against the spec validator of the application if it has one.
+func (sr *ScenarioRegistryImpl) send(hc *HookContext, scenario interfaces.Scenario) (interfaces.Response, error) {
+	req := hc.Request().Clone(hc)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = body
+	}
+
+	start := time.Now()
+	httpResp, attempts, err := SendWithRetry(hc, scenarioHTTPClient(scenario), req, EffectiveRetryPolicy(scenario))
+	hc.responseTime = time.Since(start)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	response, err := HandleResponse(httpResp)
+	if err != nil {
+		return nil, err
+	}
+	if resp, ok := response.(*Response); ok {
+		resp.Attempts = attempts
+		if validator := scenarioSpecValidator(scenario); validator != nil {
+			resp.SpecDrift = validator.Validate(req, response)
+		}
+	}
 	return response, nil
 }
 
+// scenarioHTTPClient returns the client of the application the scenario belongs to.
+func scenarioHTTPClient(scenario interfaces.Scenario) *http.Client {
+	route := scenario.GetParentRoute()
+	if route == nil {
+		return http.DefaultClient
+	}
+	return httpClient(route.GetParentApplication())
+}
+
+// scenarioSpecValidator returns the spec validator of the application the scenario belongs to, or nil.
+func scenarioSpecValidator(scenario interfaces.Scenario) interfaces.SpecValidator {
+	route := scenario.GetParentRoute()
+	if route == nil || route.GetParentApplication() == nil {
+		return nil
+	}
+	return route.GetParentApplication().GetSpecValidator()
+}
+
+// poll re-sends the request of the scenario until the condition is met or the poll policy times out.
+// On timeout the last response received is returned together with the last condition error.
+func (sr *ScenarioRegistryImpl) poll(hc *HookContext, scenario interfaces.Scenario, condition interfaces.Condition, policy interfaces.PollPolicy) (interfaces.Response, error) {
+	start := time.Now()
+	deadline := start.Add(policy.GetTimeout())
+
+	var last interfaces.Response
+	for polls := 1; ; polls++ {
+		response, err := sr.send(hc, scenario)
+		if response != nil {
+			last = response
+			if resp, ok := response.(*Response); ok {
+				resp.Polls = polls
+				resp.PollDuration = time.Since(start)
+			}
+			err = condition(response)
+		}
+		if err == nil {
+			return last, nil
+		}
+
+		wait := policy.NextInterval(polls)
+		if time.Now().Add(wait).After(deadline) {
+			return last, fmt.Errorf("condition not met after %d polls in %s: %v", polls, time.Since(start).Round(time.Millisecond), err)
+		}
+		if sleepErr := sleep(hc, wait); sleepErr != nil {
+			return last, fmt.Errorf("condition not met after %d polls: %v", polls, sleepErr)
+		}
+	}
+}
+
+// Run executes every scenario in the registry and adds their results to the report.
+// The scenarios run between the BeforeAll and AfterAll hooks of their route, and each of them is wrapped
+// in the lifecycle hooks of the application, route and scenario. The AfterAll hooks always run,
+// and their failures are added to the report.
+// It stops starting new scenarios once the context is done.
+func (sr *ScenarioRegistryImpl) Run(ctx context.Context, report interfaces.Report) {
+	scenarios := *sr.GetScenarios()
+	if len(scenarios) == 0 {
+		return
+	}
+	route := scenarios[0].GetParentRoute()
+	hooks := route.GetRouteHooksRegistry()
+
+	if err := hooks.RunBeforeAllHooks(ctx); err != nil {
+		skipScenarios(report, scenarios, fmt.Errorf("before all hooks of route %s failed: %v", route.GetName(), err))
+	} else {
+		for _, scenario := range scenarios {
+			if ctx.Err() != nil {
+				break
+			}
+			sr.runScenario(ctx, scenario, report)
+		}
+	}
+
+	tctx, cancel := teardownContext(ctx)
+	defer cancel()
+	if err := hooks.RunAfterAllHooks(tctx); err != nil {
+		report.AddError(fmt.Errorf("after all hooks of route %s failed: %v", route.GetName(), err))
+	}
+}
+
 // GetScenarios returns all the scenarios registered in the registry.
 func (s *ScenarioRegistryImpl) GetScenarios() *[]interfaces.Scenario {
 	s.mux.Lock()
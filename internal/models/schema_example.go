@@ -0,0 +1,38 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/qatoolist/RouTest/internal/generator"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// maxGenerateAttempts bounds the values generated until one validates against the schema.
+const maxGenerateAttempts = 20
+
+// generateFromSchema generates a value matching the schema with the given seed.
+// Values are generated until one validates, since a oneOf, uniqueItems or a pattern combined with length bounds
+// can be missed by the generator; the attempts are deterministic too.
+func generateFromSchema(loader gojsonschema.JSONLoader, seed int64, validate func(interface{}) error) (interface{}, error) {
+	schema, err := loader.LoadJSON()
+	if err != nil {
+		return nil, err
+	}
+	root, ok := schema.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("schema is not an object")
+	}
+
+	g := generator.New(seed)
+	for attempt := 1; ; attempt++ {
+		value := g.Generate(root)
+		err := validate(value)
+		if err == nil {
+			return value, nil
+		}
+		if attempt == maxGenerateAttempts {
+			return nil, fmt.Errorf("could not generate a value matching the schema: %v", err)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/qatoolist/RouTest/internal/interfaces"
+)
+
+// Session executes the scenarios of an application repeatedly, e.g. as a virtual user of a load test.
+// The BeforeAll hooks of the application, its routes and its scenarios run once when the session starts
+// and their AfterAll hooks once when it is closed, so that e.g. a login done in a BeforeAll hook is shared
+// by every execution. Every execution runs the BeforeEach, Around and AfterEach hooks as in a normal run.
+// A session is not safe for concurrent use: every virtual user needs its own application.
+type Session struct {
+	app       *Application
+	scenarios []interfaces.Scenario
+	started   []interfaces.HooksRegistry
+}
+
+// NewSession creates a new Session for the scenarios of the application, in the order of their routes.
+// Data-driven scenarios are expanded to one scenario per row.
+func NewSession(app *Application) *Session {
+	s := &Session{app: app}
+	for _, route := range Routes(app.RouteRegistry) {
+		for _, scenario := range *route.GetScenarioRegistry().GetScenarios() {
+			if dataset := scenario.GetDataset(); dataset != nil {
+				for i := 0; i < dataset.Len(); i++ {
+					s.scenarios = append(s.scenarios, NewDataRowScenario(scenario, i))
+				}
+				continue
+			}
+			s.scenarios = append(s.scenarios, scenario)
+		}
+	}
+	return s
+}
+
+// Scenarios returns the scenarios of the session.
+func (s *Session) Scenarios() []interfaces.Scenario {
+	return s.scenarios
+}
+
+// Start runs the BeforeAll hooks of the application, then of every route and scenario, and stops at the first failure.
+// Close must be called even when Start fails, to run the AfterAll hooks of the levels that were started.
+func (s *Session) Start(ctx context.Context) error {
+	if err := s.start(ctx, s.app.ApplicationHooksRegistry); err != nil {
+		return fmt.Errorf("before all hooks of the application failed: %v", err)
+	}
+
+	seen := make(map[interfaces.HooksRegistry]bool)
+	for _, scenario := range s.scenarios {
+		route := scenario.GetParentRoute()
+		if hooks := route.GetRouteHooksRegistry(); !seen[hooks] {
+			seen[hooks] = true
+			if err := s.start(ctx, hooks); err != nil {
+				return fmt.Errorf("before all hooks of route %s failed: %v", route.GetName(), err)
+			}
+		}
+		if hooks := scenario.GetScenarioHooksRegistry(); !seen[hooks] {
+			seen[hooks] = true
+			if err := s.start(ctx, hooks); err != nil {
+				return fmt.Errorf("before all hooks of scenario %s failed: %v", scenario.GetName(), err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Session) start(ctx context.Context, hooks interfaces.HooksRegistry) error {
+	s.started = append(s.started, hooks)
+	return hooks.RunBeforeAllHooks(ctx)
+}
+
+// Execute executes the scenario once, wrapped in its BeforeEach, Around and AfterEach hooks.
+func (s *Session) Execute(ctx context.Context, scenario interfaces.Scenario) (interfaces.Response, error) {
+	sr, ok := scenario.GetParentRoute().GetScenarioRegistry().(*ScenarioRegistryImpl)
+	if !ok {
+		return nil, fmt.Errorf("scenario %s: unsupported scenario registry", scenario.GetName())
+	}
+	return sr.runEach(ctx, scenario)
+}
+
+// Close runs the AfterAll hooks of every level that was started, innermost first.
+// All of them run, even after a failure or a cancelled context, and their failures are returned together.
+func (s *Session) Close(ctx context.Context) error {
+	tctx, cancel := teardownContext(ctx)
+	defer cancel()
+
+	var errs []error
+	for i := len(s.started) - 1; i >= 0; i-- {
+		if err := s.started[i].RunAfterAllHooks(tctx); err != nil {
+			errs = append(errs, fmt.Errorf("after all hooks failed: %v", err))
+		}
+	}
+	s.started = nil
+	return errors.Join(errs...)
+}
@@ -0,0 +1,18 @@
+package models
+
+import "regexp"
+
+// templateReference matches a {{name}} reference in a template.
+var templateReference = regexp.MustCompile(`\{\{\s*([\w.\-]+)\s*\}\}`)
+
+// ExpandTemplate replaces every {{name}} reference in s with the value returned by lookup.
+// References lookup does not know are left unchanged.
+func ExpandTemplate(s string, lookup func(name string) (string, bool)) string {
+	return templateReference.ReplaceAllStringFunc(s, func(ref string) string {
+		name := templateReference.FindStringSubmatch(ref)[1]
+		if value, ok := lookup(name); ok {
+			return value
+		}
+		return ref
+	})
+}
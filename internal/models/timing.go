@@ -0,0 +1,152 @@
+package models
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qatoolist/RouTest/internal/interfaces"
+)
+
+// Timing is the breakdown of the time spent on a request, captured with net/http/httptrace.
+type Timing struct {
+	dns              time.Duration
+	connect          time.Duration
+	tlsHandshake     time.Duration
+	timeToFirstByte  time.Duration
+	total            time.Duration
+	bytesSent        int64
+	bytesReceived    int64
+	connectionReused bool
+}
+
+// DNS returns the time spent resolving the host name.
+func (t *Timing) DNS() time.Duration {
+	return t.dns
+}
+
+// Connect returns the time spent establishing the TCP connection.
+func (t *Timing) Connect() time.Duration {
+	return t.connect
+}
+
+// TLSHandshake returns the time spent on the TLS handshake.
+func (t *Timing) TLSHandshake() time.Duration {
+	return t.tlsHandshake
+}
+
+// TimeToFirstByte returns the time between the start of the request and the first byte of the response.
+func (t *Timing) TimeToFirstByte() time.Duration {
+	return t.timeToFirstByte
+}
+
+// Total returns the time between the start of the request and the end of the response body.
+func (t *Timing) Total() time.Duration {
+	return t.total
+}
+
+// BytesSent returns the size of the request line, headers and body.
+func (t *Timing) BytesSent() int64 {
+	return t.bytesSent
+}
+
+// BytesReceived returns the size of the status line, headers and body of the response, the body as decoded by the client.
+func (t *Timing) BytesReceived() int64 {
+	return t.bytesReceived
+}
+
+// ConnectionReused reports whether the request was sent on a connection kept alive from a previous request.
+func (t *Timing) ConnectionReused() bool {
+	return t.connectionReused
+}
+
+// timingTrace collects the httptrace events of a request into a Timing.
+// The events of a single request can be reported from several goroutines, hence the lock.
+type timingTrace struct {
+	mu           sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	timing       Timing
+}
+
+// traceRequest returns a copy of the request whose progress is recorded by the returned trace.
+func traceRequest(req *http.Request) (*http.Request, *timingTrace) {
+	t := &timingTrace{start: time.Now()}
+	t.timing.bytesSent = int64(len(req.Method) + len(" ") + len(req.URL.RequestURI()) + len(" ") + len(req.Proto) + len("\r\n") + len("\r\n"))
+	if req.ContentLength > 0 {
+		t.timing.bytesSent += req.ContentLength
+	}
+
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timing.dns = time.Since(t.dnsStart)
+		},
+		ConnectStart: func(string, string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.connectStart = time.Now()
+		},
+		ConnectDone: func(string, string, error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timing.connect = time.Since(t.connectStart)
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timing.tlsHandshake = time.Since(t.tlsStart)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timing.connectionReused = info.Reused
+		},
+		WroteHeaderField: func(key string, values []string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timing.bytesSent += int64(len(key) + len(": ") + len(strings.Join(values, ",")) + len("\r\n"))
+		},
+		GotFirstResponseByte: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timing.timeToFirstByte = time.Since(t.start)
+		},
+	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace)), t
+}
+
+// done completes the timing once the response body was read, or the request failed.
+func (t *timingTrace) done(resp *http.Response, body []byte) interfaces.Timing {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	timing := t.timing
+	timing.total = time.Since(t.start)
+	if resp != nil {
+		received := len(resp.Proto) + len(" ") + len(resp.Status) + len("\r\n") + len("\r\n") + len(body)
+		for key, values := range resp.Header {
+			for _, value := range values {
+				received += len(key) + len(": ") + len(value) + len("\r\n")
+			}
+		}
+		timing.bytesReceived = int64(received)
+	}
+	return &timing
+}
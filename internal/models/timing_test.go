@@ -0,0 +1,115 @@
+package models
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestTraceRequest checks the phases recorded for a new TLS connection, then for a reused one.
+func TestTraceRequest(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte(`{"id": 1}`))
+	}))
+	listener := &countingListener{Listener: server.Listener}
+	server.Listener = listener
+	server.StartTLS()
+	defer server.Close()
+	client := server.Client()
+
+	send := func() (*Timing, int64) {
+		t.Helper()
+		read := atomic.LoadInt64(&listener.read)
+		req, _ := http.NewRequest(http.MethodPost, server.URL+"/jobs?page=2", strings.NewReader(`{"name": "job"}`))
+		req.Header.Set("Content-Type", "application/json")
+		traced, trace := traceRequest(req)
+		resp, err := client.Do(traced)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		return trace.done(resp, body).(*Timing), atomic.LoadInt64(&listener.read) - read
+	}
+
+	first, _ := send()
+	if first.ConnectionReused() {
+		t.Error("expected a new connection for the first request")
+	}
+	if first.Connect() <= 0 || first.TLSHandshake() <= 0 {
+		t.Errorf("expected the connect and TLS handshake phases, got %s and %s", first.Connect(), first.TLSHandshake())
+	}
+	if first.TimeToFirstByte() < 20*time.Millisecond || first.Total() < first.TimeToFirstByte() {
+		t.Errorf("expected the time to first byte to include the server delay and be within the total, got %s and %s", first.TimeToFirstByte(), first.Total())
+	}
+	if want := int64(len("HTTP/1.1 200 OK\r\n\r\n") + len(`{"id": 1}`)); first.BytesReceived() <= want {
+		t.Errorf("expected more than %d bytes received with the headers, got %d", want, first.BytesReceived())
+	}
+
+	second, read := send()
+	if !second.ConnectionReused() {
+		t.Error("expected the connection to be reused for the second request")
+	}
+	if second.Connect() != 0 || second.TLSHandshake() != 0 || second.DNS() != 0 {
+		t.Errorf("expected no DNS, connect or TLS handshake phase on a reused connection, got %s, %s and %s", second.DNS(), second.Connect(), second.TLSHandshake())
+	}
+	if second.BytesSent() >= read {
+		t.Errorf("expected less than the %d bytes read by the server, which include the TLS records, got %d", read, second.BytesSent())
+	}
+}
+
+// TestTraceRequestBytesSent checks that the bytes sent are the bytes the server read.
+func TestTraceRequestBytesSent(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+	}))
+	listener := &countingListener{Listener: server.Listener}
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/jobs?page=2", strings.NewReader(`{"name": "job"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Add("X-Tags", "a")
+	traced, trace := traceRequest(req)
+	resp, err := server.Client().Do(traced)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if sent, read := trace.done(resp, nil).BytesSent(), atomic.LoadInt64(&listener.read); sent != read {
+		t.Errorf("expected the %d bytes read by the server, got %d", read, sent)
+	}
+}
+
+// countingListener counts the bytes read from all its connections.
+type countingListener struct {
+	net.Listener
+	read int64
+}
+
+func (l *countingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &countingConn{Conn: conn, read: &l.read}, nil
+}
+
+type countingConn struct {
+	net.Conn
+	read *int64
+}
+
+func (c *countingConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	atomic.AddInt64(c.read, int64(n))
+	return n, err
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"sync"
+
+	"github.com/qatoolist/RouTest/internal/interfaces"
+)
+
+// VariableStore is a store of values captured while the scenarios run.
+type VariableStore struct {
+	mu        sync.RWMutex
+	variables map[string]string
+}
+
+// NewVariableStore creates a new empty VariableStore.
+func NewVariableStore() interfaces.Variables {
+	return &VariableStore{
+		variables: make(map[string]string),
+	}
+}
+
+// Get returns the value of the variable and whether it is defined.
+func (v *VariableStore) Get(key string) (string, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	value, ok := v.variables[key]
+	return value, ok
+}
+
+// Set defines or overrides the value of the variable.
+func (v *VariableStore) Set(key string, value string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.variables[key] = value
+}
+
+// All returns a copy of every variable defined.
+func (v *VariableStore) All() map[string]string {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	copy := make(map[string]string, len(v.variables))
+	for key, value := range v.variables {
+		copy[key] = value
+	}
+	return copy
+}
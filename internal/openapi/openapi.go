@@ -0,0 +1,459 @@
+// Package openapi checks live responses against an OpenAPI 3 document. Every difference between what the service
+// returned and what the document describes is reported as spec drift: an undocumented path or operation,
+// an undocumented status code, header or content type, and a body that does not match the documented schema.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/qatoolist/RouTest/internal/interfaces"
+	"github.com/xeipuuv/gojsonschema"
+	"gopkg.in/yaml.v3"
+)
+
+// IgnoredHeaders are the response headers set by HTTP servers and proxies rather than by the API,
+// which documents never describe. They are never reported as undocumented.
+var IgnoredHeaders = []string{
+	"Access-Control-Allow-Credentials", "Access-Control-Allow-Headers", "Access-Control-Allow-Methods",
+	"Access-Control-Allow-Origin", "Access-Control-Expose-Headers", "Access-Control-Max-Age",
+	"Age", "Alt-Svc", "Cache-Control", "Connection", "Content-Encoding", "Content-Length", "Content-Type",
+	"Date", "Etag", "Expires", "Keep-Alive", "Last-Modified", "Pragma", "Server", "Strict-Transport-Security",
+	"Transfer-Encoding", "Vary", "Via",
+}
+
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Validator checks responses against an OpenAPI document.
+type Validator struct {
+	doc   map[string]interface{}
+	paths []*pathItem
+}
+
+// pathItem is a path of the document, matched against the path of requests with every server prefix.
+type pathItem struct {
+	template string
+	patterns []*regexp.Regexp
+	params   int
+	item     map[string]interface{}
+}
+
+// Load reads the OpenAPI document at the given path, in YAML or JSON.
+func Load(path string) (*Validator, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	v, err := New(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return v, nil
+}
+
+// New creates a Validator from an OpenAPI document in YAML or JSON.
+func New(data []byte) (*Validator, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	doc, ok := stringKeys(raw).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected an OpenAPI document, got %T", raw)
+	}
+	version, _ := doc["openapi"].(string)
+	if !strings.HasPrefix(version, "3.") {
+		return nil, fmt.Errorf("unsupported OpenAPI version %q, expected 3.x", version)
+	}
+
+	prefixes := serverPrefixes(doc)
+	v := &Validator{doc: doc}
+	paths, _ := doc["paths"].(map[string]interface{})
+	for template, item := range paths {
+		resolved, ok := v.resolve(item).(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("path %s: expected a path item", template)
+		}
+		p := &pathItem{template: template, params: strings.Count(template, "{"), item: resolved}
+		for _, prefix := range prefixes {
+			p.patterns = append(p.patterns, compile(prefix+template))
+		}
+		v.paths = append(v.paths, p)
+	}
+	// The most specific paths first, so that /users/me is preferred over /users/{id}.
+	sort.Slice(v.paths, func(i, j int) bool {
+		if v.paths[i].params != v.paths[j].params {
+			return v.paths[i].params < v.paths[j].params
+		}
+		return v.paths[i].template < v.paths[j].template
+	})
+	return v, nil
+}
+
+// Validate returns the spec drift of the response to the request, one message per difference with the document.
+func (v *Validator) Validate(req *http.Request, resp interfaces.Response) []string {
+	path := v.find(req.URL.Path)
+	if path == nil {
+		return []string{fmt.Sprintf("undocumented path %s %s", req.Method, req.URL.Path)}
+	}
+	operation, ok := v.resolve(path.item[strings.ToLower(req.Method)]).(map[string]interface{})
+	if !ok {
+		return []string{fmt.Sprintf("undocumented operation %s %s", req.Method, path.template)}
+	}
+	name := req.Method + " " + path.template
+
+	status := strconv.Itoa(resp.GetStatusCode())
+	responses, _ := operation["responses"].(map[string]interface{})
+	documented, ok := v.resolve(response(responses, status)).(map[string]interface{})
+	if !ok {
+		return []string{fmt.Sprintf("undocumented status %s for %s", status, name)}
+	}
+
+	var drift []string
+	headers, _ := documented["headers"].(map[string]interface{})
+	for _, header := range resp.GetHeaders() {
+		if !contains(IgnoredHeaders, header.Key()) && !hasKey(headers, header.Key()) {
+			drift = append(drift, fmt.Sprintf("undocumented header %s in the %s response of %s", header.Key(), status, name))
+		}
+	}
+
+	content, _ := documented["content"].(map[string]interface{})
+	if len(resp.Bytes()) == 0 || len(content) == 0 {
+		if len(resp.Bytes()) > 0 {
+			drift = append(drift, fmt.Sprintf("undocumented body in the %s response of %s", status, name))
+		}
+		return drift
+	}
+
+	contentType, _ := resp.ContentType()
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = contentType
+	}
+	media, ok := v.resolve(mediaTypeObject(content, mediaType)).(map[string]interface{})
+	if !ok {
+		return append(drift, fmt.Sprintf("undocumented content type %q in the %s response of %s", contentType, status, name))
+	}
+	schema, ok := media["schema"]
+	if !ok || !isJSON(mediaType) {
+		return drift
+	}
+	if err := v.validate(schema, resp.Bytes()); err != nil {
+		drift = append(drift, fmt.Sprintf("body does not match the schema of the %s %s response of %s: %v", status, mediaType, name, err))
+	}
+	return drift
+}
+
+// Operation is an operation documented by the document.
+type Operation struct {
+	Method string
+	Path   string
+
+	// Statuses are the documented response codes, e.g. 200, 4XX or default.
+	Statuses   []string
+	Parameters []Parameter
+}
+
+// Parameter is a documented parameter of an operation.
+type Parameter struct {
+	Name string
+
+	// In is where the parameter is sent: path, query, header or cookie.
+	In string
+}
+
+// Operations returns the operations documented by the document, ordered by path and method.
+// The parameters of an operation include those of its path item that it does not override.
+func (v *Validator) Operations() []Operation {
+	var operations []Operation
+	for _, path := range v.paths {
+		for _, method := range methods {
+			operation, ok := v.resolve(path.item[method]).(map[string]interface{})
+			if !ok {
+				continue
+			}
+			op := Operation{Method: strings.ToUpper(method), Path: path.template}
+			responses, _ := operation["responses"].(map[string]interface{})
+			for status := range responses {
+				op.Statuses = append(op.Statuses, status)
+			}
+			sort.Strings(op.Statuses)
+
+			seen := map[Parameter]bool{}
+			for _, list := range []interface{}{operation["parameters"], path.item["parameters"]} {
+				params, _ := list.([]interface{})
+				for _, param := range params {
+					p, _ := v.resolve(param).(map[string]interface{})
+					name, _ := p["name"].(string)
+					in, _ := p["in"].(string)
+					if parameter := (Parameter{Name: name, In: in}); name != "" && !seen[parameter] {
+						seen[parameter] = true
+						op.Parameters = append(op.Parameters, parameter)
+					}
+				}
+			}
+			operations = append(operations, op)
+		}
+	}
+	sort.SliceStable(operations, func(i, j int) bool {
+		return operations[i].Path < operations[j].Path
+	})
+	return operations
+}
+
+// Match returns the documented path template matching the path of a request, e.g. /users/{id} for /users/42.
+func (v *Validator) Match(path string) (string, bool) {
+	if p := v.find(path); p != nil {
+		return p.template, true
+	}
+	return "", false
+}
+
+// find returns the path item matching the path of a request, or nil if none does.
+func (v *Validator) find(path string) *pathItem {
+	for _, p := range v.paths {
+		for _, pattern := range p.patterns {
+			if pattern.MatchString(path) {
+				return p
+			}
+		}
+	}
+	return nil
+}
+
+// validate validates a JSON body against a schema of the document. The components of the document are
+// added to the schema so that its references resolve, and the nullable keyword of OpenAPI 3.0 is translated.
+func (v *Validator) validate(schema interface{}, body []byte) error {
+	root := map[string]interface{}{}
+	if m, ok := nullable(schema).(map[string]interface{}); ok {
+		for key, value := range m {
+			root[key] = value
+		}
+	} else {
+		root["allOf"] = []interface{}{nullable(schema)}
+	}
+	if components, ok := v.doc["components"]; ok {
+		root["components"] = nullable(components)
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return fmt.Errorf("invalid JSON: %v", err)
+	}
+	result, err := gojsonschema.Validate(gojsonschema.NewGoLoader(root), gojsonschema.NewGoLoader(data))
+	if err != nil {
+		return err
+	}
+	if result.Valid() {
+		return nil
+	}
+	messages := make([]string, 0, len(result.Errors()))
+	for _, e := range result.Errors() {
+		messages = append(messages, e.String())
+	}
+	return fmt.Errorf("%s", strings.Join(messages, "; "))
+}
+
+// resolve follows the local reference of an object of the document, if it has one.
+func (v *Validator) resolve(value interface{}) interface{} {
+	for depth := 0; depth < 32; depth++ {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return value
+		}
+		ref, ok := m["$ref"].(string)
+		if !ok || !strings.HasPrefix(ref, "#/") {
+			return value
+		}
+		value = v.doc
+		for _, token := range strings.Split(ref[2:], "/") {
+			token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+			obj, ok := value.(map[string]interface{})
+			if !ok {
+				return nil
+			}
+			value = obj[token]
+		}
+	}
+	return nil
+}
+
+// response returns the response object documented for a status: the exact code first,
+// then its range, e.g. 4XX, then the default response.
+func response(responses map[string]interface{}, status string) interface{} {
+	if r, ok := responses[status]; ok {
+		return r
+	}
+	for key, r := range responses {
+		if strings.EqualFold(key, status[:1]+"XX") {
+			return r
+		}
+	}
+	return responses["default"]
+}
+
+// mediaTypeObject returns the media type object documented for a media type: the exact type first,
+// then its wildcard, e.g. application/*, then */*.
+func mediaTypeObject(content map[string]interface{}, mediaType string) interface{} {
+	candidates := []string{mediaType}
+	if i := strings.Index(mediaType, "/"); i > 0 {
+		candidates = append(candidates, mediaType[:i]+"/*")
+	}
+	candidates = append(candidates, "*/*")
+	for _, candidate := range candidates {
+		for key, media := range content {
+			if documented, _, err := mime.ParseMediaType(key); err == nil && strings.EqualFold(documented, candidate) {
+				return media
+			}
+		}
+	}
+	return nil
+}
+
+// serverPrefixes returns the path of every server of the document, which the paths of the document are relative to.
+func serverPrefixes(doc map[string]interface{}) []string {
+	servers, _ := doc["servers"].([]interface{})
+	seen := map[string]bool{}
+	var prefixes []string
+	for _, server := range servers {
+		s, _ := server.(map[string]interface{})
+		raw, _ := s["url"].(string)
+		prefix := raw
+		if u, err := url.Parse(raw); err == nil {
+			prefix = u.Path
+		}
+		prefix = strings.TrimSuffix(prefix, "/")
+		if !seen[prefix] {
+			seen[prefix] = true
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	if !seen[""] {
+		prefixes = append(prefixes, "")
+	}
+	return prefixes
+}
+
+var templateParam = regexp.MustCompile(`\{[^/{}]+\}`)
+
+// compile returns the regular expression matching the paths of a path template, e.g. /users/{id}.
+func compile(template string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	last := 0
+	for _, loc := range templateParam.FindAllStringIndex(template, -1) {
+		b.WriteString(regexp.QuoteMeta(template[last:loc[0]]))
+		b.WriteString("[^/]+")
+		last = loc[1]
+	}
+	b.WriteString(regexp.QuoteMeta(template[last:]))
+	b.WriteString("/?$")
+	return regexp.MustCompile(b.String())
+}
+
+// nullable returns a copy of a schema where the nullable keyword of OpenAPI 3.0 is expressed as a null type.
+func nullable(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, e := range v {
+			out[key] = nullable(e)
+		}
+		if isNullable, _ := v["nullable"].(bool); !isNullable {
+			return out
+		}
+		delete(out, "nullable")
+		if ref, ok := v["$ref"]; ok {
+			return map[string]interface{}{"anyOf": []interface{}{
+				map[string]interface{}{"$ref": ref},
+				map[string]interface{}{"type": "null"},
+			}}
+		}
+		if t, ok := v["type"].(string); ok {
+			out["type"] = []interface{}{t, "null"}
+		}
+		if enum, ok := out["enum"].([]interface{}); ok {
+			out["enum"] = append(enum, nil)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, e := range v {
+			out[i] = nullable(e)
+		}
+		return out
+	}
+	return value
+}
+
+// stringKeys converts the mappings decoded from YAML to mappings with string keys, e.g. the status codes of responses.
+func stringKeys(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, e := range v {
+			out[fmt.Sprint(key)] = stringKeys(e)
+		}
+		return out
+	case map[string]interface{}:
+		for key, e := range v {
+			v[key] = stringKeys(e)
+		}
+		return v
+	case []interface{}:
+		for i, e := range v {
+			v[i] = stringKeys(e)
+		}
+		return v
+	}
+	return value
+}
+
+func isJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+func hasKey(m map[string]interface{}, name string) bool {
+	for key := range m {
+		if strings.EqualFold(key, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, name string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, name) {
+			return true
+		}
+	}
+	return false
+}
+
+/* Example usage -
+
+validator, err := openapi.Load("openapi.yaml")
+if err != nil {
+    log.Fatal(err)
+}
+app.SetSpecValidator(validator)
+
+report := app.Run(ctx)
+for _, result := range report.Results() {
+    if resp := result.Response(); resp != nil {
+        for _, drift := range resp.GetSpecDrift() {
+            fmt.Printf("%s/%s: %s\n", result.RouteName(), result.ScenarioName(), drift)
+        }
+    }
+}
+
+*/
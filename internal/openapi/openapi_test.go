@@ -0,0 +1,171 @@
+package openapi
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/qatoolist/RouTest/internal/models"
+)
+
+const testDocument = `
+openapi: 3.0.3
+servers:
+  - url: https://api.example.com/v1
+  - url: /
+paths:
+  /users/{id}:
+    parameters:
+      - {name: id, in: path}
+    get:
+      parameters:
+        - {name: fields, in: query}
+      responses:
+        200:
+          headers:
+            X-Rate-Limit: {schema: {type: integer}}
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/User'}
+        4XX:
+          description: client error
+        default:
+          description: unexpected error
+  /users/me:
+    get:
+      responses:
+        '204': {description: no content}
+  /users/{id}/posts/{post}:
+    $ref: '#/components/pathItems/post'
+components:
+  pathItems:
+    post:
+      delete:
+        responses:
+          '204': {description: deleted}
+  schemas:
+    User:
+      type: object
+      required: [id, name]
+      properties:
+        id: {type: integer}
+        name: {type: string, nullable: true}
+        role: {type: string, enum: [admin, user], nullable: true}
+        manager: {$ref: '#/components/schemas/User', nullable: true}
+`
+
+// TestMatch checks the documented path templates matched by the paths of requests.
+func TestMatch(t *testing.T) {
+	v, err := New([]byte(testDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/users/42", "/users/{id}"},
+		{"/users/42/", "/users/{id}"},
+		{"/v1/users/42", "/users/{id}"},
+		{"/users/me", "/users/me"},
+		{"/v1/users/me", "/users/me"},
+		{"/users/42/posts/7", "/users/{id}/posts/{post}"},
+		{"/users", ""},
+		{"/users/42/comments", ""},
+		{"/v2/users/42", ""},
+	}
+	for _, test := range tests {
+		var got string
+		if p := v.find(test.path); p != nil {
+			got = p.template
+		}
+		if got != test.want {
+			t.Errorf("%s: expected %q, got %q", test.path, test.want, got)
+		}
+	}
+}
+
+// TestValidate checks the spec drift of responses, the nullable keyword of OpenAPI 3.0 included.
+func TestValidate(t *testing.T) {
+	v, err := New([]byte(testDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonHeaders := map[string]string{"Content-Type": "application/json; charset=utf-8"}
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		status  int
+		headers map[string]string
+		body    string
+		want    []string
+	}{
+		{name: "valid", method: "GET", path: "/users/1", status: 200, headers: jsonHeaders, body: `{"id": 1, "name": "bob", "role": "admin"}`},
+		{name: "nullable", method: "GET", path: "/users/1", status: 200, headers: jsonHeaders, body: `{"id": 1, "name": null, "role": null, "manager": null}`},
+		{name: "nullable reference", method: "GET", path: "/users/1", status: 200, headers: jsonHeaders, body: `{"id": 1, "name": "a", "manager": {"id": 2, "name": null}}`},
+		{name: "status range", method: "GET", path: "/users/1", status: 404},
+		{name: "default status", method: "GET", path: "/users/1", status: 503},
+		{name: "referenced path item", method: "DELETE", path: "/users/1/posts/2", status: 204},
+		{name: "schema", method: "GET", path: "/users/1", status: 200, headers: jsonHeaders, body: `{"id": "1", "role": "owner"}`,
+			want: []string{"body does not match the schema of the 200 application/json response of GET /users/{id}"}},
+		{name: "nullable without the keyword", method: "GET", path: "/users/1", status: 200, headers: jsonHeaders, body: `{"id": null, "name": "a"}`,
+			want: []string{"body does not match the schema"}},
+		{name: "invalid JSON", method: "GET", path: "/users/1", status: 200, headers: jsonHeaders, body: `{`,
+			want: []string{"invalid JSON"}},
+		{name: "header", method: "GET", path: "/users/1", status: 200, headers: map[string]string{"Content-Type": "application/json", "X-Rate-Limit": "10", "X-Debug": "1"}, body: `{"id": 1, "name": "a"}`,
+			want: []string{"undocumented header X-Debug in the 200 response of GET /users/{id}"}},
+		{name: "content type", method: "GET", path: "/users/1", status: 200, headers: map[string]string{"Content-Type": "text/plain"}, body: "bob",
+			want: []string{`undocumented content type "text/plain" in the 200 response of GET /users/{id}`}},
+		{name: "body", method: "GET", path: "/users/me", status: 204, body: "x",
+			want: []string{"undocumented body in the 204 response of GET /users/me"}},
+		{name: "status", method: "GET", path: "/users/me", status: 200,
+			want: []string{"undocumented status 200 for GET /users/me"}},
+		{name: "operation", method: "POST", path: "/users/1", status: 201,
+			want: []string{"undocumented operation POST /users/{id}"}},
+		{name: "path", method: "GET", path: "/orders", status: 200,
+			want: []string{"undocumented path GET /orders"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, _ := http.NewRequest(test.method, "https://api.example.com"+test.path, nil)
+			drift := v.Validate(req, models.NewStaticResponse(test.status, test.headers, []byte(test.body)))
+			if len(drift) != len(test.want) {
+				t.Fatalf("expected %d differences, got %q", len(test.want), drift)
+			}
+			for i := range drift {
+				if !strings.Contains(drift[i], test.want[i]) {
+					t.Errorf("expected %q to contain %q", drift[i], test.want[i])
+				}
+			}
+		})
+	}
+}
+
+// TestNewInvalid checks the documents that are refused.
+func TestNewInvalid(t *testing.T) {
+	for _, doc := range []string{"swagger: '2.0'", "- a", "openapi: 3.0.0\npaths: {/a: 1}"} {
+		if _, err := New([]byte(doc)); err == nil {
+			t.Errorf("%q: expected an error", doc)
+		}
+	}
+}
+
+// TestOperations checks the documented operations, with the parameters they inherit from their path.
+func TestOperations(t *testing.T) {
+	v, err := New([]byte(testDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, op := range v.Operations() {
+		got = append(got, op.Method+" "+op.Path+" "+strings.Join(op.Statuses, ","))
+		if op.Path == "/users/{id}" && len(op.Parameters) != 2 {
+			t.Errorf("expected the fields and id parameters, got %v", op.Parameters)
+		}
+	}
+	want := "GET /users/me 204|GET /users/{id} 200,4XX,default|DELETE /users/{id}/posts/{post} 204"
+	if strings.Join(got, "|") != want {
+		t.Errorf("expected %s, got %s", want, strings.Join(got, "|"))
+	}
+}
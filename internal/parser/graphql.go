@@ -0,0 +1,149 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/qatoolist/RouTest/internal/graphql"
+	"github.com/qatoolist/RouTest/internal/interfaces"
+)
+
+// GraphQLSpec describes the GraphQL operation sent by a route or scenario over HTTP POST, as a JSON body.
+// The spec of a scenario is merged over the one of its route: its query and operation name replace the ones
+// of the route, and its variables are added to the ones of the route.
+type GraphQLSpec struct {
+	// Query is the GraphQL document.
+	Query string `yaml:"query"`
+
+	// QueryFile is the path of the file of the GraphQL document, relative to the suite file, instead of Query.
+	QueryFile string `yaml:"query_file"`
+
+	// OperationName is the operation of the document to execute, required when it has several.
+	OperationName string `yaml:"operation_name"`
+
+	// Variables are the values of the variables of the operation. Strings may reference the columns of
+	// the data row or the captured variables as {{name}}.
+	Variables map[string]interface{} `yaml:"variables"`
+}
+
+// merge returns the spec of the scenario merged over the spec of the route.
+func (gs *GraphQLSpec) merge(scenario *GraphQLSpec) *GraphQLSpec {
+	if scenario == nil {
+		return gs
+	}
+	merged := *gs
+	if scenario.Query != "" || scenario.QueryFile != "" {
+		merged.Query, merged.QueryFile = scenario.Query, scenario.QueryFile
+	}
+	if scenario.OperationName != "" {
+		merged.OperationName = scenario.OperationName
+	}
+	merged.Variables = make(map[string]interface{}, len(gs.Variables)+len(scenario.Variables))
+	for key, value := range gs.Variables {
+		merged.Variables[key] = value
+	}
+	for key, value := range scenario.Variables {
+		merged.Variables[key] = value
+	}
+	return &merged
+}
+
+// operation returns the operation described by the spec, reading its query file from dir.
+func (gs *GraphQLSpec) operation(dir string) (graphql.Operation, error) {
+	op := graphql.Operation{Query: gs.Query, OperationName: gs.OperationName, Variables: gs.Variables}
+	if gs.Query != "" && gs.QueryFile != "" {
+		return op, errors.New("query and query_file are mutually exclusive")
+	}
+	if gs.QueryFile != "" {
+		path := gs.QueryFile
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return op, err
+		}
+		op.Query = string(data)
+	}
+	if strings.TrimSpace(op.Query) == "" {
+		return op, errors.New("query is not defined")
+	}
+	return op, nil
+}
+
+// hasHeader reports whether the parameters set the header, whatever its case.
+func (ps ParametersSpec) hasHeader(name string) bool {
+	for key := range ps.Headers {
+		if strings.EqualFold(key, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// body returns the JSON body of the operation of the spec, or nil when the spec is nil
+// or has no query of its own.
+func (gs *GraphQLSpec) body(dir string) ([]byte, error) {
+	if gs == nil || gs.Query == "" && gs.QueryFile == "" {
+		return nil, nil
+	}
+	op, err := gs.operation(dir)
+	if err != nil {
+		return nil, err
+	}
+	return op.Body()
+}
+
+// graphQLHooks returns the Response Hooks checking the GraphQL result of the scenario against its expectations.
+func (es ExpectSpec) graphQLHooks() ([]interfaces.ContextHook, error) {
+	hooks := []interfaces.ContextHook{graphql.ErrorsHook(es.Errors)}
+	if len(es.Data) > 0 {
+		hook, err := graphql.DataHook(es.Data)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, hook)
+	}
+	return hooks, nil
+}
+
+// validateGraphQL validates the operation of every GraphQL route and scenario against the schema at path.
+func (s *Suite) validateGraphQL(path string) error {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(s.dir, path)
+	}
+	schema, err := graphql.LoadSchema(path)
+	if err != nil {
+		return err
+	}
+
+	var messages []string
+	validate := func(where string, spec *GraphQLSpec) {
+		op, err := spec.operation(s.dir)
+		if err != nil {
+			messages = append(messages, fmt.Sprintf("%s: %v", where, err))
+			return
+		}
+		for _, err := range schema.Validate(op.Query, op.OperationName, op.Variables) {
+			messages = append(messages, fmt.Sprintf("%s: %v", where, err))
+		}
+	}
+	for _, rs := range s.Routes {
+		if rs.GraphQL == nil {
+			continue
+		}
+		if len(rs.Scenarios) == 0 {
+			validate("route "+rs.Name, rs.GraphQL)
+		}
+		for _, ss := range rs.Scenarios {
+			validate("route "+rs.Name+": scenario "+ss.Name, rs.GraphQL.merge(ss.GraphQL))
+		}
+	}
+	if len(messages) > 0 {
+		return fmt.Errorf("invalid GraphQL operations:\n  %s", strings.Join(messages, "\n  "))
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"errors"
+	"path/filepath"
+
+	"github.com/qatoolist/RouTest/internal/grpc"
+	"github.com/qatoolist/RouTest/internal/interfaces"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// GRPCSpec describes the unary method invoked by the scenarios of a gRPC route, with the body of the route
+// or scenario as its JSON input message. Its descriptor is read from a descriptor set or resolved with the
+// server reflection service of the server.
+type GRPCSpec struct {
+	// Method is the full name of the method, e.g. shop.v1.Orders/GetOrder.
+	Method string `yaml:"method"`
+
+	// Protoset is the path of the descriptor set defining the method, relative to the suite file, as written by
+	// protoc --descriptor_set_out --include_imports or buf build -o.
+	Protoset string `yaml:"protoset"`
+
+	// Reflection resolves the method with server reflection instead of a descriptor set.
+	Reflection bool `yaml:"reflection"`
+}
+
+// path returns the HTTP/2 path of the method, e.g. /shop.v1.Orders/GetOrder.
+func (gs *GRPCSpec) path() (string, error) {
+	service, method, err := grpc.ParseMethod(gs.Method)
+	if err != nil {
+		return "", err
+	}
+	return "/" + service + "/" + method, nil
+}
+
+// exchange returns the exchange invoking the method. The descriptor sets are loaded once per suite, and
+// the connections and reflected descriptors are shared by the routes of the suite.
+func (gs *GRPCSpec) exchange(suite *Suite) (interfaces.Exchange, error) {
+	if (gs.Protoset != "") == gs.Reflection {
+		return nil, errors.New("either protoset or reflection is required")
+	}
+	if suite.grpcClients == nil {
+		suite.grpcClients = grpc.NewClients()
+	}
+	if gs.Reflection {
+		return grpc.Exchange(grpc.Options{Method: gs.Method, Clients: suite.grpcClients})
+	}
+
+	path := gs.Protoset
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(suite.dir, path)
+	}
+	files, ok := suite.protosets[path]
+	if !ok {
+		var err error
+		if files, err = grpc.LoadDescriptorSet(path); err != nil {
+			return nil, err
+		}
+		if suite.protosets == nil {
+			suite.protosets = make(map[string]*protoregistry.Files)
+		}
+		suite.protosets[path] = files
+	}
+	return grpc.Exchange(grpc.Options{Method: gs.Method, Files: files, Clients: suite.grpcClients})
+}
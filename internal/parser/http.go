@@ -0,0 +1,933 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/qatoolist/RouTest/internal/interfaces"
+	"github.com/qatoolist/RouTest/internal/jsonpath"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// httpMethods are the methods a request line may start with.
+	httpMethods = map[string]bool{
+		"GET": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true, "HEAD": true, "OPTIONS": true, "TRACE": true, "CONNECT": true,
+	}
+
+	// httpVariable matches an @name = value file variable definition.
+	httpVariable = regexp.MustCompile(`^@([\w.\-]+)\s*=\s*(.*)$`)
+
+	// httpName matches a # @name or // @name request name.
+	httpName = regexp.MustCompile(`^(?:#|//)\s*@name\s*=?\s*(.+)$`)
+
+	// httpReference matches a {{name}} reference, including system variables such as {{$processEnv HOME}}.
+	httpReference = regexp.MustCompile(`\{\{\s*([^{}]+?)\s*\}\}`)
+
+	// httpRequestReference matches the value of a named request referenced as {{login.response.body.$.token}}
+	// or {{login.response.headers.X-Token}}.
+	httpRequestReference = regexp.MustCompile(`^([\w\-]+)\.response\.(body|headers)\.(.+)$`)
+)
+
+// ParseHTTP parses a suite from a .http file, the format of the REST clients of VS Code and JetBrains IDEs.
+// Every request of the file, separated by ### lines, becomes a route with a single scenario named after
+// the request, in the order of the file. Requests with the same name get routes suffixed with -2, -3 and so on.
+//
+// @name = value lines define file variables, referenced as {{name}} anywhere in the requests. References
+// to other names are expanded when the scenario runs, with the variables of the environment configuration
+// and the values captured by earlier requests. {{$processEnv NAME}} and {{$dotenv NAME}} read the environment
+// and the .env file next to the suite.
+//
+// A request named with # @name login can be referenced by the following ones as {{login.response.body.$.token}}
+// or {{login.response.headers.X-Token}}. Response handler blocks, > {% ... %}, are turned into assertions
+// and captures as long as they are made of client.test, client.assert and client.global.set calls
+// on response.status, response.body, response.headers.valueOf and response.contentType.mimeType.
+//
+// URLs are absolute, e.g. https://api.example.com/users, or relative to the host of the environment,
+// e.g. /users or {{host}}/users with host not defined in the file. All the absolute URLs share one host.
+func ParseHTTP(data []byte) (*Suite, error) {
+	return parseHTTP(data, ".")
+}
+
+// httpRequest is a request of a .http file.
+type httpRequest struct {
+	name    string
+	line    int
+	method  string
+	url     string
+	headers [][2]string
+	body    string
+	handler string
+}
+
+// httpHandler holds the assertions and captures of the response handler of a request.
+type httpHandler struct {
+	checks   []httpCheck
+	captures []httpCapture
+}
+
+// httpCheck is an assertion on a value of the response.
+type httpCheck struct {
+	source   httpSource
+	operator string
+	expected interface{}
+	message  string
+}
+
+// httpCapture stores a value of the response as a variable.
+type httpCapture struct {
+	variable string
+	source   httpSource
+}
+
+// httpSource is a value of a response: its status, a header, its content type, or a field of its body.
+type httpSource struct {
+	kind   string // status, header, content-type or body
+	header string
+	path   *jsonpath.Path
+}
+
+func parseHTTP(data []byte, dir string) (*Suite, error) {
+	requests, variables, err := splitHTTP(string(data), dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(requests) == 0 {
+		return nil, errors.New("the file does not define any request")
+	}
+
+	dotenv, _ := godotenv.Read(filepath.Join(dir, ".env"))
+	expand := func(s string) (string, error) {
+		return expandHTTP(s, variables, dotenv, 0)
+	}
+
+	suite := &Suite{dir: dir}
+	handlers := make([]*httpHandler, len(requests))
+	named := make(map[string]int)
+	routeNames := make(map[string]int)
+	for i := range requests {
+		req := &requests[i]
+		where := fmt.Sprintf("%s (line %d)", req.name, req.line)
+
+		if req.url, err = expand(req.url); err != nil {
+			return nil, fmt.Errorf("%s: %v", where, err)
+		}
+		for j := range req.headers {
+			if req.headers[j][1], err = expand(req.headers[j][1]); err != nil {
+				return nil, fmt.Errorf("%s: %v", where, err)
+			}
+		}
+		if req.body, err = expand(req.body); err != nil {
+			return nil, fmt.Errorf("%s: %v", where, err)
+		}
+
+		// References to earlier named requests become captures of their responses.
+		references := func(s string) (string, error) {
+			return referenceHTTP(s, named, handlers)
+		}
+		if req.url, err = references(req.url); err != nil {
+			return nil, fmt.Errorf("%s: %v", where, err)
+		}
+		for j := range req.headers {
+			if req.headers[j][1], err = references(req.headers[j][1]); err != nil {
+				return nil, fmt.Errorf("%s: %v", where, err)
+			}
+		}
+		if req.body, err = references(req.body); err != nil {
+			return nil, fmt.Errorf("%s: %v", where, err)
+		}
+
+		route, err := suite.httpRoute(req)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", where, err)
+		}
+		route.Name = httpSlug(req.name)
+		if routeNames[route.Name]++; routeNames[route.Name] > 1 {
+			route.Name = fmt.Sprintf("%s-%d", route.Name, routeNames[route.Name])
+		}
+
+		scenario := &route.Scenarios[0]
+		if req.handler != "" {
+			handler, status, err := parseHTTPHandler(req.handler)
+			if err != nil {
+				return nil, fmt.Errorf("%s: response handler: %v", where, err)
+			}
+			scenario.Expect.Status = status
+			handlers[i] = mergeHandlers(handlers[i], handler)
+		}
+		named[req.name] = i
+		suite.Routes = append(suite.Routes, *route)
+	}
+
+	// The handlers are attached once every reference has added its captures.
+	for i, handler := range handlers {
+		suite.Routes[i].Scenarios[0].handler = handler
+	}
+	return suite, nil
+}
+
+// splitHTTP splits the file into its requests and returns them with the file variables.
+func splitHTTP(content string, dir string) ([]httpRequest, map[string]string, error) {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	variables := make(map[string]string)
+
+	var requests []httpRequest
+	var current *httpRequest
+	var name string
+	section := "" // request line, headers, body, handler, or end once the handler is read
+	var body, handler []string
+
+	flush := func() error {
+		if current == nil {
+			return nil
+		}
+		for len(body) > 0 && strings.TrimSpace(body[0]) == "" {
+			body = body[1:]
+		}
+		for len(body) > 0 && strings.TrimSpace(body[len(body)-1]) == "" {
+			body = body[:len(body)-1]
+		}
+		if len(body) > 0 && strings.HasPrefix(body[0], "< ") {
+			path := strings.TrimSpace(body[0][2:])
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("%s (line %d): body: %v", current.name, current.line, err)
+			}
+			body = []string{string(data)}
+		}
+		current.body = strings.Join(body, "\n")
+		current.handler = strings.Join(handler, "\n")
+		requests = append(requests, *current)
+		current, name, section, body, handler = nil, "", "", nil, nil
+		return nil
+	}
+
+	for n, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "###") {
+			if err := flush(); err != nil {
+				return nil, nil, err
+			}
+			name = strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+			continue
+		}
+
+		if section == "handler" {
+			handler = append(handler, line)
+			if strings.Contains(line, "%}") {
+				section = "end"
+			}
+			continue
+		}
+
+		if current == nil || section == "request line" || section == "headers" {
+			if m := httpName.FindStringSubmatch(trimmed); m != nil {
+				if current == nil {
+					name = strings.TrimSpace(m[1])
+				} else {
+					current.name = strings.TrimSpace(m[1])
+				}
+				continue
+			}
+			if strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "//") {
+				continue
+			}
+			if m := httpVariable.FindStringSubmatch(trimmed); m != nil {
+				variables[m[1]] = strings.TrimSpace(m[2])
+				continue
+			}
+		}
+
+		switch {
+		case current == nil:
+			if trimmed == "" {
+				continue
+			}
+			current = &httpRequest{name: name, line: n + 1}
+			if current.name == "" {
+				current.name = fmt.Sprintf("request %d", len(requests)+1)
+			}
+			current.method, current.url = "GET", trimmed
+			if fields := strings.Fields(trimmed); len(fields) > 1 && httpMethods[strings.ToUpper(fields[0])] {
+				current.method, current.url = strings.ToUpper(fields[0]), strings.TrimSpace(trimmed[len(fields[0]):])
+			}
+			if i := strings.LastIndex(current.url, " HTTP/"); i >= 0 {
+				current.url = strings.TrimSpace(current.url[:i])
+			}
+			section = "request line"
+
+		case section == "request line" && (strings.HasPrefix(trimmed, "?") || strings.HasPrefix(trimmed, "&")):
+			current.url += trimmed
+
+		case section == "request line" || section == "headers":
+			if trimmed == "" {
+				section = "body"
+				continue
+			}
+			if strings.HasPrefix(trimmed, ">") || strings.HasPrefix(trimmed, "<>") {
+				if err := handlerLine(trimmed, dir, &handler, &section); err != nil {
+					return nil, nil, fmt.Errorf("line %d: %v", n+1, err)
+				}
+				continue
+			}
+			key, value, ok := strings.Cut(trimmed, ":")
+			if !ok {
+				return nil, nil, fmt.Errorf("line %d: expected a header, got %q", n+1, trimmed)
+			}
+			current.headers = append(current.headers, [2]string{strings.TrimSpace(key), strings.TrimSpace(value)})
+			section = "headers"
+
+		default:
+			if strings.HasPrefix(trimmed, "> ") || strings.HasPrefix(trimmed, ">{%") || strings.HasPrefix(trimmed, "<> ") || strings.HasPrefix(trimmed, ">>") {
+				if err := handlerLine(trimmed, dir, &handler, &section); err != nil {
+					return nil, nil, fmt.Errorf("line %d: %v", n+1, err)
+				}
+				continue
+			}
+			if section == "end" {
+				if trimmed != "" {
+					return nil, nil, fmt.Errorf("line %d: unexpected %q after the response handler, separate requests with ###", n+1, trimmed)
+				}
+				continue
+			}
+			body = append(body, line)
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, nil, err
+	}
+	return requests, variables, nil
+}
+
+// handlerLine reads a line following the body of a request: a response handler, inline or from a file,
+// a reference to a previous response or a redirection of the response to a file, which are both ignored.
+func handlerLine(line, dir string, handler *[]string, section *string) error {
+	switch {
+	case strings.HasPrefix(line, "<>"), strings.HasPrefix(line, ">>"):
+		return nil
+	case strings.HasPrefix(strings.TrimSpace(line[1:]), "{%"):
+		*handler = append(*handler, strings.TrimSpace(line[1:]))
+		*section = "end"
+		if !strings.Contains(line, "%}") {
+			*section = "handler"
+		}
+		return nil
+	default:
+		*section = "end"
+		path := strings.TrimSpace(line[1:])
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("response handler: %v", err)
+		}
+		*handler = append(*handler, string(data))
+		return nil
+	}
+}
+
+// expandHTTP expands the references to file and system variables in s, leaving the other ones to the run.
+func expandHTTP(s string, variables, dotenv map[string]string, depth int) (string, error) {
+	if depth > 10 {
+		return "", fmt.Errorf("file variables referencing each other in a cycle: %s", s)
+	}
+	var err error
+	expanded := httpReference.ReplaceAllStringFunc(s, func(ref string) string {
+		name := httpReference.FindStringSubmatch(ref)[1]
+		if strings.HasPrefix(name, "$") {
+			fields := strings.Fields(name)
+			switch {
+			case fields[0] == "$processEnv" && len(fields) == 2:
+				return os.Getenv(fields[1])
+			case fields[0] == "$dotenv" && len(fields) == 2:
+				return dotenv[fields[1]]
+			default:
+				err = fmt.Errorf("system variable {{%s}} is not supported", name)
+				return ref
+			}
+		}
+		value, ok := variables[name]
+		if !ok {
+			return ref
+		}
+		value, expandErr := expandHTTP(value, variables, dotenv, depth+1)
+		if expandErr != nil {
+			err = expandErr
+		}
+		return value
+	})
+	return expanded, err
+}
+
+// referenceHTTP replaces the references to the responses of earlier named requests with variables,
+// captured by the response handlers of these requests.
+func referenceHTTP(s string, named map[string]int, handlers []*httpHandler) (string, error) {
+	var err error
+	replaced := httpReference.ReplaceAllStringFunc(s, func(ref string) string {
+		m := httpRequestReference.FindStringSubmatch(httpReference.FindStringSubmatch(ref)[1])
+		if m == nil {
+			return ref
+		}
+		i, ok := named[m[1]]
+		if !ok {
+			err = fmt.Errorf("%s references request %s, which is not named before it", ref, m[1])
+			return ref
+		}
+
+		var source httpSource
+		if m[2] == "headers" {
+			source = httpSource{kind: "header", header: m[3]}
+		} else {
+			expr := m[3]
+			if expr == "*" {
+				expr = "$"
+			}
+			path, parseErr := jsonpath.Parse(expr)
+			if parseErr != nil {
+				err = parseErr
+				return ref
+			}
+			source = httpSource{kind: "body", path: path}
+		}
+
+		if handlers[i] == nil {
+			handlers[i] = &httpHandler{}
+		}
+		for _, capture := range handlers[i].captures {
+			if reflect.DeepEqual(capture.source, source) {
+				return "{{" + capture.variable + "}}"
+			}
+		}
+		variable := fmt.Sprintf("%s.response.%d", m[1], len(handlers[i].captures)+1)
+		handlers[i].captures = append(handlers[i].captures, httpCapture{variable: variable, source: source})
+		return "{{" + variable + "}}"
+	})
+	return replaced, err
+}
+
+// httpRoute returns the route of the request, with its scenario.
+func (s *Suite) httpRoute(req *httpRequest) (*RouteSpec, error) {
+	scenario := ScenarioSpec{Name: req.name}
+
+	var host string
+	for _, header := range req.headers {
+		if strings.EqualFold(header[0], "Host") {
+			host = header[1]
+			continue
+		}
+		if scenario.Parameters.Headers == nil {
+			scenario.Parameters.Headers = make(map[string]string)
+		}
+		scenario.Parameters.Headers[header[0]] = header[1]
+	}
+
+	raw := req.url
+	if strings.HasPrefix(raw, "{{") {
+		// The base URL is a variable of the environment: the request is sent to the host of the environment.
+		raw = raw[strings.Index(raw, "}}")+2:]
+	} else if !strings.Contains(raw, "://") && host != "" {
+		raw = "http://" + host + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	if u.Host != "" {
+		spec, err := httpHost(u)
+		if err != nil {
+			return nil, err
+		}
+		if s.Host.Hostname == "" {
+			s.Host = spec
+		} else if spec != s.Host {
+			return nil, fmt.Errorf("host %s differs from the host %s of the earlier requests, a suite has a single host", u.Host, s.Host.Hostname)
+		}
+	}
+
+	path := u.Path
+	if u.RawPath != "" {
+		path = u.RawPath
+	}
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		query, err := url.ParseQuery(u.RawQuery)
+		if err != nil {
+			return nil, fmt.Errorf("query string: %v", err)
+		}
+		scenario.Parameters.Query = make(map[string]string)
+		for key, values := range query {
+			scenario.Parameters.Query[key] = values[len(values)-1]
+		}
+	}
+
+	if req.body != "" {
+		// Quoted, so that a body made of the word generate is not generated.
+		scenario.Body = yaml.Node{Kind: yaml.ScalarNode, Style: yaml.DoubleQuotedStyle, Value: req.body}
+	}
+	return &RouteSpec{Method: req.method, Path: path, Scenarios: []ScenarioSpec{scenario}}, nil
+}
+
+// httpHost returns the host of an absolute URL, with the default port of its scheme.
+func httpHost(u *url.URL) (HostSpec, error) {
+	spec := HostSpec{Protocol: u.Scheme, Hostname: u.Hostname()}
+	switch port := u.Port(); {
+	case port != "":
+		p, err := strconv.Atoi(port)
+		if err != nil {
+			return spec, fmt.Errorf("invalid port %q", port)
+		}
+		spec.Port = p
+	case u.Scheme == "https":
+		spec.Port = 443
+	default:
+		spec.Port = 80
+	}
+	return spec, nil
+}
+
+// httpSlug returns the name of the request in lower case with dashes, for the name of its route.
+func httpSlug(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	if b.Len() == 0 {
+		return "request"
+	}
+	return b.String()
+}
+
+var (
+	// handlerCall matches the calls of a response handler, up to their opening parenthesis.
+	handlerCall = regexp.MustCompile(`client\.(test|assert|global\.set|log)\s*\(`)
+
+	// handlerNoise matches what may be left of a response handler once its calls are removed.
+	handlerNoise = regexp.MustCompile(`(?s)\{%|%\}|function\s*\(\s*\)|\(\s*\)\s*=>|[{}();,\s]`)
+)
+
+// parseHTTPHandler parses a response handler into assertions and captures. An assertion on the status
+// is returned apart, as the expected status of the scenario.
+func parseHTTPHandler(script string) (*httpHandler, string, error) {
+	script = stripComments(script)
+	handler := &httpHandler{}
+	status := ""
+
+	var rest strings.Builder
+	for script != "" {
+		loc := handlerCall.FindStringSubmatchIndex(script)
+		if loc == nil {
+			rest.WriteString(script)
+			break
+		}
+		rest.WriteString(script[:loc[0]])
+		call := script[loc[2]:loc[3]]
+		args, end, err := callArguments(script, loc[1])
+		if err != nil {
+			return nil, "", err
+		}
+
+		switch call {
+		case "test":
+			// The body of the test is parsed with the rest of the script.
+			if len(args) < 2 {
+				return nil, "", errors.New("client.test expects a name and a function")
+			}
+			script = args[1] + script[end:]
+			continue
+		case "log":
+		case "global.set":
+			if len(args) != 2 {
+				return nil, "", errors.New("client.global.set expects a name and a value")
+			}
+			variable, err := jsString(args[0])
+			if err != nil {
+				return nil, "", err
+			}
+			source, err := parseSource(args[1])
+			if err != nil {
+				return nil, "", err
+			}
+			handler.captures = append(handler.captures, httpCapture{variable: variable, source: source})
+		case "assert":
+			if len(args) == 0 || len(args) > 2 {
+				return nil, "", errors.New("client.assert expects a condition and an optional message")
+			}
+			check, err := parseCheck(args[0])
+			if err != nil {
+				return nil, "", err
+			}
+			if len(args) == 2 {
+				if check.message, err = jsString(args[1]); err != nil {
+					return nil, "", err
+				}
+			}
+			if check.source.kind == "status" && (check.operator == "==" || check.operator == "===") && status == "" {
+				status = fmt.Sprint(check.expected)
+			} else {
+				handler.checks = append(handler.checks, check)
+			}
+		}
+		script = script[end:]
+	}
+
+	if left := strings.Join(strings.Fields(handlerNoise.ReplaceAllString(rest.String(), " ")), " "); left != "" {
+		return nil, "", fmt.Errorf("unsupported statement near %q: only client.test, client.assert and client.global.set "+
+			"on response.status, response.body, response.headers.valueOf and response.contentType.mimeType are supported", left)
+	}
+	return handler, status, nil
+}
+
+// callArguments splits the arguments of the call whose opening parenthesis ends at start,
+// and returns them with the offset following the closing parenthesis.
+func callArguments(script string, start int) ([]string, int, error) {
+	var args []string
+	depth, from := 0, start
+	var quote byte
+	for i := start; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'' || c == '`':
+			quote = c
+		case c == '(' || c == '{' || c == '[':
+			depth++
+		case c == ')' && depth == 0:
+			if arg := strings.TrimSpace(script[from:i]); arg != "" {
+				args = append(args, arg)
+			}
+			return args, i + 1, nil
+		case c == ')' || c == '}' || c == ']':
+			depth--
+		case c == ',' && depth == 0:
+			args = append(args, strings.TrimSpace(script[from:i]))
+			from = i + 1
+		}
+	}
+	return nil, 0, errors.New("unbalanced parentheses")
+}
+
+// stripComments removes the // and /* */ comments of a script, outside of strings.
+func stripComments(script string) string {
+	var b strings.Builder
+	var quote byte
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case quote != 0:
+			b.WriteByte(c)
+			if c == '\\' && i+1 < len(script) {
+				i++
+				b.WriteByte(script[i])
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'' || c == '`':
+			quote = c
+			b.WriteByte(c)
+		case strings.HasPrefix(script[i:], "//"):
+			for i < len(script) && script[i] != '\n' {
+				i++
+			}
+			b.WriteByte('\n')
+		case strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				return b.String()
+			}
+			i += end + 3
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// parseCheck parses the condition of an assertion: a value of the response compared to a literal, or alone.
+func parseCheck(condition string) (httpCheck, error) {
+	for _, operator := range []string{"===", "!==", "==", "!=", ">=", "<=", ">", "<"} {
+		left, right, ok := strings.Cut(condition, operator)
+		if !ok {
+			continue
+		}
+		left, right = strings.TrimSpace(left), strings.TrimSpace(right)
+		if !strings.HasPrefix(left, "response.") {
+			left, right = right, left
+			operator = mirror(operator)
+		}
+		source, err := parseSource(left)
+		if err != nil {
+			return httpCheck{}, err
+		}
+		expected, err := jsLiteral(right)
+		if err != nil {
+			return httpCheck{}, fmt.Errorf("condition %s: %v", condition, err)
+		}
+		return httpCheck{source: source, operator: operator, expected: expected}, nil
+	}
+
+	source, err := parseSource(strings.TrimSpace(condition))
+	if err != nil {
+		return httpCheck{}, err
+	}
+	return httpCheck{source: source, operator: "truthy"}, nil
+}
+
+// mirror returns the operator comparing the operands in the other order.
+func mirror(operator string) string {
+	switch operator {
+	case ">":
+		return "<"
+	case "<":
+		return ">"
+	case ">=":
+		return "<="
+	case "<=":
+		return ">="
+	}
+	return operator
+}
+
+// parseSource parses an expression pointing at a value of the response.
+func parseSource(expr string) (httpSource, error) {
+	switch {
+	case expr == "response.status":
+		return httpSource{kind: "status"}, nil
+	case expr == "response.contentType.mimeType":
+		return httpSource{kind: "content-type"}, nil
+	case strings.HasPrefix(expr, "response.headers.valueOf(") && strings.HasSuffix(expr, ")"):
+		name, err := jsString(expr[len("response.headers.valueOf(") : len(expr)-1])
+		if err != nil {
+			return httpSource{}, err
+		}
+		return httpSource{kind: "header", header: name}, nil
+	case expr == "response.body" || strings.HasPrefix(expr, "response.body.") || strings.HasPrefix(expr, "response.body["):
+		path, err := jsonpath.Parse("$" + strings.TrimPrefix(expr, "response.body"))
+		if err != nil {
+			return httpSource{}, err
+		}
+		return httpSource{kind: "body", path: path}, nil
+	}
+	return httpSource{}, fmt.Errorf("unsupported expression %s, expected response.status, response.body, response.headers.valueOf or response.contentType.mimeType", expr)
+}
+
+// jsLiteral decodes a JavaScript number, string, boolean or null literal.
+func jsLiteral(literal string) (interface{}, error) {
+	if strings.HasPrefix(literal, "'") || strings.HasPrefix(literal, "`") {
+		return jsString(literal)
+	}
+	var value interface{}
+	if err := json.Unmarshal([]byte(literal), &value); err != nil {
+		return nil, fmt.Errorf("unsupported literal %s", literal)
+	}
+	return value, nil
+}
+
+// jsString decodes a JavaScript string literal.
+func jsString(literal string) (string, error) {
+	if len(literal) < 2 || strings.IndexByte("\"'`", literal[0]) < 0 || literal[len(literal)-1] != literal[0] {
+		return "", fmt.Errorf("expected a string literal, got %s", literal)
+	}
+	if literal[0] == '"' {
+		var s string
+		if err := json.Unmarshal([]byte(literal), &s); err == nil {
+			return s, nil
+		}
+	}
+	return strings.ReplaceAll(literal[1:len(literal)-1], `\`+literal[:1], literal[:1]), nil
+}
+
+// mergeHandlers returns the handler with the assertions and captures of both handlers.
+func mergeHandlers(a, b *httpHandler) *httpHandler {
+	if a == nil {
+		return b
+	}
+	a.checks = append(a.checks, b.checks...)
+	a.captures = append(a.captures, b.captures...)
+	return a
+}
+
+// hook returns the Response Hook checking the assertions of the handler and capturing its values.
+func (h *httpHandler) hook() interfaces.ContextHook {
+	return func(hc interfaces.HookContext) error {
+		resp := hc.Response()
+		for _, check := range h.checks {
+			actual, found := check.source.value(resp)
+			if !check.holds(actual, found) {
+				if check.message != "" {
+					return errors.New(check.message)
+				}
+				if check.operator == "truthy" {
+					return fmt.Errorf("expected %s to be set, got %s", check.source, format(actual, found))
+				}
+				return fmt.Errorf("expected %s %s %s, got %s", check.source, check.operator, format(check.expected, true), format(actual, found))
+			}
+		}
+		for _, capture := range h.captures {
+			value, found := capture.source.value(resp)
+			if !found {
+				return fmt.Errorf("cannot capture %s: %s not found in the response", capture.variable, capture.source)
+			}
+			s, ok := value.(string)
+			if !ok {
+				data, _ := json.Marshal(value)
+				s = string(data)
+			}
+			hc.Variables().Set(capture.variable, s)
+		}
+		return nil
+	}
+}
+
+// value returns the value of the response the source points at, and whether it was found.
+func (s httpSource) value(resp interfaces.Response) (interface{}, bool) {
+	switch s.kind {
+	case "status":
+		return float64(resp.GetStatusCode()), true
+	case "header":
+		value, err := resp.HeaderValue(s.header)
+		return value, err == nil
+	case "content-type":
+		value, err := resp.HeaderValue("Content-Type")
+		if err != nil {
+			return nil, false
+		}
+		return strings.TrimSpace(strings.Split(value, ";")[0]), true
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(resp.Bytes(), &doc); err != nil {
+		if s.path.String() == "$" {
+			return resp.String(), true
+		}
+		return nil, false
+	}
+	values := s.path.Get(doc)
+	if len(values) == 0 {
+		return nil, false
+	}
+	return values[0], true
+}
+
+// String returns the expression of the handler the source was parsed from.
+func (s httpSource) String() string {
+	switch s.kind {
+	case "status":
+		return "response.status"
+	case "header":
+		return fmt.Sprintf("response.headers.valueOf(%q)", s.header)
+	case "content-type":
+		return "response.contentType.mimeType"
+	}
+	return "response.body" + strings.TrimPrefix(s.path.String(), "$")
+}
+
+// holds reports whether the actual value satisfies the check. == and != compare loosely, as JavaScript does
+// for numbers and their string representation.
+func (c httpCheck) holds(actual interface{}, found bool) bool {
+	switch c.operator {
+	case "truthy":
+		return found && actual != nil && actual != false && actual != "" && actual != float64(0)
+	case "===":
+		return found && reflect.DeepEqual(actual, c.expected)
+	case "!==":
+		return !found || !reflect.DeepEqual(actual, c.expected)
+	case "==":
+		return found && (reflect.DeepEqual(actual, c.expected) || fmt.Sprint(actual) == fmt.Sprint(c.expected))
+	case "!=":
+		return !found || !(reflect.DeepEqual(actual, c.expected) || fmt.Sprint(actual) == fmt.Sprint(c.expected))
+	}
+
+	a, aok := number(actual)
+	e, eok := number(c.expected)
+	if !found || !aok || !eok {
+		return false
+	}
+	switch c.operator {
+	case ">":
+		return a > e
+	case ">=":
+		return a >= e
+	case "<":
+		return a < e
+	}
+	return a <= e
+}
+
+func number(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func format(value interface{}, found bool) string {
+	if !found {
+		return "nothing"
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+/* Example .http file -
+
+@user = 1
+
+### login
+# @name login
+POST https://api.example.com/auth/login
+Content-Type: application/json
+
+{"username": "bob", "password": "{{$processEnv API_PASSWORD}}"}
+
+> {%
+    client.test("logged in", function() {
+        client.assert(response.status === 200, "login failed");
+        client.assert(response.body.expires_in > 0);
+    });
+    client.global.set("token", response.body.token);
+%}
+
+### get user
+GET https://api.example.com/users/{{user}}
+Authorization: Bearer {{login.response.body.$.token}}
+Accept: application/json
+
+*/
@@ -0,0 +1,270 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testHTTPFile = `@base = https://api.example.com:8443
+@user = {{id}}
+@id = 7
+
+### Get user
+GET {{base}}/users/{{user}}
+    ?fields=name
+    &fields=email
+Accept: application/json
+
+### create user
+POST {{base}}/users HTTP/1.1
+Content-Type: application/json
+
+{"name": "{{name}}"}
+
+> {%
+    client.assert(response.status === 201);
+%}
+
+### Get user
+{{base}}/health
+`
+
+// TestParseHTTP checks the routes and scenarios of the requests of a .http file.
+func TestParseHTTP(t *testing.T) {
+	suite, err := ParseHTTP([]byte(testHTTPFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suite.Host.Protocol != "https" || suite.Host.Hostname != "api.example.com" || suite.Host.Port != 8443 {
+		t.Errorf("expected the host of the requests, got %+v", suite.Host)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		query  map[string]string
+		status string
+	}{
+		{"get-user", "GET", "/users/7", map[string]string{"fields": "email"}, ""},
+		{"create-user", "POST", "/users", nil, "201"},
+		{"get-user-2", "GET", "/health", nil, ""},
+	}
+	if len(suite.Routes) != len(tests) {
+		t.Fatalf("expected %d routes, got %d", len(tests), len(suite.Routes))
+	}
+	for i, test := range tests {
+		route := suite.Routes[i]
+		scenario := route.Scenarios[0]
+		if route.Name != test.name || route.Method != test.method || route.Path != test.path {
+			t.Errorf("expected route %s %s %s, got %s %s %s", test.name, test.method, test.path, route.Name, route.Method, route.Path)
+		}
+		if fmt.Sprint(scenario.Parameters.Query) != fmt.Sprint(test.query) {
+			t.Errorf("%s: expected query %v, got %v", test.name, test.query, scenario.Parameters.Query)
+		}
+		if scenario.Expect.Status != test.status {
+			t.Errorf("%s: expected status %q, got %q", test.name, test.status, scenario.Expect.Status)
+		}
+	}
+
+	create := suite.Routes[1].Scenarios[0]
+	if create.Body.Value != `{"name": "{{name}}"}` || create.Parameters.Headers["Content-Type"] != "application/json" {
+		t.Errorf("expected the body and headers of the request, with {{name}} left to the run, got %q %v", create.Body.Value, create.Parameters.Headers)
+	}
+}
+
+// TestParseHTTPInvalid checks the errors of files that cannot be read as suites.
+func TestParseHTTPInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want string
+	}{
+		{"empty", "# nothing\n", "does not define any request"},
+		{"header", "GET /users\nnot a header\n", "expected a header"},
+		{"hosts", "GET https://a.example.com/\n\n###\nGET https://b.example.com/\n", "a suite has a single host"},
+		{"cycle", "@a = {{b}}\n@b = {{a}}\nGET /{{a}}\n", "cycle"},
+		{"system variable", "GET /{{$random}}\n", "not supported"},
+		{"reference", "GET /\nAuthorization: {{login.response.body.$.token}}\n", "not named before it"},
+		{"after handler", "GET /\n\n> {% client.assert(response.status === 200); %}\nGET /other\n", "separate requests with ###"},
+		{"handler", "GET /\n\n> {% console.log(response.body); %}\n", "unsupported statement"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseHTTP([]byte(test.file))
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("expected an error containing %q, got %v", test.want, err)
+			}
+		})
+	}
+}
+
+// TestParseHTTPHandler checks the assertions and captures parsed from the JavaScript of response handlers.
+func TestParseHTTPHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		script   string
+		status   string
+		checks   []string
+		captures []string
+		err      string
+	}{
+		{
+			name:   "status",
+			script: `{% client.test("ok", function() { client.assert(response.status === 200, "not ok"); }); %}`,
+			status: "200",
+		},
+		{
+			name: "checks",
+			script: `{%
+    // the user
+    client.test("user", () => {
+        client.assert(response.body.id == "7");
+        client.assert(0 < response.body.items.length, 'no items'); /* mirrored */
+        client.assert(response.headers.valueOf("X-Id"));
+        client.assert(response.contentType.mimeType !== "text/html");
+    });
+%}`,
+			checks: []string{
+				`response.body.id == "7"`,
+				"response.body.items.length > 0",
+				`response.headers.valueOf("X-Id") truthy true`,
+				`response.contentType.mimeType !== "text/html"`,
+			},
+		},
+		{
+			name:     "captures",
+			script:   `{% client.global.set("token", response.body.token); client.global.set("id", response.body["data"][0].id); client.log("done"); %}`,
+			captures: []string{"token=response.body.token", `id=response.body["data"][0].id`},
+		},
+		{name: "string with parenthesis", script: `{% client.assert(response.body.name === "a)b"); %}`, checks: []string{`response.body.name === "a)b"`}},
+		{name: "unsupported expression", script: `{% client.assert(response.cookies.a === 1); %}`, err: "unsupported expression"},
+		{name: "unsupported literal", script: `{% client.assert(response.status === ok); %}`, err: "unsupported literal"},
+		{name: "unsupported statement", script: `{% const a = 1; %}`, err: "unsupported statement"},
+		{name: "unbalanced", script: `{% client.assert(response.status === 200; %}`, err: "unbalanced parentheses"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler, status, err := parseHTTPHandler(test.script)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Errorf("expected an error containing %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status != test.status {
+				t.Errorf("expected status %q, got %q", test.status, status)
+			}
+			var checks, captures []string
+			for _, c := range handler.checks {
+				expected := format(c.expected, true)
+				if c.operator == "truthy" {
+					expected = "true"
+				}
+				checks = append(checks, fmt.Sprintf("%s %s %s", c.source, c.operator, expected))
+			}
+			for _, c := range handler.captures {
+				captures = append(captures, c.variable+"="+c.source.String())
+			}
+			if strings.Join(checks, "\n") != strings.Join(test.checks, "\n") {
+				t.Errorf("expected checks:\n%s\ngot:\n%s", strings.Join(test.checks, "\n"), strings.Join(checks, "\n"))
+			}
+			if strings.Join(captures, "\n") != strings.Join(test.captures, "\n") {
+				t.Errorf("expected captures %v, got %v", test.captures, captures)
+			}
+		})
+	}
+}
+
+// TestHTTPCheck checks the comparisons of the assertions of response handlers, loose as in JavaScript.
+func TestHTTPCheck(t *testing.T) {
+	tests := []struct {
+		operator string
+		expected interface{}
+		actual   interface{}
+		found    bool
+		want     bool
+	}{
+		{"===", "7", "7", true, true},
+		{"===", float64(7), "7", true, false},
+		{"==", float64(7), "7", true, true},
+		{"!=", float64(7), "8", true, true},
+		{"!==", "7", nil, false, true},
+		{">", float64(1), "2", true, true},
+		{"<=", float64(1), float64(1), true, true},
+		{">", float64(1), "a", true, false},
+		{"truthy", nil, "", true, false},
+		{"truthy", nil, float64(0), true, false},
+		{"truthy", nil, "x", true, true},
+	}
+	for _, test := range tests {
+		check := httpCheck{operator: test.operator, expected: test.expected}
+		if got := check.holds(test.actual, test.found); got != test.want {
+			t.Errorf("%v %s %v: expected %v, got %v", test.actual, test.operator, test.expected, test.want, got)
+		}
+	}
+}
+
+// TestParseHTTPRun checks that the responses of named requests are captured and referenced by the following ones,
+// and that the assertions of the handlers are checked.
+func TestParseHTTPRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		switch r.URL.Path {
+		case "/login":
+			w.Header().Set("X-Session", "s-1")
+			fmt.Fprint(w, `{"token": "t-1", "expires_in": 60}`)
+		case "/me":
+			if r.Header.Get("Authorization") != "Bearer t-1" || r.Header.Get("X-Session") != "s-1" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, `{"name": "bob"}`)
+		}
+	}))
+	defer server.Close()
+
+	file := `### login
+# @name login
+POST ` + server.URL + `/login
+
+> {%
+    client.assert(response.status === 200);
+    client.assert(response.body.expires_in > 0, "expired");
+    client.assert(response.contentType.mimeType === "application/json");
+%}
+
+### me
+GET ` + server.URL + `/me
+Authorization: Bearer {{login.response.body.$.token}}
+X-Session: {{login.response.headers.X-Session}}
+
+> {% client.assert(response.body.name === "alice"); %}
+`
+	suite, err := ParseHTTP([]byte(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	app, err := suite.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := app.Run(context.Background()).Results()
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if err := results[0].Err(); err != nil {
+		t.Errorf("login: expected to pass, got %v", err)
+	}
+	err = results[1].Err()
+	if err == nil || !strings.Contains(err.Error(), `expected response.body.name === "alice", got "bob"`) {
+		t.Errorf("me: expected the assertion on the name to fail after the authorized request, got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"errors"
+	"path/filepath"
+	"time"
+
+	"github.com/qatoolist/RouTest/internal/interfaces"
+	"github.com/qatoolist/RouTest/internal/stream"
+	"gopkg.in/yaml.v3"
+)
+
+// StreamSpec describes the reading of a text/event-stream response, whose Server-Sent Events are read as they
+// arrive until a number of events or a timeout, instead of reading a body that never ends.
+type StreamSpec struct {
+	// Events stops the reading after that many events, failing the scenario if fewer arrive.
+	Events int `yaml:"events"`
+
+	// Timeout stops the reading after that time. Defaults to 30s when Events is not set.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// DownloadSpec describes the streaming of a large response body to a SHA-256 hash, and optionally a file,
+// instead of memory. It can also be given as true alone.
+type DownloadSpec struct {
+	// File is the path the body is written to, relative to the suite file. Empty only hashes the body.
+	File string `yaml:"file"`
+}
+
+// UnmarshalYAML accepts either a mapping or a boolean enabling the download without a file.
+func (ds *DownloadSpec) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		var enabled bool
+		if err := node.Decode(&enabled); err != nil {
+			return err
+		}
+		if !enabled {
+			return errors.New("download: expected true or a mapping")
+		}
+		return nil
+	}
+	type plain DownloadSpec
+	return node.Decode((*plain)(ds))
+}
+
+// EventSpec describes what an event of a streaming response is expected to look like.
+type EventSpec struct {
+	// Event is the expected type of the event. Empty accepts any type.
+	Event string `yaml:"event"`
+
+	// Data are the expected values in the JSON data of the event, by JSONPath expression, e.g. status.
+	Data map[string]interface{} `yaml:"data"`
+
+	// DataContains is a string the data of the event is expected to contain.
+	DataContains string `yaml:"data_contains"`
+}
+
+// streamExchange returns the exchange reading the streaming response of the scenario, or nil if it has none.
+func (ss *ScenarioSpec) streamExchange(suite *Suite) (interfaces.Exchange, error) {
+	es := ss.Expect
+	switch {
+	case ss.Stream != nil && ss.Download != nil:
+		return nil, errors.New("stream and download are mutually exclusive")
+	case ss.Stream == nil && (len(es.Events) > 0 || es.MaxTimeToFirstEvent > 0):
+		return nil, errors.New("expect.events and expect.max_time_to_first_event require stream")
+	case ss.Download == nil && (es.SHA256 != "" || es.Size > 0):
+		return nil, errors.New("expect.sha256 and expect.size require download")
+	}
+
+	if ss.Download != nil {
+		file := ss.Download.File
+		if file != "" && !filepath.IsAbs(file) {
+			file = filepath.Join(suite.dir, file)
+		}
+		return stream.Download(stream.DownloadOptions{File: file, SHA256: es.SHA256, Size: es.Size}), nil
+	}
+
+	if ss.Stream == nil {
+		return nil, nil
+	}
+	if ss.Stream.Events < 0 {
+		return nil, errors.New("stream: events cannot be negative")
+	}
+	expect := make([]stream.EventExpectation, len(es.Events))
+	for i, event := range es.Events {
+		expect[i] = stream.EventExpectation{Type: event.Event, Data: event.Data, DataContains: event.DataContains}
+	}
+	return stream.Events(stream.EventsOptions{
+		Count:               ss.Stream.Events,
+		Timeout:             ss.Stream.Timeout,
+		Expect:              expect,
+		MaxTimeToFirstEvent: es.MaxTimeToFirstEvent,
+	}), nil
+}
@@ -0,0 +1,1037 @@
+// Package parser reads declarative test suites and builds the application, routes and scenarios they describe.
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/qatoolist/RouTest/internal/grpc"
+	"github.com/qatoolist/RouTest/internal/interfaces"
+	"github.com/qatoolist/RouTest/internal/loaders"
+	"github.com/qatoolist/RouTest/internal/models"
+	"github.com/qatoolist/RouTest/internal/openapi"
+	"github.com/qatoolist/RouTest/internal/snapshot"
+	"github.com/qatoolist/RouTest/internal/websocket"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultMeta is used for the application, routes and scenarios that do not declare their own meta.
+const defaultMeta = "automation_status: automated\nimportance: medium\n"
+
+// Suite is the declarative description of an application under test, its routes and their scenarios.
+type Suite struct {
+	// Meta is the metadata of the application, in the format of models.Meta.
+	Meta yaml.Node `yaml:"meta"`
+
+	// Host is the server the scenarios are sent to.
+	Host HostSpec `yaml:"host"`
+
+	// Retry is the application level retry policy, in the format of models.RetryPolicy.
+	Retry yaml.Node `yaml:"retry"`
+
+	// Parameters are the application level parameters.
+	Parameters ParametersSpec `yaml:",inline"`
+
+	// Routes are the routes of the application.
+	Routes []RouteSpec `yaml:"routes"`
+
+	// Config is the directory of the environment configurations, relative to the suite file. Defaults to "config".
+	Config string `yaml:"config"`
+
+	// Seed seeds the bodies declared as "body: generate". Every route and scenario gets its own body,
+	// the same on every run with the same seed.
+	Seed int64 `yaml:"seed"`
+
+	// OpenAPI is the path of the OpenAPI document every response is checked against, relative to the suite file.
+	// The differences are reported as spec drift, apart from the failures of the scenarios. Empty disables the check.
+	OpenAPI string `yaml:"openapi"`
+
+	// GraphQLSchema is the path of the SDL schema the operations of the GraphQL routes and scenarios are
+	// validated against when the suite is built, relative to the suite file. Empty disables the validation.
+	GraphQLSchema string `yaml:"graphql_schema"`
+
+	// UpdateSnapshots rewrites the golden files of the snapshot assertions instead of comparing against them.
+	UpdateSnapshots bool `yaml:"-"`
+
+	// SkipSnapshots builds the scenarios without their snapshot assertions, e.g. when comparing environments.
+	SkipSnapshots bool `yaml:"-"`
+
+	// dir is the directory of the suite file, relative paths in the suite are resolved from it.
+	dir string
+
+	// protosets are the descriptor sets of the gRPC routes, by path.
+	protosets map[string]*protoregistry.Files
+
+	// grpcClients are the connections of the gRPC routes and the descriptors they resolved with server reflection.
+	grpcClients *grpc.Clients
+}
+
+// HostSpec describes the protocol, hostname and port of a server.
+type HostSpec struct {
+	Protocol string `yaml:"protocol"`
+	Hostname string `yaml:"hostname"`
+	Port     int    `yaml:"port"`
+}
+
+// ParametersSpec describes the headers, query parameters and path variables of an application, route or scenario.
+type ParametersSpec struct {
+	Headers       map[string]string `yaml:"headers"`
+	Query         map[string]string `yaml:"query"`
+	PathVariables map[string]string `yaml:"path_variables"`
+}
+
+// RouteSpec describes a route and its scenarios.
+type RouteSpec struct {
+	Name           string         `yaml:"name"`
+	Description    string         `yaml:"description"`
+	Method         string         `yaml:"method"`
+	Path           string         `yaml:"path"`
+	Meta           yaml.Node      `yaml:"meta"`
+	Retry          yaml.Node      `yaml:"retry"`
+	Body           yaml.Node      `yaml:"body"`
+	RequestSchema  string         `yaml:"request_schema"`
+	ResponseSchema string         `yaml:"response_schema"`
+	Parameters     ParametersSpec `yaml:",inline"`
+	Scenarios      []ScenarioSpec `yaml:"scenarios"`
+
+	// GraphQL makes the route a GraphQL route: the operation is sent as its body, and the scenarios fail
+	// when the errors array of the result is not empty.
+	GraphQL *GraphQLSpec `yaml:"graphql"`
+
+	// WebSocket makes the route a WebSocket route: its scenarios open a WebSocket connection with the host,
+	// over wss when its protocol is https, and the headers of the request, then perform their steps.
+	WebSocket bool `yaml:"websocket"`
+
+	// GRPC makes the route a gRPC route: its scenarios invoke the unary method on the host, over TLS when its
+	// protocol is https, with their body as the JSON input message and their headers as metadata.
+	GRPC *GRPCSpec `yaml:"grpc"`
+}
+
+// ScenarioSpec describes a scenario of a route.
+type ScenarioSpec struct {
+	Name        string         `yaml:"name"`
+	Description string         `yaml:"description"`
+	Meta        yaml.Node      `yaml:"meta"`
+	Retry       yaml.Node      `yaml:"retry"`
+	Body        yaml.Node      `yaml:"body"`
+	Timeout     time.Duration  `yaml:"timeout"`
+	Dataset     DatasetSpec    `yaml:"dataset"`
+	Parameters  ParametersSpec `yaml:",inline"`
+	Expect      ExpectSpec     `yaml:"expect"`
+	Example     *ExampleSpec   `yaml:"example"`
+	Snapshot    *SnapshotSpec  `yaml:"snapshot"`
+	Contract    *ContractSpec  `yaml:"contract"`
+	GraphQL     *GraphQLSpec   `yaml:"graphql"`
+	Steps       []StepSpec     `yaml:"steps"`
+	Stream      *StreamSpec    `yaml:"stream"`
+	Download    *DownloadSpec  `yaml:"download"`
+	WaitUntil   *WaitUntilSpec `yaml:"wait_until"`
+
+	// handler holds the assertions and captures of the response handler of a request of a .http file.
+	handler *httpHandler
+}
+
+// ContractSpec describes what the consumer relies on in the response of a scenario, for the contracts
+// published with routest publish-contracts. Without fields or values every field of the response body is
+// part of the contract, matched by type. It can also be given as false to leave the scenario out of the contracts.
+type ContractSpec struct {
+	// Fields are the JSONPath expressions of the response fields the consumer reads, matched by type.
+	Fields []string `yaml:"fields"`
+
+	// Values are the JSONPath expressions of the response fields whose exact value the consumer relies on.
+	Values []string `yaml:"values"`
+
+	// Exclude leaves the scenario out of the contracts.
+	Exclude bool `yaml:"-"`
+}
+
+// UnmarshalYAML accepts either a mapping or a boolean, false leaving the scenario out of the contracts.
+func (cs *ContractSpec) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		var included bool
+		if err := node.Decode(&included); err != nil {
+			return err
+		}
+		cs.Exclude = !included
+		return nil
+	}
+	type plain ContractSpec
+	return node.Decode((*plain)(cs))
+}
+
+// SnapshotSpec describes a snapshot assertion: the response body is compared against a golden file
+// in the __snapshots__ directory next to the suite, written on the first run.
+// It can also be given as true alone.
+type SnapshotSpec struct {
+	// Ignore are the JSONPath expressions of volatile fields, e.g. $.id or $..created_at.
+	Ignore []string `yaml:"ignore"`
+}
+
+// UnmarshalYAML accepts either a mapping or a boolean enabling the snapshot without ignore rules.
+func (ss *SnapshotSpec) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		var enabled bool
+		if err := node.Decode(&enabled); err != nil {
+			return err
+		}
+		if !enabled {
+			return errors.New("snapshot: expected true or a mapping")
+		}
+		return nil
+	}
+	type plain SnapshotSpec
+	return node.Decode((*plain)(ss))
+}
+
+// ExampleSpec describes the response served for a scenario by the mock server.
+type ExampleSpec struct {
+	Status  int               `yaml:"status"`
+	Headers map[string]string `yaml:"headers"`
+	Body    yaml.Node         `yaml:"body"`
+}
+
+// DatasetSpec describes the dataset a data-driven scenario is executed with, once per row.
+// It can also be given as the path of the dataset file alone.
+type DatasetSpec struct {
+	// File is the path of the CSV, JSON or YAML dataset, relative to the suite file.
+	File string `yaml:"file"`
+
+	// Name is the column naming the test case of every row. Defaults to "name".
+	Name string `yaml:"name"`
+
+	// Tags is the column holding the comma-separated tags of every row. Defaults to "tags".
+	Tags string `yaml:"tags"`
+}
+
+// UnmarshalYAML accepts either a mapping or the path of the dataset file.
+func (ds *DatasetSpec) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		ds.File = node.Value
+		return nil
+	}
+	type plain DatasetSpec
+	return node.Decode((*plain)(ds))
+}
+
+// ExpectSpec describes what the response of a scenario is expected to look like.
+// Every value may reference the columns of the data row or the captured variables as {{name}}.
+type ExpectSpec struct {
+	// Status is the expected status code. Empty accepts any 2xx status, or 101 on WebSocket routes.
+	Status string `yaml:"status"`
+
+	// GRPCStatus is the expected status of the call of a gRPC route, by name or number, e.g. NOT_FOUND or 5.
+	// Empty expects OK unless Status is set.
+	GRPCStatus string `yaml:"grpc_status"`
+
+	// Headers are the expected values of response headers.
+	Headers map[string]string `yaml:"headers"`
+
+	// BodyContains is a string the response body is expected to contain.
+	BodyContains string `yaml:"body_contains"`
+
+	// MaxDuration is the maximum total time of the request, e.g. 500ms. Zero disables the check.
+	MaxDuration time.Duration `yaml:"max_duration"`
+
+	// MaxTimeToFirstByte is the maximum time until the first byte of the response. Zero disables the check.
+	MaxTimeToFirstByte time.Duration `yaml:"max_ttfb"`
+
+	// Data are the expected values in the data of the result of a GraphQL route, by JSONPath expression
+	// relative to data, e.g. user.email, or in the output message of a gRPC route, e.g. order.status.
+	Data map[string]interface{} `yaml:"data"`
+
+	// Errors are the messages, or parts of messages, of the errors the result of a GraphQL route is expected
+	// to have. Empty expects no errors.
+	Errors []string `yaml:"errors"`
+
+	// Events are the expected first events of a streamed text/event-stream response, in order.
+	Events []EventSpec `yaml:"events"`
+
+	// MaxTimeToFirstEvent is the maximum time until the first event of a streamed response. Zero disables the check.
+	MaxTimeToFirstEvent time.Duration `yaml:"max_time_to_first_event"`
+
+	// SHA256 is the expected hexadecimal SHA-256 digest of a downloaded body. Empty disables the check.
+	SHA256 string `yaml:"sha256"`
+
+	// Size is the expected size in bytes of a downloaded body. Zero disables the check.
+	Size int64 `yaml:"size"`
+}
+
+// ParseSuiteFile reads and parses the suite file at the given path.
+// Files with the .http or .rest extension are parsed with ParseHTTP.
+func ParseSuiteFile(path string) (*Suite, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".http" || ext == ".rest" {
+		suite, err := parseHTTP(data, filepath.Dir(path))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		return suite, nil
+	}
+	suite, err := ParseSuite(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	suite.dir = filepath.Dir(path)
+	return suite, nil
+}
+
+// ParseSuite parses a suite from its YAML representation.
+func ParseSuite(data []byte) (*Suite, error) {
+	var suite Suite
+	if err := yaml.Unmarshal(data, &suite); err != nil {
+		return nil, err
+	}
+	if len(suite.Routes) == 0 {
+		return nil, errors.New("suite does not define any route")
+	}
+	return &suite, nil
+}
+
+// Build creates the application described by the suite, with all its routes and scenarios.
+func (s *Suite) Build() (*models.Application, error) {
+	return s.BuildForEnvironment("")
+}
+
+// BuildForEnvironment creates the application described by the suite for the given environment.
+// The configuration of the environment, "<env>.json", "<env>.yaml" or "<env>.env" in the config directory,
+// can override the host and the retry policy of the suite, add headers and set variables:
+//
+//	host: {protocol: https, hostname: staging.example.com, port: 443}
+//	headers: {X-Env: staging}
+//	variables: {user_id: "42"}
+//
+// Every call creates a new application, so that several environments can run in the same process.
+func (s *Suite) BuildForEnvironment(env string) (*models.Application, error) {
+	meta, err := models.NewMetaFromString(nodeString(&s.Meta, defaultMeta))
+	if err != nil {
+		return nil, fmt.Errorf("application meta: %v", err)
+	}
+
+	var host interfaces.Host
+	if s.Host.Hostname != "" {
+		host = models.NewHost(s.Host.Protocol, s.Host.Hostname, s.Host.Port)
+	}
+
+	config := models.NewConfig()
+	if env != "" {
+		dir := s.Config
+		if dir == "" {
+			dir = "config"
+		}
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(s.dir, dir)
+		}
+		loaded, err := loaders.LoadEnvironmentConfig(env, dir)
+		if err != nil {
+			return nil, err
+		}
+		config.CopyFromTemp(loaded)
+
+		if _, err := config.Get("host"); err == nil {
+			if host, err = config.GetHost(); err != nil {
+				return nil, fmt.Errorf("environment %s: %v", env, err)
+			}
+		}
+	}
+
+	if host == nil {
+		return nil, errors.New("host hostname is not defined")
+	}
+
+	app, err := models.NewApplication(env, config, models.NewRequirements(), meta, host)
+	if err != nil {
+		return nil, err
+	}
+
+	if policy, err := retryPolicy(&s.Retry); err != nil {
+		return nil, fmt.Errorf("application retry: %v", err)
+	} else if policy != nil {
+		app.SetRetryPolicy(policy)
+	}
+
+	if s.OpenAPI != "" {
+		path := s.OpenAPI
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(s.dir, path)
+		}
+		validator, err := openapi.Load(path)
+		if err != nil {
+			return nil, fmt.Errorf("openapi: %v", err)
+		}
+		app.SetSpecValidator(validator)
+	}
+
+	if s.GraphQLSchema != "" {
+		if err := s.validateGraphQL(s.GraphQLSchema); err != nil {
+			return nil, fmt.Errorf("graphql schema: %v", err)
+		}
+	}
+
+	if err := s.Parameters.register(app.GetApplicationParametersRegistry()); err != nil {
+		return nil, err
+	}
+
+	if err := applyEnvironment(app, config); err != nil {
+		return nil, fmt.Errorf("environment %s: %v", env, err)
+	}
+
+	for i := range s.Routes {
+		if err := s.Routes[i].build(app, s); err != nil {
+			return nil, err
+		}
+	}
+
+	return app, nil
+}
+
+// Select keeps only the routes and scenarios matching one of the patterns, which are matched with path.Match
+// against "route" to select every scenario of a route, or against "route/scenario" to select single scenarios.
+// It returns an error when nothing matches.
+func (s *Suite) Select(patterns []string) error {
+	if len(patterns) == 0 {
+		return nil
+	}
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid selection %q: %v", pattern, err)
+		}
+	}
+
+	var routes []RouteSpec
+	for _, rs := range s.Routes {
+		var scenarios []ScenarioSpec
+		for _, ss := range rs.Scenarios {
+			if MatchAny(patterns, rs.Name) || MatchAny(patterns, rs.Name+"/"+ss.Name) {
+				scenarios = append(scenarios, ss)
+			}
+		}
+		if len(scenarios) > 0 {
+			rs.Scenarios = scenarios
+			routes = append(routes, rs)
+		}
+	}
+	if len(routes) == 0 {
+		return fmt.Errorf("no scenario matches %s", strings.Join(patterns, ", "))
+	}
+	s.Routes = routes
+	return nil
+}
+
+// MatchAny reports whether the name matches one of the shell patterns of path.Match, invalid patterns matching nothing.
+func MatchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// ContractFields returns what the consumer relies on in the response of a scenario and whether the scenario
+// is part of the contracts, as described by its contract key; see contract.Options.
+// The rows of a data-driven scenario, named "scenario [row]", share the description of their scenario.
+func (s *Suite) ContractFields(route, scenario string) (fields, values []string, include bool) {
+	for _, rs := range s.Routes {
+		if rs.Name != route {
+			continue
+		}
+		for _, ss := range rs.Scenarios {
+			if ss.Contract != nil && (ss.Name == scenario || strings.HasPrefix(scenario, ss.Name+" [")) {
+				return ss.Contract.Fields, ss.Contract.Values, !ss.Contract.Exclude
+			}
+		}
+	}
+	return nil, nil, true
+}
+
+// SnapshotIgnore returns the ignore rules of the snapshot assertion of a scenario, if it has one.
+// The rows of a data-driven scenario, named "scenario [row]", share the rules of their scenario.
+func (s *Suite) SnapshotIgnore(route, scenario string) []string {
+	for _, rs := range s.Routes {
+		if rs.Name != route {
+			continue
+		}
+		for _, ss := range rs.Scenarios {
+			if ss.Snapshot != nil && (ss.Name == scenario || strings.HasPrefix(scenario, ss.Name+" [")) {
+				return ss.Snapshot.Ignore
+			}
+		}
+	}
+	return nil
+}
+
+// applyEnvironment applies the retry policy, headers and variables of the environment configuration to the application.
+func applyEnvironment(app *models.Application, config interfaces.Config) error {
+	policy, err := config.GetRetryPolicy()
+	if err != nil {
+		return err
+	}
+	if policy != nil {
+		app.SetRetryPolicy(policy)
+	}
+
+	if headers, err := config.Get("headers"); err == nil {
+		values, ok := headers.(map[string]interface{})
+		if !ok {
+			return errors.New("headers must be a mapping")
+		}
+		for key, value := range values {
+			if err := app.GetApplicationParametersRegistry().RegisterHeader(key, fmt.Sprint(value)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if variables, err := config.Get("variables"); err == nil {
+		values, ok := variables.(map[string]interface{})
+		if !ok {
+			return errors.New("variables must be a mapping")
+		}
+		for key, value := range values {
+			app.GetVariables().Set(key, fmt.Sprint(value))
+		}
+	}
+	return nil
+}
+
+func (rs *RouteSpec) build(app *models.Application, suite *Suite) error {
+	if rs.Name == "" {
+		return errors.New("route name is not defined")
+	}
+	method := rs.Method
+	path := rs.Path
+	if rs.GraphQL != nil && rs.WebSocket {
+		return fmt.Errorf("route %s: graphql and websocket are mutually exclusive", rs.Name)
+	}
+	if rs.GRPC != nil {
+		if rs.GraphQL != nil || rs.WebSocket {
+			return fmt.Errorf("route %s: grpc, graphql and websocket are mutually exclusive", rs.Name)
+		}
+		if method == "" {
+			method = http.MethodPost
+		}
+		if path == "" {
+			var err error
+			if path, err = rs.GRPC.path(); err != nil {
+				return fmt.Errorf("route %s: grpc: %v", rs.Name, err)
+			}
+		}
+		if _, err := rs.GRPC.exchange(suite); err != nil {
+			return fmt.Errorf("route %s: grpc: %v", rs.Name, err)
+		}
+	}
+	if rs.WebSocket {
+		if rs.Body.Kind != 0 {
+			return fmt.Errorf("route %s: body and websocket are mutually exclusive", rs.Name)
+		}
+		if method == "" {
+			method = http.MethodGet
+		}
+	}
+	if rs.GraphQL != nil {
+		if rs.Body.Kind != 0 {
+			return fmt.Errorf("route %s: body and graphql are mutually exclusive", rs.Name)
+		}
+		if method == "" {
+			method = http.MethodPost
+		}
+	}
+	if method == "" {
+		return fmt.Errorf("route %s: method is not defined", rs.Name)
+	}
+
+	info := &models.Info{}
+	info.SetName(rs.Name)
+	info.SetDescription(rs.Description)
+	info.SetPath(path)
+	info.SetMethod(models.Method{Name: strings.ToUpper(method)})
+
+	meta := nodeString(&rs.Meta, defaultMeta)
+	if _, err := models.NewMetaFromString(meta); err != nil {
+		return fmt.Errorf("route %s: meta: %v", rs.Name, err)
+	}
+
+	route := app.NewRoute(info, meta)
+	app.AddRoute(rs.Name, &route)
+
+	if rs.RequestSchema != "" {
+		if err := route.SetReqBodySchema(rs.RequestSchema); err != nil {
+			return fmt.Errorf("route %s: request schema: %v", rs.Name, err)
+		}
+	}
+	if rs.ResponseSchema != "" {
+		if err := route.SetResBodySchema(rs.ResponseSchema); err != nil {
+			return fmt.Errorf("route %s: response schema: %v", rs.Name, err)
+		}
+	}
+
+	body, err := suite.body(&rs.Body, route, rs.Name)
+	if rs.GraphQL != nil {
+		body, err = rs.GraphQL.body(suite.dir)
+	}
+	if err != nil {
+		return fmt.Errorf("route %s: body: %v", rs.Name, err)
+	}
+	route.SetBody(body)
+
+	if policy, err := retryPolicy(&rs.Retry); err != nil {
+		return fmt.Errorf("route %s: retry: %v", rs.Name, err)
+	} else if policy != nil {
+		route.SetRetryPolicy(policy)
+	}
+
+	if err := rs.Parameters.register(route.GetRouteParametersRegistry()); err != nil {
+		return fmt.Errorf("route %s: %v", rs.Name, err)
+	}
+	if rs.GraphQL != nil && !rs.Parameters.hasHeader("Content-Type") {
+		route.GetRouteParametersRegistry().RegisterHeader("Content-Type", "application/json")
+	}
+
+	for i := range rs.Scenarios {
+		if err := rs.Scenarios[i].build(route, rs, suite); err != nil {
+			return fmt.Errorf("route %s: %v", rs.Name, err)
+		}
+	}
+	return nil
+}
+
+// build adds the scenario to the route built from rs.
+func (ss *ScenarioSpec) build(route interfaces.Route, rs *RouteSpec, suite *Suite) error {
+	if ss.Name == "" {
+		return errors.New("scenario name is not defined")
+	}
+	graphQL := rs.GraphQL
+	if graphQL == nil && (ss.GraphQL != nil || len(ss.Expect.Errors) > 0) {
+		return fmt.Errorf("scenario %s: graphql and expect.errors require a graphql route", ss.Name)
+	}
+	if graphQL == nil && rs.GRPC == nil && len(ss.Expect.Data) > 0 {
+		return fmt.Errorf("scenario %s: expect.data requires a graphql or grpc route", ss.Name)
+	}
+	if !rs.WebSocket && len(ss.Steps) > 0 {
+		return fmt.Errorf("scenario %s: steps require a websocket route", ss.Name)
+	}
+	if rs.GRPC == nil && ss.Expect.GRPCStatus != "" {
+		return fmt.Errorf("scenario %s: expect.grpc_status requires a grpc route", ss.Name)
+	}
+
+	info := &models.Info{}
+	info.SetName(ss.Name)
+	info.SetDescription(ss.Description)
+
+	meta := nodeString(&ss.Meta, defaultMeta)
+	if _, err := models.NewMetaFromString(meta); err != nil {
+		return fmt.Errorf("scenario %s: meta: %v", ss.Name, err)
+	}
+
+	scenario := route.NewScenario(info, meta)
+	scenario.SetTimeout(ss.Timeout)
+
+	body, err := suite.body(&ss.Body, route, route.GetName()+"/"+ss.Name)
+	if ss.GraphQL != nil {
+		if ss.Body.Kind != 0 {
+			return fmt.Errorf("scenario %s: body and graphql are mutually exclusive", ss.Name)
+		}
+		body, err = graphQL.merge(ss.GraphQL).body(suite.dir)
+	}
+	if err != nil {
+		return fmt.Errorf("scenario %s: body: %v", ss.Name, err)
+	}
+	if body != nil {
+		scenario.SetBody(body)
+	}
+
+	if policy, err := retryPolicy(&ss.Retry); err != nil {
+		return fmt.Errorf("scenario %s: retry: %v", ss.Name, err)
+	} else if policy != nil {
+		scenario.SetRetryPolicy(policy)
+	}
+
+	if err := ss.Parameters.register(scenario.GetScenarioParametersRegistry()); err != nil {
+		return fmt.Errorf("scenario %s: %v", ss.Name, err)
+	}
+
+	if ss.WaitUntil != nil {
+		condition, policy, err := ss.WaitUntil.condition()
+		if err != nil {
+			return fmt.Errorf("scenario %s: wait_until: %v", ss.Name, err)
+		}
+		scenario.SetWaitUntil(condition, policy)
+	}
+
+	if ss.Dataset.File != "" {
+		path := ss.Dataset.File
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(suite.dir, path)
+		}
+		dataset, err := models.NewDatasetFromFile(path, ss.Dataset.Name, ss.Dataset.Tags)
+		if err != nil {
+			return fmt.Errorf("scenario %s: dataset: %v", ss.Name, err)
+		}
+		scenario.SetDataset(dataset)
+	}
+
+	if ss.Example != nil {
+		resp, err := ss.Example.response()
+		if err != nil {
+			return fmt.Errorf("scenario %s: example: %v", ss.Name, err)
+		}
+		scenario.SetResponse(resp)
+	}
+
+	expect := ss.Expect
+	if rs.WebSocket {
+		if ss.Body.Kind != 0 {
+			return fmt.Errorf("scenario %s: body and websocket are mutually exclusive", ss.Name)
+		}
+		exchangeSteps, err := steps(ss.Steps)
+		if err != nil {
+			return fmt.Errorf("scenario %s: %v", ss.Name, err)
+		}
+		scenario.SetExchange(websocket.Exchange(exchangeSteps))
+		if expect.Status == "" {
+			expect.Status = strconv.Itoa(http.StatusSwitchingProtocols)
+		}
+	}
+	if rs.GRPC != nil {
+		exchange, err := rs.GRPC.exchange(suite)
+		if err != nil {
+			return fmt.Errorf("scenario %s: grpc: %v", ss.Name, err)
+		}
+		scenario.SetExchange(exchange)
+		if expect.Status == "" && expect.GRPCStatus == "" {
+			expect.GRPCStatus = "OK"
+		}
+		if expect.GRPCStatus != "" {
+			scenario.GetScenarioHooksRegistry().RegisterResponseHook(grpc.StatusHook(expect.GRPCStatus))
+		}
+		if len(expect.Data) > 0 {
+			hook, err := grpc.MessageHook(expect.Data)
+			if err != nil {
+				return fmt.Errorf("scenario %s: expect: %v", ss.Name, err)
+			}
+			scenario.GetScenarioHooksRegistry().RegisterResponseHook(hook)
+		}
+	}
+
+	if exchange, err := ss.streamExchange(suite); err != nil {
+		return fmt.Errorf("scenario %s: %v", ss.Name, err)
+	} else if exchange != nil {
+		if rs.WebSocket || rs.GRPC != nil {
+			return fmt.Errorf("scenario %s: stream and download are not supported on websocket and grpc routes", ss.Name)
+		}
+		scenario.SetExchange(exchange)
+	}
+
+	scenario.GetScenarioHooksRegistry().RegisterResponseHook(expect.hook())
+	if ss.handler != nil {
+		scenario.GetScenarioHooksRegistry().RegisterResponseHook(ss.handler.hook())
+	}
+	if graphQL != nil {
+		hooks, err := ss.Expect.graphQLHooks()
+		if err != nil {
+			return fmt.Errorf("scenario %s: expect: %v", ss.Name, err)
+		}
+		for _, hook := range hooks {
+			scenario.GetScenarioHooksRegistry().RegisterResponseHook(hook)
+		}
+	}
+
+	if ss.Snapshot != nil && !suite.SkipSnapshots {
+		hook, err := ss.Snapshot.hook(filepath.Join(suite.dir, "__snapshots__"), suite.UpdateSnapshots)
+		if err != nil {
+			return fmt.Errorf("scenario %s: snapshot: %v", ss.Name, err)
+		}
+		scenario.GetScenarioHooksRegistry().RegisterResponseHook(hook)
+	}
+	return nil
+}
+
+// hook returns the Response Hook comparing the response body against the golden file of the scenario.
+// Every row of a data-driven scenario has its own golden file.
+func (ss *SnapshotSpec) hook(dir string, update bool) (interfaces.ContextHook, error) {
+	// The ignore rules are checked once here rather than on every response.
+	if _, err := snapshot.New("", ss.Ignore, update); err != nil {
+		return nil, err
+	}
+
+	return func(hc interfaces.HookContext) error {
+		var route string
+		if r := hc.Scenario().GetParentRoute(); r != nil {
+			route = r.GetName()
+		}
+
+		s, err := snapshot.New(snapshot.PathFor(dir, route, hc.Scenario().GetName()), ss.Ignore, update)
+		if err != nil {
+			return err
+		}
+		written, err := s.Assert(hc.Response().Bytes())
+		if written {
+			hc.Logger().Printf("snapshot %s written", s.Path())
+		}
+		return err
+	}, nil
+}
+
+// response returns the example response. The status defaults to 200.
+func (es *ExampleSpec) response() (interfaces.Response, error) {
+	body, err := nodeBody(&es.Body)
+	if err != nil {
+		return nil, err
+	}
+	status := es.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	return models.NewStaticResponse(status, es.Headers, body), nil
+}
+
+// hook returns the Response Hook that checks the response against the expectations.
+func (es ExpectSpec) hook() interfaces.ContextHook {
+	return func(hc interfaces.HookContext) error {
+		resp := hc.Response()
+
+		if status := hc.Expand(es.Status); status != "" {
+			expected, err := strconv.Atoi(status)
+			if err != nil {
+				return fmt.Errorf("invalid expected status %q", status)
+			}
+			if resp.GetStatusCode() != expected {
+				return fmt.Errorf("expected status %d, got %d", expected, resp.GetStatusCode())
+			}
+		} else if es.GRPCStatus == "" && !resp.IsSuccess() {
+			return fmt.Errorf("expected a successful status, got %d", resp.GetStatusCode())
+		}
+
+		for key, value := range es.Headers {
+			expected := hc.Expand(value)
+			actual, err := resp.HeaderValue(key)
+			if err != nil || actual != expected {
+				return fmt.Errorf("expected header %s to be %q, got %q", key, expected, actual)
+			}
+		}
+
+		if es.BodyContains != "" {
+			expected := hc.Expand(es.BodyContains)
+			if !strings.Contains(resp.String(), expected) {
+				return fmt.Errorf("expected body to contain %q", expected)
+			}
+		}
+
+		if timing := resp.GetTiming(); timing != nil {
+			if es.MaxDuration > 0 && timing.Total() > es.MaxDuration {
+				return fmt.Errorf("expected the request to take at most %s, took %s", es.MaxDuration, timing.Total())
+			}
+			if es.MaxTimeToFirstByte > 0 && timing.TimeToFirstByte() > es.MaxTimeToFirstByte {
+				return fmt.Errorf("expected the first byte within %s, got it after %s", es.MaxTimeToFirstByte, timing.TimeToFirstByte())
+			}
+		}
+		return nil
+	}
+}
+
+func (ps ParametersSpec) register(registry interfaces.ParametersRegistry) error {
+	for key, value := range ps.Headers {
+		if err := registry.RegisterHeader(key, value); err != nil {
+			return err
+		}
+	}
+	for key, value := range ps.Query {
+		if err := registry.RegisterQueryParameter(key, value); err != nil {
+			return err
+		}
+	}
+	for key, value := range ps.PathVariables {
+		if err := registry.RegisterPathVariable(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// retryPolicy builds the retry policy described by the node, or returns nil if the node is empty.
+func retryPolicy(node *yaml.Node) (interfaces.RetryPolicy, error) {
+	s := nodeString(node, "")
+	if s == "" {
+		return nil, nil
+	}
+	return models.NewRetryPolicyFromString(s)
+}
+
+// nodeString returns the YAML representation of the node, or def if the node is empty.
+// A scalar node is returned as is, so that YAML documents can also be given as block strings.
+func nodeString(node *yaml.Node, def string) string {
+	if node.Kind == 0 {
+		return def
+	}
+	if node.Kind == yaml.ScalarNode {
+		return node.Value
+	}
+	data, err := yaml.Marshal(node)
+	if err != nil {
+		return def
+	}
+	return string(data)
+}
+
+// generateBody is the plain scalar body generating the body from the request body schema of the route.
+const generateBody = "generate"
+
+// body returns the request body described by the node. A plain, unquoted "generate" generates a body
+// from the request body schema of the route, seeded with the seed of the suite and the given name,
+// so that every route and scenario gets its own body.
+func (s *Suite) body(node *yaml.Node, route interfaces.Route, name string) ([]byte, error) {
+	if node.Kind != yaml.ScalarNode || node.Value != generateBody || node.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle) != 0 {
+		return nodeBody(node)
+	}
+
+	schema := route.GetInfo().GetRequestBodySchema()
+	if schema == nil {
+		return nil, errors.New("generating a body requires a request_schema on the route")
+	}
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	value, err := schema.Generate(s.Seed ^ int64(h.Sum64()))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+// nodeBody returns the request body described by the node, or nil if the node is empty.
+// Scalars are sent as is and mappings or sequences are encoded as JSON.
+func nodeBody(node *yaml.Node) ([]byte, error) {
+	if node.Kind == 0 {
+		return nil, nil
+	}
+	if node.Kind == yaml.ScalarNode {
+		return []byte(node.Value), nil
+	}
+	var data interface{}
+	if err := node.Decode(&data); err != nil {
+		return nil, err
+	}
+	return json.Marshal(data)
+}
+
+/* Example suite -
+
+host:
+  protocol: https
+  hostname: api.example.com
+openapi: openapi.yaml        # every response is checked for spec drift
+graphql_schema: schema.graphql  # GraphQL operations are validated when the suite is built
+headers:
+  Accept: application/json
+retry:
+  max_attempts: 3
+  retry_on_status: [502, 503]
+routes:
+  - name: get-user
+    method: GET
+    path: /users/{id}
+    scenarios:
+      - name: existing user
+        timeout: 5s
+        path_variables:
+          id: "1"
+        expect:
+          status: 200
+      - name: missing user
+        path_variables:
+          id: "0"
+        expect:
+          status: 404
+        example:            # served by routest mock
+          status: 404
+          body: {error: not found}
+        snapshot:
+          ignore: [$.request_id, $..timestamp]
+      - name: user by dataset
+        dataset:
+          file: users.csv   # columns: case, id, status, tags
+          name: case
+        path_variables:
+          id: "{{id}}"
+        expect:
+          status: "{{status}}"
+  - name: create-user
+    method: POST
+    path: /users
+    request_schema: '{"type":"object","required":["email"],"properties":{"email":{"type":"string","format":"email"}}}'
+    scenarios:
+      - name: generated user
+        body: generate      # generated from request_schema, seeded by the seed of the suite
+        expect:
+          status: 201
+  - name: user-graphql
+    path: /graphql          # POST by default, failing on the errors of the result
+    graphql:
+      query: 'query User($id: ID!) { user(id: $id) { email } }'
+    scenarios:
+      - name: existing user
+        graphql:
+          variables: {id: "1"}
+        expect:
+          data:
+            user.email: jane@example.com
+  - name: notifications
+    path: /ws               # GET by default, over wss with the https protocol
+    websocket: true
+    scenarios:
+      - name: subscribe
+        steps:
+          - send: {type: subscribe, channel: orders}
+          - expect:
+              match: {type: subscribed}
+              timeout: 2s
+          - close
+  - name: job-events
+    method: GET
+    path: /jobs/1/events
+    scenarios:
+      - name: first events
+        stream: {events: 2, timeout: 10s}   # Server-Sent Events, read as they arrive
+        expect:
+          max_time_to_first_event: 500ms
+          events:
+            - event: status
+              data: {state: queued}
+  - name: export
+    method: GET
+    path: /export
+    scenarios:
+      - name: full export
+        download: {file: out/export.csv}  # streamed to a hash and a file instead of memory
+        expect:
+          sha256: 9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08
+  - name: get-order
+    grpc:
+      method: shop.v1.Orders/GetOrder
+      protoset: orders.protoset   # or reflection: true
+    headers:
+      x-tenant: acme                # sent as metadata
+    scenarios:
+      - name: existing order
+        body: {id: "42"}            # the JSON of the input message
+        expect:
+          data:
+            order.status: SHIPPED
+      - name: missing order
+        body: {id: "0"}
+        expect:
+          grpc_status: NOT_FOUND
+
+*/
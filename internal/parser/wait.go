@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/qatoolist/RouTest/internal/interfaces"
+	"github.com/qatoolist/RouTest/internal/jsonpath"
+	"github.com/qatoolist/RouTest/internal/models"
+)
+
+// WaitUntilSpec describes the condition a scenario re-sends its request until, e.g. the completion of an
+// asynchronous job, and how often and for how long it polls. Every condition given must be met. The values
+// are compared as they are written: variables are not expanded in them.
+type WaitUntilSpec struct {
+	// Status is the expected status code. Zero accepts any status.
+	Status int `yaml:"status"`
+
+	// Match are the expected values in the JSON response body, by JSONPath expression, e.g. job.state.
+	Match map[string]interface{} `yaml:"match"`
+
+	// Headers are the expected values of response headers.
+	Headers map[string]string `yaml:"headers"`
+
+	// BodyContains is a string the response body is expected to contain.
+	BodyContains string `yaml:"body_contains"`
+
+	// Interval is the delay between the first and the second poll. Defaults to 1s.
+	Interval time.Duration `yaml:"interval"`
+
+	// MaxInterval caps the interval when it grows with Multiplier. Zero means no cap.
+	MaxInterval time.Duration `yaml:"max_interval"`
+
+	// Multiplier is the factor the interval grows by after every poll. Defaults to 1, a fixed interval.
+	Multiplier float64 `yaml:"multiplier"`
+
+	// Timeout is the maximum time spent polling before the scenario fails. Defaults to 30s.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// condition returns the condition and the poll policy of the wait.
+func (ws *WaitUntilSpec) condition() (interfaces.Condition, interfaces.PollPolicy, error) {
+	if ws.Status == 0 && len(ws.Match) == 0 && len(ws.Headers) == 0 && ws.BodyContains == "" {
+		return nil, nil, errors.New("expected at least one of status, match, headers and body_contains")
+	}
+
+	policy := models.NewPollPolicy()
+	if ws.Interval > 0 {
+		policy.Interval = ws.Interval
+	}
+	if ws.Multiplier != 0 {
+		if ws.Multiplier < 1 {
+			return nil, nil, errors.New("invalid multiplier value")
+		}
+		policy.Multiplier = ws.Multiplier
+	}
+	if ws.Timeout > 0 {
+		policy.Timeout = ws.Timeout
+	}
+	policy.MaxInterval = ws.MaxInterval
+
+	expectations, err := jsonpath.NewExpectations(ws.Match)
+	if err != nil {
+		return nil, nil, fmt.Errorf("match %v", err)
+	}
+
+	return func(resp interfaces.Response) error {
+		if ws.Status != 0 && resp.GetStatusCode() != ws.Status {
+			return fmt.Errorf("expected status %d, got %d", ws.Status, resp.GetStatusCode())
+		}
+		for key, expected := range ws.Headers {
+			if actual, err := resp.HeaderValue(key); err != nil || actual != expected {
+				return fmt.Errorf("expected header %s to be %q, got %q", key, expected, actual)
+			}
+		}
+		if ws.BodyContains != "" && !strings.Contains(resp.String(), ws.BodyContains) {
+			return fmt.Errorf("expected body to contain %q", ws.BodyContains)
+		}
+		if len(expectations) > 0 {
+			var body interface{}
+			if err := json.Unmarshal(resp.Bytes(), &body); err != nil {
+				return fmt.Errorf("the response body is not JSON: %v", err)
+			}
+			if err := expectations.Check(body, nil); err != nil {
+				return fmt.Errorf("body: %v", err)
+			}
+		}
+		return nil
+	}, policy, nil
+}
@@ -0,0 +1,99 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+const waitSuite = `
+host: {protocol: http, hostname: 127.0.0.1, port: %s}
+routes:
+  - name: get-job
+    method: GET
+    path: /jobs/1
+    scenarios:
+      - name: wait
+        wait_until: {%s}
+`
+
+// TestWaitUntil checks that the scenarios poll until the wait_until conditions are met or time out.
+func TestWaitUntil(t *testing.T) {
+	tests := []struct {
+		name      string
+		waitUntil string
+		wantPass  bool
+		wantPolls int32
+	}{
+		{"status", "status: 200, interval: 1ms", true, 3},
+		{"match", "match: {job.state: done, job.progress: 100}, interval: 1ms", true, 3},
+		{"header", "headers: {X-Job-State: done}, interval: 1ms", true, 3},
+		{"body contains", "body_contains: done, interval: 1ms", true, 3},
+		{"every condition", "status: 200, match: {job.state: running}, interval: 1ms, timeout: 50ms", false, 0},
+		{"growing interval", "status: 200, interval: 1ms, multiplier: 2, max_interval: 2ms", true, 3},
+		{"timeout", "match: {job.state: failed}, interval: 5ms, timeout: 30ms", false, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&calls, 1) < 3 {
+					w.Header().Set("X-Job-State", "running")
+					w.WriteHeader(http.StatusAccepted)
+					fmt.Fprint(w, `{"job": {"state": "running", "progress": 50}}`)
+					return
+				}
+				w.Header().Set("X-Job-State", "done")
+				fmt.Fprint(w, `{"job": {"state": "done", "progress": 100}}`)
+			}))
+			defer server.Close()
+			_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+			suite, err := ParseSuite([]byte(fmt.Sprintf(waitSuite, port, test.waitUntil)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			app, err := suite.Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			report := app.Run(context.Background())
+
+			if passed := report.Passed() == 1; passed != test.wantPass {
+				t.Errorf("expected passed to be %v, got %v: %v", test.wantPass, passed, report.Results()[0].Err())
+			}
+			if test.wantPolls > 0 && atomic.LoadInt32(&calls) != test.wantPolls {
+				t.Errorf("expected %d polls, got %d", test.wantPolls, calls)
+			}
+		})
+	}
+}
+
+// TestWaitUntilInvalid checks the wait_until blocks refused when the suite is built.
+func TestWaitUntilInvalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		waitUntil string
+		wantErr   string
+	}{
+		{"no condition", "timeout: 1s", "expected at least one of"},
+		{"multiplier", "status: 200, multiplier: 0.5", "invalid multiplier"},
+		{"expression", "match: {'job[': done}", "match job["},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			suite, err := ParseSuite([]byte(fmt.Sprintf(waitSuite, "80", test.waitUntil)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := suite.Build(); err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("expected an error containing %q, got %v", test.wantErr, err)
+			}
+		})
+	}
+}
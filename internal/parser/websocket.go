@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/qatoolist/RouTest/internal/websocket"
+	"gopkg.in/yaml.v3"
+)
+
+// StepSpec describes a step of the scenario of a WebSocket route, one of:
+//
+//	steps:
+//	  - connect
+//	  - send: hello                       # a text frame
+//	  - send: {type: subscribe}           # a JSON frame
+//	  - expect: {match: {$.type: subscribed}, timeout: 2s}
+//	  - close
+type StepSpec struct {
+	// Connect opens the connection. It is implied before the first step otherwise.
+	Connect bool `yaml:"-"`
+
+	// Close closes the connection. It is implied after the last step otherwise.
+	Close bool `yaml:"-"`
+
+	// Send is the message sent: scalars are sent as they are, mappings and sequences as JSON.
+	Send yaml.Node `yaml:"send"`
+
+	// Expect describes the message expected.
+	Expect *MessageSpec `yaml:"expect"`
+}
+
+// MessageSpec describes a message expected from the server. The messages that do not match are skipped
+// until one does or the timeout expires.
+type MessageSpec struct {
+	// Match are the expected values in the message, by JSONPath expression, e.g. $.type or type.
+	Match map[string]interface{} `yaml:"match"`
+
+	// Contains is a string the message is expected to contain.
+	Contains string `yaml:"contains"`
+
+	// Timeout is the time waited for a matching message. Defaults to 5s.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// UnmarshalYAML accepts either connect or close, or a mapping with either send or expect.
+func (ss *StepSpec) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		switch node.Value {
+		case websocket.ActionConnect:
+			ss.Connect = true
+		case websocket.ActionClose:
+			ss.Close = true
+		default:
+			return fmt.Errorf("unknown step %q, expected connect, close, send or expect", node.Value)
+		}
+		return nil
+	}
+	type plain StepSpec
+	if err := node.Decode((*plain)(ss)); err != nil {
+		return err
+	}
+	if (ss.Send.Kind != 0) == (ss.Expect != nil) {
+		return errors.New("a step is either connect, close, send or expect")
+	}
+	return nil
+}
+
+// step returns the step of the exchange described by the spec.
+func (ss *StepSpec) step() (websocket.Step, error) {
+	switch {
+	case ss.Connect:
+		return websocket.Step{Action: websocket.ActionConnect}, nil
+	case ss.Close:
+		return websocket.Step{Action: websocket.ActionClose}, nil
+	case ss.Expect != nil:
+		if len(ss.Expect.Match) == 0 && ss.Expect.Contains == "" {
+			return websocket.Step{}, errors.New("expect requires match or contains")
+		}
+		return websocket.Step{Action: websocket.ActionExpect, Match: ss.Expect.Match, Contains: ss.Expect.Contains, Timeout: ss.Expect.Timeout}, nil
+	}
+	message, err := nodeBody(&ss.Send)
+	return websocket.Step{Action: websocket.ActionSend, Message: string(message)}, err
+}
+
+// steps returns the steps of the exchange of a scenario of a WebSocket route.
+func steps(specs []StepSpec) ([]websocket.Step, error) {
+	steps := make([]websocket.Step, len(specs))
+	for i := range specs {
+		step, err := specs[i].step()
+		if err != nil {
+			return nil, fmt.Errorf("step %d: %v", i+1, err)
+		}
+		steps[i] = step
+	}
+	return steps, nil
+}
@@ -0,0 +1,167 @@
+// Package snapshot compares response bodies against golden files written on the first run.
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/qatoolist/RouTest/internal/jsondiff"
+	"github.com/qatoolist/RouTest/internal/jsonpath"
+)
+
+// Ignored replaces the values of ignored fields in the golden files.
+const Ignored = "<ignored>"
+
+// Snapshot is the golden file of a response body.
+// JSON bodies are normalized before they are written or compared: members are sorted, the document is indented
+// and the fields selected by the ignore rules are replaced with Ignored, so that volatile values such as
+// timestamps and ids do not fail the comparison. Other bodies are compared as text.
+type Snapshot struct {
+	path   string
+	ignore []*jsonpath.Path
+	update bool
+}
+
+// New creates a new Snapshot stored at the given path, ignoring the fields selected by the JSONPath expressions.
+// When update is set, the golden file is rewritten instead of compared.
+func New(path string, ignore []string, update bool) (*Snapshot, error) {
+	s := &Snapshot{path: path, update: update}
+	for _, expr := range ignore {
+		p, err := jsonpath.Parse(expr)
+		if err != nil {
+			return nil, err
+		}
+		s.ignore = append(s.ignore, p)
+	}
+	return s, nil
+}
+
+// Path returns the path of the golden file.
+func (s *Snapshot) Path() string {
+	return s.path
+}
+
+// Assert compares the body against the golden file. The golden file is written instead when it does not exist yet
+// or when the snapshot is updated, and written reports so.
+func (s *Snapshot) Assert(body []byte) (written bool, err error) {
+	normalized := s.normalize(body)
+
+	golden, err := ioutil.ReadFile(s.path)
+	if s.update || errors.Is(err, os.ErrNotExist) {
+		if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+			return false, err
+		}
+		return true, ioutil.WriteFile(s.path, normalized, 0o644)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	golden = s.normalize(golden)
+	if bytes.Equal(golden, normalized) {
+		return false, nil
+	}
+
+	// JSON bodies are compared structurally, so that numbers written differently, e.g. 3 and 3.0, match.
+	var expected, actual interface{}
+	var details string
+	if decode(golden, &expected) == nil && decode(normalized, &actual) == nil {
+		diffs := jsondiff.Compare(expected, actual)
+		if len(diffs) == 0 {
+			return false, nil
+		}
+		details = jsondiff.Format(diffs, "  ")
+	} else {
+		details = textDiff(string(golden), string(normalized))
+	}
+	return false, fmt.Errorf("response does not match the snapshot %s, run with --update-snapshots to accept it:\n%s", s.path, details)
+}
+
+// normalize returns the normalized form of a body.
+func (s *Snapshot) normalize(body []byte) []byte {
+	var doc interface{}
+	if err := decode(body, &doc); err != nil {
+		return body
+	}
+	for _, p := range s.ignore {
+		p.Replace(doc, func(interface{}) interface{} { return Ignored })
+	}
+	var normalized bytes.Buffer
+	enc := json.NewEncoder(&normalized)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return body
+	}
+	return normalized.Bytes()
+}
+
+// decode decodes a JSON body, keeping the numbers as they were written.
+func decode(body []byte, v *interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return errors.New("trailing data after the JSON document")
+	}
+	return nil
+}
+
+// textDiff describes the first line that differs between two texts.
+func textDiff(expected, actual string) string {
+	e, a := strings.Split(expected, "\n"), strings.Split(actual, "\n")
+	for i := 0; i < len(e) || i < len(a); i++ {
+		var el, al string
+		if i < len(e) {
+			el = e[i]
+		}
+		if i < len(a) {
+			al = a[i]
+		}
+		if el != al {
+			return fmt.Sprintf("  line %d: expected %q, got %q", i+1, el, al)
+		}
+	}
+	return ""
+}
+
+// unsafeChars are the characters replaced in the file names of golden files.
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// PathFor returns the path of the golden file of a scenario in the snapshots directory.
+func PathFor(dir, route, scenario string) string {
+	return filepath.Join(dir, fileName(route), fileName(scenario)+".snap")
+}
+
+func fileName(name string) string {
+	name = strings.Trim(unsafeChars.ReplaceAllString(name, "_"), "_")
+	if name == "" {
+		return "unnamed"
+	}
+	return name
+}
+
+/* Example usage -
+
+s, err := snapshot.New(snapshot.PathFor("__snapshots__", "get-user", "existing user"),
+    []string{"$.id", "$..created_at"}, false)
+if err != nil {
+    log.Fatal(err)
+}
+
+if written, err := s.Assert(response.Bytes()); err != nil {
+    log.Fatal(err)
+} else if written {
+    log.Printf("snapshot %s written", s.Path())
+}
+
+*/
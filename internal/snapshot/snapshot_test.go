@@ -0,0 +1,99 @@
+package snapshot
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestAssert checks the writing of golden files and the comparison of later bodies against them.
+func TestAssert(t *testing.T) {
+	tests := []struct {
+		name    string
+		ignore  []string
+		golden  string
+		body    string
+		wantErr []string
+	}{
+		{"match", nil, `{"name": "bob", "age": 3}`, `{"age": 3.0, "name": "bob"}`, nil},
+		{"mismatch", nil, `{"name": "bob", "tags": ["a"]}`, `{"name": "alice", "tags": []}`, []string{
+			`$.name: expected "bob", got "alice"`,
+			`$.tags[0]: missing, expected "a"`,
+			"--update-snapshots",
+		}},
+		{"ignored fields", []string{"$.id", "$..created_at"}, `{"id": 1, "items": [{"created_at": "monday"}]}`, `{"id": 2, "items": [{"created_at": "tuesday"}]}`, nil},
+		{"ignored fields only", []string{"$.id"}, `{"id": 1, "name": "bob"}`, `{"id": 2, "name": "alice"}`, []string{`$.name: expected "bob", got "alice"`}},
+		{"text match", nil, "pong\n", "pong\n", nil},
+		{"text mismatch", nil, "line 1\nline 2\n", "line 1\nline two\n", []string{`line 2: expected "line 2", got "line two"`}},
+		{"json and text", nil, `{"name": "bob"}`, "<html></html>", []string{`line 1: expected "{", got "<html></html>"`}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := PathFor(t.TempDir(), "get user", test.name)
+			s, err := New(path, test.ignore, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			written, err := s.Assert([]byte(test.golden))
+			if err != nil || !written {
+				t.Fatalf("expected the golden file to be written on the first run, got %v, %v", written, err)
+			}
+			written, err = s.Assert([]byte(test.body))
+			if written {
+				t.Error("expected the golden file to be compared, not written")
+			}
+			if len(test.wantErr) == 0 {
+				if err != nil {
+					t.Errorf("expected a match, got %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected a mismatch")
+			}
+			for _, want := range test.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("expected %q in the error, got:\n%v", want, err)
+				}
+			}
+		})
+	}
+}
+
+// TestAssertUpdate checks that an updated snapshot rewrites the golden file, which later runs match.
+func TestAssertUpdate(t *testing.T) {
+	path := PathFor(t.TempDir(), "get-user", "existing user")
+	s, _ := New(path, []string{"$.id"}, false)
+	if _, err := s.Assert([]byte(`{"id": 1, "name": "bob"}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	updated, _ := New(path, []string{"$.id"}, true)
+	if written, err := updated.Assert([]byte(`{"name": "alice", "id": 2}`)); err != nil || !written {
+		t.Fatalf("expected the golden file to be rewritten, got %v, %v", written, err)
+	}
+	golden, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "{\n  \"id\": \"<ignored>\",\n  \"name\": \"alice\"\n}\n"; string(golden) != want {
+		t.Errorf("expected the normalized body, got:\n%s", golden)
+	}
+	if _, err := s.Assert([]byte(`{"id": 3, "name": "alice"}`)); err != nil {
+		t.Errorf("expected the updated golden file to match, got %v", err)
+	}
+}
+
+func TestNewInvalidIgnore(t *testing.T) {
+	if _, err := New("x.snap", []string{"$["}, false); err == nil {
+		t.Error("expected an error for an invalid JSONPath expression")
+	}
+}
+
+func TestPathFor(t *testing.T) {
+	if got, want := PathFor("snaps", "get user/{id}", "???"), filepath.Join("snaps", "get_user_id", "unnamed.snap"); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,92 @@
+package stream
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Event is a Server-Sent Event.
+type Event struct {
+	// ID is the last event ID set by the stream, which carries over to the following events.
+	ID string
+
+	// Type is the type of the event, "message" when the stream does not set one.
+	Type string
+
+	// Data is the data of the event, its data lines joined with newlines.
+	Data string
+
+	// Retry is the reconnection time in milliseconds set by the event, zero when it sets none.
+	Retry int
+}
+
+// EventReader reads the Server-Sent Events of a text/event-stream body incrementally,
+// as specified by the HTML Living Standard.
+type EventReader struct {
+	r      *bufio.Reader
+	lastID string
+}
+
+// NewEventReader creates a new EventReader reading the events from r.
+func NewEventReader(r io.Reader) *EventReader {
+	return &EventReader{r: bufio.NewReader(r)}
+}
+
+// Next blocks until the next event is complete and returns it. It returns io.EOF at the end of the stream,
+// an incomplete last event being discarded.
+func (er *EventReader) Next() (Event, error) {
+	event := Event{}
+	var data strings.Builder
+	hasData := false
+
+	for {
+		line, err := er.r.ReadString('\n')
+		if err != nil {
+			if err == io.EOF {
+				return Event{}, io.EOF
+			}
+			return Event{}, err
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		if line == "" {
+			if !hasData {
+				// An event without data is not dispatched, but its type and retry are reset.
+				event = Event{}
+				continue
+			}
+			event.ID = er.lastID
+			event.Data = strings.TrimSuffix(data.String(), "\n")
+			if event.Type == "" {
+				event.Type = "message"
+			}
+			return event, nil
+		}
+		if strings.HasPrefix(line, ":") {
+			continue // comment, e.g. a keep-alive
+		}
+
+		field, value := line, ""
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+		switch field {
+		case "event":
+			event.Type = value
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+			hasData = true
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				er.lastID = value
+			}
+		case "retry":
+			if retry, err := strconv.Atoi(value); err == nil && retry >= 0 {
+				event.Retry = retry
+			}
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package stream
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEventReader(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream string
+		want   []Event
+	}{
+		{
+			name:   "single line",
+			stream: "data: hello\n\n",
+			want:   []Event{{Type: "message", Data: "hello"}},
+		},
+		{
+			name:   "multi-line data",
+			stream: "event: log\ndata: line 1\ndata:line 2\ndata\n\n",
+			want:   []Event{{Type: "log", Data: "line 1\nline 2\n"}},
+		},
+		{
+			name:   "id carried over",
+			stream: "id: 1\ndata: a\n\ndata: b\n\nid\ndata: c\n\n",
+			want:   []Event{{ID: "1", Type: "message", Data: "a"}, {ID: "1", Type: "message", Data: "b"}, {Type: "message", Data: "c"}},
+		},
+		{
+			name:   "retry",
+			stream: "retry: 3000\ndata: a\n\nretry: soon\ndata: b\n\n",
+			want:   []Event{{Type: "message", Data: "a", Retry: 3000}, {Type: "message", Data: "b"}},
+		},
+		{
+			name:   "comments and events without data",
+			stream: ": keep-alive\n\nevent: ping\n\ndata: a\n\n",
+			want:   []Event{{Type: "message", Data: "a"}},
+		},
+		{
+			name:   "CRLF",
+			stream: "event: status\r\ndata: {\"state\": \"done\"}\r\n\r\n",
+			want:   []Event{{Type: "status", Data: `{"state": "done"}`}},
+		},
+		{
+			name:   "incomplete last event",
+			stream: "data: a\n\ndata: b\n",
+			want:   []Event{{Type: "message", Data: "a"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reader := NewEventReader(strings.NewReader(test.stream))
+			var events []Event
+			for {
+				event, err := reader.Next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				events = append(events, event)
+			}
+			if !reflect.DeepEqual(events, test.want) {
+				t.Errorf("expected %+v, got %+v", test.want, events)
+			}
+		})
+	}
+}
@@ -0,0 +1,350 @@
+// Package stream performs the scenarios of streaming endpoints, whose bodies cannot be read whole before the
+// response is handled: Server-Sent Events are read incrementally until a number of events or a timeout,
+// and large downloads are streamed to a hash, and optionally a file, instead of memory.
+package stream
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/qatoolist/RouTest/internal/interfaces"
+	"github.com/qatoolist/RouTest/internal/jsonpath"
+	"github.com/qatoolist/RouTest/internal/models"
+)
+
+// DefaultTimeout is the time events are read for when neither a number of events nor a timeout is set,
+// since event streams usually never end.
+const DefaultTimeout = 30 * time.Second
+
+// maxErrorBody bounds the size of the bodies of the responses read whole, e.g. the errors of the server.
+const maxErrorBody = 1 << 20
+
+// EventsOptions configures the reading of Server-Sent Events and the expectations on them.
+type EventsOptions struct {
+	// Count stops the reading after that many events. Zero reads until the end of the stream or the timeout.
+	Count int
+
+	// Timeout stops the reading after that time, failing the scenario when fewer than Count events were received.
+	// Zero defaults to DefaultTimeout when Count is not set, and to no timeout other than the scenario's otherwise.
+	Timeout time.Duration
+
+	// Expect are the expectations on the first events, in order.
+	Expect []EventExpectation
+
+	// MaxTimeToFirstEvent is the maximum time between sending the request and receiving the first event.
+	// Zero disables the check.
+	MaxTimeToFirstEvent time.Duration
+}
+
+// EventExpectation describes what an event is expected to look like.
+// Every value may reference the columns of the data row or the captured variables as {{name}}.
+type EventExpectation struct {
+	// Type is the expected type of the event. Empty accepts any type.
+	Type string
+
+	// Data are the expected values in the JSON data of the event, by JSONPath expression, e.g. $.status or status.
+	// Strings are compared with the text of the values, other values as JSON.
+	Data map[string]interface{}
+
+	// DataContains is a string the data of the event is expected to contain.
+	DataContains string
+}
+
+// Events returns the exchange sending the request of the scenario and reading the Server-Sent Events of
+// its text/event-stream response until the count of events, the timeout or the end of the stream, before
+// checking them against the expectations.
+//
+// The response of the exchange has the status and headers of the HTTP response and, as its body, the JSON array
+// of the events received, their data as JSON values when they are JSON. Responses without a successful status
+// are read whole and returned as they are, so that their status can be asserted on.
+func Events(opts EventsOptions) interfaces.Exchange {
+	return func(hc interfaces.HookContext) (interfaces.Response, error) {
+		timeout := opts.Timeout
+		if timeout <= 0 && opts.Count == 0 {
+			timeout = DefaultTimeout
+		}
+		var ctx context.Context = hc
+		var cancel context.CancelFunc
+		if timeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+		} else {
+			ctx, cancel = context.WithCancel(ctx)
+		}
+		defer cancel()
+
+		start := time.Now()
+		resp, err := send(ctx, hc)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return whole(resp)
+		}
+		if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "text/event-stream" {
+			response, err := whole(resp)
+			if err == nil {
+				err = fmt.Errorf("expected a text/event-stream response, got %q", resp.Header.Get("Content-Type"))
+			}
+			return response, err
+		}
+
+		var events []Event
+		var firstEvent time.Duration
+		var readErr error
+		reader := NewEventReader(resp.Body)
+		for opts.Count == 0 || len(events) < opts.Count {
+			event, err := reader.Next()
+			if err != nil {
+				switch {
+				case hc.Err() != nil:
+					readErr = hc.Err()
+				case ctx.Err() == nil && err != io.EOF:
+					readErr = err
+				}
+				break
+			}
+			if len(events) == 0 {
+				firstEvent = time.Since(start)
+			}
+			events = append(events, event)
+		}
+
+		response, err := eventsResponse(resp, events)
+		if err != nil {
+			return nil, err
+		}
+		if readErr != nil {
+			return response, readErr
+		}
+		return response, opts.check(hc, events, firstEvent, ctx.Err() != nil, timeout)
+	}
+}
+
+// check checks the events received against the options. timedOut reports whether the reading was stopped
+// by the timeout rather than the count of events or the end of the stream.
+func (opts EventsOptions) check(hc interfaces.HookContext, events []Event, firstEvent time.Duration, timedOut bool, timeout time.Duration) error {
+	if opts.Count > 0 && len(events) < opts.Count {
+		if timedOut {
+			return fmt.Errorf("received %d of %d events within %s", len(events), opts.Count, timeout)
+		}
+		return fmt.Errorf("received %d of %d events before the end of the stream", len(events), opts.Count)
+	}
+
+	if opts.MaxTimeToFirstEvent > 0 {
+		if len(events) == 0 {
+			return errors.New("expected a first event, received none")
+		}
+		if firstEvent > opts.MaxTimeToFirstEvent {
+			return fmt.Errorf("expected the first event within %s, got it after %s", opts.MaxTimeToFirstEvent, firstEvent.Round(time.Millisecond))
+		}
+	}
+
+	if len(events) < len(opts.Expect) {
+		return fmt.Errorf("expected at least %d events, received %d", len(opts.Expect), len(events))
+	}
+	for i, expected := range opts.Expect {
+		if err := expected.check(hc, events[i]); err != nil {
+			return fmt.Errorf("event %d: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+func (ee EventExpectation) check(hc interfaces.HookContext, event Event) error {
+	if expected := hc.Expand(ee.Type); expected != "" && event.Type != expected {
+		return fmt.Errorf("expected type %q, got %q", expected, event.Type)
+	}
+	if expected := hc.Expand(ee.DataContains); expected != "" && !strings.Contains(event.Data, expected) {
+		return fmt.Errorf("expected data to contain %q, got %s", expected, event.Data)
+	}
+	if len(ee.Data) == 0 {
+		return nil
+	}
+
+	var data interface{}
+	if err := json.Unmarshal([]byte(event.Data), &data); err != nil {
+		return fmt.Errorf("data is not JSON: %s", event.Data)
+	}
+	expectations, err := jsonpath.NewExpectations(ee.Data)
+	if err != nil {
+		return fmt.Errorf("data %v", err)
+	}
+	if err := expectations.Check(data, hc.Expand); err != nil {
+		return fmt.Errorf("data: %v", err)
+	}
+	return nil
+}
+
+// eventsResponse returns the response of an exchange of events.
+func eventsResponse(resp *http.Response, events []Event) (interfaces.Response, error) {
+	type jsonEvent struct {
+		ID    string      `json:"id,omitempty"`
+		Type  string      `json:"event"`
+		Data  interface{} `json:"data"`
+		Retry int         `json:"retry,omitempty"`
+	}
+	list := make([]jsonEvent, len(events))
+	for i, event := range events {
+		var data interface{} = event.Data
+		if json.Valid([]byte(event.Data)) {
+			json.Unmarshal([]byte(event.Data), &data)
+		}
+		list[i] = jsonEvent{ID: event.ID, Type: event.Type, Data: data, Retry: event.Retry}
+	}
+	body, err := json.Marshal(list)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewStaticResponse(resp.StatusCode, headers(resp), body), nil
+}
+
+// DownloadOptions configures the streaming of a response body to a hash and a file, and the expectations on it.
+type DownloadOptions struct {
+	// File is the path the body is written to, which may reference the columns of the data row or the captured
+	// variables as {{name}}. Empty only hashes the body.
+	File string
+
+	// SHA256 is the expected hexadecimal SHA-256 digest of the body. Empty disables the check.
+	SHA256 string
+
+	// Size is the expected size of the body in bytes. Zero disables the check.
+	Size int64
+}
+
+// Download returns the exchange sending the request of the scenario and streaming the body of its response
+// to a SHA-256 hash and, optionally, a file, so that large bodies are never held in memory.
+//
+// The response of the exchange has the status and headers of the HTTP response and, as its body, the JSON
+// object of the size and SHA-256 digest of the body, e.g. {"size": 1024, "sha256": "9f86d0..."}, and the path
+// of the file. Responses without a successful status are read whole and returned as they are.
+func Download(opts DownloadOptions) interfaces.Exchange {
+	return func(hc interfaces.HookContext) (interfaces.Response, error) {
+		resp, err := send(hc, hc)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return whole(resp)
+		}
+
+		hash := sha256.New()
+		var w io.Writer = hash
+		var file *os.File
+		path := hc.Expand(opts.File)
+		if path != "" {
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return nil, err
+			}
+			if file, err = os.Create(path); err != nil {
+				return nil, err
+			}
+			w = io.MultiWriter(hash, file)
+		}
+		size, copyErr := io.Copy(w, resp.Body)
+		var closeErr error
+		if file != nil {
+			closeErr = file.Close()
+		}
+
+		summary := struct {
+			Size   int64  `json:"size"`
+			SHA256 string `json:"sha256"`
+			File   string `json:"file,omitempty"`
+		}{size, hex.EncodeToString(hash.Sum(nil)), path}
+		body, err := json.Marshal(summary)
+		if err != nil {
+			return nil, err
+		}
+		response := models.NewStaticResponse(resp.StatusCode, headers(resp), body)
+
+		switch {
+		case copyErr != nil:
+			return response, fmt.Errorf("download interrupted after %d bytes: %v", size, copyErr)
+		case closeErr != nil:
+			return response, fmt.Errorf("download not saved to %s: %v", path, closeErr)
+		case opts.Size > 0 && size != opts.Size:
+			return response, fmt.Errorf("expected a body of %d bytes, got %d", opts.Size, size)
+		case opts.SHA256 != "" && !strings.EqualFold(summary.SHA256, hc.Expand(opts.SHA256)):
+			return response, fmt.Errorf("expected a body with the SHA-256 digest %s, got %s", hc.Expand(opts.SHA256), summary.SHA256)
+		}
+		return response, nil
+	}
+}
+
+// send sends a copy of the request of the scenario with the HTTP client of its application, bound to ctx.
+// The timeout of the client does not apply, since it would cut the streams.
+func send(ctx context.Context, hc interfaces.HookContext) (*http.Response, error) {
+	req := hc.Request().Clone(ctx)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = body
+	}
+
+	client := http.DefaultClient
+	if route := hc.Scenario().GetParentRoute(); route != nil && route.GetParentApplication() != nil && route.GetParentApplication().GetHTTPClient() != nil {
+		client = route.GetParentApplication().GetHTTPClient()
+	}
+	streaming := *client
+	streaming.Timeout = 0
+	return streaming.Do(req)
+}
+
+// whole returns the response with its body read whole, up to maxErrorBody bytes.
+func whole(resp *http.Response) (interfaces.Response, error) {
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if err != nil {
+		return nil, err
+	}
+	return models.NewStaticResponse(resp.StatusCode, headers(resp), body), nil
+}
+
+func headers(resp *http.Response) map[string]string {
+	headers := make(map[string]string, len(resp.Header))
+	for key := range resp.Header {
+		headers[key] = resp.Header.Get(key)
+	}
+	return headers
+}
+
+/* Example usage -
+
+scenario.SetExchange(stream.Events(stream.EventsOptions{
+    Count:               3,
+    Timeout:             10 * time.Second,
+    MaxTimeToFirstEvent: 500 * time.Millisecond,
+    Expect: []stream.EventExpectation{
+        {Type: "status", Data: map[string]interface{}{"state": "queued"}},
+    },
+}))
+
+scenario.SetExchange(stream.Download(stream.DownloadOptions{File: "out/report.pdf", SHA256: "9f86d081884c7d65..."}))
+
+reader := stream.NewEventReader(resp.Body)
+for {
+    event, err := reader.Next()
+    if err != nil {
+        break
+    }
+    fmt.Println(event.Type, event.Data)
+}
+
+*/
@@ -0,0 +1,267 @@
+package stream
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qatoolist/RouTest/internal/interfaces"
+	"github.com/qatoolist/RouTest/internal/models"
+)
+
+// eventServer serves streams of Server-Sent Events, by path. The streams that do not end block until
+// the client goes away.
+func eventServer(w http.ResponseWriter, r *http.Request) {
+	event := func(format string, args ...interface{}) {
+		fmt.Fprintf(w, format, args...)
+		w.(http.Flusher).Flush()
+	}
+	block := func() { <-r.Context().Done() }
+
+	switch r.URL.Path {
+	case "/unavailable":
+		http.Error(w, `{"error": "unavailable"}`, http.StatusServiceUnavailable)
+		return
+	case "/json":
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"events": []}`)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+	switch r.URL.Path {
+	case "/jobs":
+		event("event: status\ndata: {\"job\": \"j-1\", \"state\": \"queued\"}\n\n")
+		event("id: 7\nevent: status\ndata: {\"job\": \"j-1\", \"state\": \"done\", \"progress\": 100}\n\n")
+		event("data: not JSON\n\n")
+		block()
+	case "/one":
+		event("data: 1\n\n")
+		block()
+	case "/one-and-end":
+		event("data: 1\n\n")
+	case "/empty":
+		event(": no events\n\n")
+	case "/ticks":
+		for i := 1; r.Context().Err() == nil; i++ {
+			event("data: %d\n\n", i)
+			time.Sleep(10 * time.Millisecond)
+		}
+	case "/slow":
+		time.Sleep(100 * time.Millisecond)
+		event("data: late\n\n")
+		block()
+	}
+}
+
+// TestEvents checks the reading of event streams until a count of events, a timeout or their end,
+// and the expectations on their events.
+func TestEvents(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(eventServer))
+	defer server.Close()
+
+	status := func(state string) EventExpectation {
+		return EventExpectation{Type: "status", Data: map[string]interface{}{"job": "{{job}}", "state": state}}
+	}
+	tests := []struct {
+		name       string
+		path       string
+		opts       EventsOptions
+		wantStatus int
+		wantBody   string
+		wantErr    string
+	}{
+		{
+			name: "count", path: "/jobs",
+			opts:     EventsOptions{Count: 2, Expect: []EventExpectation{status("queued"), status("done")}},
+			wantBody: `[{"event":"status","data":{"job":"j-1","state":"queued"}},{"id":"7","event":"status","data":{"job":"j-1","progress":100,"state":"done"}}]`,
+		},
+		{
+			name: "non-JSON data", path: "/jobs",
+			opts:     EventsOptions{Count: 3, Expect: []EventExpectation{{}, {Data: map[string]interface{}{"progress": 100}}, {DataContains: "not"}}},
+			wantBody: `[{"event":"status","data":{"job":"j-1","state":"queued"}},{"id":"7","event":"status","data":{"job":"j-1","progress":100,"state":"done"}},{"id":"7","event":"message","data":"not JSON"}]`,
+		},
+		{
+			name: "unexpected event", path: "/jobs",
+			opts:    EventsOptions{Count: 2, Expect: []EventExpectation{status("queued"), status("failed")}},
+			wantErr: `event 2: data: expected state to be "failed", got done`,
+		},
+		{
+			name: "count not reached within the timeout", path: "/one",
+			opts:     EventsOptions{Count: 3, Timeout: 50 * time.Millisecond},
+			wantBody: `[{"event":"message","data":1}]`,
+			wantErr:  "received 1 of 3 events within 50ms",
+		},
+		{
+			name: "count not reached before the end", path: "/one-and-end",
+			opts:    EventsOptions{Count: 3, Timeout: 5 * time.Second},
+			wantErr: "received 1 of 3 events before the end of the stream",
+		},
+		{
+			name: "timeout without count", path: "/ticks",
+			opts: EventsOptions{Timeout: 50 * time.Millisecond, Expect: []EventExpectation{{DataContains: "1"}, {DataContains: "2"}}},
+		},
+		{
+			name: "first event in time", path: "/slow",
+			opts: EventsOptions{Count: 1, MaxTimeToFirstEvent: 5 * time.Second},
+		},
+		{
+			name: "first event too late", path: "/slow",
+			opts:    EventsOptions{Count: 1, MaxTimeToFirstEvent: 20 * time.Millisecond},
+			wantErr: "expected the first event within 20ms",
+		},
+		{
+			name: "no first event", path: "/empty",
+			opts:     EventsOptions{MaxTimeToFirstEvent: time.Second},
+			wantBody: "[]",
+			wantErr:  "expected a first event, received none",
+		},
+		{
+			name: "error status", path: "/unavailable",
+			opts:       EventsOptions{Count: 1},
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   `{"error": "unavailable"}` + "\n",
+		},
+		{
+			name: "not an event stream", path: "/json",
+			opts:     EventsOptions{Count: 1},
+			wantBody: `{"events": []}`,
+			wantErr:  `expected a text/event-stream response, got "application/json"`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp, err := execute(t, server, test.path, Events(test.opts))
+			if test.wantErr == "" && err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)) {
+				t.Fatalf("expected the error %q, got %v", test.wantErr, err)
+			}
+			if resp == nil {
+				return
+			}
+			if want := test.wantStatus; want != 0 && resp.GetStatusCode() != want {
+				t.Errorf("expected the status %d, got %d", want, resp.GetStatusCode())
+			}
+			if test.wantBody != "" && resp.String() != test.wantBody {
+				t.Errorf("expected the body %s, got %s", test.wantBody, resp.String())
+			}
+		})
+	}
+}
+
+// TestDownload checks the streaming of a body to a hash and a file and the expectations on them.
+func TestDownload(t *testing.T) {
+	content := strings.Repeat("0123456789", 100000)
+	sum := sha256.Sum256([]byte(content))
+	digest := hex.EncodeToString(sum[:])
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, content)
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		path     string
+		opts     DownloadOptions
+		wantBody string
+		wantErr  string
+	}{
+		{
+			name:     "hash only",
+			path:     "/report",
+			opts:     DownloadOptions{SHA256: strings.ToUpper(digest), Size: int64(len(content))},
+			wantBody: fmt.Sprintf(`{"size":%d,"sha256":"%s"}`, len(content), digest),
+		},
+		{
+			name:     "file",
+			path:     "/report",
+			opts:     DownloadOptions{File: filepath.Join(dir, "{{job}}", "report.txt"), SHA256: "{{digest}}"},
+			wantBody: fmt.Sprintf(`{"size":%d,"sha256":"%s","file":%s}`, len(content), digest, strconv.Quote(filepath.Join(dir, "j-1", "report.txt"))),
+		},
+		{
+			name:    "size mismatch",
+			path:    "/report",
+			opts:    DownloadOptions{Size: 10},
+			wantErr: fmt.Sprintf("expected a body of 10 bytes, got %d", len(content)),
+		},
+		{
+			name:    "digest mismatch",
+			path:    "/report",
+			opts:    DownloadOptions{SHA256: "9f86d081884c7d65"},
+			wantErr: "expected a body with the SHA-256 digest 9f86d081884c7d65, got " + digest,
+		},
+		{
+			name:     "error status",
+			path:     "/missing",
+			opts:     DownloadOptions{File: filepath.Join(dir, "missing.txt"), Size: 10},
+			wantBody: "404 page not found\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp, err := execute(t, server, test.path, Download(test.opts))
+			if test.wantErr == "" && err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if test.wantErr != "" && (err == nil || err.Error() != test.wantErr) {
+				t.Fatalf("expected the error %q, got %v", test.wantErr, err)
+			}
+			if test.wantBody != "" && resp.String() != test.wantBody {
+				t.Errorf("expected the body %s, got %s", test.wantBody, resp.String())
+			}
+		})
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "j-1", "report.txt"))
+	if err != nil || string(data) != content {
+		t.Errorf("expected the body to be written to the expanded path, got %d bytes, %v", len(data), err)
+	}
+}
+
+// execute runs a scenario of a route with the given path and exchange against the server.
+// The variables job and digest are set on the application.
+func execute(t *testing.T, server *httptest.Server, path string, exchange interfaces.Exchange) (interfaces.Response, error) {
+	t.Helper()
+
+	host, portString, _ := net.SplitHostPort(server.Listener.Addr().String())
+	port, _ := strconv.Atoi(portString)
+	const testMeta = "automation_status: automated\nimportance: medium\n"
+	meta, _ := models.NewMetaFromString(testMeta)
+	app, err := models.NewApplication("", models.NewConfig(), models.NewRequirements(), meta, models.NewHost("http", host, port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.GetVariables().Set("job", "j-1")
+	sum := sha256.Sum256([]byte(strings.Repeat("0123456789", 100000)))
+	app.GetVariables().Set("digest", hex.EncodeToString(sum[:]))
+
+	info := &models.Info{}
+	info.SetName("stream")
+	info.SetPath(path)
+	info.SetMethod(models.Method{Name: http.MethodGet})
+	route := app.NewRoute(info, testMeta)
+	app.AddRoute("stream", &route)
+
+	scenarioInfo := &models.Info{}
+	scenarioInfo.SetName("example")
+	scenario := route.NewScenario(scenarioInfo, testMeta)
+	scenario.SetExchange(exchange)
+	return route.GetScenarioRegistry().Execute(context.Background(), scenario)
+}
@@ -0,0 +1,344 @@
+// Package websocket is a WebSocket client, as specified by RFC 6455, performing the scenarios of WebSocket routes
+// as steps: connecting with the request built for the scenario, sending frames, expecting messages and closing.
+package websocket
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// The opcodes of the frames.
+const (
+	OpContinuation = 0x0
+	OpText         = 0x1
+	OpBinary       = 0x2
+	OpClose        = 0x8
+	OpPing         = 0x9
+	OpPong         = 0xA
+)
+
+// CloseNormal is the status code of a normal closure.
+const CloseNormal = 1000
+
+// acceptGUID is appended to the key of the handshake to compute the accept key of the server.
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// maxMessageSize bounds the size of the messages read, so that a misbehaving server cannot exhaust memory.
+const maxMessageSize = 32 << 20
+
+// ErrClosed is returned when reading from a connection the server closed.
+var ErrClosed = errors.New("the connection was closed by the server")
+
+// Conn is a client WebSocket connection.
+type Conn struct {
+	conn net.Conn
+	r    *bufio.Reader
+
+	// CloseCode and CloseReason are the status code and reason of the close frame received from the server, if any.
+	CloseCode   int
+	CloseReason string
+}
+
+// Dial performs the opening handshake of a WebSocket connection with the request, whose scheme is ws, wss,
+// or http and https for the same connections. Its headers, e.g. Authorization or Sec-WebSocket-Protocol,
+// are sent with the handshake, and its context bounds it. tlsConfig configures wss connections, it may be nil.
+// When the server does not switch protocols, its response is returned with a nil connection and no error,
+// so that the refusal can be asserted on. The body of the response is read and closed in every case.
+func Dial(req *http.Request, tlsConfig *tls.Config) (*Conn, *http.Response, error) {
+	secure := false
+	switch req.URL.Scheme {
+	case "ws", "http":
+	case "wss", "https":
+		secure = true
+	default:
+		return nil, nil, fmt.Errorf("unsupported scheme %q for a WebSocket connection", req.URL.Scheme)
+	}
+
+	host := req.URL.Host
+	if req.URL.Port() == "" {
+		if secure {
+			host = net.JoinHostPort(req.URL.Hostname(), "443")
+		} else {
+			host = net.JoinHostPort(req.URL.Hostname(), "80")
+		}
+	}
+
+	ctx := req.Context()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if secure {
+		config := &tls.Config{}
+		if tlsConfig != nil {
+			config = tlsConfig.Clone()
+		}
+		if config.ServerName == "" {
+			config.ServerName = req.URL.Hostname()
+		}
+		tlsConn := tls.Client(conn, config)
+		if err := tlsConn.Handshake(); err != nil {
+			conn.Close()
+			return nil, nil, err
+		}
+		conn = tlsConn
+	}
+
+	r := bufio.NewReader(conn)
+	resp, err := handshake(conn, r, req, secure)
+	if err != nil || resp.StatusCode != http.StatusSwitchingProtocols {
+		conn.Close()
+		return nil, resp, err
+	}
+	conn.SetDeadline(time.Time{})
+	return &Conn{conn: conn, r: r}, resp, nil
+}
+
+// handshake sends the upgrade request on the connection and checks the response of the server, read from r.
+// The frames the server may send right after its response are left buffered in r.
+func handshake(conn net.Conn, r *bufio.Reader, req *http.Request, secure bool) (*http.Response, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	upgrade := req.Clone(req.Context())
+	upgrade.Method = http.MethodGet
+	upgrade.Body, upgrade.GetBody, upgrade.ContentLength = nil, nil, 0
+	upgrade.URL.Scheme = "http"
+	if secure {
+		upgrade.URL.Scheme = "https"
+	}
+	upgrade.Header.Set("Upgrade", "websocket")
+	upgrade.Header.Set("Connection", "Upgrade")
+	upgrade.Header.Set("Sec-WebSocket-Key", key)
+	upgrade.Header.Set("Sec-WebSocket-Version", "13")
+	upgrade.Header.Del("Content-Length")
+	if err := upgrade.Write(conn); err != nil {
+		return nil, err
+	}
+
+	resp, err := http.ReadResponse(r, upgrade)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		// The body is read so that it can be asserted on, before the connection is closed.
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxMessageSize))
+		resp.Body.Close()
+		resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+		return resp, nil
+	}
+	if !strings.EqualFold(resp.Header.Get("Upgrade"), "websocket") {
+		return resp, errors.New("the server switched to another protocol than websocket")
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		return resp, errors.New("the server answered the handshake with an invalid Sec-WebSocket-Accept")
+	}
+	resp.Body = http.NoBody
+	return resp, nil
+}
+
+// acceptKey returns the accept key the server answers to the key of the handshake.
+func acceptKey(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// SetDeadline sets the deadline of the reads and writes on the connection. The zero time clears it.
+func (c *Conn) SetDeadline(t time.Time) error {
+	return c.conn.SetDeadline(t)
+}
+
+// WriteMessage sends a message in a single frame.
+func (c *Conn) WriteMessage(opcode int, data []byte) error {
+	return writeFrame(c.conn, opcode, data, true)
+}
+
+// ReadMessage returns the next text or binary message, reassembled from its fragments.
+// Pings are answered while reading. When the server closes the connection the close is answered,
+// the connection is closed and ErrClosed is returned, with the status code and reason of the server in CloseCode and CloseReason.
+func (c *Conn) ReadMessage() (opcode int, data []byte, err error) {
+	for {
+		f, err := readFrame(c.r)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch f.opcode {
+		case OpPing:
+			if err := writeFrame(c.conn, OpPong, f.payload, true); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case OpPong:
+			continue
+		case OpClose:
+			c.CloseCode, c.CloseReason = closeStatus(f.payload)
+			writeFrame(c.conn, OpClose, f.payload[:min(len(f.payload), 2)], true)
+			c.conn.Close()
+			return 0, nil, ErrClosed
+		case OpContinuation:
+			if opcode == 0 {
+				return 0, nil, errors.New("unexpected continuation frame")
+			}
+		default:
+			if opcode != 0 {
+				return 0, nil, errors.New("new message before the end of the fragmented one")
+			}
+			opcode = f.opcode
+		}
+
+		data = append(data, f.payload...)
+		if len(data) > maxMessageSize {
+			return 0, nil, fmt.Errorf("message larger than %d bytes", maxMessageSize)
+		}
+		if f.fin {
+			if opcode == OpText && !utf8.Valid(data) {
+				return 0, nil, errors.New("text message is not valid UTF-8")
+			}
+			return opcode, data, nil
+		}
+	}
+}
+
+// Close sends a close frame with the status code and waits for the close frame of the server until the deadline,
+// before closing the connection.
+func (c *Conn) Close(code int, deadline time.Time) error {
+	payload := make([]byte, 2)
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	err := writeFrame(c.conn, OpClose, payload, true)
+	if err == nil {
+		c.conn.SetDeadline(deadline)
+		for {
+			f, readErr := readFrame(c.r)
+			if readErr != nil {
+				break
+			}
+			if f.opcode == OpClose {
+				c.CloseCode, c.CloseReason = closeStatus(f.payload)
+				break
+			}
+		}
+	}
+	if closeErr := c.conn.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func closeStatus(payload []byte) (int, string) {
+	if len(payload) < 2 {
+		return 1005, "" // no status received
+	}
+	return int(binary.BigEndian.Uint16(payload)), string(payload[2:])
+}
+
+// frame is a single WebSocket frame.
+type frame struct {
+	fin     bool
+	opcode  int
+	payload []byte
+}
+
+// writeFrame writes a final frame. Clients must mask the frames they send, servers must not.
+func writeFrame(w io.Writer, opcode int, payload []byte, mask bool) error {
+	header := []byte{0x80 | byte(opcode), 0}
+	switch n := len(payload); {
+	case n < 126:
+		header[1] = byte(n)
+	case n <= 0xFFFF:
+		header[1] = 126
+		header = append(header, 0, 0)
+		binary.BigEndian.PutUint16(header[2:], uint16(n))
+	default:
+		header[1] = 127
+		header = append(header, make([]byte, 8)...)
+		binary.BigEndian.PutUint64(header[2:], uint64(n))
+	}
+
+	data := payload
+	if mask {
+		header[1] |= 0x80
+		key := make([]byte, 4)
+		if _, err := rand.Read(key); err != nil {
+			return err
+		}
+		header = append(header, key...)
+		data = make([]byte, len(payload))
+		for i := range payload {
+			data[i] = payload[i] ^ key[i%4]
+		}
+	}
+	_, err := w.Write(append(header, data...))
+	return err
+}
+
+// readFrame reads a frame, unmasking its payload if it is masked.
+func readFrame(r io.Reader) (frame, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return frame{}, err
+	}
+	f := frame{fin: header[0]&0x80 != 0, opcode: int(header[0] & 0x0F)}
+	if header[0]&0x70 != 0 {
+		return frame{}, errors.New("frame with reserved bits set, no extension was negotiated")
+	}
+
+	n := uint64(header[1] & 0x7F)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return frame{}, err
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return frame{}, err
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	if n > maxMessageSize {
+		return frame{}, fmt.Errorf("frame larger than %d bytes", maxMessageSize)
+	}
+
+	var key [4]byte
+	masked := header[1]&0x80 != 0
+	if masked {
+		if _, err := io.ReadFull(r, key[:]); err != nil {
+			return frame{}, err
+		}
+	}
+	f.payload = make([]byte, n)
+	if _, err := io.ReadFull(r, f.payload); err != nil {
+		return frame{}, err
+	}
+	if masked {
+		for i := range f.payload {
+			f.payload[i] ^= key[i%4]
+		}
+	}
+	return f, nil
+}
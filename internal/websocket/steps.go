@@ -0,0 +1,274 @@
+package websocket
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/qatoolist/RouTest/internal/interfaces"
+	"github.com/qatoolist/RouTest/internal/jsonpath"
+	"github.com/qatoolist/RouTest/internal/models"
+)
+
+// The actions of the steps.
+const (
+	ActionConnect = "connect"
+	ActionSend    = "send"
+	ActionExpect  = "expect"
+	ActionClose   = "close"
+)
+
+// DefaultTimeout is the time an expect step waits for a matching message, and a close step for the close
+// of the server, when the step sets no timeout.
+const DefaultTimeout = 5 * time.Second
+
+// Step is a step of the scenario of a WebSocket route.
+type Step struct {
+	// Action is the action of the step: ActionConnect, ActionSend, ActionExpect or ActionClose.
+	Action string
+
+	// Message is the text message sent by a send step. It may reference the columns of the data row
+	// or the captured variables as {{name}}.
+	Message string
+
+	// Match are the values the message of an expect step must have, by JSONPath expression, e.g. $.type
+	// or type. Strings are compared with the text of the values, other values as JSON.
+	Match map[string]interface{}
+
+	// Contains is a string the message of an expect step must contain.
+	Contains string
+
+	// Timeout is the time an expect step waits for a matching message, or a close step for the close
+	// of the server. Zero defaults to DefaultTimeout.
+	Timeout time.Duration
+}
+
+// Exchange returns the exchange performing the steps over a WebSocket connection opened with the request
+// of the scenario, whose headers carry the parameters and credentials of the application, route and scenario.
+// The connection is opened before the first step unless it is a connect step, and closed after the last one
+// unless it is a close step. An expect step skips the messages that do not match until one does or its timeout
+// expires; the skipped messages are not seen by the following steps.
+//
+// The response of the exchange has the status and headers of the handshake and, as its body, the JSON array of
+// the messages received, JSON messages as their values and others as strings. When the server refuses the
+// upgrade, its response is returned and the steps are not performed, so that the refusal can be asserted on.
+func Exchange(steps []Step) interfaces.Exchange {
+	return func(hc interfaces.HookContext) (interfaces.Response, error) {
+		x := &exchange{hc: hc}
+		err := x.run(steps)
+		if x.refused != nil {
+			return x.refused, err
+		}
+		if x.handshake == nil {
+			return nil, err
+		}
+
+		headers := make(map[string]string)
+		for key := range x.handshake.Header {
+			headers[key] = x.handshake.Header.Get(key)
+		}
+		body, marshalErr := json.Marshal(x.received)
+		if err == nil {
+			err = marshalErr
+		}
+		return models.NewStaticResponse(x.handshake.StatusCode, headers, body), err
+	}
+}
+
+// exchange is the state of the steps of an execution.
+type exchange struct {
+	hc        interfaces.HookContext
+	conn      *Conn
+	handshake *http.Response
+	refused   interfaces.Response
+	received  []interface{}
+}
+
+func (x *exchange) run(steps []Step) error {
+	// The steps are numbered as given, the implied connect step being step 0.
+	first := 1
+	if len(steps) == 0 || steps[0].Action != ActionConnect {
+		steps = append([]Step{{Action: ActionConnect}}, steps...)
+		first = 0
+	}
+	defer func() {
+		if x.conn != nil {
+			x.conn.Close(CloseNormal, time.Now().Add(DefaultTimeout))
+		}
+	}()
+
+	for i, step := range steps {
+		if err := x.step(step); err != nil {
+			return fmt.Errorf("step %d (%s): %v", i+first, step.Action, err)
+		}
+		if x.refused != nil {
+			return nil
+		}
+	}
+	if x.conn != nil {
+		return x.close(DefaultTimeout)
+	}
+	return nil
+}
+
+func (x *exchange) step(step Step) error {
+	if step.Action != ActionConnect && x.conn == nil {
+		return errors.New("the connection is not open")
+	}
+	timeout := step.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	switch step.Action {
+	case ActionConnect:
+		if x.handshake != nil {
+			return errors.New("the connection is already open")
+		}
+		conn, resp, err := Dial(x.hc.Request().WithContext(x.hc), tlsConfig(x.hc.Scenario()))
+		if err != nil {
+			return err
+		}
+		if conn == nil {
+			refused, err := models.HandleResponse(resp)
+			if err != nil {
+				return err
+			}
+			x.refused = refused
+			return nil
+		}
+		x.conn, x.handshake = conn, resp
+		return nil
+
+	case ActionSend:
+		x.conn.SetDeadline(x.deadline(timeout))
+		return x.conn.WriteMessage(OpText, []byte(x.hc.Expand(step.Message)))
+
+	case ActionExpect:
+		return x.expect(step, timeout)
+
+	case ActionClose:
+		return x.close(timeout)
+	}
+	return fmt.Errorf("unknown action %q", step.Action)
+}
+
+// deadline returns the deadline of a step with the timeout, bounded by the deadline of the scenario.
+func (x *exchange) deadline(timeout time.Duration) time.Time {
+	deadline := time.Now().Add(timeout)
+	if d, ok := x.hc.Deadline(); ok && d.Before(deadline) {
+		return d
+	}
+	return deadline
+}
+
+func (x *exchange) close(timeout time.Duration) error {
+	err := x.conn.Close(CloseNormal, x.deadline(timeout))
+	x.conn = nil
+	return err
+}
+
+func (x *exchange) expect(step Step, timeout time.Duration) error {
+	match, err := newMatcher(step, x.hc)
+	if err != nil {
+		return err
+	}
+
+	x.conn.SetDeadline(x.deadline(timeout))
+	var mismatch error
+	for {
+		opcode, data, err := x.conn.ReadMessage()
+		if err != nil {
+			if errors.Is(err, ErrClosed) {
+				err = fmt.Errorf("%v with status %d %s", err, x.conn.CloseCode, x.conn.CloseReason)
+				x.conn = nil
+			} else if isTimeout(err) {
+				err = fmt.Errorf("no matching message within %s", timeout)
+			}
+			if mismatch != nil {
+				return fmt.Errorf("%v, the last one received: %v", err, mismatch)
+			}
+			return err
+		}
+
+		var message interface{} = string(data)
+		if opcode == OpBinary {
+			message = base64.StdEncoding.EncodeToString(data)
+		} else if json.Valid(data) {
+			json.Unmarshal(data, &message)
+		}
+		x.received = append(x.received, message)
+
+		if mismatch = match(string(data), message); mismatch == nil {
+			return nil
+		}
+	}
+}
+
+func isTimeout(err error) bool {
+	var timeout interface{ Timeout() bool }
+	return errors.As(err, &timeout) && timeout.Timeout()
+}
+
+// newMatcher returns the function checking a message against the conditions of the expect step,
+// given the raw message and its value.
+func newMatcher(step Step, hc interfaces.HookContext) (func(raw string, message interface{}) error, error) {
+	expectations, err := jsonpath.NewExpectations(step.Match)
+	if err != nil {
+		return nil, fmt.Errorf("match %v", err)
+	}
+	contains := hc.Expand(step.Contains)
+
+	return func(raw string, message interface{}) error {
+		if contains != "" && !strings.Contains(raw, contains) {
+			return fmt.Errorf("expected the message to contain %q", contains)
+		}
+		if err := expectations.Check(message, hc.Expand); err != nil {
+			return fmt.Errorf("message: %v", err)
+		}
+		return nil
+	}, nil
+}
+
+// tlsConfig returns the TLS configuration of the HTTP client of the application of the scenario, if it has one,
+// so that wss connections trust the same certificates as the HTTP requests.
+func tlsConfig(scenario interfaces.Scenario) *tls.Config {
+	route := scenario.GetParentRoute()
+	if route == nil || route.GetParentApplication() == nil {
+		return nil
+	}
+	client := route.GetParentApplication().GetHTTPClient()
+	if client == nil {
+		return nil
+	}
+	if transport, ok := client.Transport.(*http.Transport); ok {
+		return transport.TLSClientConfig
+	}
+	return nil
+}
+
+/* Example usage -
+
+scenario.SetExchange(websocket.Exchange([]websocket.Step{
+    {Action: websocket.ActionSend, Message: `{"type": "subscribe", "channel": "orders"}`},
+    {Action: websocket.ActionExpect, Match: map[string]interface{}{"type": "subscribed"}, Timeout: 2 * time.Second},
+    {Action: websocket.ActionClose},
+}))
+
+conn, resp, err := websocket.Dial(req, nil)
+if err != nil {
+    log.Fatal(err)
+}
+if conn == nil {
+    log.Fatalf("upgrade refused with status %d", resp.StatusCode)
+}
+conn.WriteMessage(websocket.OpText, []byte("hello"))
+_, message, err := conn.ReadMessage()
+conn.Close(websocket.CloseNormal, time.Now().Add(time.Second))
+
+*/
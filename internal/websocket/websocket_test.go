@@ -0,0 +1,112 @@
+package websocket
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qatoolist/RouTest/internal/interfaces"
+	"github.com/qatoolist/RouTest/internal/models"
+)
+
+// echoServer greets every client with a welcome message, then echoes its text messages until it closes.
+// Clients without an Authorization header are refused.
+func echoServer(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	conn, rw, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n",
+		acceptKey(r.Header.Get("Sec-WebSocket-Key")))
+	writeFrame(rw, OpText, []byte(`{"type": "welcome"}`), false)
+	rw.Flush()
+
+	for {
+		f, err := readFrame(rw)
+		if err != nil {
+			return
+		}
+		writeFrame(rw, f.opcode, f.payload, false)
+		rw.Flush()
+		if f.opcode == OpClose {
+			return
+		}
+	}
+}
+
+// TestExchange runs WebSocket scenarios against the local echo server.
+func TestExchange(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(echoServer))
+	defer server.Close()
+
+	host, portString, _ := net.SplitHostPort(server.Listener.Addr().String())
+	port, _ := strconv.Atoi(portString)
+	const testMeta = "automation_status: automated\nimportance: medium\n"
+	meta, _ := models.NewMetaFromString(testMeta)
+	app, err := models.NewApplication("", models.NewConfig(), models.NewRequirements(), meta, models.NewHost("http", host, port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.GetVariables().Set("channel", "orders")
+
+	info := &models.Info{}
+	info.SetName("notifications")
+	info.SetPath("/ws")
+	info.SetMethod(models.Method{Name: http.MethodGet})
+	route := app.NewRoute(info, testMeta)
+	app.AddRoute("notifications", &route)
+
+	scenario := func(name string, authorized bool, steps ...Step) {
+		info := &models.Info{}
+		info.SetName(name)
+		s := route.NewScenario(info, testMeta)
+		if authorized {
+			s.GetScenarioParametersRegistry().RegisterHeader("Authorization", "Bearer token")
+		}
+		s.SetExchange(Exchange(steps))
+	}
+	scenario("echo", true,
+		Step{Action: ActionSend, Message: `{"type": "subscribe", "channel": "{{channel}}"}`},
+		Step{Action: ActionExpect, Match: map[string]interface{}{"type": "subscribe", "$.channel": "{{channel}}"}},
+		Step{Action: ActionSend, Message: "ping"},
+		Step{Action: ActionExpect, Contains: "ping"},
+		Step{Action: ActionClose},
+	)
+	scenario("no match", true,
+		Step{Action: ActionExpect, Match: map[string]interface{}{"type": "never"}, Timeout: 200 * time.Millisecond},
+	)
+	scenario("unauthorized", false)
+
+	results := make(map[string]interfaces.ScenarioResult)
+	for _, result := range app.Run(context.Background()).Results() {
+		results[result.ScenarioName()] = result
+	}
+
+	if r := results["echo"]; r.Err() != nil {
+		t.Errorf("echo: %v", r.Err())
+	} else if r.Response().GetStatusCode() != http.StatusSwitchingProtocols {
+		t.Errorf("echo: expected status 101, got %d", r.Response().GetStatusCode())
+	} else if body := r.Response().String(); body != `[{"type":"welcome"},{"channel":"orders","type":"subscribe"},"ping"]` {
+		t.Errorf("echo: unexpected messages %s", body)
+	}
+
+	if r := results["no match"]; r.Err() == nil || !strings.Contains(r.Err().Error(), "no matching message within 200ms") {
+		t.Errorf("no match: expected a timeout, got %v", r.Err())
+	}
+
+	if r := results["unauthorized"]; r.Err() != nil || r.Response().GetStatusCode() != http.StatusUnauthorized {
+		t.Errorf("unauthorized: expected the refusal with status 401, got %v", r.Err())
+	}
+}